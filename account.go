@@ -0,0 +1,37 @@
+package thalex
+
+import (
+	"context"
+
+	"github.com/amiwrpremium/go-thalex/internal/account"
+)
+
+// WithAccount returns a copy of ctx that routes private REST requests to the
+// given account, overriding the client's configured account number.
+//
+//	summary, err := client.AccountSummary(thalex.WithAccount(ctx, "A123"))
+//
+// WebSocket sessions are bound to a single account at login; use
+// [github.com/amiwrpremium/go-thalex/ws.SessionManager] to select a
+// per-account connection from the context instead.
+func WithAccount(ctx context.Context, accountNumber string) context.Context {
+	return account.WithAccount(ctx, accountNumber)
+}
+
+// AccountFromContext returns the account override carried by ctx, if any.
+func AccountFromContext(ctx context.Context) (string, bool) {
+	return account.FromContext(ctx)
+}
+
+// FanOut calls fn concurrently once per account. Each call receives a context
+// carrying that account (see [WithAccount]), so REST calls made with it are
+// routed accordingly. Successful results are keyed by account; failures are
+// joined into the returned error, and the partial results are still returned.
+//
+//	summaries, err := thalex.FanOut(ctx, accounts,
+//	    func(ctx context.Context, _ string) (types.AccountSummary, error) {
+//	        return client.AccountSummary(ctx)
+//	    })
+func FanOut[T any](ctx context.Context, accounts []string, fn func(ctx context.Context, account string) (T, error)) (map[string]T, error) {
+	return account.FanOut(ctx, accounts, fn)
+}
@@ -0,0 +1,144 @@
+package apierr
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Sentinel errors for the common categories of Thalex API errors. Match them
+// with [errors.Is]:
+//
+//	if errors.Is(err, apierr.ErrInsufficientMargin) { ... }
+var (
+	// ErrInsufficientMargin reports that an order was rejected for lack of margin.
+	ErrInsufficientMargin = errors.New("thalex: insufficient margin")
+	// ErrOrderNotFound reports that the referenced order does not exist or is
+	// no longer open.
+	ErrOrderNotFound = errors.New("thalex: order not found")
+	// ErrRateLimited reports that the request was throttled.
+	ErrRateLimited = errors.New("thalex: rate limited")
+	// ErrPostOnlyRejected reports that a post-only order would have crossed
+	// the book.
+	ErrPostOnlyRejected = errors.New("thalex: post-only order rejected")
+	// ErrInstrumentNotTradeable reports an unknown, expired or halted instrument.
+	ErrInstrumentNotTradeable = errors.New("thalex: instrument not tradeable")
+	// ErrAuthFailed reports an authentication or permission failure. Every
+	// [AuthError] matches it.
+	ErrAuthFailed = errors.New("thalex: authentication failed")
+)
+
+// JSON-RPC error codes used by the Thalex API for malformed requests and
+// server faults.
+const (
+	CodeParseError     = -32700
+	CodeServerError    = -32000
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+)
+
+// protocolCodes are the JSON-RPC codes for malformed requests and server
+// faults. They say what went wrong, so an error with one of them is never
+// classified by its message.
+var protocolCodes = map[int]bool{
+	CodeParseError:     true,
+	CodeInvalidRequest: true,
+	CodeMethodNotFound: true,
+	CodeInternalError:  true,
+}
+
+// kindPhrases maps message phrases to sentinels, for API errors whose
+// status and code do not identify the category. A phrase must name the
+// condition on its own; phrases are matched case-insensitively, in order.
+var kindPhrases = []struct {
+	kind    error
+	phrases []string
+}{
+	{ErrRateLimited, []string{"rate limit exceeded", "too many requests"}},
+	{ErrAuthFailed, []string{"not logged in", "not authenticated", "invalid token"}},
+	{ErrInsufficientMargin, []string{"insufficient margin", "not enough margin"}},
+	{ErrPostOnlyRejected, []string{"post_only order would cross", "post-only order would cross"}},
+	{ErrOrderNotFound, []string{"order not found", "no such order", "order does not exist"}},
+	{ErrInstrumentNotTradeable, []string{"unknown instrument", "instrument not found", "instrument not tradeable", "instrument expired", "trading halted"}},
+}
+
+// Kind returns the sentinel error describing the category of e, or nil if
+// the error does not fall into one of the known categories. The HTTP
+// status of a REST error is decisive, and an error with a JSON-RPC
+// protocol code is never classified. Otherwise the message is matched
+// against a short list of phrases.
+func (e *APIError) Kind() error {
+	switch e.HTTPStatus {
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrAuthFailed
+	}
+	if protocolCodes[e.Code] {
+		return nil
+	}
+	msg := strings.ToLower(e.Message)
+	for _, p := range kindPhrases {
+		for _, f := range p.phrases {
+			if strings.Contains(msg, f) {
+				return p.kind
+			}
+		}
+	}
+	return nil
+}
+
+// IsTransient reports whether err is a temporary condition that is not caused
+// by the request itself: rate limiting, server faults, connection failures
+// and timeouts. A transient failure may still have left the request applied;
+// use [IsRetryable] to decide whether resending is safe.
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if IsRetryable(err) {
+		return true
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.HTTPStatus >= http.StatusInternalServerError || apiErr.Code == CodeInternalError
+	}
+	var connErr *ConnectionError
+	var timeoutErr *TimeoutError
+	if errors.As(err, &connErr) || errors.As(err, &timeoutErr) {
+		return true
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// IsRetryable reports whether the request that produced err certainly had no
+// effect and may be sent again unchanged: rate limiting, server faults
+// reported before processing, and connection failures that happened before
+// the request was written. Timeouts are transient but not retryable, because
+// the exchange may have processed the request.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		if errors.Is(apiErr, ErrRateLimited) {
+			return true
+		}
+		return apiErr.HTTPStatus == http.StatusBadGateway ||
+			apiErr.HTTPStatus == http.StatusServiceUnavailable
+	}
+	var connErr *ConnectionError
+	if errors.As(err, &connErr) {
+		return !connErr.Sent
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package apierr_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/amiwrpremium/go-thalex/apierr"
+)
+
+func TestAPIError_Kind(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *apierr.APIError
+		want error
+	}{
+		{"InsufficientMargin", &apierr.APIError{Code: 10010, Message: "Insufficient margin"}, apierr.ErrInsufficientMargin},
+		{"OrderNotFound", &apierr.APIError{Code: 1, Message: "order not found"}, apierr.ErrOrderNotFound},
+		{"RateLimitedMessage", &apierr.APIError{Code: 1, Message: "rate limit exceeded"}, apierr.ErrRateLimited},
+		{"RateLimitedStatus", &apierr.APIError{Code: 1, Message: "slow down", HTTPStatus: http.StatusTooManyRequests}, apierr.ErrRateLimited},
+		{"PostOnly", &apierr.APIError{Code: 1, Message: "post_only order would cross"}, apierr.ErrPostOnlyRejected},
+		{"NotTradeable", &apierr.APIError{Code: -32602, Message: "unknown instrument 'X'"}, apierr.ErrInstrumentNotTradeable},
+		{"AuthMessage", &apierr.APIError{Code: 1, Message: "not logged in"}, apierr.ErrAuthFailed},
+		{"AuthStatus", &apierr.APIError{Code: 1, Message: "nope", HTTPStatus: http.StatusUnauthorized}, apierr.ErrAuthFailed},
+		{"Unclassified", &apierr.APIError{Code: -32600, Message: "invalid request"}, nil},
+		// Words that merely appear in a message do not classify it.
+		{"ForbiddenWord", &apierr.APIError{Code: -32000, Message: "self-trade forbidden", HTTPStatus: http.StatusBadRequest}, nil},
+		{"PostOnlyParam", &apierr.APIError{Code: -32602, Message: "post_only: expected a boolean"}, nil},
+		// A protocol code says what went wrong.
+		{"ProtocolCode", &apierr.APIError{Code: apierr.CodeMethodNotFound, Message: "method not found: private/order_not_found"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Kind(); got != tt.want {
+				t.Errorf("Kind() = %v, want %v", got, tt.want)
+			}
+			if tt.want != nil && !errors.Is(fmt.Errorf("wrapped: %w", tt.err), tt.want) {
+				t.Errorf("errors.Is(wrapped, %v) = false", tt.want)
+			}
+		})
+	}
+}
+
+func TestAPIError_KindFromPayload(t *testing.T) {
+	tests := []struct {
+		payload string
+		want    error
+	}{
+		{`{"code": -32602, "message": "instrument_name: unknown instrument 'INVALID-PERP'"}`, apierr.ErrInstrumentNotTradeable},
+		{`{"code": -32000, "message": "insufficient margin"}`, apierr.ErrInsufficientMargin},
+		{`{"code": -32000, "message": "price outside collar"}`, nil},
+		{`{"code": -32601, "message": "method not found"}`, nil},
+	}
+	for _, tt := range tests {
+		var e apierr.APIError
+		if err := json.Unmarshal([]byte(tt.payload), &e); err != nil {
+			t.Fatal(err)
+		}
+		if got := e.Kind(); got != tt.want {
+			t.Errorf("%s: Kind() = %v, want %v", tt.payload, got, tt.want)
+		}
+	}
+}
+
+func TestAuthError_IsErrAuthFailed(t *testing.T) {
+	if !errors.Is(&apierr.AuthError{Message: "bad key"}, apierr.ErrAuthFailed) {
+		t.Error("AuthError should match ErrAuthFailed")
+	}
+}
+
+func TestIsRetryableAndTransient(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		wantRetryable bool
+		wantTransient bool
+	}{
+		{"Nil", nil, false, false},
+		{"RateLimited", &apierr.APIError{Message: "too many requests"}, true, true},
+		{"ServiceUnavailable", &apierr.APIError{HTTPStatus: http.StatusServiceUnavailable}, true, true},
+		{"InternalServerError", &apierr.APIError{HTTPStatus: http.StatusInternalServerError}, false, true},
+		{"RejectedOrder", &apierr.APIError{Message: "insufficient margin"}, false, false},
+		{"UnsentConnection", &apierr.ConnectionError{Message: "not connected"}, true, true},
+		{"SentConnection", &apierr.ConnectionError{Message: "closed", Sent: true}, false, true},
+		{"Timeout", &apierr.TimeoutError{Message: "call", Err: context.DeadlineExceeded}, false, true},
+		{"Deadline", context.DeadlineExceeded, false, true},
+		{"Canceled", context.Canceled, false, false},
+		{"Auth", &apierr.AuthError{Message: "bad key"}, false, false},
+		{"WrappedUnsent", fmt.Errorf("max retries exceeded: %w", &apierr.ConnectionError{}), true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := apierr.IsRetryable(tt.err); got != tt.wantRetryable {
+				t.Errorf("IsRetryable() = %v, want %v", got, tt.wantRetryable)
+			}
+			if got := apierr.IsTransient(tt.err); got != tt.wantTransient {
+				t.Errorf("IsTransient() = %v, want %v", got, tt.wantTransient)
+			}
+		})
+	}
+}
@@ -3,6 +3,11 @@
 // All error types implement the standard error interface.
 // [ConnectionError], [AuthError], and [TimeoutError] also implement
 // the Unwrap interface for use with [errors.As] and [errors.Is].
+//
+// The REST and WebSocket clients return the same types, so an [APIError]
+// can be matched against the sentinel errors such as [ErrOrderNotFound]
+// regardless of transport, and any error can be classified with
+// [IsRetryable] and [IsTransient].
 package apierr
 
 import (
@@ -10,12 +15,19 @@ import (
 	"fmt"
 )
 
+// ErrResponseTooLarge reports a REST response body larger than the
+// configured limit; see config.WithMaxResponseSize.
+var ErrResponseTooLarge = errors.New("thalex: response too large")
+
 // APIError represents an error returned by the Thalex API.
 type APIError struct {
 	// Code is the numeric error code from the API.
 	Code int `json:"code"`
 	// Message is the human-readable error description.
 	Message string `json:"message"`
+	// HTTPStatus is the HTTP status code of a REST response, or zero for
+	// errors received over WebSocket.
+	HTTPStatus int `json:"-"`
 }
 
 // Error implements the error interface.
@@ -23,12 +35,22 @@ func (e *APIError) Error() string {
 	return fmt.Sprintf("thalex: API error %d: %s", e.Code, e.Message)
 }
 
+// Is reports whether the error belongs to the category of a sentinel error
+// such as [ErrInsufficientMargin], so errors.Is(err, ErrOrderNotFound) works
+// for API errors from either transport.
+func (e *APIError) Is(target error) bool {
+	return target != nil && e.Kind() == target
+}
+
 // ConnectionError represents a connection-level error.
 type ConnectionError struct {
 	// Message describes the connection error.
 	Message string
 	// Err is the underlying error, if any.
 	Err error
+	// Sent reports whether the request was written before the connection
+	// failed. A sent request may have been processed by the exchange.
+	Sent bool
 }
 
 // Error implements the error interface.
@@ -65,6 +87,11 @@ func (e *AuthError) Unwrap() error {
 	return e.Err
 }
 
+// Is reports whether target is [ErrAuthFailed].
+func (e *AuthError) Is(target error) bool {
+	return target == ErrAuthFailed
+}
+
 // TimeoutError represents a request timeout error.
 type TimeoutError struct {
 	// Message describes what timed out.
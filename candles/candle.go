@@ -0,0 +1,147 @@
+// Package candles builds live OHLC candles from trade streams.
+//
+// A [Series] aggregates the trades of one instrument into candles at an
+// [enums.Resolution], from either the recent_trades channel or the trades
+// carried by book channel updates. Closed candles are reported to OnClose
+// callbacks, and historical candles can be stitched in front of the live
+// series with [Series.Backfill]. [Resample] converts a series to a coarser
+// resolution.
+//
+//	s, err := candles.NewSeries("BTC-PERPETUAL", enums.Resolution1m)
+//	s.OnClose(func(c candles.Candle) { fmt.Println(c.Time, c.Close, c.Volume) })
+//	wsClient.OnRecentTrades(types.RecentTradesChannel("BTC-PERPETUAL", enums.RecentTradesCategoryAll), s.AddRecentTrades)
+package candles
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/amiwrpremium/go-thalex/enums"
+	"github.com/amiwrpremium/go-thalex/types"
+)
+
+// ErrInvalidResolution is returned for resolutions that are not recognized
+// or cannot be converted between.
+var ErrInvalidResolution = errors.New("candles: invalid resolution")
+
+// weekOrigin is the Monday that weekly candles are aligned to.
+var weekOrigin = time.Date(1970, 1, 5, 0, 0, 0, 0, time.UTC)
+
+// Candle is one interval of trade activity. The embedded OHLC's Time is the
+// start of the interval.
+type Candle struct {
+	types.OHLC
+	// Volume is the total traded amount, BuyVolume and SellVolume the part
+	// traded by buying and selling takers.
+	Volume     float64 `json:"volume"`
+	BuyVolume  float64 `json:"buy_volume"`
+	SellVolume float64 `json:"sell_volume"`
+	// Trades is the number of trades in the interval.
+	Trades int `json:"trades"`
+}
+
+// Trade is a single trade fed into a Series.
+type Trade struct {
+	Time   types.Timestamp
+	Price  float64
+	Amount float64
+	// Direction is the taker's side.
+	Direction enums.Direction
+}
+
+// FromRecentTrade converts a recent_trades notification entry.
+func FromRecentTrade(t types.RecentTrade) Trade {
+	return Trade{Time: t.Time, Price: t.Price, Amount: t.Amount, Direction: t.Direction}
+}
+
+// FromBookTrade converts a trade carried by a book update.
+func FromBookTrade(t types.BookTrade) Trade {
+	return Trade{Time: t.Time, Price: t.Price, Amount: t.Amount, Direction: t.Direction}
+}
+
+// FromOHLC converts historical data points into candles without volume,
+// for use with Backfill.
+func FromOHLC(series []types.OHLC) []Candle {
+	out := make([]Candle, len(series))
+	for i, p := range series {
+		out[i] = Candle{OHLC: p}
+	}
+	return out
+}
+
+// Start returns the start of the interval at res that contains t. Weekly
+// intervals start on Monday 00:00 UTC; all others are aligned to the Unix
+// epoch.
+func Start(t time.Time, res enums.Resolution) time.Time {
+	d := res.Duration()
+	if d == 0 {
+		return t
+	}
+	if res == enums.Resolution1w {
+		return weekOrigin.Add(t.Sub(weekOrigin).Truncate(d))
+	}
+	return t.Truncate(d).UTC()
+}
+
+// startOf is Start for exchange timestamps.
+func startOf(ts types.Timestamp, res enums.Resolution) types.Timestamp {
+	return types.NewTimestamp(Start(ts.Time(), res))
+}
+
+// flat returns an interval without trades, priced at the previous close.
+func flat(start types.Timestamp, price float64) Candle {
+	return Candle{OHLC: types.OHLC{Time: start, Open: price, High: price, Low: price, Close: price}}
+}
+
+// Resample aggregates candles at resolution from into the coarser
+// resolution to. Candles must be in ascending time order. The first and
+// last output candles cover only the input they were built from.
+func Resample(candles []Candle, from, to enums.Resolution) ([]Candle, error) {
+	if err := checkResample(from, to); err != nil {
+		return nil, err
+	}
+	var out []Candle
+	for _, c := range candles {
+		start := startOf(c.Time, to)
+		if n := len(out); n > 0 && out[n-1].Time == start {
+			last := &out[n-1]
+			last.High = max(last.High, c.High)
+			last.Low = min(last.Low, c.Low)
+			last.Close = c.Close
+			last.Volume += c.Volume
+			last.BuyVolume += c.BuyVolume
+			last.SellVolume += c.SellVolume
+			last.Trades += c.Trades
+			continue
+		}
+		c.Time = start
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+// ResampleOHLC aggregates historical OHLC data points, such as the result
+// of IndexPriceHistoricalResult.Data, into a coarser resolution.
+func ResampleOHLC(series []types.OHLC, from, to enums.Resolution) ([]types.OHLC, error) {
+	candles, err := Resample(FromOHLC(series), from, to)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]types.OHLC, len(candles))
+	for i, c := range candles {
+		out[i] = c.OHLC
+	}
+	return out, nil
+}
+
+func checkResample(from, to enums.Resolution) error {
+	f, t := from.Duration(), to.Duration()
+	if f == 0 || t == 0 {
+		return fmt.Errorf("%w: %q to %q", ErrInvalidResolution, from, to)
+	}
+	if t < f || t%f != 0 {
+		return fmt.Errorf("%w: %s is not a multiple of %s", ErrInvalidResolution, to, from)
+	}
+	return nil
+}
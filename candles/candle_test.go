@@ -0,0 +1,67 @@
+package candles_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/amiwrpremium/go-thalex/candles"
+	"github.com/amiwrpremium/go-thalex/enums"
+	"github.com/amiwrpremium/go-thalex/types"
+)
+
+func TestStart(t *testing.T) {
+	ts := time.Date(2025, 3, 13, 14, 47, 31, 0, time.UTC) // a Thursday
+	tests := []struct {
+		res  enums.Resolution
+		want time.Time
+	}{
+		{enums.Resolution1m, time.Date(2025, 3, 13, 14, 47, 0, 0, time.UTC)},
+		{enums.Resolution15m, time.Date(2025, 3, 13, 14, 45, 0, 0, time.UTC)},
+		{enums.Resolution1h, time.Date(2025, 3, 13, 14, 0, 0, 0, time.UTC)},
+		{enums.Resolution1d, time.Date(2025, 3, 13, 0, 0, 0, 0, time.UTC)},
+		{enums.Resolution1w, time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		if got := candles.Start(ts, tt.res); !got.Equal(tt.want) {
+			t.Errorf("Start(%s) = %v, want %v", tt.res, got, tt.want)
+		}
+	}
+}
+
+func TestResample(t *testing.T) {
+	in := []candles.Candle{
+		{OHLC: types.OHLC{Time: at(0), Open: 10, High: 12, Low: 9, Close: 11}, Volume: 1, BuyVolume: 1, Trades: 1},
+		{OHLC: types.OHLC{Time: at(time.Minute), Open: 11, High: 15, Low: 11, Close: 14}, Volume: 2, SellVolume: 2, Trades: 3},
+		{OHLC: types.OHLC{Time: at(4 * time.Minute), Open: 14, High: 14, Low: 8, Close: 9}, Volume: 1, BuyVolume: 1, Trades: 1},
+		{OHLC: types.OHLC{Time: at(5 * time.Minute), Open: 9, High: 10, Low: 9, Close: 10}},
+	}
+	got, err := candles.Resample(in, enums.Resolution1m, enums.Resolution5m)
+	if err != nil {
+		t.Fatalf("Resample error: %v", err)
+	}
+	want := []candles.Candle{
+		{OHLC: types.OHLC{Time: at(0), Open: 10, High: 15, Low: 8, Close: 9}, Volume: 4, BuyVolume: 2, SellVolume: 2, Trades: 5},
+		{OHLC: types.OHLC{Time: at(5 * time.Minute), Open: 9, High: 10, Low: 9, Close: 10}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Resample = %+v, want %+v", got, want)
+	}
+
+	ohlc, err := candles.ResampleOHLC([]types.OHLC{in[0].OHLC, in[1].OHLC}, enums.Resolution1m, enums.Resolution1h)
+	if err != nil || len(ohlc) != 1 || ohlc[0].High != 15 || ohlc[0].Close != 14 {
+		t.Errorf("ResampleOHLC = %+v, %v", ohlc, err)
+	}
+}
+
+func TestResample_InvalidResolutions(t *testing.T) {
+	for _, tc := range [][2]enums.Resolution{
+		{enums.Resolution1h, enums.Resolution1m},
+		{enums.Resolution1m, "2h"},
+	} {
+		if _, err := candles.Resample(nil, tc[0], tc[1]); !errors.Is(err, candles.ErrInvalidResolution) {
+			t.Errorf("Resample(%s -> %s) err = %v, want ErrInvalidResolution", tc[0], tc[1], err)
+		}
+	}
+}
@@ -0,0 +1,330 @@
+package candles
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/amiwrpremium/go-thalex/enums"
+	"github.com/amiwrpremium/go-thalex/types"
+)
+
+// Option configures a Series.
+type Option func(*Series)
+
+// WithGrace keeps a candle open for d after its interval ends, so trades
+// delivered late still count toward it. WebSocket notifications are
+// dispatched concurrently and may arrive slightly out of order.
+func WithGrace(d time.Duration) Option {
+	return func(s *Series) { s.grace = d }
+}
+
+// WithMaxCandles bounds the number of closed candles kept, dropping the
+// oldest. The default of 0 keeps every candle.
+func WithMaxCandles(n int) Option {
+	return func(s *Series) { s.maxLen = n }
+}
+
+// Series aggregates the trades of one instrument into candles. It is safe
+// for concurrent use.
+//
+// A candle closes once a trade or Advance call is at least one grace
+// period past its end. Intervals without trades close as flat candles at
+// the previous close, so the series has no gaps. Trades older than the
+// last closed candle are dropped and counted by Late.
+type Series struct {
+	instrument string
+	res        enums.Resolution
+	step       time.Duration
+	grace      time.Duration
+	maxLen     int
+
+	// emitMu serialises updates so OnClose callbacks see candles in order.
+	// It is held while callbacks run, so they may read from the series but
+	// must not feed it.
+	emitMu sync.Mutex
+
+	mu        sync.RWMutex
+	closed    []Candle
+	open      map[types.Timestamp]*openCandle
+	next      time.Time // start of the next interval to close; zero before the first trade
+	watermark time.Time
+	last      float64 // close of the last closed candle
+	late      int
+	onClose   []func(Candle)
+}
+
+// openCandle tracks the trade times behind Open and Close, since trades
+// may arrive out of order.
+type openCandle struct {
+	Candle
+	first, latest types.Timestamp
+}
+
+// NewSeries returns an empty series for instrument at res. An empty
+// instrument accepts trades of every instrument.
+func NewSeries(instrument string, res enums.Resolution, opts ...Option) (*Series, error) {
+	step := res.Duration()
+	if step == 0 {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidResolution, res)
+	}
+	s := &Series{
+		instrument: instrument,
+		res:        res,
+		step:       step,
+		open:       make(map[types.Timestamp]*openCandle),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s, nil
+}
+
+// Instrument returns the instrument the series aggregates.
+func (s *Series) Instrument() string { return s.instrument }
+
+// Resolution returns the candle resolution.
+func (s *Series) Resolution() enums.Resolution { return s.res }
+
+// OnClose registers a callback for every candle closed by live data,
+// including flat candles for intervals without trades. Candles added by
+// Backfill are not reported.
+func (s *Series) OnClose(fn func(Candle)) {
+	s.mu.Lock()
+	s.onClose = append(s.onClose, fn)
+	s.mu.Unlock()
+}
+
+// AddTrade adds one trade. It reports false if the trade was late.
+func (s *Series) AddTrade(t Trade) bool {
+	var ok bool
+	s.update(func() { ok = s.add(t) })
+	return ok
+}
+
+// AddRecentTrades adds the trades of a recent_trades notification that
+// belong to the series' instrument. It has the signature of a
+// ws.Client.OnRecentTrades handler.
+func (s *Series) AddRecentTrades(trades []types.RecentTrade) {
+	s.update(func() {
+		for _, t := range trades {
+			if s.instrument == "" || t.InstrumentName == s.instrument {
+				s.add(FromRecentTrade(t))
+			}
+		}
+	})
+}
+
+// AddBookUpdate adds the trades carried by a book update. It has the
+// signature of a ws.Client.OnBook handler.
+func (s *Series) AddBookUpdate(u types.BookUpdate) {
+	if len(u.Trades) == 0 {
+		return
+	}
+	s.update(func() {
+		for _, t := range u.Trades {
+			s.add(FromBookTrade(t))
+		}
+	})
+}
+
+// Advance closes every candle that ended at least one grace period before
+// now. Call it from a ticker to close candles when no trades arrive.
+func (s *Series) Advance(now time.Time) {
+	s.update(func() { s.advance(now) })
+}
+
+// update runs fn under the write lock, then reports the candles it closed.
+func (s *Series) update(fn func()) {
+	s.emitMu.Lock()
+	defer s.emitMu.Unlock()
+
+	s.mu.Lock()
+	n := len(s.closed)
+	fn()
+	var emitted []Candle
+	if len(s.closed) > n {
+		emitted = slices.Clone(s.closed[n:])
+	}
+	s.trim()
+	handlers := s.onClose
+	s.mu.Unlock()
+
+	for _, c := range emitted {
+		for _, h := range handlers {
+			h(c)
+		}
+	}
+}
+
+// add records t and closes the candles it moves past. s.mu must be held.
+func (s *Series) add(t Trade) bool {
+	tt := t.Time.Time()
+	start := Start(tt, s.res)
+	if s.next.IsZero() {
+		s.next = start
+	}
+	if start.Before(s.next) {
+		s.late++
+		return false
+	}
+
+	key := types.NewTimestamp(start)
+	c, ok := s.open[key]
+	if !ok {
+		c = &openCandle{
+			Candle: Candle{OHLC: types.OHLC{Time: key, Open: t.Price, High: t.Price, Low: t.Price, Close: t.Price}},
+			first:  t.Time, latest: t.Time,
+		}
+		s.open[key] = c
+	}
+	c.High = max(c.High, t.Price)
+	c.Low = min(c.Low, t.Price)
+	if t.Time < c.first {
+		c.first, c.Open = t.Time, t.Price
+	}
+	if t.Time >= c.latest {
+		c.latest, c.Close = t.Time, t.Price
+	}
+	c.Volume += t.Amount
+	switch t.Direction {
+	case enums.DirectionBuy:
+		c.BuyVolume += t.Amount
+	case enums.DirectionSell:
+		c.SellVolume += t.Amount
+	}
+	c.Trades++
+
+	s.advance(tt)
+	return true
+}
+
+// advance closes intervals that ended a grace period before now. s.mu
+// must be held.
+func (s *Series) advance(now time.Time) {
+	if now.After(s.watermark) {
+		s.watermark = now
+	}
+	if s.next.IsZero() {
+		return
+	}
+	for !s.next.Add(s.step + s.grace).After(s.watermark) {
+		key := types.NewTimestamp(s.next)
+		if c, ok := s.open[key]; ok {
+			delete(s.open, key)
+			s.closed = append(s.closed, c.Candle)
+			s.last = c.Close
+		} else {
+			s.closed = append(s.closed, flat(key, s.last))
+		}
+		s.next = s.next.Add(s.step)
+	}
+}
+
+// trim drops the oldest candles beyond maxLen. s.mu must be held.
+func (s *Series) trim() {
+	if s.maxLen > 0 && len(s.closed) > s.maxLen {
+		s.closed = slices.Delete(s.closed, 0, len(s.closed)-s.maxLen)
+	}
+}
+
+// Backfill stitches historical candles, such as converted
+// MarkPriceHistoricalData or IndexPriceHistoricalData results at the same
+// resolution, in front of the live series. Candles at or after the start
+// of the live series are ignored, so live data always wins and repeated
+// backfills add nothing twice. Missing intervals are filled with flat
+// candles at the previous close.
+func (s *Series) Backfill(history []Candle) error {
+	history = slices.Clone(history)
+	for _, c := range history {
+		if start := startOf(c.Time, s.res); start != c.Time {
+			return fmt.Errorf("candles: backfill candle at %s is not aligned to %s", c.Time, s.res)
+		}
+	}
+	slices.SortStableFunc(history, func(a, b Candle) int { return cmp.Compare(a.Time, b.Time) })
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	first, live := s.start()
+	var merged []Candle
+	for _, c := range history {
+		if live && !c.Time.Time().Before(first) {
+			break
+		}
+		n := len(merged)
+		if n > 0 && merged[n-1].Time == c.Time {
+			merged[n-1] = c
+			continue
+		}
+		if n > 0 {
+			merged = s.fill(merged, c.Time.Time())
+		}
+		merged = append(merged, c)
+	}
+	if len(merged) == 0 {
+		return nil
+	}
+	if live {
+		merged = s.fill(merged, first)
+		s.closed = append(merged, s.closed...)
+	} else {
+		s.closed = merged
+		s.last = merged[len(merged)-1].Close
+		s.next = merged[len(merged)-1].Time.Time().Add(s.step)
+	}
+	s.trim()
+	return nil
+}
+
+// start returns the start of the earliest candle in the series. s.mu must
+// be held.
+func (s *Series) start() (time.Time, bool) {
+	if len(s.closed) > 0 {
+		return s.closed[0].Time.Time(), true
+	}
+	return s.next, !s.next.IsZero()
+}
+
+// fill appends flat candles to series up to, but excluding, the interval
+// starting at end.
+func (s *Series) fill(series []Candle, end time.Time) []Candle {
+	last := series[len(series)-1]
+	for t := last.Time.Time().Add(s.step); t.Before(end); t = t.Add(s.step) {
+		series = append(series, flat(types.NewTimestamp(t), last.Close))
+	}
+	return series
+}
+
+// Candles returns the closed candles, oldest first.
+func (s *Series) Candles() []Candle {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Clone(s.closed)
+}
+
+// Current returns the latest candle that has trades but has not closed.
+func (s *Series) Current() (Candle, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var cur *openCandle
+	for _, c := range s.open {
+		if cur == nil || c.Time > cur.Time {
+			cur = c
+		}
+	}
+	if cur == nil {
+		return Candle{}, false
+	}
+	return cur.Candle, true
+}
+
+// Late returns the number of trades dropped because their candle had
+// already closed.
+func (s *Series) Late() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.late
+}
@@ -0,0 +1,198 @@
+package candles_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/amiwrpremium/go-thalex/candles"
+	"github.com/amiwrpremium/go-thalex/enums"
+	"github.com/amiwrpremium/go-thalex/types"
+)
+
+// base is a minute boundary.
+var base = time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+func at(d time.Duration) types.Timestamp { return types.NewTimestamp(base.Add(d)) }
+
+func trade(d time.Duration, price, amount float64, dir enums.Direction) candles.Trade {
+	return candles.Trade{Time: at(d), Price: price, Amount: amount, Direction: dir}
+}
+
+func newSeries(t *testing.T, opts ...candles.Option) *candles.Series {
+	t.Helper()
+	s, err := candles.NewSeries("BTC-PERPETUAL", enums.Resolution1m, opts...)
+	if err != nil {
+		t.Fatalf("NewSeries error: %v", err)
+	}
+	return s
+}
+
+func times(cs []candles.Candle) []types.Timestamp {
+	out := make([]types.Timestamp, len(cs))
+	for i, c := range cs {
+		out[i] = c.Time
+	}
+	return out
+}
+
+func TestNewSeries_InvalidResolution(t *testing.T) {
+	if _, err := candles.NewSeries("X", "2h"); !errors.Is(err, candles.ErrInvalidResolution) {
+		t.Errorf("err = %v, want ErrInvalidResolution", err)
+	}
+}
+
+func TestSeries_Aggregates(t *testing.T) {
+	s := newSeries(t)
+	var closed []candles.Candle
+	s.OnClose(func(c candles.Candle) { closed = append(closed, c) })
+
+	s.AddTrade(trade(5*time.Second, 100, 1, enums.DirectionBuy))
+	s.AddTrade(trade(30*time.Second, 104, 2, enums.DirectionSell))
+	// Arrives out of order: earliest trade, so it sets the open.
+	s.AddTrade(trade(1*time.Second, 101, 1, enums.DirectionBuy))
+	s.AddTrade(trade(50*time.Second, 98, 0.5, enums.DirectionSell))
+
+	cur, ok := s.Current()
+	if !ok || cur.Open != 101 || cur.Close != 98 || cur.High != 104 || cur.Low != 98 {
+		t.Fatalf("Current = %+v, %v", cur, ok)
+	}
+	if len(closed) != 0 {
+		t.Fatalf("closed early: %+v", closed)
+	}
+
+	s.AddTrade(trade(3*time.Minute+time.Second, 99, 1, enums.DirectionBuy))
+	if len(closed) != 3 {
+		t.Fatalf("closed %d candles, want 3 (one with trades, two flat)", len(closed))
+	}
+	want := candles.Candle{
+		OHLC:   types.OHLC{Time: at(0), Open: 101, High: 104, Low: 98, Close: 98},
+		Volume: 4.5, BuyVolume: 2, SellVolume: 2.5, Trades: 4,
+	}
+	if closed[0] != want {
+		t.Errorf("closed[0] = %+v, want %+v", closed[0], want)
+	}
+	gap := candles.Candle{OHLC: types.OHLC{Time: at(time.Minute), Open: 98, High: 98, Low: 98, Close: 98}}
+	if closed[1] != gap {
+		t.Errorf("closed[1] = %+v, want flat %+v", closed[1], gap)
+	}
+	if !reflect.DeepEqual(s.Candles(), closed) {
+		t.Error("Candles() differs from closed callbacks")
+	}
+
+	if s.AddTrade(trade(2*time.Minute, 97, 1, enums.DirectionBuy)) {
+		t.Error("trade for a closed candle accepted")
+	}
+	if s.Late() != 1 {
+		t.Errorf("Late = %d, want 1", s.Late())
+	}
+}
+
+func TestSeries_GraceAndAdvance(t *testing.T) {
+	s := newSeries(t, candles.WithGrace(2*time.Second))
+	s.AddTrade(trade(59*time.Second, 100, 1, enums.DirectionBuy))
+	s.AddTrade(trade(time.Minute+time.Second, 101, 1, enums.DirectionBuy))
+	if len(s.Candles()) != 0 {
+		t.Fatal("candle closed within grace period")
+	}
+	// Delivered late, but within the grace period.
+	if !s.AddTrade(trade(59500*time.Millisecond, 102, 1, enums.DirectionSell)) {
+		t.Fatal("late trade within grace dropped")
+	}
+	s.Advance(base.Add(time.Minute + 2*time.Second))
+	got := s.Candles()
+	if len(got) != 1 || got[0].Close != 102 || got[0].Trades != 2 {
+		t.Fatalf("Candles = %+v", got)
+	}
+	s.Advance(base.Add(3*time.Minute + 2*time.Second))
+	if got := times(s.Candles()); !reflect.DeepEqual(got, []types.Timestamp{at(0), at(time.Minute), at(2 * time.Minute)}) {
+		t.Errorf("times = %v", got)
+	}
+}
+
+func TestSeries_RecentTradesAndBookUpdates(t *testing.T) {
+	s := newSeries(t)
+	s.AddRecentTrades([]types.RecentTrade{
+		{InstrumentName: "BTC-PERPETUAL", Price: 100, Amount: 1, Direction: enums.DirectionBuy, Time: at(time.Second)},
+		{InstrumentName: "ETH-PERPETUAL", Price: 5, Amount: 9, Direction: enums.DirectionBuy, Time: at(2 * time.Second)},
+	})
+	s.AddBookUpdate(types.BookUpdate{Trades: []types.BookTrade{
+		{Direction: enums.DirectionSell, Price: 99, Amount: 2, Time: at(3 * time.Second)},
+	}})
+	cur, _ := s.Current()
+	if cur.Trades != 2 || cur.Volume != 3 || cur.SellVolume != 2 {
+		t.Errorf("Current = %+v", cur)
+	}
+}
+
+func TestSeries_MaxCandles(t *testing.T) {
+	s := newSeries(t, candles.WithMaxCandles(2))
+	s.AddTrade(trade(0, 100, 1, enums.DirectionBuy))
+	s.Advance(base.Add(5 * time.Minute))
+	if got := times(s.Candles()); !reflect.DeepEqual(got, []types.Timestamp{at(3 * time.Minute), at(4 * time.Minute)}) {
+		t.Errorf("times = %v", got)
+	}
+}
+
+func TestSeries_Backfill(t *testing.T) {
+	s := newSeries(t)
+	s.AddTrade(trade(5*time.Minute, 100, 1, enums.DirectionBuy))
+	s.Advance(base.Add(7 * time.Minute))
+
+	history := candles.FromOHLC([]types.OHLC{
+		{Time: at(4 * time.Minute), Open: 1, High: 1, Low: 1, Close: 1},
+		{Time: at(time.Minute), Open: 90, High: 92, Low: 89, Close: 91},
+		{Time: at(2 * time.Minute), Open: 91, High: 91, Low: 91, Close: 91},
+		{Time: at(5 * time.Minute), Open: 1, High: 1, Low: 1, Close: 1},
+	})
+	if err := s.Backfill(history); err != nil {
+		t.Fatalf("Backfill error: %v", err)
+	}
+	got := s.Candles()
+	want := []types.Timestamp{at(time.Minute), at(2 * time.Minute), at(3 * time.Minute), at(4 * time.Minute), at(5 * time.Minute), at(6 * time.Minute)}
+	if !reflect.DeepEqual(times(got), want) {
+		t.Fatalf("times = %v, want %v", times(got), want)
+	}
+	if got[2].Close != 91 || got[2].Volume != 0 {
+		t.Errorf("gap candle = %+v, want flat at 91", got[2])
+	}
+	if got[3].Close != 1 {
+		t.Errorf("history candle before live start = %+v", got[3])
+	}
+	if got[4].Close != 100 || got[4].Volume != 1 {
+		t.Errorf("live candle overwritten: %+v", got[4])
+	}
+
+	// Backfilling again adds nothing twice.
+	if err := s.Backfill(history); err != nil {
+		t.Fatalf("second Backfill error: %v", err)
+	}
+	if len(s.Candles()) != len(want) {
+		t.Errorf("second Backfill duplicated candles: %v", times(s.Candles()))
+	}
+
+	misaligned := []candles.Candle{{OHLC: types.OHLC{Time: at(30 * time.Second)}}}
+	if err := s.Backfill(misaligned); err == nil {
+		t.Error("expected error for misaligned candle")
+	}
+}
+
+func TestSeries_BackfillBeforeLive(t *testing.T) {
+	s := newSeries(t)
+	err := s.Backfill(candles.FromOHLC([]types.OHLC{
+		{Time: at(0), Open: 10, High: 10, Low: 10, Close: 10},
+		{Time: at(time.Minute), Open: 10, High: 11, Low: 10, Close: 11},
+	}))
+	if err != nil {
+		t.Fatalf("Backfill error: %v", err)
+	}
+	if s.AddTrade(trade(90*time.Second, 12, 1, enums.DirectionBuy)) {
+		t.Error("trade inside a backfilled candle accepted")
+	}
+	s.AddTrade(trade(3*time.Minute, 12, 1, enums.DirectionBuy))
+	got := s.Candles()
+	if len(got) != 3 || got[2].Time != at(2*time.Minute) || got[2].Close != 11 {
+		t.Errorf("Candles = %+v, want a flat 12:02 candle at 11", got)
+	}
+}
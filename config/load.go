@@ -0,0 +1,536 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amiwrpremium/go-thalex/auth"
+)
+
+// EnvPrefix is the prefix of every environment variable read by [Load].
+const EnvPrefix = "THALEX_"
+
+// EnvConfigFile names the environment variable [Load] consults for a config
+// file path when none is given.
+const EnvConfigFile = EnvPrefix + "CONFIG_FILE"
+
+// FieldError describes a configuration value rejected by [Load].
+type FieldError struct {
+	// Key is the configuration key, e.g. "ws_ping_interval".
+	Key string
+	// Source names where the value came from, e.g. "env THALEX_NETWORK".
+	Source string
+	// Err describes the problem.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *FieldError) Error() string {
+	if e.Source == "" {
+		return fmt.Sprintf("config: %s: %v", e.Key, e.Err)
+	}
+	return fmt.Sprintf("config: %s (from %s): %v", e.Key, e.Source, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// loadKeys lists every key understood by Load. Environment variables are the
+// key upper-cased with EnvPrefix, e.g. ws_ping_interval is read from
+// THALEX_WS_PING_INTERVAL.
+var loadKeys = []string{
+	"network",
+	"rest_url",
+	"ws_url",
+	"ws_failover_urls",
+	"key_id",
+	"pem_path",
+	"account_number",
+	"user_agent",
+	"max_retries",
+	"retry_base_wait",
+	"ws_dial_timeout",
+	"ws_ping_interval",
+	"ws_reconnect",
+	"ws_max_reconnects",
+	"ws_reconnect_wait",
+}
+
+// loadValue is a raw configuration value and where it came from.
+type loadValue struct {
+	raw    any
+	source string
+}
+
+// Load reads client configuration from a file and the environment and returns
+// options ready to pass to rest.NewClient or ws.NewClient.
+//
+// The file may be JSON or a TOML-style list of "key = value" lines; the format
+// is chosen by extension (.json, .toml) or, failing that, by content. Nested
+// JSON objects and TOML [sections] prefix their keys, so ping_interval inside
+// a "ws" section is read as ws_ping_interval. If path is empty, the file named
+// by THALEX_CONFIG_FILE is read, if set.
+//
+// Environment variables override file values. Each key maps to an upper-cased
+// variable with the THALEX_ prefix:
+//
+//	THALEX_NETWORK            production | testnet
+//	THALEX_REST_URL           custom REST base URL
+//	THALEX_WS_URL             custom WebSocket URL
+//	THALEX_WS_FAILOVER_URLS   comma-separated WebSocket failover URLs
+//	THALEX_KEY_ID             API key ID
+//	THALEX_PEM_PATH           path to the PEM-encoded private key
+//	THALEX_ACCOUNT_NUMBER     default account number
+//	THALEX_USER_AGENT         user agent
+//	THALEX_MAX_RETRIES        REST retry attempts
+//	THALEX_RETRY_BASE_WAIT    REST retry backoff, e.g. "500ms"
+//	THALEX_WS_DIAL_TIMEOUT    WebSocket dial timeout
+//	THALEX_WS_PING_INTERVAL   WebSocket ping interval
+//	THALEX_WS_RECONNECT       true | false
+//	THALEX_WS_MAX_RECONNECTS  reconnection attempts
+//	THALEX_WS_RECONNECT_WAIT  reconnection backoff
+//
+// Durations are Go duration strings, or numbers of seconds in a file. Every
+// value is validated and all problems are reported together as [FieldError]s
+// joined with [errors.Join]; no options are returned in that case.
+func Load(path string) ([]ClientOption, error) {
+	var errs []error
+	values := make(map[string]loadValue)
+
+	if path == "" {
+		path = os.Getenv(EnvConfigFile)
+	}
+	if path != "" {
+		errs = append(errs, readConfigFile(path, values)...)
+	}
+	for _, key := range loadKeys {
+		name := EnvPrefix + strings.ToUpper(key)
+		if v, ok := os.LookupEnv(name); ok {
+			values[key] = loadValue{raw: v, source: "env " + name}
+		}
+	}
+
+	opts, convErrs := buildOptions(values)
+	errs = append(errs, convErrs...)
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return opts, nil
+}
+
+func readConfigFile(path string, values map[string]loadValue) []error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return []error{fmt.Errorf("config: reading %s: %w", path, err)}
+	}
+	source := "file " + path
+
+	var raw map[string]any
+	var errs []error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		raw, err = parseJSONConfig(data)
+	case ".toml":
+		raw, errs = parseTOMLConfig(data)
+	default:
+		if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+			raw, err = parseJSONConfig(data)
+		} else {
+			raw, errs = parseTOMLConfig(data)
+		}
+	}
+	if err != nil {
+		return []error{fmt.Errorf("config: parsing %s: %w", path, err)}
+	}
+	for i := range errs {
+		errs[i] = fmt.Errorf("config: parsing %s: %w", path, errs[i])
+	}
+
+	known := make(map[string]bool, len(loadKeys))
+	for _, k := range loadKeys {
+		known[k] = true
+	}
+	keys := make([]string, 0, len(raw))
+	for k := range raw {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if !known[k] {
+			errs = append(errs, &FieldError{Key: k, Source: source, Err: errors.New("unknown key")})
+			continue
+		}
+		values[k] = loadValue{raw: raw[k], source: source}
+	}
+	return errs
+}
+
+func parseJSONConfig(data []byte) (map[string]any, error) {
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	out := make(map[string]any)
+	flattenJSON("", doc, out)
+	return out, nil
+}
+
+func flattenJSON(prefix string, doc map[string]any, out map[string]any) {
+	for k, v := range doc {
+		key := k
+		if prefix != "" {
+			key = prefix + "_" + k
+		}
+		if nested, ok := v.(map[string]any); ok {
+			flattenJSON(key, nested, out)
+			continue
+		}
+		out[key] = v
+	}
+}
+
+// parseTOMLConfig parses the flat subset of TOML used for client settings:
+// comments, [section] headers, and key = value pairs whose values are
+// quoted strings, numbers, booleans or single-line arrays of strings.
+func parseTOMLConfig(data []byte) (map[string]any, []error) {
+	out := make(map[string]any)
+	var errs []error
+	section := ""
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(stripTOMLComment(scanner.Text()))
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		k, v, ok := strings.Cut(line, "=")
+		if !ok {
+			errs = append(errs, fmt.Errorf("line %d: expected key = value", lineNo))
+			continue
+		}
+		key := strings.TrimSpace(k)
+		if section != "" {
+			key = section + "_" + key
+		}
+		val, err := parseTOMLValue(strings.TrimSpace(v))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %s: %w", lineNo, key, err))
+			continue
+		}
+		out[key] = val
+	}
+	if err := scanner.Err(); err != nil {
+		errs = append(errs, err)
+	}
+	return out, errs
+}
+
+func stripTOMLComment(line string) string {
+	inString := false
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			if inString {
+				i++
+			}
+		case '"':
+			inString = !inString
+		case '#':
+			if !inString {
+				return line[:i]
+			}
+		}
+	}
+	return line
+}
+
+func parseTOMLValue(s string) (any, error) {
+	switch {
+	case s == "":
+		return nil, errors.New("missing value")
+	case s == "true":
+		return true, nil
+	case s == "false":
+		return false, nil
+	case strings.HasPrefix(s, `"`):
+		return strconv.Unquote(s)
+	case strings.HasPrefix(s, "["):
+		if !strings.HasSuffix(s, "]") {
+			return nil, errors.New("unterminated array")
+		}
+		body := strings.TrimSpace(s[1 : len(s)-1])
+		items := []any{}
+		if body == "" {
+			return items, nil
+		}
+		for _, part := range strings.Split(body, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			item, err := strconv.Unquote(part)
+			if err != nil {
+				return nil, fmt.Errorf("array items must be quoted strings: %s", part)
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	default:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %s", s)
+		}
+		return f, nil
+	}
+}
+
+// buildOptions validates the collected values and converts them to options.
+func buildOptions(values map[string]loadValue) ([]ClientOption, []error) {
+	var (
+		opts []ClientOption
+		errs []error
+	)
+	fail := func(key string, err error) {
+		errs = append(errs, &FieldError{Key: key, Source: values[key].source, Err: err})
+	}
+	str := func(key string) (string, bool) {
+		v, ok := values[key]
+		if !ok {
+			return "", false
+		}
+		s, ok := v.raw.(string)
+		if !ok {
+			fail(key, fmt.Errorf("must be a string, got %v", v.raw))
+			return "", false
+		}
+		return s, true
+	}
+	nonNegInt := func(key string, apply func(int) ClientOption) {
+		v, ok := values[key]
+		if !ok {
+			return
+		}
+		n, err := toInt(v.raw)
+		if err == nil && n < 0 {
+			err = fmt.Errorf("must not be negative, got %d", n)
+		}
+		if err != nil {
+			fail(key, err)
+			return
+		}
+		opts = append(opts, apply(n))
+	}
+	duration := func(key string, apply func(time.Duration) ClientOption) {
+		v, ok := values[key]
+		if !ok {
+			return
+		}
+		d, err := toDuration(v.raw)
+		if err == nil && d < 0 {
+			err = fmt.Errorf("must not be negative, got %s", d)
+		}
+		if err != nil {
+			fail(key, err)
+			return
+		}
+		opts = append(opts, apply(d))
+	}
+
+	if s, ok := str("network"); ok {
+		switch strings.ToLower(s) {
+		case Production.String():
+			opts = append(opts, WithNetwork(Production))
+		case Testnet.String():
+			opts = append(opts, WithNetwork(Testnet))
+		default:
+			fail("network", fmt.Errorf("unknown network %q (want %q or %q)", s, Production, Testnet))
+		}
+	}
+
+	var endpoint Endpoint
+	if s, ok := str("rest_url"); ok {
+		if err := checkURL(s, "http", "https"); err != nil {
+			fail("rest_url", err)
+		} else {
+			endpoint.BaseURL = s
+		}
+	}
+	if s, ok := str("ws_url"); ok {
+		if err := checkURL(s, "ws", "wss"); err != nil {
+			fail("ws_url", err)
+		} else {
+			endpoint.WebSocketURL = s
+		}
+	}
+	if endpoint.BaseURL != "" || endpoint.WebSocketURL != "" {
+		endpoint.Name = "config"
+		opts = append(opts, WithEndpoint(endpoint))
+	}
+
+	if v, ok := values["ws_failover_urls"]; ok {
+		urls, err := toStrings(v.raw)
+		var failovers []Endpoint
+		for _, u := range urls {
+			if err == nil {
+				err = checkURL(u, "ws", "wss")
+			}
+			failovers = append(failovers, Endpoint{WebSocketURL: u})
+		}
+		if err != nil {
+			fail("ws_failover_urls", err)
+		} else {
+			opts = append(opts, WithWSFailoverEndpoints(failovers...))
+		}
+	}
+
+	keyID, hasKeyID := str("key_id")
+	pemPath, hasPEM := str("pem_path")
+	switch {
+	case hasKeyID && !hasPEM:
+		fail("pem_path", errors.New("required when key_id is set"))
+	case hasPEM && !hasKeyID:
+		fail("key_id", errors.New("required when pem_path is set"))
+	case hasKeyID && hasPEM:
+		pemData, err := os.ReadFile(pemPath)
+		if err != nil {
+			fail("pem_path", fmt.Errorf("reading key: %w", err))
+			break
+		}
+		creds, err := auth.NewCredentialsFromPEM(keyID, pemData)
+		if err != nil {
+			fail("pem_path", err)
+			break
+		}
+		opts = append(opts, WithCredentials(creds))
+	}
+
+	if s, ok := str("account_number"); ok {
+		opts = append(opts, WithAccountNumber(s))
+	}
+	if s, ok := str("user_agent"); ok {
+		if s == "" {
+			fail("user_agent", errors.New("must not be empty"))
+		} else {
+			opts = append(opts, WithUserAgent(s))
+		}
+	}
+
+	nonNegInt("max_retries", WithMaxRetries)
+	duration("retry_base_wait", WithRetryBaseWait)
+	duration("ws_dial_timeout", WithWSDialTimeout)
+	duration("ws_ping_interval", WithWSPingInterval)
+	if v, ok := values["ws_reconnect"]; ok {
+		b, err := toBool(v.raw)
+		if err != nil {
+			fail("ws_reconnect", err)
+		} else {
+			opts = append(opts, WithWSReconnect(b))
+		}
+	}
+	nonNegInt("ws_max_reconnects", WithWSMaxReconnects)
+	duration("ws_reconnect_wait", WithWSReconnectWait)
+
+	return opts, errs
+}
+
+func checkURL(s string, schemes ...string) error {
+	u, err := url.Parse(s)
+	if err != nil {
+		return err
+	}
+	for _, scheme := range schemes {
+		if u.Scheme == scheme && u.Host != "" {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid URL %q (want scheme %s)", s, strings.Join(schemes, " or "))
+}
+
+func toInt(v any) (int, error) {
+	switch n := v.(type) {
+	case float64:
+		if n != math.Trunc(n) {
+			return 0, fmt.Errorf("must be an integer, got %v", n)
+		}
+		return int(n), nil
+	case string:
+		i, err := strconv.Atoi(strings.TrimSpace(n))
+		if err != nil {
+			return 0, fmt.Errorf("must be an integer, got %q", n)
+		}
+		return i, nil
+	default:
+		return 0, fmt.Errorf("must be an integer, got %v", v)
+	}
+}
+
+func toDuration(v any) (time.Duration, error) {
+	switch d := v.(type) {
+	case float64:
+		return time.Duration(d * float64(time.Second)), nil
+	case string:
+		parsed, err := time.ParseDuration(strings.TrimSpace(d))
+		if err != nil {
+			return 0, fmt.Errorf("must be a duration such as \"5s\", got %q", d)
+		}
+		return parsed, nil
+	default:
+		return 0, fmt.Errorf("must be a duration, got %v", v)
+	}
+}
+
+func toBool(v any) (bool, error) {
+	switch b := v.(type) {
+	case bool:
+		return b, nil
+	case string:
+		parsed, err := strconv.ParseBool(strings.TrimSpace(b))
+		if err != nil {
+			return false, fmt.Errorf("must be true or false, got %q", b)
+		}
+		return parsed, nil
+	default:
+		return false, fmt.Errorf("must be true or false, got %v", v)
+	}
+}
+
+func toStrings(v any) ([]string, error) {
+	switch s := v.(type) {
+	case string:
+		var out []string
+		for _, part := range strings.Split(s, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				out = append(out, part)
+			}
+		}
+		return out, nil
+	case []any:
+		out := make([]string, 0, len(s))
+		for _, item := range s {
+			str, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("must be a list of strings, got %v", item)
+			}
+			out = append(out, str)
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("must be a list of strings, got %v", v)
+	}
+}
@@ -0,0 +1,251 @@
+package config_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amiwrpremium/go-thalex/config"
+)
+
+func writeTestFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	return path
+}
+
+func writeTestKey(t *testing.T) string {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	data := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	return writeTestFile(t, "key.pem", string(data))
+}
+
+func applyLoaded(t *testing.T, path string) config.ClientConfig {
+	t.Helper()
+	opts, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	cfg := config.DefaultClientConfig()
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+func TestLoad_TOML(t *testing.T) {
+	keyPath := writeTestKey(t)
+	path := writeTestFile(t, "thalex.toml", `
+# trading bot
+network = "testnet"
+key_id = "K1"
+pem_path = "`+keyPath+`"
+account_number = "ACC-1" # inline comment
+max_retries = 5
+retry_base_wait = "250ms"
+
+[ws]
+url = "wss://ws.example.com/ws/api/v2"
+failover_urls = ["wss://backup.example.com/ws/api/v2"]
+ping_interval = 3
+reconnect = false
+max_reconnects = 7
+`)
+	cfg := applyLoaded(t, path)
+
+	if cfg.Network != config.Testnet {
+		t.Errorf("Network = %v, want Testnet", cfg.Network)
+	}
+	if cfg.Credentials == nil || cfg.Credentials.KeyID != "K1" {
+		t.Errorf("Credentials = %+v, want key ID K1", cfg.Credentials)
+	}
+	if cfg.AccountNumber != "ACC-1" {
+		t.Errorf("AccountNumber = %q, want ACC-1", cfg.AccountNumber)
+	}
+	if cfg.MaxRetries != 5 || cfg.RetryBaseWait != 250*time.Millisecond {
+		t.Errorf("retry = %d/%v, want 5/250ms", cfg.MaxRetries, cfg.RetryBaseWait)
+	}
+	if cfg.WSPingInterval != 3*time.Second {
+		t.Errorf("WSPingInterval = %v, want 3s", cfg.WSPingInterval)
+	}
+	if cfg.WSReconnect || cfg.WSMaxReconnects != 7 {
+		t.Errorf("reconnect = %v/%d, want false/7", cfg.WSReconnect, cfg.WSMaxReconnects)
+	}
+	urls := wsURLs(cfg)
+	if len(urls) != 2 || urls[0] != "wss://ws.example.com/ws/api/v2" || urls[1] != "wss://backup.example.com/ws/api/v2" {
+		t.Errorf("WebSocketEndpoints() = %v", urls)
+	}
+	if cfg.BaseURL() != config.Testnet.BaseURL() {
+		t.Errorf("BaseURL() = %q, want network default", cfg.BaseURL())
+	}
+}
+
+func TestLoad_JSON(t *testing.T) {
+	path := writeTestFile(t, "thalex.json", `{
+		"network": "production",
+		"rest_url": "https://rest.example.com/api/v2",
+		"user_agent": "bot/1.0",
+		"ws": {"dial_timeout": "2s", "reconnect_wait": 0.5}
+	}`)
+	cfg := applyLoaded(t, path)
+
+	if cfg.BaseURL() != "https://rest.example.com/api/v2" {
+		t.Errorf("BaseURL() = %q", cfg.BaseURL())
+	}
+	if cfg.UserAgent != "bot/1.0" {
+		t.Errorf("UserAgent = %q, want bot/1.0", cfg.UserAgent)
+	}
+	if cfg.WSDialTimeout != 2*time.Second || cfg.WSReconnectWait != 500*time.Millisecond {
+		t.Errorf("ws durations = %v/%v, want 2s/500ms", cfg.WSDialTimeout, cfg.WSReconnectWait)
+	}
+}
+
+func TestLoad_EnvOverridesFile(t *testing.T) {
+	path := writeTestFile(t, "thalex.toml", "network = \"production\"\nmax_retries = 1\n")
+	t.Setenv("THALEX_CONFIG_FILE", path)
+	t.Setenv("THALEX_NETWORK", "testnet")
+	t.Setenv("THALEX_WS_FAILOVER_URLS", "wss://a.example.com, wss://b.example.com")
+	t.Setenv("THALEX_WS_RECONNECT", "false")
+
+	cfg := applyLoaded(t, "")
+
+	if cfg.Network != config.Testnet {
+		t.Errorf("Network = %v, want env override Testnet", cfg.Network)
+	}
+	if cfg.MaxRetries != 1 {
+		t.Errorf("MaxRetries = %d, want 1 from file", cfg.MaxRetries)
+	}
+	if cfg.WSReconnect {
+		t.Error("WSReconnect should be false from env")
+	}
+	if got := wsURLs(cfg); len(got) != 3 {
+		t.Errorf("WebSocketEndpoints() = %v, want primary plus two failovers", got)
+	}
+}
+
+func TestLoad_NoSources(t *testing.T) {
+	opts, err := config.Load("")
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	if len(opts) != 0 {
+		t.Errorf("got %d options, want 0", len(opts))
+	}
+}
+
+func TestLoad_ReportsEveryProblem(t *testing.T) {
+	path := writeTestFile(t, "thalex.toml", `
+network = "mainnet"
+retry_base_wait = "-1s"
+ws_max_reconnects = -2
+ws_url = "https://not-a-websocket"
+key_id = "K1"
+pem_path = "/does/not/exist.pem"
+colour = "blue"
+`)
+	t.Setenv("THALEX_WS_RECONNECT", "maybe")
+
+	opts, err := config.Load(path)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if opts != nil {
+		t.Errorf("options = %v, want nil on error", opts)
+	}
+
+	want := map[string]bool{
+		"network":           false,
+		"retry_base_wait":   false,
+		"ws_max_reconnects": false,
+		"ws_url":            false,
+		"pem_path":          false,
+		"colour":            false,
+		"ws_reconnect":      false,
+	}
+	for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var fe *config.FieldError
+		if !errors.As(e, &fe) {
+			t.Errorf("unexpected error type %T: %v", e, e)
+			continue
+		}
+		if _, ok := want[fe.Key]; !ok {
+			t.Errorf("unexpected field error: %v", fe)
+			continue
+		}
+		want[fe.Key] = true
+	}
+	for key, seen := range want {
+		if !seen {
+			t.Errorf("missing error for %s in: %v", key, err)
+		}
+	}
+	if !strings.Contains(err.Error(), "env THALEX_WS_RECONNECT") {
+		t.Errorf("error should name the env source: %v", err)
+	}
+}
+
+func TestLoad_CredentialsNeedBothKeys(t *testing.T) {
+	t.Setenv("THALEX_KEY_ID", "K1")
+
+	_, err := config.Load("")
+	var fe *config.FieldError
+	if !errors.As(err, &fe) || fe.Key != "pem_path" {
+		t.Fatalf("expected pem_path field error, got %v", err)
+	}
+}
+
+func TestLoad_InvalidKeyFile(t *testing.T) {
+	t.Setenv("THALEX_KEY_ID", "K1")
+	t.Setenv("THALEX_PEM_PATH", writeTestFile(t, "bad.pem", "not a key"))
+
+	_, err := config.Load("")
+	var fe *config.FieldError
+	if !errors.As(err, &fe) || fe.Key != "pem_path" {
+		t.Fatalf("expected pem_path field error, got %v", err)
+	}
+}
+
+func TestLoad_FileErrors(t *testing.T) {
+	t.Run("missing file", func(t *testing.T) {
+		if _, err := config.Load(filepath.Join(t.TempDir(), "nope.toml")); err == nil {
+			t.Fatal("expected error for missing file")
+		}
+	})
+
+	t.Run("bad JSON", func(t *testing.T) {
+		if _, err := config.Load(writeTestFile(t, "c.json", "{")); err == nil {
+			t.Fatal("expected error for malformed JSON")
+		}
+	})
+
+	t.Run("bad TOML line", func(t *testing.T) {
+		_, err := config.Load(writeTestFile(t, "c.toml", "network\nmax_retries = [1]\n"))
+		if err == nil {
+			t.Fatal("expected error for malformed TOML")
+		}
+		if !strings.Contains(err.Error(), "line 1") || !strings.Contains(err.Error(), "line 2") {
+			t.Errorf("error should report both lines: %v", err)
+		}
+	})
+
+	t.Run("sniffs JSON without extension", func(t *testing.T) {
+		cfg := applyLoaded(t, writeTestFile(t, "thalexrc", `{"network":"testnet"}`))
+		if cfg.Network != config.Testnet {
+			t.Errorf("Network = %v, want Testnet", cfg.Network)
+		}
+	})
+}
@@ -49,3 +49,16 @@ func (n Network) String() string {
 		return "production"
 	}
 }
+
+// Endpoint describes a custom API location, such as a local stand-in,
+// a recording proxy or a colocated gateway.
+//
+// An empty BaseURL or WebSocketURL falls back to the configured [Network].
+type Endpoint struct {
+	// Name is an optional label used in connection errors.
+	Name string
+	// BaseURL is the REST API base URL, e.g. "http://localhost:8080/api/v2".
+	BaseURL string
+	// WebSocketURL is the WebSocket API URL, e.g. "ws://localhost:8080/ws/api/v2".
+	WebSocketURL string
+}
@@ -1,8 +1,10 @@
 package config
 
 import (
+	"crypto/tls"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/amiwrpremium/go-thalex/auth"
@@ -23,6 +25,64 @@ type ClientConfig struct {
 	WSReconnectWait time.Duration
 	AccountNumber   string
 	UserAgent       string
+
+	// Endpoint overrides the Network URLs when set.
+	Endpoint *Endpoint
+	// WSFailoverEndpoints are tried in order after the primary WebSocket
+	// endpoint fails, both on Connect and by the reconnector, wrapping
+	// around to the primary.
+	WSFailoverEndpoints []Endpoint
+	// WSTLSConfig is passed to the WebSocket dialer.
+	WSTLSConfig *tls.Config
+	// WSProxy selects an HTTP CONNECT proxy for the WebSocket dialer.
+	WSProxy func(*http.Request) (*url.URL, error)
+	// WSHeaders are extra HTTP headers sent with the WebSocket handshake.
+	WSHeaders http.Header
+	// WSStrictDecoding reports notifications with unknown fields or invalid
+	// enum values as decode errors, while still delivering them.
+	WSStrictDecoding bool
+	// PreTradeCheck, when set, is called with the params of every order,
+	// amend, mass quote, conditional order and bot creation before it is
+	// sent. A non-nil error aborts the request. The check may modify the
+	// params, e.g. to round prices to the tick.
+	PreTradeCheck func(params any) error
+	// MaxResponseBytes limits the size of a REST response body. Zero uses
+	// the default of 64 MiB; a negative value disables the limit.
+	MaxResponseBytes int64
+}
+
+// BaseURL returns the REST API base URL, honouring a custom Endpoint.
+func (c ClientConfig) BaseURL() string {
+	if c.Endpoint != nil && c.Endpoint.BaseURL != "" {
+		return c.Endpoint.BaseURL
+	}
+	return c.Network.BaseURL()
+}
+
+// BaseURLName returns the name of the endpoint BaseURL points at: the
+// custom Endpoint's Name, or the network name.
+func (c ClientConfig) BaseURLName() string {
+	if c.Endpoint != nil && c.Endpoint.BaseURL != "" {
+		return c.Endpoint.Name
+	}
+	return c.Network.String()
+}
+
+// WebSocketEndpoints returns the primary WebSocket endpoint followed by the
+// failover endpoints that have a WebSocket URL, in order. The primary is
+// named after the network unless a custom Endpoint supplies its URL.
+func (c ClientConfig) WebSocketEndpoints() []Endpoint {
+	primary := Endpoint{Name: c.Network.String(), WebSocketURL: c.Network.WebSocketURL()}
+	if c.Endpoint != nil && c.Endpoint.WebSocketURL != "" {
+		primary = Endpoint{Name: c.Endpoint.Name, WebSocketURL: c.Endpoint.WebSocketURL}
+	}
+	endpoints := []Endpoint{primary}
+	for _, e := range c.WSFailoverEndpoints {
+		if e.WebSocketURL != "" {
+			endpoints = append(endpoints, e)
+		}
+	}
+	return endpoints
 }
 
 // DefaultClientConfig returns sensible defaults.
@@ -108,3 +168,53 @@ func WithAccountNumber(accountNumber string) ClientOption {
 func WithUserAgent(ua string) ClientOption {
 	return func(c *ClientConfig) { c.UserAgent = ua }
 }
+
+// WithMaxResponseSize limits the size of a REST response body; larger
+// responses fail with apierr.ErrResponseTooLarge. The default is 64 MiB; a
+// negative n disables the limit.
+func WithMaxResponseSize(n int64) ClientOption {
+	return func(c *ClientConfig) { c.MaxResponseBytes = n }
+}
+
+// WithEndpoint points the clients at a custom endpoint instead of the
+// built-in Network URLs.
+func WithEndpoint(e Endpoint) ClientOption {
+	return func(c *ClientConfig) { c.Endpoint = &e }
+}
+
+// WithWSFailoverEndpoints sets the ordered list of endpoints the WebSocket
+// client rotates through when the current endpoint is unreachable, on the
+// first Connect as well as when reconnecting.
+func WithWSFailoverEndpoints(endpoints ...Endpoint) ClientOption {
+	return func(c *ClientConfig) { c.WSFailoverEndpoints = endpoints }
+}
+
+// WithWSTLSConfig sets the TLS configuration used by the WebSocket dialer.
+func WithWSTLSConfig(tlsConfig *tls.Config) ClientOption {
+	return func(c *ClientConfig) { c.WSTLSConfig = tlsConfig }
+}
+
+// WithWSProxy routes WebSocket connections through an HTTP CONNECT proxy.
+func WithWSProxy(proxyURL *url.URL) ClientOption {
+	return func(c *ClientConfig) { c.WSProxy = http.ProxyURL(proxyURL) }
+}
+
+// WithWSHeaders sets extra HTTP headers sent with the WebSocket handshake.
+func WithWSHeaders(h http.Header) ClientOption {
+	return func(c *ClientConfig) { c.WSHeaders = h }
+}
+
+// WithWSStrictDecoding enables strict decoding of subscription notifications.
+// Payloads with unknown fields or enum values that fail IsValid are still
+// delivered, but are also reported to the decode error handler so API
+// drift is noticed early.
+func WithWSStrictDecoding(enabled bool) ClientOption {
+	return func(c *ClientConfig) { c.WSStrictDecoding = enabled }
+}
+
+// WithPreTradeCheck installs a check that runs client-side before every
+// order, amend, mass quote, conditional order and bot request, typically
+// the Validate method of a types.Validator.
+func WithPreTradeCheck(check func(params any) error) ClientOption {
+	return func(c *ClientConfig) { c.PreTradeCheck = check }
+}
@@ -1,9 +1,12 @@
 package config_test
 
 import (
+	"crypto/tls"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"os"
+	"reflect"
 	"testing"
 	"time"
 
@@ -224,6 +227,15 @@ func TestWithUserAgent(t *testing.T) {
 	}
 }
 
+func TestWithMaxResponseSize(t *testing.T) {
+	cfg := config.DefaultClientConfig()
+	config.WithMaxResponseSize(1 << 20)(&cfg)
+
+	if cfg.MaxResponseBytes != 1<<20 {
+		t.Errorf("MaxResponseBytes = %d, want %d", cfg.MaxResponseBytes, 1<<20)
+	}
+}
+
 func TestApplyMultipleOptions(t *testing.T) {
 	cfg := config.DefaultClientConfig()
 	options := []config.ClientOption{
@@ -321,3 +333,155 @@ func TestWithLogger_Nil(t *testing.T) {
 		t.Error("Logger should be nil after setting nil")
 	}
 }
+
+func TestWithEndpoint(t *testing.T) {
+	cfg := config.DefaultClientConfig()
+	config.WithEndpoint(config.Endpoint{
+		Name:         "local",
+		BaseURL:      "http://localhost:8080/api/v2",
+		WebSocketURL: "ws://localhost:8080/ws/api/v2",
+	})(&cfg)
+
+	if cfg.Endpoint == nil || cfg.Endpoint.Name != "local" {
+		t.Fatalf("Endpoint = %+v, want local endpoint", cfg.Endpoint)
+	}
+	if got := cfg.BaseURL(); got != "http://localhost:8080/api/v2" {
+		t.Errorf("BaseURL() = %q, want custom REST URL", got)
+	}
+	if got := wsURLs(cfg); len(got) != 1 || got[0] != "ws://localhost:8080/ws/api/v2" {
+		t.Errorf("WebSocketEndpoints() = %v, want custom WS URL only", got)
+	}
+}
+
+func TestClientConfig_URLFallback(t *testing.T) {
+	t.Run("NoEndpoint", func(t *testing.T) {
+		cfg := config.DefaultClientConfig()
+		config.WithNetwork(config.Testnet)(&cfg)
+		if got := cfg.BaseURL(); got != config.Testnet.BaseURL() {
+			t.Errorf("BaseURL() = %q, want %q", got, config.Testnet.BaseURL())
+		}
+		if got := wsURLs(cfg); len(got) != 1 || got[0] != config.Testnet.WebSocketURL() {
+			t.Errorf("WebSocketEndpoints() = %v, want [%s]", got, config.Testnet.WebSocketURL())
+		}
+	})
+
+	t.Run("PartialEndpoint", func(t *testing.T) {
+		cfg := config.DefaultClientConfig()
+		config.WithEndpoint(config.Endpoint{WebSocketURL: "ws://gw:9000"})(&cfg)
+		if got := cfg.BaseURL(); got != config.Production.BaseURL() {
+			t.Errorf("BaseURL() = %q, want network fallback", got)
+		}
+		if got := wsURLs(cfg)[0]; got != "ws://gw:9000" {
+			t.Errorf("WebSocketEndpoints()[0] = %q, want ws://gw:9000", got)
+		}
+	})
+}
+
+func TestWithWSFailoverEndpoints(t *testing.T) {
+	cfg := config.DefaultClientConfig()
+	config.WithWSFailoverEndpoints(
+		config.Endpoint{Name: "a", WebSocketURL: "ws://a"},
+		config.Endpoint{Name: "rest-only", BaseURL: "http://b"},
+		config.Endpoint{Name: "c", WebSocketURL: "ws://c"},
+	)(&cfg)
+
+	got := cfg.WebSocketEndpoints()
+	want := []config.Endpoint{
+		{Name: "production", WebSocketURL: config.Production.WebSocketURL()},
+		{Name: "a", WebSocketURL: "ws://a"},
+		{Name: "c", WebSocketURL: "ws://c"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("WebSocketEndpoints() = %+v, want %+v", got, want)
+	}
+}
+
+func TestClientConfig_EndpointNames(t *testing.T) {
+	cfg := config.DefaultClientConfig()
+	if got := cfg.BaseURLName(); got != "production" {
+		t.Errorf("BaseURLName() = %q, want network name", got)
+	}
+	config.WithEndpoint(config.Endpoint{Name: "colo", WebSocketURL: "ws://colo"})(&cfg)
+	if got := cfg.BaseURLName(); got != "production" {
+		t.Errorf("BaseURLName() = %q, want network name for the network REST URL", got)
+	}
+	if got := cfg.WebSocketEndpoints()[0].Name; got != "colo" {
+		t.Errorf("primary name = %q, want colo", got)
+	}
+	config.WithEndpoint(config.Endpoint{Name: "colo", BaseURL: "http://colo"})(&cfg)
+	if got := cfg.BaseURLName(); got != "colo" {
+		t.Errorf("BaseURLName() = %q, want colo", got)
+	}
+}
+
+func wsURLs(cfg config.ClientConfig) []string {
+	var urls []string
+	for _, e := range cfg.WebSocketEndpoints() {
+		urls = append(urls, e.WebSocketURL)
+	}
+	return urls
+}
+
+func TestWithWSTLSConfig(t *testing.T) {
+	cfg := config.DefaultClientConfig()
+	tlsCfg := &tls.Config{ServerName: "gw.internal"}
+	config.WithWSTLSConfig(tlsCfg)(&cfg)
+
+	if cfg.WSTLSConfig != tlsCfg {
+		t.Error("WSTLSConfig should match the provided config")
+	}
+}
+
+func TestWithWSProxy(t *testing.T) {
+	cfg := config.DefaultClientConfig()
+	proxyURL, _ := url.Parse("http://proxy.local:3128")
+	config.WithWSProxy(proxyURL)(&cfg)
+
+	if cfg.WSProxy == nil {
+		t.Fatal("WSProxy should be set")
+	}
+	req, _ := http.NewRequest(http.MethodGet, "https://thalex.com/ws/api/v2", nil)
+	got, err := cfg.WSProxy(req)
+	if err != nil {
+		t.Fatalf("WSProxy error: %v", err)
+	}
+	if got.String() != proxyURL.String() {
+		t.Errorf("WSProxy() = %v, want %v", got, proxyURL)
+	}
+}
+
+func TestWithWSHeaders(t *testing.T) {
+	cfg := config.DefaultClientConfig()
+	config.WithWSHeaders(http.Header{"X-Gateway": []string{"colo-1"}})(&cfg)
+
+	if cfg.WSHeaders.Get("X-Gateway") != "colo-1" {
+		t.Errorf("WSHeaders X-Gateway = %q, want colo-1", cfg.WSHeaders.Get("X-Gateway"))
+	}
+}
+
+func TestWithWSStrictDecoding(t *testing.T) {
+	cfg := config.DefaultClientConfig()
+	if cfg.WSStrictDecoding {
+		t.Fatal("strict decoding should be off by default")
+	}
+	config.WithWSStrictDecoding(true)(&cfg)
+	if !cfg.WSStrictDecoding {
+		t.Error("WSStrictDecoding = false, want true")
+	}
+}
+
+func TestWithPreTradeCheck(t *testing.T) {
+	cfg := config.DefaultClientConfig()
+	if cfg.PreTradeCheck != nil {
+		t.Fatal("no pre-trade check should be installed by default")
+	}
+	var got any
+	config.WithPreTradeCheck(func(params any) error { got = params; return nil })(&cfg)
+	if cfg.PreTradeCheck == nil {
+		t.Fatal("PreTradeCheck not set")
+	}
+	_ = cfg.PreTradeCheck("params")
+	if got != "params" {
+		t.Errorf("check received %v", got)
+	}
+}
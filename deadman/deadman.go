@@ -0,0 +1,295 @@
+// Package deadman cancels a session's orders and quotes when the
+// application stops showing signs of life.
+//
+// Cancel-on-disconnect only helps when the connection drops. A Switch
+// covers the other case, a strategy that hangs while its connection stays
+// up: the application calls Beat regularly, and if no beat arrives within
+// the interval, the Switch trips. It then cancels the session's orders,
+// all orders and the mass quotes, and reports an Event. A latching Switch
+// also refuses new orders until it is re-armed with Arm.
+package deadman
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/amiwrpremium/go-thalex/types"
+)
+
+// DefaultCancelTimeout bounds the cancel requests sent when a Switch trips.
+const DefaultCancelTimeout = 5 * time.Second
+
+// ErrTripped is returned by Check while a latching Switch is tripped.
+var ErrTripped = errors.New("deadman: switch tripped, re-arm to resume trading")
+
+// Client is the part of a client a Switch uses. ws.Client implements it.
+type Client interface {
+	CancelSession(ctx context.Context) (int, error)
+	CancelAll(ctx context.Context) (int, error)
+	CancelMassQuote(ctx context.Context) error
+}
+
+// Action is a cancel request sent when a Switch trips.
+type Action string
+
+const (
+	ActionCancelSession   Action = "cancel_session"
+	ActionCancelAll       Action = "cancel_all"
+	ActionCancelMassQuote Action = "cancel_mass_quote"
+)
+
+// Result is the outcome of one Action.
+type Result struct {
+	Action Action
+	// Cancelled is the number of orders cancelled, as reported by the
+	// exchange. It is zero for ActionCancelMassQuote.
+	Cancelled int
+	Err       error
+}
+
+// Event reports a tripped Switch.
+type Event struct {
+	// Time is when the Switch tripped.
+	Time time.Time
+	// LastBeat is the time of the last beat, or of arming if none came.
+	LastBeat time.Time
+	// Results holds the outcome of each action, in the configured order.
+	Results []Result
+}
+
+// Err joins the errors of the actions.
+func (e Event) Err() error {
+	var errs []error
+	for _, r := range e.Results {
+		errs = append(errs, r.Err)
+	}
+	return errors.Join(errs...)
+}
+
+// Option configures a Switch.
+type Option func(*Switch)
+
+// WithActions sets the cancel requests sent when the Switch trips. The
+// default is all three actions.
+func WithActions(actions ...Action) Option {
+	return func(s *Switch) { s.actions = actions }
+}
+
+// WithHandler registers a callback for trips. It is called after the
+// cancel requests complete.
+func WithHandler(fn func(Event)) Option {
+	return func(s *Switch) { s.onTrip = fn }
+}
+
+// WithLatch makes the Switch refuse new orders through Check once it has
+// tripped, until Arm is called. Without it, the next Beat re-arms the
+// Switch.
+func WithLatch() Option {
+	return func(s *Switch) { s.latch = true }
+}
+
+// WithCheckInterval sets how often the background loop started by Start
+// compares the time since the last beat with the interval. The default is
+// a tenth of the interval.
+func WithCheckInterval(d time.Duration) Option {
+	return func(s *Switch) {
+		if d > 0 {
+			s.checkEvery = d
+		}
+	}
+}
+
+// WithCancelTimeout bounds the cancel requests. The default is
+// DefaultCancelTimeout.
+func WithCancelTimeout(d time.Duration) Option {
+	return func(s *Switch) {
+		if d > 0 {
+			s.cancelTimeout = d
+		}
+	}
+}
+
+// WithClock sets the time source. It is intended for tests, together with
+// Poll.
+func WithClock(now func() time.Time) Option {
+	return func(s *Switch) { s.now = now }
+}
+
+// Switch is a dead man's switch. It is safe for concurrent use.
+type Switch struct {
+	client        Client
+	interval      time.Duration
+	actions       []Action
+	onTrip        func(Event)
+	latch         bool
+	checkEvery    time.Duration
+	cancelTimeout time.Duration
+	now           func() time.Time
+
+	mu       sync.Mutex
+	lastBeat time.Time
+	tripped  bool
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// NewSwitch creates a switch that trips when no beat arrives for interval.
+// It is armed, with the clock starting now.
+func NewSwitch(client Client, interval time.Duration, opts ...Option) *Switch {
+	s := &Switch{
+		client:        client,
+		interval:      interval,
+		actions:       []Action{ActionCancelMassQuote, ActionCancelSession, ActionCancelAll},
+		checkEvery:    interval / 10,
+		cancelTimeout: DefaultCancelTimeout,
+		now:           time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.checkEvery <= 0 {
+		s.checkEvery = time.Millisecond
+	}
+	s.lastBeat = s.now()
+	return s
+}
+
+// Beat records a sign of life. It re-arms a tripped Switch unless it
+// latches.
+func (s *Switch) Beat() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tripped && s.latch {
+		return
+	}
+	s.lastBeat, s.tripped = s.now(), false
+}
+
+// Arm re-arms a tripped Switch and restarts its interval.
+func (s *Switch) Arm() {
+	s.mu.Lock()
+	s.lastBeat, s.tripped = s.now(), false
+	s.mu.Unlock()
+}
+
+// Tripped reports whether the Switch has tripped and not been re-armed.
+func (s *Switch) Tripped() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tripped
+}
+
+// Check returns ErrTripped for order, quote, conditional order and bot
+// params while a latching Switch is tripped, and nil otherwise. Install
+// it with config.WithPreTradeCheck(s.Check).
+func (s *Switch) Check(params any) error {
+	switch params.(type) {
+	case *types.InsertOrderParams, *types.AmendOrderParams, *types.MassQuoteParams,
+		*types.CreateConditionalOrderParams, *types.SGSLBotParams, *types.OCQBotParams,
+		*types.LevelsBotParams, *types.GridBotParams, *types.DHedgeBotParams, *types.DFollowBotParams:
+	default:
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.latch && s.tripped {
+		return ErrTripped
+	}
+	return nil
+}
+
+// Start checks the Switch in the background every check interval until
+// ctx is done or Stop is called. An armed Switch restarts its interval.
+func (s *Switch) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		return
+	}
+	if !s.tripped {
+		s.lastBeat = s.now()
+	}
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.done = make(chan struct{})
+	go s.loop(ctx, s.done)
+}
+
+// Stop stops the background checks and waits for a trip in progress to
+// finish.
+func (s *Switch) Stop() {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.cancel, s.done = nil, nil
+	s.mu.Unlock()
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+func (s *Switch) loop(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	t := time.NewTicker(s.checkEvery)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			s.Poll(ctx)
+		}
+	}
+}
+
+// Poll trips the Switch if the interval has passed since the last beat.
+// It reports whether it tripped. The background loop calls it; with
+// WithClock, tests can call it directly. The cancel requests are sent
+// before Poll returns.
+func (s *Switch) Poll(ctx context.Context) bool {
+	s.mu.Lock()
+	now := s.now()
+	if s.tripped || now.Sub(s.lastBeat) < s.interval {
+		s.mu.Unlock()
+		return false
+	}
+	s.tripped = true
+	ev := Event{Time: now, LastBeat: s.lastBeat}
+	s.mu.Unlock()
+
+	ev.Results = s.fire(ctx)
+	if s.onTrip != nil {
+		s.onTrip(ev)
+	}
+	return true
+}
+
+// fire sends the cancel requests concurrently. They run on a context
+// detached from ctx's cancellation, so stopping the Switch does not abort
+// a trip in progress.
+func (s *Switch) fire(ctx context.Context) []Result {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.cancelTimeout)
+	defer cancel()
+	results := make([]Result, len(s.actions))
+	var wg sync.WaitGroup
+	for i, a := range s.actions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = Result{Action: a}
+			switch a {
+			case ActionCancelSession:
+				results[i].Cancelled, results[i].Err = s.client.CancelSession(ctx)
+			case ActionCancelAll:
+				results[i].Cancelled, results[i].Err = s.client.CancelAll(ctx)
+			case ActionCancelMassQuote:
+				results[i].Err = s.client.CancelMassQuote(ctx)
+			default:
+				results[i].Err = errors.New("deadman: unknown action " + string(a))
+			}
+		}()
+	}
+	wg.Wait()
+	return results
+}
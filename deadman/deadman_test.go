@@ -0,0 +1,193 @@
+package deadman_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/amiwrpremium/go-thalex/deadman"
+	"github.com/amiwrpremium/go-thalex/types"
+	"github.com/amiwrpremium/go-thalex/ws"
+)
+
+var _ deadman.Client = (*ws.Client)(nil)
+
+type fakeClient struct {
+	mu    sync.Mutex
+	calls []string
+	err   error
+}
+
+func (f *fakeClient) record(call string) {
+	f.mu.Lock()
+	f.calls = append(f.calls, call)
+	f.mu.Unlock()
+}
+
+func (f *fakeClient) CancelSession(ctx context.Context) (int, error) {
+	f.record("session")
+	return 2, ctx.Err()
+}
+
+func (f *fakeClient) CancelAll(context.Context) (int, error) {
+	f.record("all")
+	return 5, f.err
+}
+
+func (f *fakeClient) CancelMassQuote(context.Context) error {
+	f.record("mass_quote")
+	return nil
+}
+
+func (f *fakeClient) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.calls)
+}
+
+type clock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func (c *clock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+func (c *clock) advance(d time.Duration) {
+	c.mu.Lock()
+	c.t = c.t.Add(d)
+	c.mu.Unlock()
+}
+
+func TestSwitch_Trip(t *testing.T) {
+	ctx := context.Background()
+	c := &clock{t: time.Unix(1000, 0)}
+	f := &fakeClient{err: errors.New("boom")}
+	var events []deadman.Event
+	s := deadman.NewSwitch(f, time.Second, deadman.WithClock(c.now),
+		deadman.WithHandler(func(ev deadman.Event) { events = append(events, ev) }))
+
+	c.advance(900 * time.Millisecond)
+	s.Beat()
+	c.advance(900 * time.Millisecond)
+	if s.Poll(ctx) || s.Tripped() {
+		t.Fatal("tripped within the interval")
+	}
+	c.advance(100 * time.Millisecond)
+	if !s.Poll(ctx) || !s.Tripped() {
+		t.Fatal("not tripped after the interval")
+	}
+	if s.Poll(ctx) {
+		t.Error("tripped twice")
+	}
+	if len(events) != 1 {
+		t.Fatalf("%d events", len(events))
+	}
+	ev := events[0]
+	if !ev.Time.Equal(time.Unix(1001, 900e6)) || !ev.LastBeat.Equal(time.Unix(1000, 900e6)) {
+		t.Errorf("event times %v, %v", ev.Time, ev.LastBeat)
+	}
+	want := []deadman.Result{
+		{Action: deadman.ActionCancelMassQuote},
+		{Action: deadman.ActionCancelSession, Cancelled: 2},
+		{Action: deadman.ActionCancelAll, Cancelled: 5, Err: f.err},
+	}
+	for i, r := range ev.Results {
+		if r != want[i] {
+			t.Errorf("result %d = %+v, want %+v", i, r, want[i])
+		}
+	}
+	if !errors.Is(ev.Err(), f.err) {
+		t.Errorf("Err() = %v", ev.Err())
+	}
+
+	// Without a latch, a beat re-arms the switch and trading continues.
+	if err := s.Check(types.NewBuyOrderParams("BTC-PERPETUAL", 1)); err != nil {
+		t.Errorf("Check = %v without a latch", err)
+	}
+	s.Beat()
+	if s.Tripped() {
+		t.Error("still tripped after a beat")
+	}
+	c.advance(time.Second)
+	if !s.Poll(ctx) || len(events) != 2 {
+		t.Error("re-armed switch did not trip again")
+	}
+}
+
+func TestSwitch_Latch(t *testing.T) {
+	ctx := context.Background()
+	c := &clock{t: time.Unix(1000, 0)}
+	f := &fakeClient{}
+	s := deadman.NewSwitch(f, time.Second, deadman.WithClock(c.now), deadman.WithLatch(),
+		deadman.WithActions(deadman.ActionCancelSession))
+
+	order := types.NewBuyOrderParams("BTC-PERPETUAL", 1)
+	if err := s.Check(order); err != nil {
+		t.Fatal(err)
+	}
+	c.advance(time.Second)
+	if !s.Poll(ctx) {
+		t.Fatal("not tripped")
+	}
+	if f.count() != 1 || f.calls[0] != "session" {
+		t.Errorf("calls = %v, want the configured action only", f.calls)
+	}
+	for _, p := range []any{order, types.NewMassQuoteParams(nil), types.NewDHedgeBotParams("BTC-PERPETUAL", 60)} {
+		if err := s.Check(p); !errors.Is(err, deadman.ErrTripped) {
+			t.Errorf("Check(%T) = %v, want ErrTripped", p, err)
+		}
+	}
+	if err := s.Check("cancel"); err != nil {
+		t.Errorf("Check of other params = %v", err)
+	}
+
+	// A beat does not re-arm a latching switch.
+	s.Beat()
+	if !s.Tripped() {
+		t.Fatal("beat re-armed a latching switch")
+	}
+	s.Arm()
+	if s.Tripped() || s.Check(order) != nil {
+		t.Error("Arm did not re-arm")
+	}
+	c.advance(500 * time.Millisecond)
+	if s.Poll(ctx) {
+		t.Error("tripped before the interval after Arm")
+	}
+}
+
+func TestSwitch_Start(t *testing.T) {
+	f := &fakeClient{}
+	tripped := make(chan deadman.Event, 1)
+	s := deadman.NewSwitch(f, 20*time.Millisecond, deadman.WithCheckInterval(time.Millisecond),
+		deadman.WithHandler(func(ev deadman.Event) { tripped <- ev }))
+	s.Start(context.Background())
+	defer s.Stop()
+
+	// Beating keeps the switch armed.
+	for i := 0; i < 10; i++ {
+		s.Beat()
+		time.Sleep(5 * time.Millisecond)
+	}
+	if s.Tripped() {
+		t.Fatal("tripped while beating")
+	}
+	select {
+	case ev := <-tripped:
+		if err := ev.Err(); err != nil {
+			t.Errorf("cancel errors: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("not tripped after beats stopped")
+	}
+	s.Stop()
+	if f.count() != 3 {
+		t.Errorf("calls = %v", f.calls)
+	}
+}
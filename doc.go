@@ -10,6 +10,17 @@
 //   - [github.com/amiwrpremium/go-thalex/types] — request/response types
 //   - [github.com/amiwrpremium/go-thalex/rest] — REST API client
 //   - [github.com/amiwrpremium/go-thalex/ws] — WebSocket JSON-RPC client with subscriptions
+//   - [github.com/amiwrpremium/go-thalex/instruments] — live instrument registry with lookup indexes
+//   - [github.com/amiwrpremium/go-thalex/orderbook] — local order books maintained from the book channel
+//   - [github.com/amiwrpremium/go-thalex/candles] — live OHLC candles built from trade streams
+//   - [github.com/amiwrpremium/go-thalex/history] — chunked, concurrent historical data downloads and caching
+//   - [github.com/amiwrpremium/go-thalex/oms] — order management by client order ID
+//   - [github.com/amiwrpremium/go-thalex/reconcile] — startup reconciliation of live items with local intent
+//   - [github.com/amiwrpremium/go-thalex/journal] — durable order and trade journal with replay
+//   - [github.com/amiwrpremium/go-thalex/risk] — pre-trade risk limits
+//   - [github.com/amiwrpremium/go-thalex/killswitch] — one-call cancellation of all trading activity
+//   - [github.com/amiwrpremium/go-thalex/deadman] — dead man's switch heartbeat
+//   - [github.com/amiwrpremium/go-thalex/quoting] — mass quote manager that sends only changes
 //
 // # Quick Start
 //
@@ -2,6 +2,7 @@ package enums_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/amiwrpremium/go-thalex/enums"
 )
@@ -1122,6 +1123,27 @@ func TestResolutionValues(t *testing.T) {
 	}
 }
 
+func TestResolution_Duration(t *testing.T) {
+	tests := []struct {
+		v    enums.Resolution
+		want time.Duration
+	}{
+		{enums.Resolution1m, time.Minute},
+		{enums.Resolution5m, 5 * time.Minute},
+		{enums.Resolution15m, 15 * time.Minute},
+		{enums.Resolution30m, 30 * time.Minute},
+		{enums.Resolution1h, time.Hour},
+		{enums.Resolution1d, 24 * time.Hour},
+		{enums.Resolution1w, 7 * 24 * time.Hour},
+		{enums.Resolution("2h"), 0},
+	}
+	for _, tt := range tests {
+		if got := tt.v.Duration(); got != tt.want {
+			t.Errorf("%q.Duration() = %v, want %v", tt.v, got, tt.want)
+		}
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Sort
 // ---------------------------------------------------------------------------
@@ -1,5 +1,7 @@
 package enums
 
+import "time"
+
 // Resolution represents the time resolution for historical data.
 type Resolution string
 
@@ -27,6 +29,28 @@ func (r Resolution) IsValid() bool {
 	return false
 }
 
+// Duration returns the length of one interval at the resolution, or 0 if
+// the resolution is not recognized.
+func (r Resolution) Duration() time.Duration {
+	switch r {
+	case Resolution1m:
+		return time.Minute
+	case Resolution5m:
+		return 5 * time.Minute
+	case Resolution15m:
+		return 15 * time.Minute
+	case Resolution30m:
+		return 30 * time.Minute
+	case Resolution1h:
+		return time.Hour
+	case Resolution1d:
+		return 24 * time.Hour
+	case Resolution1w:
+		return 7 * 24 * time.Hour
+	}
+	return 0
+}
+
 // ResolutionValues returns all valid Resolution values.
 func ResolutionValues() []Resolution {
 	return []Resolution{Resolution1m, Resolution5m, Resolution15m, Resolution30m, Resolution1h, Resolution1d, Resolution1w}
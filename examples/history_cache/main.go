@@ -0,0 +1,87 @@
+// Example: Caching historical market data on disk, and pruning the cache.
+//
+//	go run ./examples/history_cache/ -dir ./thalex-cache fetch
+//	go run ./examples/history_cache/ -dir ./thalex-cache prune -keep 30
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/amiwrpremium/go-thalex/config"
+	"github.com/amiwrpremium/go-thalex/enums"
+	"github.com/amiwrpremium/go-thalex/history"
+	"github.com/amiwrpremium/go-thalex/rest"
+)
+
+func main() {
+	dir := flag.String("dir", "thalex-cache", "cache directory")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [-dir path] fetch|prune [flags]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	client := rest.NewClient(config.WithNetwork(config.Testnet))
+	cache, err := history.NewCache(*dir, client)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	switch flag.Arg(0) {
+	case "fetch", "":
+		fetch(cache, flag.Args())
+	case "prune":
+		prune(cache, flag.Args()[1:])
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+// fetch downloads candles through the cache. Run it twice: the second run
+// only requests the current day.
+func fetch(cache *history.Cache, args []string) {
+	fs := flag.NewFlagSet("fetch", flag.ExitOnError)
+	instrument := fs.String("instrument", "BTC-PERPETUAL", "instrument name")
+	days := fs.Int("days", 7, "days of history")
+	if len(args) > 0 {
+		fs.Parse(args[1:])
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	// The Downloader fetches windows concurrently; each is served from the
+	// cache where possible.
+	d := history.NewDownloader(cache)
+	to := time.Now()
+	from := to.AddDate(0, 0, -*days)
+	n := 0
+	var last float64
+	for c, err := range d.Mark(ctx, *instrument, from, to, enums.Resolution1h) {
+		if err != nil {
+			log.Fatal(err)
+		}
+		n++
+		last = c.Close
+	}
+	fmt.Printf("%s: %d hourly candles, last close %.2f\n", *instrument, n, last)
+}
+
+// prune removes cached days older than -keep days.
+func prune(cache *history.Cache, args []string) {
+	fs := flag.NewFlagSet("prune", flag.ExitOnError)
+	keep := fs.Int("keep", 30, "days to keep")
+	fs.Parse(args)
+
+	n, err := cache.Prune(time.Now().AddDate(0, 0, -*keep))
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("removed %d cached days\n", n)
+}
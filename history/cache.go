@@ -0,0 +1,454 @@
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/amiwrpremium/go-thalex/enums"
+	"github.com/amiwrpremium/go-thalex/types"
+)
+
+const (
+	cacheHeader   = "#thalex-history v1 "
+	cacheComplete = "#complete "
+	cacheExt      = ".rows"
+	dayLayout     = "2006-01-02"
+	kindMark      = "mark"
+	kindIndex     = "index"
+)
+
+// errCorrupt marks a cache file that failed its integrity checks.
+var errCorrupt = errors.New("history: corrupt cache file")
+
+// CacheOption configures a Cache.
+type CacheOption func(*Cache)
+
+// WithCacheClock sets the time source used to decide which days are
+// complete. It is intended for tests.
+func WithCacheClock(now func() time.Time) CacheOption {
+	return func(c *Cache) { c.now = now }
+}
+
+// WithCacheCandlesPerRequest sets the number of candles requested per
+// call to the Source. The default is DefaultCandlesPerRequest.
+func WithCacheCandlesPerRequest(n int) CacheOption {
+	return func(c *Cache) { c.perRequest = max(n, 1) }
+}
+
+// Cache stores historical data on disk, one file per instrument or index,
+// resolution and UTC day, and serves reads from it. It satisfies Source,
+// so it can stand in for a client, including underneath a Downloader.
+//
+// A day is fetched once it is needed. Days that have ended are marked
+// complete and never fetched again. For the current day, only the range
+// from the last cached candle onward is fetched, since that candle may
+// still change. Files are append-only until their day completes, and every
+// row carries a CRC-32 checksum. A file that fails its checks is discarded
+// and its day fetched again.
+type Cache struct {
+	dir        string
+	source     Source
+	now        func() time.Time
+	perRequest int
+
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex // by file path
+}
+
+// NewCache returns a Cache in dir, creating the directory if needed, that
+// fetches missing data from source.
+func NewCache(dir string, source Source, opts ...CacheOption) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("history: %w", err)
+	}
+	c := &Cache{
+		dir:        dir,
+		source:     source,
+		now:        time.Now,
+		perRequest: DefaultCandlesPerRequest,
+		locks:      make(map[string]*sync.Mutex),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// MarkPriceHistoricalData returns mark price data for [from, to), reading
+// cached days from disk and fetching the rest.
+func (c *Cache) MarkPriceHistoricalData(ctx context.Context, instrumentName string, from, to float64, resolution enums.Resolution) (types.MarkPriceHistoricalResult, error) {
+	var result types.MarkPriceHistoricalResult
+	kind, rows, err := c.read(ctx, kindMark, instrumentName, from, to, resolution)
+	if err != nil {
+		return result, err
+	}
+	err = decodeRows(map[string]any{"instrument_type": kind, "mark": rows, "no_data": len(rows) == 0}, &result)
+	return result, err
+}
+
+// IndexPriceHistoricalData returns index price data for [from, to),
+// reading cached days from disk and fetching the rest.
+func (c *Cache) IndexPriceHistoricalData(ctx context.Context, indexName string, from, to float64, resolution enums.Resolution) (types.IndexPriceHistoricalResult, error) {
+	var result types.IndexPriceHistoricalResult
+	_, rows, err := c.read(ctx, kindIndex, indexName, from, to, resolution)
+	if err != nil {
+		return result, err
+	}
+	err = decodeRows(map[string]any{"index": rows, "no_data": len(rows) == 0}, &result)
+	return result, err
+}
+
+// decodeRows decodes cached rows through the result's UnmarshalJSON, so
+// they are typed and checked exactly like a server response.
+func decodeRows(v map[string]any, result any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, result)
+}
+
+// read returns the rows of [from, to) and, for mark data, the instrument
+// type.
+func (c *Cache) read(ctx context.Context, kind, name string, from, to float64, resolution enums.Resolution) (string, [][]any, error) {
+	if resolution.Duration() == 0 {
+		return "", nil, fmt.Errorf("history: invalid resolution %q", resolution)
+	}
+	lo, hi := types.Timestamp(from), types.Timestamp(to)
+	now := c.now()
+	end := hi.Time()
+	if end.After(now) {
+		end = now
+	}
+	var typ string
+	var out [][]any
+	for day := lo.Time().UTC().Truncate(24 * time.Hour); day.Before(end); day = day.AddDate(0, 0, 1) {
+		f, err := c.day(ctx, kind, name, resolution, day, now)
+		if err != nil {
+			return "", nil, err
+		}
+		if f.typ != "" {
+			typ = f.typ
+		}
+		for _, row := range f.rows {
+			if t := rowTime(row); t >= lo && t < hi {
+				out = append(out, row)
+			}
+		}
+	}
+	return typ, out, nil
+}
+
+// dayFile is the parsed content of one cache file.
+type dayFile struct {
+	typ      string
+	rows     [][]any
+	raw      [][]byte // JSON of each row
+	byTime   map[types.Timestamp]int
+	complete bool
+}
+
+func (f *dayFile) set(row []any, raw []byte) bool {
+	t := rowTime(row)
+	if i, ok := f.byTime[t]; ok {
+		if bytes.Equal(f.raw[i], raw) {
+			return false
+		}
+		f.rows[i], f.raw[i] = row, raw
+		return true
+	}
+	f.byTime[t] = len(f.rows)
+	f.rows = append(f.rows, row)
+	f.raw = append(f.raw, raw)
+	return true
+}
+
+func rowTime(row []any) types.Timestamp {
+	if len(row) == 0 {
+		return 0
+	}
+	t, _ := row[0].(float64)
+	return types.Timestamp(t)
+}
+
+// day returns the rows of one day, fetching what is missing or mutable.
+func (c *Cache) day(ctx context.Context, kind, name string, resolution enums.Resolution, day, now time.Time) (*dayFile, error) {
+	path := c.path(kind, name, resolution, day)
+	unlock := c.lock(path)
+	defer unlock()
+
+	f, err := readDayFile(path)
+	rewrite := false
+	if errors.Is(err, errCorrupt) {
+		f, rewrite = newDayFile(), true
+	} else if err != nil {
+		return nil, err
+	}
+	if f.complete {
+		return f, nil
+	}
+
+	step := resolution.Duration()
+	dayEnd := day.Add(24 * time.Hour)
+	complete := !now.Before(dayEnd) && !now.Before(day.Add(step))
+
+	fetchFrom := day
+	if n := len(f.rows); n > 0 {
+		// The last cached candle may have been partial.
+		fetchFrom = rowTime(f.rows[n-1]).Time()
+	}
+	fetchTo := dayEnd
+	if fetchTo.After(now) {
+		fetchTo = now
+	}
+	typ, rows, err := c.fetch(ctx, kind, name, resolution, fetchFrom, fetchTo)
+	if err != nil {
+		return nil, err
+	}
+	if f.typ == "" {
+		f.typ = typ
+	}
+
+	var appended [][]byte
+	lo, hi := types.NewTimestamp(fetchFrom), types.NewTimestamp(dayEnd)
+	for _, row := range rows {
+		if t := rowTime(row); t < lo || t >= hi {
+			continue
+		}
+		raw, err := json.Marshal(row)
+		if err != nil {
+			return nil, err
+		}
+		if f.set(row, raw) {
+			appended = append(appended, raw)
+		}
+	}
+
+	f.complete = complete
+	switch {
+	case rewrite || complete:
+		err = writeDayFile(path, f)
+	case len(appended) > 0:
+		err = appendDayFile(path, f.typ, appended)
+	}
+	return f, err
+}
+
+// fetch requests [from, to) from the source in windows.
+func (c *Cache) fetch(ctx context.Context, kind, name string, resolution enums.Resolution, from, to time.Time) (string, [][]any, error) {
+	windows, err := Windows(from, to, resolution, c.perRequest)
+	if err != nil {
+		return "", nil, err
+	}
+	var typ string
+	if kind == kindIndex {
+		typ = kindIndex
+	}
+	var rows [][]any
+	for _, w := range windows {
+		if kind == kindIndex {
+			r, err := c.source.IndexPriceHistoricalData(ctx, name, w.From.Seconds(), w.To.Seconds(), resolution)
+			if err != nil {
+				return "", nil, err
+			}
+			rows = append(rows, r.Index...)
+			continue
+		}
+		r, err := c.source.MarkPriceHistoricalData(ctx, name, w.From.Seconds(), w.To.Seconds(), resolution)
+		if err != nil {
+			return "", nil, err
+		}
+		if r.InstrumentType != "" {
+			typ = string(r.InstrumentType)
+		}
+		rows = append(rows, r.Mark...)
+	}
+	return typ, rows, nil
+}
+
+// path returns the file of one day:
+// <dir>/<kind>/<name>/<resolution>/<yyyy-mm-dd>.rows.
+func (c *Cache) path(kind, name string, resolution enums.Resolution, day time.Time) string {
+	return filepath.Join(c.dir, kind, url.PathEscape(name), string(resolution), day.Format(dayLayout)+cacheExt)
+}
+
+// lock serialises access to one file and returns its unlock function.
+func (c *Cache) lock(path string) func() {
+	c.mu.Lock()
+	m, ok := c.locks[path]
+	if !ok {
+		m = &sync.Mutex{}
+		c.locks[path] = m
+	}
+	c.mu.Unlock()
+	m.Lock()
+	return m.Unlock
+}
+
+func newDayFile() *dayFile {
+	return &dayFile{byTime: make(map[types.Timestamp]int)}
+}
+
+// readDayFile parses a cache file. A missing file yields an empty day.
+//
+// The format is a header line naming the instrument type, one line per
+// row of "<crc32 hex>\t<row JSON>", and, once the day is complete, a
+// "#complete <rows>" trailer. Later rows replace earlier rows with the
+// same time.
+func readDayFile(path string) (*dayFile, error) {
+	f := newDayFile()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("history: %w", err)
+	}
+	if len(data) == 0 || data[len(data)-1] != '\n' {
+		return nil, fmt.Errorf("%w: %s: truncated", errCorrupt, path)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	typ, ok := strings.CutPrefix(lines[0], cacheHeader)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s: bad header", errCorrupt, path)
+	}
+	f.typ = typ
+	lines = lines[1:]
+	for i, line := range lines {
+		if n, ok := strings.CutPrefix(line, cacheComplete); ok {
+			if i != len(lines)-1 || n != strconv.Itoa(i) {
+				return nil, fmt.Errorf("%w: %s: bad trailer", errCorrupt, path)
+			}
+			f.complete = true
+			break
+		}
+		sum, payload, ok := strings.Cut(line, "\t")
+		if !ok || sum != fmt.Sprintf("%08x", crc32.ChecksumIEEE([]byte(payload))) {
+			return nil, fmt.Errorf("%w: %s: line %d fails checksum", errCorrupt, path, i+2)
+		}
+		var row []any
+		if err := json.Unmarshal([]byte(payload), &row); err != nil || len(row) == 0 {
+			return nil, fmt.Errorf("%w: %s: line %d: bad row", errCorrupt, path, i+2)
+		}
+		f.set(row, []byte(payload))
+	}
+	return f, nil
+}
+
+func rowLine(w *bufio.Writer, raw []byte) {
+	fmt.Fprintf(w, "%08x\t%s\n", crc32.ChecksumIEEE(raw), raw)
+}
+
+// writeDayFile replaces the file with a compacted copy of f.
+func writeDayFile(path string, f *dayFile) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("history: %w", err)
+	}
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	w.WriteString(cacheHeader + f.typ + "\n")
+	for _, raw := range f.raw {
+		rowLine(w, raw)
+	}
+	if f.complete {
+		fmt.Fprintf(w, "%s%d\n", cacheComplete, len(f.raw))
+	}
+	w.Flush()
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("history: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return fmt.Errorf("history: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("history: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("history: %w", err)
+	}
+	return nil
+}
+
+// appendDayFile appends rows, creating the file with its header if needed.
+func appendDayFile(path, typ string, rows [][]byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("history: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("history: %w", err)
+	}
+	w := bufio.NewWriter(file)
+	if info, err := file.Stat(); err == nil && info.Size() == 0 {
+		w.WriteString(cacheHeader + typ + "\n")
+	}
+	for _, raw := range rows {
+		rowLine(w, raw)
+	}
+	err = w.Flush()
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("history: %w", err)
+	}
+	return nil
+}
+
+// Prune removes cached days that ended before cutoff and any directories
+// left empty, and returns the number of files removed.
+func (c *Cache) Prune(cutoff time.Time) (int, error) {
+	removed := 0
+	var dirs []string
+	err := filepath.WalkDir(c.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != c.dir {
+				dirs = append(dirs, path)
+			}
+			return nil
+		}
+		name, ok := strings.CutSuffix(d.Name(), cacheExt)
+		if !ok {
+			return nil
+		}
+		day, err := time.Parse(dayLayout, name)
+		if err != nil || day.Add(24*time.Hour).After(cutoff) {
+			return nil
+		}
+		unlock := c.lock(path)
+		defer unlock()
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		removed++
+		return nil
+	})
+	// Deepest first, so parents are empty by the time they are reached.
+	for i := len(dirs) - 1; i >= 0; i-- {
+		_ = os.Remove(dirs[i]) // fails harmlessly unless empty
+	}
+	if err != nil {
+		return removed, fmt.Errorf("history: %w", err)
+	}
+	return removed, nil
+}
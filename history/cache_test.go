@@ -0,0 +1,214 @@
+package history_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/amiwrpremium/go-thalex/enums"
+	"github.com/amiwrpremium/go-thalex/history"
+	"github.com/amiwrpremium/go-thalex/types"
+)
+
+var _ history.Source = (*history.Cache)(nil)
+
+// cacheSource returns one hourly row per candle start in [from, to],
+// priced at its time plus version. Mark rows are perpetual rows.
+type cacheSource struct {
+	mu      sync.Mutex
+	version float64
+	err     error
+	calls   []history.Window
+}
+
+func (s *cacheSource) rows(from, to float64) ([][]any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = append(s.calls, history.Window{From: types.Timestamp(from), To: types.Timestamp(to)})
+	if s.err != nil {
+		return nil, s.err
+	}
+	var out [][]any
+	first := float64(int64(from) / 3600 * 3600)
+	for t := first; t <= to; t += 3600 {
+		v := t + s.version
+		out = append(out, []any{t, v, v + 1, v - 1, v})
+	}
+	return out, nil
+}
+
+func (s *cacheSource) MarkPriceHistoricalData(_ context.Context, _ string, from, to float64, _ enums.Resolution) (types.MarkPriceHistoricalResult, error) {
+	rows, err := s.rows(from, to)
+	for i := range rows {
+		rows[i] = append(rows[i], 0.0) // funding payment
+	}
+	return types.MarkPriceHistoricalResult{InstrumentType: enums.InstrumentTypePerpetual, Mark: rows}, err
+}
+
+func (s *cacheSource) IndexPriceHistoricalData(_ context.Context, _ string, from, to float64, _ enums.Resolution) (types.IndexPriceHistoricalResult, error) {
+	rows, err := s.rows(from, to)
+	return types.IndexPriceHistoricalResult{Index: rows}, err
+}
+
+func (s *cacheSource) reset(version float64, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.version, s.err, s.calls = version, err, nil
+}
+
+func (s *cacheSource) windows() []history.Window {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]history.Window(nil), s.calls...)
+}
+
+func newCache(t *testing.T, dir string, src history.Source, now *time.Time) *history.Cache {
+	t.Helper()
+	c, err := history.NewCache(dir, src, history.WithCacheClock(func() time.Time { return *now }))
+	if err != nil {
+		t.Fatalf("NewCache error: %v", err)
+	}
+	return c
+}
+
+func secs(t time.Time) float64 { return types.NewTimestamp(t).Seconds() }
+
+func TestCache_ServesCompleteDaysFromDisk(t *testing.T) {
+	dir := t.TempDir()
+	now := start.Add(50 * time.Hour)
+	src := &cacheSource{}
+	c := newCache(t, dir, src, &now)
+	ctx := context.Background()
+
+	from, to := start.Add(6*time.Hour), start.Add(30*time.Hour)
+	r, err := c.MarkPriceHistoricalData(ctx, "BTC-PERPETUAL", secs(from), secs(to), enums.Resolution1h)
+	if err != nil {
+		t.Fatalf("MarkPriceHistoricalData error: %v", err)
+	}
+	if len(r.Perpetual) != 24 || r.Perpetual[0].Time != types.NewTimestamp(from) || r.InstrumentType != enums.InstrumentTypePerpetual {
+		t.Fatalf("got %d rows from %v (%s), want 24 from %v", len(r.Perpetual), r.Perpetual[0].Time, r.InstrumentType, from)
+	}
+	if n := len(src.windows()); n != 2 {
+		t.Errorf("fetched %d windows, want one per day", n)
+	}
+
+	// A fresh Cache over the same directory needs no requests.
+	src.reset(0, errors.New("offline"))
+	c = newCache(t, dir, src, &now)
+	r2, err := c.MarkPriceHistoricalData(ctx, "BTC-PERPETUAL", secs(start), secs(start.Add(48*time.Hour)), enums.Resolution1h)
+	if err != nil {
+		t.Fatalf("cached read error: %v", err)
+	}
+	if len(r2.Perpetual) != 48 || r2.Perpetual[29].Close != r.Perpetual[23].Close {
+		t.Errorf("cached read returned %d rows", len(r2.Perpetual))
+	}
+
+	idx, err := newCache(t, dir, &cacheSource{}, &now).IndexPriceHistoricalData(ctx, "BTCUSD", secs(start), secs(start.Add(2*time.Hour)), enums.Resolution1h)
+	if err != nil || len(idx.Rows) != 2 || idx.Rows[1].Close != secs(start.Add(time.Hour)) {
+		t.Errorf("IndexPriceHistoricalData = %+v, %v", idx.Rows, err)
+	}
+}
+
+func TestCache_RefetchesPartialCandle(t *testing.T) {
+	dir := t.TempDir()
+	now := start.Add(10*time.Hour + 30*time.Minute)
+	src := &cacheSource{}
+	c := newCache(t, dir, src, &now)
+	ctx := context.Background()
+	to := secs(start.Add(24 * time.Hour))
+
+	r, err := c.MarkPriceHistoricalData(ctx, "BTC-PERPETUAL", secs(start), to, enums.Resolution1h)
+	if err != nil || len(r.Perpetual) != 11 {
+		t.Fatalf("got %d rows, %v; want 11 up to the open 10:00 candle", len(r.Perpetual), err)
+	}
+
+	now = start.Add(12*time.Hour + 30*time.Minute)
+	src.reset(0.5, nil)
+	r, err = c.MarkPriceHistoricalData(ctx, "BTC-PERPETUAL", secs(start), to, enums.Resolution1h)
+	if err != nil || len(r.Perpetual) != 13 {
+		t.Fatalf("got %d rows, %v; want 13", len(r.Perpetual), err)
+	}
+	if w := src.windows(); len(w) != 1 || w[0].From != types.NewTimestamp(start.Add(10*time.Hour)) {
+		t.Errorf("fetched %v, want only from the last cached candle", w)
+	}
+	if r.Perpetual[9].Close != secs(start.Add(9*time.Hour)) {
+		t.Errorf("closed candle changed: %+v", r.Perpetual[9])
+	}
+	if r.Perpetual[10].Close != secs(start.Add(10*time.Hour))+0.5 {
+		t.Errorf("partial candle not updated: %+v", r.Perpetual[10])
+	}
+
+	// The appended file reads back with the updated candle; only the open
+	// candle is fetched again.
+	src.reset(0.5, nil)
+	r, err = newCache(t, dir, src, &now).MarkPriceHistoricalData(ctx, "BTC-PERPETUAL", secs(start), to, enums.Resolution1h)
+	if err != nil || len(r.Perpetual) != 13 || r.Perpetual[10].Close != secs(start.Add(10*time.Hour))+0.5 {
+		t.Errorf("reload = %d rows, %v", len(r.Perpetual), err)
+	}
+	if w := src.windows(); len(w) != 1 || w[0].From != types.NewTimestamp(start.Add(12*time.Hour)) {
+		t.Errorf("reload fetched %v, want only the open 12:00 candle", w)
+	}
+}
+
+func TestCache_RefetchesCorruptFile(t *testing.T) {
+	dir := t.TempDir()
+	now := start.Add(30 * time.Hour)
+	src := &cacheSource{}
+	c := newCache(t, dir, src, &now)
+	ctx := context.Background()
+	from, to := secs(start), secs(start.Add(24*time.Hour))
+	if _, err := c.IndexPriceHistoricalData(ctx, "BTCUSD", from, to, enums.Resolution1h); err != nil {
+		t.Fatalf("IndexPriceHistoricalData error: %v", err)
+	}
+
+	path := filepath.Join(dir, "index", "BTCUSD", "1h", "2025-01-01.rows")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading cache file: %v", err)
+	}
+	data[len(data)/2] ^= 1
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	src.reset(0, nil)
+	r, err := c.IndexPriceHistoricalData(ctx, "BTCUSD", from, to, enums.Resolution1h)
+	if err != nil || len(r.Rows) != 24 {
+		t.Fatalf("got %d rows, %v", len(r.Rows), err)
+	}
+	if len(src.windows()) != 1 {
+		t.Error("corrupt day was not fetched again")
+	}
+	src.reset(0, nil)
+	if _, err := c.IndexPriceHistoricalData(ctx, "BTCUSD", from, to, enums.Resolution1h); err != nil || len(src.windows()) != 0 {
+		t.Errorf("rewritten day not served from disk: %v, %d fetches", err, len(src.windows()))
+	}
+}
+
+func TestCache_Prune(t *testing.T) {
+	dir := t.TempDir()
+	now := start.Add(72 * time.Hour)
+	c := newCache(t, dir, &cacheSource{}, &now)
+	if _, err := c.MarkPriceHistoricalData(context.Background(), "BTC-PERPETUAL", secs(start), secs(now), enums.Resolution1h); err != nil {
+		t.Fatalf("MarkPriceHistoricalData error: %v", err)
+	}
+	n, err := c.Prune(start.Add(48 * time.Hour))
+	if err != nil || n != 2 {
+		t.Fatalf("Prune = %d, %v; want 2", n, err)
+	}
+	left, _ := filepath.Glob(filepath.Join(dir, "mark", "*", "1h", "*.rows"))
+	if len(left) != 1 || filepath.Base(left[0]) != "2025-01-03.rows" {
+		t.Errorf("left %v", left)
+	}
+
+	if n, err := c.Prune(now); err != nil || n != 1 {
+		t.Fatalf("Prune = %d, %v; want 1", n, err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("empty directories left: %v", entries)
+	}
+}
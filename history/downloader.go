@@ -0,0 +1,289 @@
+// Package history downloads long ranges of historical market data.
+//
+// A [Downloader] splits a time range into windows the server accepts,
+// fetches them concurrently with retries and rate limiting, and streams the
+// merged rows in time order:
+//
+//	d := history.NewDownloader(restClient, history.WithConcurrency(4))
+//	for c, err := range d.Mark(ctx, "BTC-PERPETUAL", from, to, enums.Resolution1m) {
+//	    if err != nil {
+//	        return err
+//	    }
+//	    fmt.Println(c.Time, c.Close)
+//	}
+//
+// A [Cache] stores fetched data on disk and requests only what is missing;
+// it can be passed to NewDownloader in place of a client.
+package history
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"sync"
+	"time"
+
+	"github.com/amiwrpremium/go-thalex/apierr"
+	"github.com/amiwrpremium/go-thalex/enums"
+	"github.com/amiwrpremium/go-thalex/types"
+)
+
+// DefaultCandlesPerRequest is the default number of candles requested per
+// window.
+const DefaultCandlesPerRequest = 1000
+
+// Source fetches historical data. Both rest.Client and ws.Client satisfy
+// it.
+type Source interface {
+	MarkPriceHistoricalData(ctx context.Context, instrumentName string, from, to float64, resolution enums.Resolution) (types.MarkPriceHistoricalResult, error)
+	IndexPriceHistoricalData(ctx context.Context, indexName string, from, to float64, resolution enums.Resolution) (types.IndexPriceHistoricalResult, error)
+}
+
+// Option configures a Downloader.
+type Option func(*Downloader)
+
+// WithConcurrency sets how many windows are fetched at once. The default
+// is 4.
+func WithConcurrency(n int) Option {
+	return func(d *Downloader) { d.concurrency = max(n, 1) }
+}
+
+// WithRateLimit caps the number of requests started per second across all
+// downloads of the Downloader. The default is 10; 0 disables the limit.
+func WithRateLimit(perSecond float64) Option {
+	return func(d *Downloader) {
+		d.interval = 0
+		if perSecond > 0 {
+			d.interval = time.Duration(float64(time.Second) / perSecond)
+		}
+	}
+}
+
+// WithRetries sets how often a window is retried after a transient error,
+// waiting backoff, then twice as long, and so on. The default is 3 retries
+// starting at 500ms.
+func WithRetries(n int, backoff time.Duration) Option {
+	return func(d *Downloader) { d.retries, d.backoff = n, backoff }
+}
+
+// WithCandlesPerRequest sets the number of candles requested per window.
+// The default is DefaultCandlesPerRequest.
+func WithCandlesPerRequest(n int) Option {
+	return func(d *Downloader) { d.perRequest = max(n, 1) }
+}
+
+// Downloader fetches historical data in windows. It is safe for concurrent
+// use; the rate limit is shared by every download.
+type Downloader struct {
+	source      Source
+	concurrency int
+	retries     int
+	backoff     time.Duration
+	perRequest  int
+	interval    time.Duration
+
+	mu   sync.Mutex
+	next time.Time // earliest start of the next request
+}
+
+// NewDownloader returns a Downloader that fetches from source.
+func NewDownloader(source Source, opts ...Option) *Downloader {
+	d := &Downloader{
+		source:      source,
+		concurrency: 4,
+		retries:     3,
+		backoff:     500 * time.Millisecond,
+		perRequest:  DefaultCandlesPerRequest,
+		interval:    100 * time.Millisecond,
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// Mark streams mark price candles of instrumentName in [from, to), oldest
+// first. Iteration stops after the first error.
+func (d *Downloader) Mark(ctx context.Context, instrumentName string, from, to time.Time, resolution enums.Resolution) iter.Seq2[types.MarkCandle, error] {
+	return download(ctx, d, from, to, resolution, func(ctx context.Context, w Window) ([]types.MarkCandle, error) {
+		r, err := d.source.MarkPriceHistoricalData(ctx, instrumentName, w.From.Seconds(), w.To.Seconds(), resolution)
+		if err != nil {
+			return nil, err
+		}
+		return r.Candles(), nil
+	}, func(c types.MarkCandle) types.Timestamp { return c.Time })
+}
+
+// Index streams index price candles of indexName in [from, to), oldest
+// first. Iteration stops after the first error.
+func (d *Downloader) Index(ctx context.Context, indexName string, from, to time.Time, resolution enums.Resolution) iter.Seq2[types.OHLC, error] {
+	return download(ctx, d, from, to, resolution, func(ctx context.Context, w Window) ([]types.OHLC, error) {
+		r, err := d.source.IndexPriceHistoricalData(ctx, indexName, w.From.Seconds(), w.To.Seconds(), resolution)
+		if err != nil {
+			return nil, err
+		}
+		return r.Data(), nil
+	}, func(c types.OHLC) types.Timestamp { return c.Time })
+}
+
+// Window is one request's time range, [From, To).
+type Window struct {
+	From, To types.Timestamp
+}
+
+// Windows splits [from, to) into consecutive windows of at most perRequest
+// candles at resolution.
+func Windows(from, to time.Time, resolution enums.Resolution, perRequest int) ([]Window, error) {
+	step := resolution.Duration()
+	if step == 0 {
+		return nil, fmt.Errorf("history: invalid resolution %q", resolution)
+	}
+	if perRequest < 1 {
+		return nil, fmt.Errorf("history: invalid candles per request %d", perRequest)
+	}
+	size := step * time.Duration(perRequest)
+	var out []Window
+	for start := from; start.Before(to); start = start.Add(size) {
+		end := start.Add(size)
+		if end.After(to) {
+			end = to
+		}
+		out = append(out, Window{From: types.NewTimestamp(start), To: types.NewTimestamp(end)})
+	}
+	return out, nil
+}
+
+type windowResult[T any] struct {
+	rows []T
+	err  error
+}
+
+// download fetches the windows of [from, to) with up to d.concurrency
+// requests in flight and yields their rows in order. Rows outside the
+// range or not after the previous row are dropped, so windows that overlap
+// at their boundaries yield each candle once.
+func download[T any](ctx context.Context, d *Downloader, from, to time.Time, resolution enums.Resolution,
+	fetch func(context.Context, Window) ([]T, error), timeOf func(T) types.Timestamp) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		windows, err := Windows(from, to, resolution, d.perRequest)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+
+		// Stop and wait for every fetch before returning, so no request
+		// outlives the iteration.
+		ctx, cancel := context.WithCancel(ctx)
+		var wg sync.WaitGroup
+		defer wg.Wait()
+		defer cancel()
+
+		results := make([]chan windowResult[T], len(windows))
+		for i := range results {
+			results[i] = make(chan windowResult[T], 1)
+		}
+		// ahead bounds how many fetched windows may wait for the consumer.
+		ahead := make(chan struct{}, 2*d.concurrency)
+		workers := make(chan struct{}, d.concurrency)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i, w := range windows {
+				select {
+				case ahead <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+				select {
+				case workers <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					defer func() { <-workers }()
+					rows, err := fetchWindow(ctx, d, w, fetch)
+					results[i] <- windowResult[T]{rows: rows, err: err}
+				}()
+			}
+		}()
+
+		lo, hi := types.NewTimestamp(from), types.NewTimestamp(to)
+		var last types.Timestamp
+		for i := range windows {
+			var r windowResult[T]
+			select {
+			case r = <-results[i]:
+			case <-ctx.Done():
+				yield(zero, ctx.Err())
+				return
+			}
+			<-ahead
+			if r.err != nil {
+				yield(zero, fmt.Errorf("history: window %s-%s: %w", windows[i].From, windows[i].To, r.err))
+				return
+			}
+			for _, row := range r.rows {
+				t := timeOf(row)
+				if t < lo || t >= hi || (last != 0 && t <= last) {
+					continue
+				}
+				last = t
+				if !yield(row, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// fetchWindow runs fetch for one window with rate limiting, retrying
+// transient errors.
+func fetchWindow[T any](ctx context.Context, d *Downloader, w Window, fetch func(context.Context, Window) ([]T, error)) ([]T, error) {
+	backoff := d.backoff
+	for attempt := 0; ; attempt++ {
+		if err := d.wait(ctx); err != nil {
+			return nil, err
+		}
+		rows, err := fetch(ctx, w)
+		if err == nil || attempt >= d.retries || ctx.Err() != nil || !apierr.IsTransient(err) {
+			return rows, err
+		}
+		if err := sleep(ctx, backoff); err != nil {
+			return nil, err
+		}
+		backoff *= 2
+	}
+}
+
+// wait blocks until the rate limit allows another request.
+func (d *Downloader) wait(ctx context.Context) error {
+	if d.interval == 0 {
+		return nil
+	}
+	d.mu.Lock()
+	now := time.Now()
+	start := d.next
+	if start.Before(now) {
+		start = now
+	}
+	d.next = start.Add(d.interval)
+	d.mu.Unlock()
+	return sleep(ctx, start.Sub(now))
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,199 @@
+package history_test
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/amiwrpremium/go-thalex/apierr"
+	"github.com/amiwrpremium/go-thalex/enums"
+	"github.com/amiwrpremium/go-thalex/history"
+	"github.com/amiwrpremium/go-thalex/rest"
+	"github.com/amiwrpremium/go-thalex/types"
+	"github.com/amiwrpremium/go-thalex/ws"
+)
+
+var (
+	_ history.Source = (*rest.Client)(nil)
+	_ history.Source = (*ws.Client)(nil)
+)
+
+var start = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// fakeSource returns one row per minute in [from, to], including the
+// inclusive upper bound, so consecutive windows overlap by one row.
+type fakeSource struct {
+	mu       sync.Mutex
+	calls    int
+	failures map[float64]int // remaining failures by window start
+	err      error
+	inFlight atomic.Int32
+	peak     atomic.Int32
+}
+
+func (s *fakeSource) rows(from, to float64) ([][]any, error) {
+	n := s.inFlight.Add(1)
+	defer s.inFlight.Add(-1)
+	for {
+		p := s.peak.Load()
+		if n <= p || s.peak.CompareAndSwap(p, n) {
+			break
+		}
+	}
+	time.Sleep(time.Duration(rand.IntN(3)) * time.Millisecond)
+
+	s.mu.Lock()
+	s.calls++
+	if s.failures[from] > 0 {
+		s.failures[from]--
+		s.mu.Unlock()
+		return nil, s.err
+	}
+	s.mu.Unlock()
+
+	var out [][]any
+	for t := from; t <= to; t += 60 {
+		out = append(out, []any{t, t, t + 1, t - 1, t})
+	}
+	return out, nil
+}
+
+func (s *fakeSource) MarkPriceHistoricalData(_ context.Context, _ string, from, to float64, _ enums.Resolution) (types.MarkPriceHistoricalResult, error) {
+	rows, err := s.rows(from, to)
+	var perp []types.PerpetualMarkData
+	for _, r := range rows {
+		t := r[0].(float64)
+		perp = append(perp, types.PerpetualMarkData{OHLC: types.OHLC{Time: types.Timestamp(t), Close: t}})
+	}
+	return types.MarkPriceHistoricalResult{InstrumentType: enums.InstrumentTypePerpetual, Perpetual: perp}, err
+}
+
+func (s *fakeSource) IndexPriceHistoricalData(_ context.Context, _ string, from, to float64, _ enums.Resolution) (types.IndexPriceHistoricalResult, error) {
+	rows, err := s.rows(from, to)
+	return types.IndexPriceHistoricalResult{Index: rows}, err
+}
+
+func TestWindows(t *testing.T) {
+	w, err := history.Windows(start, start.Add(25*time.Minute), enums.Resolution1m, 10)
+	if err != nil {
+		t.Fatalf("Windows error: %v", err)
+	}
+	if len(w) != 3 {
+		t.Fatalf("got %d windows, want 3", len(w))
+	}
+	if w[2].From != types.NewTimestamp(start.Add(20*time.Minute)) || w[2].To != types.NewTimestamp(start.Add(25*time.Minute)) {
+		t.Errorf("last window = %+v", w[2])
+	}
+	if _, err := history.Windows(start, start.Add(time.Hour), "2h", 10); err == nil {
+		t.Error("expected error for invalid resolution")
+	}
+}
+
+func TestDownloader_IndexInOrderWithoutDuplicates(t *testing.T) {
+	src := &fakeSource{}
+	d := history.NewDownloader(src, history.WithCandlesPerRequest(7), history.WithConcurrency(3), history.WithRateLimit(0))
+
+	var got []types.Timestamp
+	for c, err := range d.Index(context.Background(), "BTCUSD", start, start.Add(100*time.Minute), enums.Resolution1m) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got = append(got, c.Time)
+	}
+	if len(got) != 100 {
+		t.Fatalf("got %d candles, want 100", len(got))
+	}
+	for i, ts := range got {
+		if want := types.NewTimestamp(start.Add(time.Duration(i) * time.Minute)); ts != want {
+			t.Fatalf("candle %d at %v, want %v", i, ts, want)
+		}
+	}
+	if src.calls != 15 {
+		t.Errorf("calls = %d, want 15", src.calls)
+	}
+	if p := src.peak.Load(); p > 3 {
+		t.Errorf("peak concurrency = %d, want <= 3", p)
+	}
+}
+
+func TestDownloader_MarkRetriesTransientErrors(t *testing.T) {
+	src := &fakeSource{
+		failures: map[float64]int{types.NewTimestamp(start.Add(10 * time.Minute)).Seconds(): 2},
+		err:      &apierr.ConnectionError{Message: "reset"},
+	}
+	d := history.NewDownloader(src, history.WithCandlesPerRequest(10), history.WithRateLimit(0), history.WithRetries(2, time.Millisecond))
+
+	n := 0
+	for c, err := range d.Mark(context.Background(), "BTC-PERPETUAL", start, start.Add(30*time.Minute), enums.Resolution1m) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if c.Perpetual == nil {
+			t.Fatal("expected perpetual candle")
+		}
+		n++
+	}
+	if n != 30 {
+		t.Errorf("got %d candles, want 30", n)
+	}
+}
+
+func TestDownloader_StopsOnError(t *testing.T) {
+	src := &fakeSource{
+		failures: map[float64]int{types.NewTimestamp(start.Add(10 * time.Minute)).Seconds(): 1},
+		err:      &apierr.APIError{Code: 1, Message: "bad request"},
+	}
+	d := history.NewDownloader(src, history.WithCandlesPerRequest(10), history.WithRateLimit(0))
+
+	n := 0
+	var gotErr error
+	for _, err := range d.Index(context.Background(), "BTCUSD", start, start.Add(30*time.Minute), enums.Resolution1m) {
+		if err != nil {
+			gotErr = err
+			break
+		}
+		n++
+	}
+	var apiErr *apierr.APIError
+	if !errors.As(gotErr, &apiErr) {
+		t.Fatalf("err = %v, want APIError", gotErr)
+	}
+	// The first window includes its upper bound, the first candle of the
+	// failed window.
+	if n != 11 {
+		t.Errorf("yielded %d candles before the failed window, want 11", n)
+	}
+}
+
+func TestDownloader_EarlyBreak(t *testing.T) {
+	src := &fakeSource{}
+	d := history.NewDownloader(src, history.WithCandlesPerRequest(5), history.WithConcurrency(2), history.WithRateLimit(0))
+	n := 0
+	for range d.Index(context.Background(), "BTCUSD", start, start.Add(24*time.Hour), enums.Resolution1m) {
+		n++
+		if n == 3 {
+			break
+		}
+	}
+	if src.calls > 10 {
+		t.Errorf("calls = %d after early break, want the look-ahead to bound fetching", src.calls)
+	}
+}
+
+func TestDownloader_RateLimit(t *testing.T) {
+	src := &fakeSource{}
+	d := history.NewDownloader(src, history.WithCandlesPerRequest(10), history.WithRateLimit(100))
+	began := time.Now()
+	for _, err := range d.Index(context.Background(), "BTCUSD", start, start.Add(50*time.Minute), enums.Resolution1m) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if elapsed := time.Since(began); elapsed < 40*time.Millisecond {
+		t.Errorf("5 requests at 100/s took %v, want at least 40ms", elapsed)
+	}
+}
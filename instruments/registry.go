@@ -0,0 +1,406 @@
+// Package instruments keeps an indexed, live view of the exchange's
+// instruments.
+//
+// A [Registry] is seeded from a REST or WebSocket client, kept current from
+// the instruments channel, and answers lookups by name, underlying, expiry,
+// strike and type without scanning. Changes are reported as [Event] values.
+//
+//	reg := instruments.NewRegistry()
+//	if err := reg.Load(ctx, restClient); err != nil {
+//	    return err
+//	}
+//	reg.Watch(wsClient)
+//	err := wsClient.Subscribe(ctx, types.ChannelInstruments)
+//
+//	inst, ok := reg.Get("BTC-PERPETUAL")
+//	strikes := reg.Strikes("BTCUSD", "2025-03-28")
+package instruments
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/amiwrpremium/go-thalex/enums"
+	"github.com/amiwrpremium/go-thalex/types"
+)
+
+// Source loads the full instrument list. Both rest.Client and ws.Client
+// implement it.
+type Source interface {
+	AllInstruments(ctx context.Context) ([]types.Instrument, error)
+}
+
+// Subscriber delivers instruments channel notifications. ws.Client
+// implements it.
+type Subscriber interface {
+	OnInstruments(fn func([]types.Instrument))
+}
+
+// EventType describes how an instrument changed.
+type EventType int
+
+const (
+	// EventAdded reports an instrument that was not in the registry.
+	EventAdded EventType = iota + 1
+	// EventRemoved reports an instrument that left the registry.
+	EventRemoved
+	// EventChanged reports an instrument whose fields changed.
+	EventChanged
+)
+
+// String returns the event type name.
+func (t EventType) String() string {
+	switch t {
+	case EventAdded:
+		return "added"
+	case EventRemoved:
+		return "removed"
+	case EventChanged:
+		return "changed"
+	}
+	return "unknown"
+}
+
+// Event is a change to the registry. For EventRemoved, Instrument is the
+// last known state. For EventChanged, Previous holds the state before.
+type Event struct {
+	Type       EventType
+	Instrument types.Instrument
+	Previous   *types.Instrument
+}
+
+// Registry is a concurrency-safe, indexed set of instruments keyed by name.
+// The zero value is not usable; create one with [NewRegistry].
+type Registry struct {
+	// emitMu serialises updates so handlers see events in the order the
+	// updates were applied. It is held while handlers run, so handlers
+	// may read from the registry but must not modify it.
+	emitMu sync.Mutex
+
+	mu           sync.RWMutex
+	byName       map[string]types.Instrument
+	byUnderlying map[string]map[string]struct{}
+	byExpiry     map[expiryKey]map[string]struct{}
+	byType       map[enums.InstrumentType]map[string]struct{}
+	handlers     []func(Event)
+}
+
+type expiryKey struct {
+	underlying string
+	date       string
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		byName:       make(map[string]types.Instrument),
+		byUnderlying: make(map[string]map[string]struct{}),
+		byExpiry:     make(map[expiryKey]map[string]struct{}),
+		byType:       make(map[enums.InstrumentType]map[string]struct{}),
+	}
+}
+
+// OnEvent registers a handler that is called for every change, in order,
+// after the change has been applied.
+func (r *Registry) OnEvent(fn func(Event)) {
+	r.mu.Lock()
+	r.handlers = append(r.handlers, fn)
+	r.mu.Unlock()
+}
+
+// Load replaces the registry contents with the instruments returned by src.
+func (r *Registry) Load(ctx context.Context, src Source) error {
+	list, err := src.AllInstruments(ctx)
+	if err != nil {
+		return err
+	}
+	r.Replace(list)
+	return nil
+}
+
+// Watch keeps the registry current from the instruments channel, which
+// reports instruments as they are activated and deactivated. A settled
+// instrument, one with a settlement price, is removed; the others are
+// added or updated. Instruments a notification does not mention are left
+// alone. The caller still subscribes to types.ChannelInstruments on the
+// client.
+func (r *Registry) Watch(sub Subscriber) {
+	sub.OnInstruments(func(list []types.Instrument) { r.applyChanges(list) })
+}
+
+// applyChanges applies one instruments channel notification.
+func (r *Registry) applyChanges(list []types.Instrument) []Event {
+	return r.update(func() []Event {
+		var events []Event
+		for _, inst := range list {
+			if inst.SettlementPrice != nil {
+				if _, ok := r.byName[inst.InstrumentName]; ok {
+					events = append(events, r.delete(inst.InstrumentName))
+				}
+				continue
+			}
+			if ev, ok := r.put(inst); ok {
+				events = append(events, ev)
+			}
+		}
+		return events
+	})
+}
+
+// Replace makes list the complete contents of the registry. Instruments
+// missing from list are removed. It returns the resulting events.
+func (r *Registry) Replace(list []types.Instrument) []Event {
+	return r.update(func() []Event {
+		seen := make(map[string]struct{}, len(list))
+		var events []Event
+		for _, inst := range list {
+			seen[inst.InstrumentName] = struct{}{}
+			if ev, ok := r.put(inst); ok {
+				events = append(events, ev)
+			}
+		}
+		for _, name := range r.sortedNames() {
+			if _, ok := seen[name]; !ok {
+				events = append(events, r.delete(name))
+			}
+		}
+		return events
+	})
+}
+
+// Upsert adds or updates the given instruments and leaves the rest alone.
+func (r *Registry) Upsert(list ...types.Instrument) []Event {
+	return r.update(func() []Event {
+		var events []Event
+		for _, inst := range list {
+			if ev, ok := r.put(inst); ok {
+				events = append(events, ev)
+			}
+		}
+		return events
+	})
+}
+
+// Remove deletes the named instruments. Unknown names are ignored.
+func (r *Registry) Remove(names ...string) []Event {
+	return r.update(func() []Event {
+		var events []Event
+		for _, name := range names {
+			if _, ok := r.byName[name]; ok {
+				events = append(events, r.delete(name))
+			}
+		}
+		return events
+	})
+}
+
+// update applies fn under the write lock and then emits its events.
+func (r *Registry) update(fn func() []Event) []Event {
+	r.emitMu.Lock()
+	defer r.emitMu.Unlock()
+
+	r.mu.Lock()
+	events := fn()
+	handlers := r.handlers
+	r.mu.Unlock()
+
+	for _, ev := range events {
+		for _, h := range handlers {
+			h(ev)
+		}
+	}
+	return events
+}
+
+// put stores inst and reports the event it caused, if any. r.mu must be
+// held.
+func (r *Registry) put(inst types.Instrument) (Event, bool) {
+	prev, exists := r.byName[inst.InstrumentName]
+	if exists && reflect.DeepEqual(prev, inst) {
+		return Event{}, false
+	}
+	if exists {
+		r.unindex(prev)
+	}
+	r.byName[inst.InstrumentName] = inst
+	r.index(inst)
+	if !exists {
+		return Event{Type: EventAdded, Instrument: inst}, true
+	}
+	return Event{Type: EventChanged, Instrument: inst, Previous: &prev}, true
+}
+
+// delete removes the named instrument. r.mu must be held.
+func (r *Registry) delete(name string) Event {
+	inst := r.byName[name]
+	delete(r.byName, name)
+	r.unindex(inst)
+	return Event{Type: EventRemoved, Instrument: inst}
+}
+
+func (r *Registry) index(inst types.Instrument) {
+	addTo(r.byUnderlying, inst.Underlying, inst.InstrumentName)
+	addTo(r.byType, inst.Type, inst.InstrumentName)
+	if inst.ExpiryDate != "" {
+		addTo(r.byExpiry, expiryKey{inst.Underlying, inst.ExpiryDate}, inst.InstrumentName)
+	}
+}
+
+func (r *Registry) unindex(inst types.Instrument) {
+	removeFrom(r.byUnderlying, inst.Underlying, inst.InstrumentName)
+	removeFrom(r.byType, inst.Type, inst.InstrumentName)
+	if inst.ExpiryDate != "" {
+		removeFrom(r.byExpiry, expiryKey{inst.Underlying, inst.ExpiryDate}, inst.InstrumentName)
+	}
+}
+
+func addTo[K comparable](m map[K]map[string]struct{}, key K, name string) {
+	set := m[key]
+	if set == nil {
+		set = make(map[string]struct{})
+		m[key] = set
+	}
+	set[name] = struct{}{}
+}
+
+func removeFrom[K comparable](m map[K]map[string]struct{}, key K, name string) {
+	set := m[key]
+	delete(set, name)
+	if len(set) == 0 {
+		delete(m, key)
+	}
+}
+
+// --- Lookups ---
+
+// Len returns the number of instruments in the registry.
+func (r *Registry) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.byName)
+}
+
+// Get returns the named instrument.
+func (r *Registry) Get(name string) (types.Instrument, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	inst, ok := r.byName[name]
+	return inst, ok
+}
+
+// All returns every instrument, sorted by name.
+func (r *Registry) All() []types.Instrument {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.lookup(r.sortedNames())
+}
+
+// ByUnderlying returns the instruments on an underlying such as "BTCUSD",
+// sorted by name.
+func (r *Registry) ByUnderlying(underlying string) []types.Instrument {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.collect(r.byUnderlying[underlying])
+}
+
+// ByType returns the instruments of the given type, sorted by name.
+func (r *Registry) ByType(t enums.InstrumentType) []types.Instrument {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.collect(r.byType[t])
+}
+
+// ByExpiry returns the futures and options on underlying that expire on
+// date (YYYY-MM-DD), sorted by name.
+func (r *Registry) ByExpiry(underlying, date string) []types.Instrument {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.collect(r.byExpiry[expiryKey{underlying, date}])
+}
+
+// Options returns the options on underlying that expire on date with the
+// given strike, typically a call and a put.
+func (r *Registry) Options(underlying, date string, strike float64) []types.Instrument {
+	var out []types.Instrument
+	for _, inst := range r.ByExpiry(underlying, date) {
+		if inst.IsOption() && inst.StrikePrice != nil && *inst.StrikePrice == strike {
+			out = append(out, inst)
+		}
+	}
+	return out
+}
+
+// Underlyings returns the distinct underlyings, sorted.
+func (r *Registry) Underlyings() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]string, 0, len(r.byUnderlying))
+	for u := range r.byUnderlying {
+		out = append(out, u)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Expiries returns the distinct expiry dates on underlying in ascending
+// order.
+func (r *Registry) Expiries(underlying string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var out []string
+	for k := range r.byExpiry {
+		if k.underlying == underlying {
+			out = append(out, k.date)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Strikes returns the distinct option strikes on underlying for an expiry
+// date in ascending order.
+func (r *Registry) Strikes(underlying, date string) []float64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var out []float64
+	for name := range r.byExpiry[expiryKey{underlying, date}] {
+		if inst := r.byName[name]; inst.IsOption() && inst.StrikePrice != nil {
+			out = append(out, *inst.StrikePrice)
+		}
+	}
+	slices.Sort(out)
+	return slices.Compact(out)
+}
+
+// collect returns the instruments named in set, sorted by name. r.mu must
+// be held.
+func (r *Registry) collect(set map[string]struct{}) []types.Instrument {
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return r.lookup(names)
+}
+
+// lookup returns the named instruments in order. r.mu must be held.
+func (r *Registry) lookup(names []string) []types.Instrument {
+	out := make([]types.Instrument, len(names))
+	for i, name := range names {
+		out[i] = r.byName[name]
+	}
+	return out
+}
+
+// sortedNames returns every instrument name, sorted. r.mu must be held.
+func (r *Registry) sortedNames() []string {
+	names := make([]string, 0, len(r.byName))
+	for name := range r.byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
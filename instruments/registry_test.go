@@ -0,0 +1,209 @@
+package instruments_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/amiwrpremium/go-thalex/enums"
+	"github.com/amiwrpremium/go-thalex/instruments"
+	"github.com/amiwrpremium/go-thalex/rest"
+	"github.com/amiwrpremium/go-thalex/types"
+	"github.com/amiwrpremium/go-thalex/ws"
+)
+
+func option(name, expiry string, strike float64, optType enums.OptionType) types.Instrument {
+	return types.Instrument{
+		InstrumentName: name, Underlying: "BTCUSD", Type: enums.InstrumentTypeOption,
+		OptionType: optType, ExpiryDate: expiry, StrikePrice: &strike, TickSize: 0.0005,
+	}
+}
+
+func fixture() []types.Instrument {
+	return []types.Instrument{
+		{InstrumentName: "BTC-PERPETUAL", Underlying: "BTCUSD", Type: enums.InstrumentTypePerpetual, TickSize: 0.5},
+		{InstrumentName: "ETH-PERPETUAL", Underlying: "ETHUSD", Type: enums.InstrumentTypePerpetual, TickSize: 0.05},
+		{InstrumentName: "BTC-28MAR25", Underlying: "BTCUSD", Type: enums.InstrumentTypeFuture, ExpiryDate: "2025-03-28", TickSize: 0.5},
+		option("BTC-28MAR25-100000-C", "2025-03-28", 100000, enums.OptionTypeCall),
+		option("BTC-28MAR25-100000-P", "2025-03-28", 100000, enums.OptionTypePut),
+		option("BTC-28MAR25-90000-C", "2025-03-28", 90000, enums.OptionTypeCall),
+		option("BTC-27JUN25-100000-C", "2025-06-27", 100000, enums.OptionTypeCall),
+	}
+}
+
+type fakeSource struct {
+	list []types.Instrument
+	err  error
+}
+
+func (s fakeSource) AllInstruments(context.Context) ([]types.Instrument, error) { return s.list, s.err }
+
+type fakeSubscriber struct{ fn func([]types.Instrument) }
+
+func (s *fakeSubscriber) OnInstruments(fn func([]types.Instrument)) { s.fn = fn }
+
+func names(list []types.Instrument) []string {
+	out := make([]string, len(list))
+	for i, inst := range list {
+		out[i] = inst.InstrumentName
+	}
+	return out
+}
+
+func TestRegistry_Lookups(t *testing.T) {
+	reg := instruments.NewRegistry()
+	if err := reg.Load(context.Background(), fakeSource{list: fixture()}); err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	if reg.Len() != 7 {
+		t.Fatalf("Len = %d, want 7", reg.Len())
+	}
+	if inst, ok := reg.Get("BTC-PERPETUAL"); !ok || inst.TickSize != 0.5 {
+		t.Errorf("Get(BTC-PERPETUAL) = %+v, %v", inst, ok)
+	}
+	if _, ok := reg.Get("DOGE-PERPETUAL"); ok {
+		t.Error("unexpected instrument")
+	}
+	if got := names(reg.ByUnderlying("ETHUSD")); !reflect.DeepEqual(got, []string{"ETH-PERPETUAL"}) {
+		t.Errorf("ByUnderlying = %v", got)
+	}
+	if got := names(reg.ByType(enums.InstrumentTypePerpetual)); !reflect.DeepEqual(got, []string{"BTC-PERPETUAL", "ETH-PERPETUAL"}) {
+		t.Errorf("ByType = %v", got)
+	}
+	if got := len(reg.ByExpiry("BTCUSD", "2025-03-28")); got != 4 {
+		t.Errorf("ByExpiry returned %d instruments, want 4", got)
+	}
+	if got := reg.Expiries("BTCUSD"); !reflect.DeepEqual(got, []string{"2025-03-28", "2025-06-27"}) {
+		t.Errorf("Expiries = %v", got)
+	}
+	if got := reg.Strikes("BTCUSD", "2025-03-28"); !reflect.DeepEqual(got, []float64{90000, 100000}) {
+		t.Errorf("Strikes = %v", got)
+	}
+	if got := names(reg.Options("BTCUSD", "2025-03-28", 100000)); !reflect.DeepEqual(got, []string{"BTC-28MAR25-100000-C", "BTC-28MAR25-100000-P"}) {
+		t.Errorf("Options = %v", got)
+	}
+	if got := reg.Underlyings(); !reflect.DeepEqual(got, []string{"BTCUSD", "ETHUSD"}) {
+		t.Errorf("Underlyings = %v", got)
+	}
+}
+
+func TestRegistry_LoadError(t *testing.T) {
+	want := errors.New("boom")
+	if err := instruments.NewRegistry().Load(context.Background(), fakeSource{err: want}); !errors.Is(err, want) {
+		t.Errorf("Load error = %v, want %v", err, want)
+	}
+}
+
+func TestRegistry_WatchEmitsEvents(t *testing.T) {
+	reg := instruments.NewRegistry()
+	reg.Replace(fixture())
+
+	var events []instruments.Event
+	reg.OnEvent(func(ev instruments.Event) { events = append(events, ev) })
+	sub := &fakeSubscriber{}
+	reg.Watch(sub)
+
+	// Notifications carry only the instruments that changed.
+	settled := fixture()[2]
+	price := 84500.0
+	settled.SettlementPrice = &price
+	eth := fixture()[1]
+	eth.TickSize = 0.01
+	next := []types.Instrument{eth, option("BTC-27JUN25-90000-C", "2025-06-27", 90000, enums.OptionTypeCall), settled}
+	sub.fn(next)
+
+	want := []struct {
+		typ  instruments.EventType
+		name string
+	}{
+		{instruments.EventChanged, "ETH-PERPETUAL"},
+		{instruments.EventAdded, "BTC-27JUN25-90000-C"},
+		{instruments.EventRemoved, "BTC-28MAR25"},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d: %+v", len(events), len(want), events)
+	}
+	for i, w := range want {
+		if events[i].Type != w.typ || events[i].Instrument.InstrumentName != w.name {
+			t.Errorf("event %d = %s %s, want %s %s", i, events[i].Type, events[i].Instrument.InstrumentName, w.typ, w.name)
+		}
+	}
+	if prev := events[0].Previous; prev == nil || prev.TickSize != 0.05 {
+		t.Errorf("Previous = %+v", prev)
+	}
+	if got := reg.Strikes("BTCUSD", "2025-06-27"); !reflect.DeepEqual(got, []float64{90000, 100000}) {
+		t.Errorf("index not updated: %v", got)
+	}
+	if got := reg.ByUnderlying("BTCUSD"); len(got) != 6 {
+		t.Errorf("ByUnderlying returned %d, want 6", len(got))
+	}
+	if _, ok := reg.Get("BTC-PERPETUAL"); !ok {
+		t.Error("instrument missing from the notification was removed")
+	}
+
+	events = nil
+	sub.fn(next)
+	if len(events) != 0 {
+		t.Errorf("unchanged notification emitted %+v", events)
+	}
+}
+
+func TestRegistry_UpsertRemove(t *testing.T) {
+	reg := instruments.NewRegistry()
+	reg.Upsert(fixture()...)
+	evs := reg.Remove("BTC-28MAR25-90000-C", "missing")
+	if len(evs) != 1 || evs[0].Type != instruments.EventRemoved {
+		t.Errorf("Remove events = %+v", evs)
+	}
+	if got := reg.Strikes("BTCUSD", "2025-03-28"); !reflect.DeepEqual(got, []float64{100000}) {
+		t.Errorf("Strikes after remove = %v", got)
+	}
+	reg.Remove("BTC-28MAR25", "BTC-28MAR25-100000-C", "BTC-28MAR25-100000-P")
+	if got := reg.Expiries("BTCUSD"); !reflect.DeepEqual(got, []string{"2025-06-27"}) {
+		t.Errorf("Expiries after removing an expiry = %v", got)
+	}
+}
+
+func TestRegistry_Snapshot(t *testing.T) {
+	reg := instruments.NewRegistry()
+	reg.Replace(fixture())
+	path := filepath.Join(t.TempDir(), "instruments.json")
+	if err := reg.SaveFile(path); err != nil {
+		t.Fatalf("SaveFile error: %v", err)
+	}
+
+	warm := instruments.NewRegistry()
+	added := 0
+	warm.OnEvent(func(ev instruments.Event) {
+		if ev.Type == instruments.EventAdded {
+			added++
+		}
+	})
+	snap, err := warm.LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile error: %v", err)
+	}
+	if snap.SavedAt.IsZero() || added != 7 {
+		t.Errorf("SavedAt=%v added=%d", snap.SavedAt, added)
+	}
+	if !reflect.DeepEqual(warm.All(), reg.All()) {
+		t.Error("restored registry differs from the original")
+	}
+
+	if _, err := warm.ReadSnapshot(bytes.NewBufferString(`{"version":99}`)); err == nil {
+		t.Error("expected error for unknown snapshot version")
+	}
+	if _, err := warm.LoadFile(filepath.Join(t.TempDir(), "missing.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("LoadFile(missing) error = %v", err)
+	}
+}
+
+var (
+	_ instruments.Source     = (*rest.Client)(nil)
+	_ instruments.Source     = (*ws.Client)(nil)
+	_ instruments.Subscriber = (*ws.Client)(nil)
+)
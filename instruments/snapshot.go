@@ -0,0 +1,82 @@
+package instruments
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/amiwrpremium/go-thalex/types"
+)
+
+// snapshotVersion is bumped whenever the snapshot layout changes
+// incompatibly.
+const snapshotVersion = 1
+
+// Snapshot is the persisted form of a registry.
+type Snapshot struct {
+	Version     int                `json:"version"`
+	SavedAt     types.Timestamp    `json:"saved_at"`
+	Instruments []types.Instrument `json:"instruments"`
+}
+
+// Snapshot returns the current contents of the registry.
+func (r *Registry) Snapshot() Snapshot {
+	return Snapshot{
+		Version:     snapshotVersion,
+		SavedAt:     types.TimestampNow(),
+		Instruments: r.All(),
+	}
+}
+
+// WriteSnapshot writes the registry contents to w as JSON.
+func (r *Registry) WriteSnapshot(w io.Writer) error {
+	return json.NewEncoder(w).Encode(r.Snapshot())
+}
+
+// ReadSnapshot replaces the registry contents with a snapshot read from
+// rd and returns it. Events are emitted as for [Registry.Replace], so a
+// warm start looks like a load to event handlers.
+func (r *Registry) ReadSnapshot(rd io.Reader) (Snapshot, error) {
+	var s Snapshot
+	if err := json.NewDecoder(rd).Decode(&s); err != nil {
+		return Snapshot{}, fmt.Errorf("instruments: decoding snapshot: %w", err)
+	}
+	if s.Version != snapshotVersion {
+		return Snapshot{}, fmt.Errorf("instruments: unsupported snapshot version %d", s.Version)
+	}
+	r.Replace(s.Instruments)
+	return s, nil
+}
+
+// SaveFile writes a snapshot to path. The file is replaced atomically so a
+// crash never leaves a truncated snapshot behind.
+func (r *Registry) SaveFile(path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("instruments: saving snapshot: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if err := r.WriteSnapshot(tmp); err != nil {
+		tmp.Close()
+		return fmt.Errorf("instruments: saving snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("instruments: saving snapshot: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("instruments: saving snapshot: %w", err)
+	}
+	return nil
+}
+
+// LoadFile replaces the registry contents with the snapshot at path.
+func (r *Registry) LoadFile(path string) (Snapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("instruments: loading snapshot: %w", err)
+	}
+	defer f.Close()
+	return r.ReadSnapshot(f)
+}
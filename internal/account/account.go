@@ -0,0 +1,58 @@
+// Package account carries per-call account overrides through a context and
+// fans reads out across several accounts.
+package account
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+type contextKey struct{}
+
+// WithAccount returns a copy of ctx that routes requests to the given account.
+func WithAccount(ctx context.Context, account string) context.Context {
+	return context.WithValue(ctx, contextKey{}, account)
+}
+
+// FromContext returns the account override carried by ctx, if any.
+// An empty account is treated as no override.
+func FromContext(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	account, ok := ctx.Value(contextKey{}).(string)
+	if !ok || account == "" {
+		return "", false
+	}
+	return account, true
+}
+
+// FanOut calls fn concurrently once per account, each with a context carrying
+// that account. Results of successful calls are keyed by account; failures are
+// joined into the returned error and prefixed with the account they belong to.
+func FanOut[T any](ctx context.Context, accounts []string, fn func(ctx context.Context, account string) (T, error)) (map[string]T, error) {
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = make(map[string]T, len(accounts))
+		errs    []error
+	)
+	for _, acct := range accounts {
+		wg.Add(1)
+		go func(acct string) {
+			defer wg.Done()
+			v, err := fn(WithAccount(ctx, acct), acct)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("account %s: %w", acct, err))
+				return
+			}
+			results[acct] = v
+		}(acct)
+	}
+	wg.Wait()
+	return results, errors.Join(errs...)
+}
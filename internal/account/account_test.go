@@ -0,0 +1,91 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestFromContext(t *testing.T) {
+	t.Run("no override", func(t *testing.T) {
+		if _, ok := FromContext(context.Background()); ok {
+			t.Error("expected no account in a bare context")
+		}
+	})
+
+	t.Run("override set", func(t *testing.T) {
+		ctx := WithAccount(context.Background(), "A123")
+		got, ok := FromContext(ctx)
+		if !ok || got != "A123" {
+			t.Errorf("FromContext = (%q, %v); want (%q, true)", got, ok, "A123")
+		}
+	})
+
+	t.Run("empty override is ignored", func(t *testing.T) {
+		ctx := WithAccount(context.Background(), "")
+		if _, ok := FromContext(ctx); ok {
+			t.Error("expected empty account to be treated as no override")
+		}
+	})
+
+	t.Run("nil context", func(t *testing.T) {
+		//nolint:staticcheck // exercising nil-context guard
+		if _, ok := FromContext(nil); ok {
+			t.Error("expected no account in a nil context")
+		}
+	})
+}
+
+func TestFanOut(t *testing.T) {
+	t.Run("collects results per account", func(t *testing.T) {
+		accounts := []string{"A1", "A2", "A3"}
+		got, err := FanOut(context.Background(), accounts, func(ctx context.Context, acct string) (string, error) {
+			fromCtx, _ := FromContext(ctx)
+			return fromCtx + "-ok", nil
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(got) != 3 {
+			t.Fatalf("len = %d; want 3", len(got))
+		}
+		for _, a := range accounts {
+			if got[a] != a+"-ok" {
+				t.Errorf("result[%s] = %q; want %q", a, got[a], a+"-ok")
+			}
+		}
+	})
+
+	t.Run("partial failure", func(t *testing.T) {
+		boom := errors.New("boom")
+		got, err := FanOut(context.Background(), []string{"A1", "A2"}, func(_ context.Context, acct string) (int, error) {
+			if acct == "A2" {
+				return 0, boom
+			}
+			return 1, nil
+		})
+		if !errors.Is(err, boom) {
+			t.Fatalf("expected joined error to wrap boom, got %v", err)
+		}
+		if !strings.Contains(err.Error(), "account A2") {
+			t.Errorf("error %q should name the failing account", err)
+		}
+		if got["A1"] != 1 {
+			t.Errorf("result[A1] = %d; want 1", got["A1"])
+		}
+		if _, ok := got["A2"]; ok {
+			t.Error("failed account should not have a result")
+		}
+	})
+
+	t.Run("no accounts", func(t *testing.T) {
+		got, err := FanOut(context.Background(), nil, func(context.Context, string) (int, error) {
+			t.Error("fn should not be called")
+			return 0, nil
+		})
+		if err != nil || len(got) != 0 {
+			t.Errorf("FanOut(nil) = (%v, %v); want empty, nil", got, err)
+		}
+	})
+}
@@ -0,0 +1,451 @@
+// Package fastjson is a small allocation-free JSON reader for the hot
+// market data decoding paths.
+//
+// It only accepts input that it decodes exactly as encoding/json would.
+// Anything else, whether malformed or merely unusual (escaped or non-ASCII
+// strings, keys that match a field only case-insensitively, duplicate
+// keys, out-of-range numbers), makes the reader fail with ErrFallback, and
+// callers then decode the same input with encoding/json. The fast path
+// therefore never changes a result, only how quickly it is produced.
+package fastjson
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+// ErrFallback reports that the input must be decoded with encoding/json.
+var ErrFallback = errors.New("fastjson: input needs encoding/json")
+
+// maxDepth bounds the nesting Skip follows before giving up.
+const maxDepth = 512
+
+// Reader reads JSON values from a byte slice. Errors are sticky: after the
+// first failure every method is a no-op and Err returns ErrFallback.
+//
+// The zero value reads an empty input; call Reset to read data.
+type Reader struct {
+	data   []byte
+	pos    int
+	failed bool
+}
+
+// Reset makes r read data from the start.
+func (r *Reader) Reset(data []byte) {
+	r.data, r.pos, r.failed = data, 0, false
+}
+
+// Err returns ErrFallback if reading failed.
+func (r *Reader) Err() error {
+	if r.failed {
+		return ErrFallback
+	}
+	return nil
+}
+
+// Fail marks the input as needing encoding/json.
+func (r *Reader) Fail() {
+	r.failed = true
+	r.pos = len(r.data)
+}
+
+// End checks that only whitespace is left and returns Err.
+func (r *Reader) End() error {
+	if r.peek() != 0 || r.pos != len(r.data) {
+		r.Fail()
+	}
+	return r.Err()
+}
+
+// peek skips whitespace and returns the next byte, or 0 at the end.
+func (r *Reader) peek() byte {
+	for r.pos < len(r.data) {
+		switch c := r.data[r.pos]; c {
+		case ' ', '\t', '\n', '\r':
+			r.pos++
+		default:
+			return c
+		}
+	}
+	return 0
+}
+
+// Null consumes a null literal if one is next.
+func (r *Reader) Null() bool {
+	if r.failed || r.peek() != 'n' {
+		return false
+	}
+	if !r.literal("null") {
+		r.Fail()
+		return false
+	}
+	return true
+}
+
+func (r *Reader) literal(lit string) bool {
+	if len(r.data)-r.pos < len(lit) || string(r.data[r.pos:r.pos+len(lit)]) != lit {
+		return false
+	}
+	r.pos += len(lit)
+	return true
+}
+
+// BeginObject consumes the '{' opening an object.
+func (r *Reader) BeginObject() bool { return r.begin('{') }
+
+// BeginArray consumes the '[' opening an array.
+func (r *Reader) BeginArray() bool { return r.begin('[') }
+
+func (r *Reader) begin(c byte) bool {
+	if r.failed {
+		return false
+	}
+	if r.peek() != c {
+		r.Fail()
+		return false
+	}
+	r.pos++
+	return true
+}
+
+// NextField reports whether the object has another member, consuming the
+// separating comma or the closing '}'. i is the number of members already
+// read:
+//
+//	for i := 0; r.NextField(i); i++ {
+//		switch string(r.Key()) { ... }
+//	}
+func (r *Reader) NextField(i int) bool { return r.next('}', i) }
+
+// NextElement is NextField for arrays.
+func (r *Reader) NextElement(i int) bool { return r.next(']', i) }
+
+func (r *Reader) next(end byte, i int) bool {
+	if r.failed {
+		return false
+	}
+	c := r.peek()
+	if c == end {
+		r.pos++
+		return false
+	}
+	if i > 0 {
+		if c != ',' {
+			r.Fail()
+			return false
+		}
+		r.pos++
+	}
+	return true
+}
+
+// Key reads an object key and its ':' separator. The returned slice aliases
+// the input. Keys that are not plain ASCII fail.
+func (r *Reader) Key() []byte {
+	key, ok := r.plainString()
+	if !ok || r.peek() != ':' {
+		r.Fail()
+		return nil
+	}
+	r.pos++
+	return key
+}
+
+// UnknownKey skips the value of a key that matched none of known. A key that
+// encoding/json would match case-insensitively to one of them fails.
+func (r *Reader) UnknownKey(key []byte, known []string) {
+	for _, k := range known {
+		if strings.EqualFold(unsafe.String(unsafe.SliceData(key), len(key)), k) {
+			r.Fail()
+			return
+		}
+	}
+	r.Skip()
+}
+
+// plainString reads a string without escapes or non-ASCII bytes and returns
+// its contents.
+func (r *Reader) plainString() ([]byte, bool) {
+	if r.failed || r.peek() != '"' {
+		return nil, false
+	}
+	start := r.pos + 1
+	for i := start; i < len(r.data); i++ {
+		switch c := r.data[i]; {
+		case c == '"':
+			r.pos = i + 1
+			return r.data[start:i], true
+		case c < 0x20 || c == '\\' || c >= 0x80:
+			return nil, false
+		}
+	}
+	return nil, false
+}
+
+// String reads a plain ASCII string into dst. Null leaves dst unchanged.
+func (r *Reader) String(dst *string) {
+	if r.Null() {
+		return
+	}
+	s, ok := r.plainString()
+	if !ok {
+		r.Fail()
+		return
+	}
+	*dst = string(s)
+}
+
+// Enum reads a plain ASCII string into dst like String, but stores the
+// matching element of values, if any, instead of allocating a copy.
+func (r *Reader) Enum(dst *string, values []string) {
+	if r.Null() {
+		return
+	}
+	s, ok := r.plainString()
+	if !ok {
+		r.Fail()
+		return
+	}
+	for _, v := range values {
+		if string(s) == v {
+			*dst = v
+			return
+		}
+	}
+	*dst = string(s)
+}
+
+// Float reads a number into dst. Null leaves dst unchanged.
+func (r *Reader) Float(dst *float64) {
+	if r.Null() {
+		return
+	}
+	if v, ok := r.float(); ok {
+		*dst = v
+	}
+}
+
+// FloatPtr reads a number into a new *float64 stored in dst. Null sets dst
+// to nil.
+func (r *Reader) FloatPtr(dst **float64) {
+	if r.Null() {
+		*dst = nil
+		return
+	}
+	if v, ok := r.float(); ok {
+		*dst = &v
+	}
+}
+
+// FloatPtrIn is FloatPtr storing the number in slot, so that several
+// optional fields can share one allocation.
+func (r *Reader) FloatPtrIn(dst **float64, slot *float64) {
+	if r.Null() {
+		*dst = nil
+		return
+	}
+	if v, ok := r.float(); ok {
+		*slot = v
+		*dst = slot
+	}
+}
+
+func (r *Reader) float() (float64, bool) {
+	num, ok := r.number()
+	if !ok {
+		r.Fail()
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(unsafe.String(unsafe.SliceData(num), len(num)), 64)
+	if err != nil {
+		r.Fail()
+		return 0, false
+	}
+	return v, true
+}
+
+// Uint reads a non-negative integer.
+func (r *Reader) Uint() uint64 {
+	num, ok := r.number()
+	if !ok {
+		r.Fail()
+		return 0
+	}
+	v, err := strconv.ParseUint(unsafe.String(unsafe.SliceData(num), len(num)), 10, 64)
+	if err != nil {
+		r.Fail()
+		return 0
+	}
+	return v
+}
+
+// Int reads an integer that fits in an int. Null leaves dst unchanged.
+func (r *Reader) Int(dst *int) {
+	if r.Null() {
+		return
+	}
+	num, ok := r.number()
+	if !ok {
+		r.Fail()
+		return
+	}
+	v, err := strconv.ParseInt(unsafe.String(unsafe.SliceData(num), len(num)), 10, strconv.IntSize)
+	if err != nil {
+		r.Fail()
+		return
+	}
+	*dst = int(v)
+}
+
+// number reads a literal matching the JSON number grammar.
+func (r *Reader) number() ([]byte, bool) {
+	if r.failed {
+		return nil, false
+	}
+	r.peek()
+	d, start := r.data, r.pos
+	i := start
+	if i < len(d) && d[i] == '-' {
+		i++
+	}
+	switch {
+	case i < len(d) && d[i] == '0':
+		i++
+	case i < len(d) && d[i] >= '1' && d[i] <= '9':
+		i = digits(d, i)
+	default:
+		return nil, false
+	}
+	if i < len(d) && d[i] == '.' {
+		if i = digits(d, i+1); d[i-1] == '.' {
+			return nil, false
+		}
+	}
+	if i < len(d) && (d[i] == 'e' || d[i] == 'E') {
+		i++
+		if i < len(d) && (d[i] == '+' || d[i] == '-') {
+			i++
+		}
+		j := digits(d, i)
+		if j == i {
+			return nil, false
+		}
+		i = j
+	}
+	r.pos = i
+	return d[start:i], true
+}
+
+func digits(d []byte, i int) int {
+	for i < len(d) && d[i] >= '0' && d[i] <= '9' {
+		i++
+	}
+	return i
+}
+
+// Raw skips the next value and returns its bytes, which alias the input.
+func (r *Reader) Raw() []byte {
+	r.peek()
+	start := r.pos
+	r.Skip()
+	if r.failed {
+		return nil
+	}
+	return r.data[start:r.pos]
+}
+
+// Skip validates and skips the next value.
+func (r *Reader) Skip() {
+	r.skip(0)
+}
+
+func (r *Reader) skip(depth int) {
+	if r.failed {
+		return
+	}
+	if depth > maxDepth {
+		r.Fail()
+		return
+	}
+	switch r.peek() {
+	case '{':
+		r.pos++
+		for i := 0; r.NextField(i); i++ {
+			if !r.skipString() || r.peek() != ':' {
+				r.Fail()
+				return
+			}
+			r.pos++
+			r.skip(depth + 1)
+		}
+	case '[':
+		r.pos++
+		for i := 0; r.NextElement(i); i++ {
+			r.skip(depth + 1)
+		}
+	case '"':
+		if !r.skipString() {
+			r.Fail()
+		}
+	case 't':
+		if !r.literal("true") {
+			r.Fail()
+		}
+	case 'f':
+		if !r.literal("false") {
+			r.Fail()
+		}
+	case 'n':
+		if !r.literal("null") {
+			r.Fail()
+		}
+	default:
+		if _, ok := r.number(); !ok {
+			r.Fail()
+		}
+	}
+}
+
+// skipString skips any valid string, including escapes.
+func (r *Reader) skipString() bool {
+	if r.peek() != '"' {
+		return false
+	}
+	d := r.data
+	for i := r.pos + 1; i < len(d); i++ {
+		switch c := d[i]; {
+		case c == '"':
+			r.pos = i + 1
+			return true
+		case c < 0x20:
+			return false
+		case c == '\\':
+			i++
+			if i >= len(d) {
+				return false
+			}
+			switch d[i] {
+			case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+			case 'u':
+				if i+4 >= len(d) {
+					return false
+				}
+				for _, h := range d[i+1 : i+5] {
+					if !isHex(h) {
+						return false
+					}
+				}
+				i += 4
+			default:
+				return false
+			}
+		}
+	}
+	return false
+}
+
+func isHex(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
@@ -0,0 +1,163 @@
+package fastjson
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestReader_Object(t *testing.T) {
+	var (
+		r     Reader
+		name  string
+		price float64
+		size  *float64
+		code  int
+		id    uint64
+	)
+	r.Reset([]byte(` {"name":"BTC","price":-1.5e2,"size":null,"code":-7,"id":12,"extra":[{"a":"é"},true,false,null]} `))
+	if !r.BeginObject() {
+		t.Fatal("BeginObject failed")
+	}
+	for i := 0; r.NextField(i); i++ {
+		switch key := r.Key(); string(key) {
+		case "name":
+			r.String(&name)
+		case "price":
+			r.Float(&price)
+		case "size":
+			r.FloatPtr(&size)
+		case "code":
+			r.Int(&code)
+		case "id":
+			id = r.Uint()
+		default:
+			r.UnknownKey(key, []string{"name", "price", "size", "code", "id"})
+		}
+	}
+	if err := r.End(); err != nil {
+		t.Fatalf("End: %v", err)
+	}
+	if name != "BTC" || price != -150 || size != nil || code != -7 || id != 12 {
+		t.Errorf("got %q %v %v %d %d", name, price, size, code, id)
+	}
+}
+
+func TestReader_Fallback(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		read func(*Reader)
+	}{
+		{"escaped string", `"a\"b"`, func(r *Reader) { var s string; r.String(&s) }},
+		{"non-ASCII string", `"é"`, func(r *Reader) { var s string; r.String(&s) }},
+		{"float range", `1e400`, func(r *Reader) { var f float64; r.Float(&f) }},
+		{"string as float", `"1"`, func(r *Reader) { var f float64; r.Float(&f) }},
+		{"leading zero", `01`, func(r *Reader) { var f float64; r.Float(&f) }},
+		{"bare dot", `1.`, func(r *Reader) { var f float64; r.Float(&f) }},
+		{"fractional int", `1.5`, func(r *Reader) { var n int; r.Int(&n) }},
+		{"negative uint", `-1`, func(r *Reader) { r.Uint() }},
+		{"folded key", `{"Name":1}`, func(r *Reader) {
+			r.BeginObject()
+			for i := 0; r.NextField(i); i++ {
+				r.UnknownKey(r.Key(), []string{"name"})
+			}
+		}},
+		{"trailing comma", `[1,]`, func(r *Reader) { r.Skip() }},
+		{"missing comma", `{"a":1 "b":2}`, func(r *Reader) { r.Skip() }},
+		{"bad literal", `nul`, func(r *Reader) { r.Skip() }},
+		{"trailing data", `1 2`, func(r *Reader) { r.Skip() }},
+		{"control character", "\"a\tb\"", func(r *Reader) { r.Skip() }},
+		{"bad escape", `"\x"`, func(r *Reader) { r.Skip() }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var r Reader
+			r.Reset([]byte(tt.data))
+			tt.read(&r)
+			if err := r.End(); !errors.Is(err, ErrFallback) {
+				t.Errorf("End = %v, want ErrFallback", err)
+			}
+		})
+	}
+}
+
+func TestReader_NullLeavesValues(t *testing.T) {
+	var r Reader
+	r.Reset([]byte(`[null,null,null]`))
+	s, f, n := "keep", 1.5, 3
+	r.BeginArray()
+	r.NextElement(0)
+	r.String(&s)
+	r.NextElement(1)
+	r.Float(&f)
+	r.NextElement(2)
+	r.Int(&n)
+	r.NextElement(3)
+	if err := r.End(); err != nil || s != "keep" || f != 1.5 || n != 3 {
+		t.Errorf("got %q %v %d, %v", s, f, n, err)
+	}
+}
+
+func TestReader_DeepNesting(t *testing.T) {
+	data := make([]byte, 0, 2*(maxDepth+2))
+	for range maxDepth + 2 {
+		data = append(data, '[')
+	}
+	for range maxDepth + 2 {
+		data = append(data, ']')
+	}
+	var r Reader
+	r.Reset(data)
+	r.Skip()
+	if err := r.End(); !errors.Is(err, ErrFallback) {
+		t.Errorf("End = %v, want ErrFallback", err)
+	}
+}
+
+// FuzzSkip checks that Skip accepts exactly the inputs encoding/json does.
+func FuzzSkip(f *testing.F) {
+	for _, s := range []string{
+		`{"a":[1,-2.5e+3,"xA\n"],"b":{"c":null,"d":true,"e":false}}`,
+		`[]`, `{}`, `""`, `0`, `-0.0E-0`, `[1,]`, `{"a" 1}`, `"\ud800"`, "\"\xff\"",
+	} {
+		f.Add([]byte(s))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if len(data) > maxDepth {
+			return
+		}
+		var r Reader
+		r.Reset(data)
+		r.Skip()
+		ok := r.End() == nil
+		if ok != json.Valid(data) {
+			t.Fatalf("Skip ok = %v, json.Valid = %v for %q", ok, !ok, data)
+		}
+	})
+}
+
+// FuzzFloat checks that Float agrees with encoding/json whenever it
+// succeeds.
+func FuzzFloat(f *testing.F) {
+	for _, s := range []string{`0`, `-1.25`, `1e308`, `1e309`, `4.9e-324`, `1e-400`, `null`, `"1"`} {
+		f.Add([]byte(s))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		got := math.Pi
+		var r Reader
+		r.Reset(data)
+		r.Float(&got)
+		if r.End() != nil {
+			return
+		}
+		want := math.Pi
+		if err := json.Unmarshal(data, &want); err != nil {
+			t.Fatalf("fast path accepted %q, encoding/json: %v", data, err)
+		}
+		if math.Float64bits(got) != math.Float64bits(want) {
+			t.Fatalf("%q: got %v, want %v", data, got, want)
+		}
+	})
+}
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"sync/atomic"
+
+	"github.com/amiwrpremium/go-thalex/internal/fastjson"
 )
 
 // Version is the JSON-RPC protocol version.
@@ -58,7 +60,127 @@ type Message struct {
 }
 
 // ParseMessage parses a raw JSON message into either a Response or Notification.
+// The result does not alias data.
 func ParseMessage(data []byte) (*Message, error) {
+	if msg, ok := parseFast(data); ok {
+		return msg, nil
+	}
+	return parseStd(data)
+}
+
+// envelopeKeys are the message members parseFast reads.
+var envelopeKeys = []string{"jsonrpc", "id", "method", "result", "error", "params"}
+
+// parseFast parses data in a single pass. It returns false for input it
+// cannot handle exactly like parseStd, including all invalid input.
+func parseFast(data []byte) (*Message, bool) {
+	var (
+		r                      fastjson.Reader
+		version, method        string
+		id                     *uint64
+		result, params, errRaw []byte
+		rpcErr                 *Error
+		seen                   uint8
+	)
+	r.Reset(data)
+	if !r.BeginObject() {
+		return nil, false
+	}
+	for i := 0; r.NextField(i); i++ {
+		key := r.Key()
+		bit := uint8(0)
+		switch string(key) {
+		case "jsonrpc":
+			bit = 1
+			r.String(&version)
+		case "id":
+			bit = 2
+			if !r.Null() {
+				v := r.Uint()
+				id = &v
+			}
+		case "method":
+			bit = 4
+			r.String(&method)
+		case "result":
+			bit = 8
+			result = r.Raw()
+		case "error":
+			bit = 16
+			errRaw = r.Raw()
+		case "params":
+			bit = 32
+			params = r.Raw()
+		default:
+			r.UnknownKey(key, envelopeKeys)
+		}
+		if seen&bit != 0 {
+			r.Fail()
+		}
+		seen |= bit
+	}
+	if r.End() != nil {
+		return nil, false
+	}
+
+	if method != "" && id == nil {
+		return &Message{Notification: &Notification{JSONRPC: version, Method: method, Params: clone(params)}}, true
+	}
+	if errRaw != nil {
+		var ok bool
+		if rpcErr, ok = parseError(&r, errRaw); !ok {
+			return nil, false
+		}
+	}
+	return &Message{Response: &Response{JSONRPC: version, ID: id, Result: clone(result), Error: rpcErr}}, true
+}
+
+var errorKeys = []string{"code", "message"}
+
+// parseError parses an error member, which is null or an object.
+func parseError(r *fastjson.Reader, data []byte) (*Error, bool) {
+	r.Reset(data)
+	if r.Null() {
+		return nil, true
+	}
+	e := &Error{}
+	if !r.BeginObject() {
+		return nil, false
+	}
+	var seen uint8
+	for i := 0; r.NextField(i); i++ {
+		key := r.Key()
+		bit := uint8(0)
+		switch string(key) {
+		case "code":
+			bit = 1
+			r.Int(&e.Code)
+		case "message":
+			bit = 2
+			r.String(&e.Message)
+		default:
+			r.UnknownKey(key, errorKeys)
+		}
+		if seen&bit != 0 {
+			r.Fail()
+		}
+		seen |= bit
+	}
+	return e, r.End() == nil
+}
+
+// clone copies a raw member out of the frame buffer, which the transport
+// reuses.
+func clone(raw []byte) json.RawMessage {
+	if raw == nil {
+		return nil
+	}
+	return append(json.RawMessage(nil), raw...)
+}
+
+// parseStd parses data with encoding/json, first to find the message kind
+// and then into the message type.
+func parseStd(data []byte) (*Message, error) {
 	// First, try to determine the type by looking for key fields.
 	var raw struct {
 		JSONRPC string           `json:"jsonrpc"`
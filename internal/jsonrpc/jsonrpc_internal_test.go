@@ -2,6 +2,8 @@ package jsonrpc
 
 import (
 	"encoding/json"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -541,3 +543,102 @@ func TestVersion_Internal(t *testing.T) {
 		t.Fatalf("Version = %q; want %q", Version, "2.0")
 	}
 }
+
+// ---------------------------------------------------------------------------
+// ParseMessage – single-pass parser equivalence
+// ---------------------------------------------------------------------------
+
+var parseSeeds = []string{
+	`{"jsonrpc":"2.0","method":"book.BTC-PERPETUAL.none","params":{"bids":[[1,2,0]],"asks":[],"time":1.5}}`,
+	`{"jsonrpc":"2.0","method":"lwt.BTC-PERPETUAL.1000ms","params":{"mark_price":1,"iv":null}}`,
+	`{"jsonrpc":"2.0","id":7,"result":{"ok":true}}`,
+	`{"id":7,"result":null}`,
+	`{"id":8,"error":{"code":-32600,"message":"bad request","data":[1]}}`,
+	`{"id":9,"result":1,"error":null}`,
+	`{"id":null,"method":"ticker","params":null}`,
+	`{"method":"ticker"}`,
+	`{"method":"ticker","error":"not an object"}`,
+	`{"ID":1,"result":2}`,
+	`{"id":1,"id":2}`,
+	`{"id":1.5}`,
+	`{"id":"x","method":"m"}`,
+	`{"method":"caf\u00e9","params":[]}`,
+	`{"error":{"code":1e2}}`,
+	`null`,
+	`[1]`,
+	``,
+}
+
+// TestParseMessage_FastPathTaken checks that ordinary messages do not fall
+// back to the two-pass parser.
+func TestParseMessage_FastPathTaken(t *testing.T) {
+	for _, s := range parseSeeds[:7] {
+		if _, ok := parseFast([]byte(s)); !ok {
+			t.Errorf("parseFast declined %s", s)
+		}
+	}
+}
+
+// TestParseMessage_MatchesStd checks ParseMessage against the two-pass
+// encoding/json parser, including input the fast path declines.
+func TestParseMessage_MatchesStd(t *testing.T) {
+	for _, s := range parseSeeds {
+		got, gotErr := ParseMessage([]byte(s))
+		want, wantErr := parseStd([]byte(s))
+		if (gotErr != nil) != (wantErr != nil) || !reflect.DeepEqual(got, want) {
+			t.Errorf("%s:\n got  %+v, %v\n want %+v, %v", s, got, gotErr, want, wantErr)
+		}
+	}
+}
+
+func TestParseMessage_DoesNotAlias(t *testing.T) {
+	data := []byte(`{"method":"m","params":{"a":1}}`)
+	msg, err := ParseMessage(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	copy(data, strings.Repeat("x", len(data)))
+	if string(msg.Notification.Params) != `{"a":1}` {
+		t.Errorf("Params = %s after the input changed", msg.Notification.Params)
+	}
+}
+
+func FuzzParseMessage(f *testing.F) {
+	for _, s := range parseSeeds {
+		f.Add([]byte(s))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		got, ok := parseFast(data)
+		if !ok {
+			return
+		}
+		want, err := parseStd(data)
+		if err != nil {
+			t.Fatalf("parseFast accepted %q, parseStd: %v", data, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("%q:\n fast %+v\n std  %+v", data, got, want)
+		}
+	})
+}
+
+func BenchmarkParseMessage(b *testing.B) {
+	data := []byte(`{"jsonrpc":"2.0","method":"book.BTC-PERPETUAL.none","params":{"bids":[[64000.5,1.2,0],[64000,3.5,0],[63999,0.4,0]],"asks":[[64001,2.1,0],[64002.5,1,0]],"last":64000.5,"time":1718000000.123}}`)
+	for _, bc := range []struct {
+		name  string
+		parse func([]byte) (*Message, error)
+	}{
+		{"std", parseStd},
+		{"fast", ParseMessage},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(data)))
+			for b.Loop() {
+				if msg, err := bc.parse(data); err != nil || msg.Notification == nil {
+					b.Fatalf("parse: %v", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+// Package paging implements the auto-paginating history iterators shared by
+// the REST and WebSocket clients.
+package paging
+
+import (
+	"context"
+	"iter"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/amiwrpremium/go-thalex/enums"
+	"github.com/amiwrpremium/go-thalex/types"
+)
+
+// Query is the range and paging part of a history request's params.
+type Query struct {
+	From, To *types.Timestamp
+	Offset   *int
+	Limit    *int
+	Sort     enums.Sort
+}
+
+// Fetch requests limit items from offset within [from, to]. A nil bound is
+// left out of the request.
+type Fetch[T any] func(ctx context.Context, from, to *types.Timestamp, offset, limit int) ([]T, error)
+
+// All pages through q with fetch and yields every item. Paging moves on
+// until a page comes back empty, and stops early once MaxItems items have
+// been yielded. Items whose key was on the previous page are skipped, so
+// items shifted across a page or window boundary are yielded once; a page
+// with nothing but such items ends paging with types.ErrPagingStalled.
+// Iteration stops after the first error.
+func All[T any](ctx context.Context, q Query, opts []types.PageOption, fetch Fetch[T], key func(T) string) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		s := types.NewPageSettings(q.Limit, opts...)
+		ranges, err := windows(q, s.Window)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		n := 0
+		var prev map[string]struct{}
+		for _, r := range ranges {
+			offset := 0
+			if q.Offset != nil && s.Window == 0 {
+				offset = *q.Offset
+			}
+			for {
+				page, err := fetch(ctx, r.from, r.to, offset, s.PageSize)
+				if err != nil {
+					yield(zero, err)
+					return
+				}
+				if len(page) == 0 {
+					break
+				}
+				offset += len(page)
+				seen := make(map[string]struct{}, len(page))
+				fresh := false
+				for _, item := range page {
+					k := key(item)
+					seen[k] = struct{}{}
+					if _, dup := prev[k]; dup {
+						continue
+					}
+					fresh = true
+					if !yield(item, nil) {
+						return
+					}
+					if n++; s.MaxItems > 0 && n >= s.MaxItems {
+						return
+					}
+				}
+				if !fresh {
+					yield(zero, types.ErrPagingStalled)
+					return
+				}
+				prev = seen
+			}
+		}
+	}
+}
+
+// Timestamp converts an optional bound given in seconds, as RfqHistory
+// and the history params take it.
+func Timestamp(seconds *float64) *types.Timestamp {
+	if seconds == nil {
+		return nil
+	}
+	t := types.Timestamp(*seconds)
+	return &t
+}
+
+// Seconds converts an optional bound back to seconds.
+func Seconds(t *types.Timestamp) *float64 {
+	if t == nil {
+		return nil
+	}
+	s := t.Seconds()
+	return &s
+}
+
+// Keys identifying the items of each history endpoint.
+
+// TradeKey identifies a trade; the legs of a combination trade share its ID.
+func TradeKey(t types.Trade) string { return t.TradeID + "/" + strconv.Itoa(t.LegIndex) }
+
+// OrderKey identifies an order.
+func OrderKey(o types.OrderHistory) string { return o.OrderID }
+
+// DailyMarkKey identifies a daily mark, which has no ID of its own.
+func DailyMarkKey(m types.DailyMark) string { return m.Time.String() + "/" + m.InstrumentName }
+
+// TransactionKey identifies a transaction.
+func TransactionKey(t types.Transaction) string { return t.TransactionID }
+
+// RfqKey identifies an RFQ.
+func RfqKey(r types.Rfq) string { return r.RfqID }
+
+type window struct {
+	from, to *types.Timestamp
+}
+
+// windows splits [q.From, q.To] into windows of length d, in q.Sort order.
+// Without d the whole range is one window.
+func windows(q Query, d time.Duration) ([]window, error) {
+	if d <= 0 {
+		return []window{{q.From, q.To}}, nil
+	}
+	if q.From == nil {
+		return nil, types.ErrNoPagingStart
+	}
+	end := types.TimestampNow()
+	if q.To != nil {
+		end = *q.To
+	}
+	var out []window
+	lo := *q.From
+	for {
+		from, to := lo, min(lo.Add(d), end)
+		out = append(out, window{&from, &to})
+		if to >= end {
+			break
+		}
+		lo = to
+	}
+	if q.Sort == enums.SortDesc {
+		slices.Reverse(out)
+	}
+	return out, nil
+}
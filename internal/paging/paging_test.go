@@ -0,0 +1,196 @@
+package paging
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/amiwrpremium/go-thalex/enums"
+	"github.com/amiwrpremium/go-thalex/types"
+)
+
+type item struct {
+	id   int
+	time types.Timestamp
+}
+
+func itemKey(i item) string { return strconv.Itoa(i.id) }
+
+// server serves items by offset, oldest first, filtered to [from, to].
+type server struct {
+	items []item
+	calls []string
+}
+
+func (s *server) fetch(_ context.Context, from, to *types.Timestamp, offset, limit int) ([]item, error) {
+	s.calls = append(s.calls, describe(from, to, offset, limit))
+	var matched []item
+	for _, it := range s.items {
+		if (from == nil || it.time >= *from) && (to == nil || it.time <= *to) {
+			matched = append(matched, it)
+		}
+	}
+	if offset >= len(matched) {
+		return nil, nil
+	}
+	return matched[offset:min(offset+limit, len(matched))], nil
+}
+
+func describe(from, to *types.Timestamp, offset, limit int) string {
+	s := ""
+	if from != nil {
+		s += strconv.FormatFloat(from.Seconds(), 'f', -1, 64)
+	}
+	s += "-"
+	if to != nil {
+		s += strconv.FormatFloat(to.Seconds(), 'f', -1, 64)
+	}
+	return s + "@" + strconv.Itoa(offset) + "/" + strconv.Itoa(limit)
+}
+
+func newServer(n int) *server {
+	s := &server{}
+	for i := range n {
+		s.items = append(s.items, item{id: i, time: types.Timestamp(100 + 10*i)})
+	}
+	return s
+}
+
+func collect(t *testing.T, seq func(func(item, error) bool)) ([]int, error) {
+	t.Helper()
+	var ids []int
+	for it, err := range seq {
+		if err != nil {
+			return ids, err
+		}
+		ids = append(ids, it.id)
+	}
+	return ids, nil
+}
+
+func ts(v float64) *types.Timestamp {
+	t := types.Timestamp(v)
+	return &t
+}
+
+func TestAll_Offsets(t *testing.T) {
+	s := newServer(5)
+	offset := 1
+	ids, err := collect(t, All(context.Background(), Query{Offset: &offset}, []types.PageOption{types.WithPageSize(2)}, s.fetch, itemKey))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(ids, []int{1, 2, 3, 4}) {
+		t.Errorf("ids = %v", ids)
+	}
+	want := []string{"-@1/2", "-@3/2", "-@5/2"}
+	if !reflect.DeepEqual(s.calls, want) {
+		t.Errorf("calls = %v, want %v (until an empty page)", s.calls, want)
+	}
+}
+
+func TestAll_LimitIsPageSize(t *testing.T) {
+	s := newServer(3)
+	limit := 3
+	if _, err := collect(t, All(context.Background(), Query{Limit: &limit}, nil, s.fetch, itemKey)); err != nil {
+		t.Fatal(err)
+	}
+	if s.calls[0] != "-@0/3" {
+		t.Errorf("first call = %s, want limit 3", s.calls[0])
+	}
+}
+
+func TestAll_MaxItems(t *testing.T) {
+	s := newServer(10)
+	ids, err := collect(t, All(context.Background(), Query{}, []types.PageOption{types.WithPageSize(2), types.WithMaxItems(3)}, s.fetch, itemKey))
+	if err != nil || !reflect.DeepEqual(ids, []int{0, 1, 2}) {
+		t.Errorf("ids = %v, %v", ids, err)
+	}
+	if len(s.calls) != 2 {
+		t.Errorf("made %d calls, want 2", len(s.calls))
+	}
+}
+
+func TestAll_SkipsShiftedItems(t *testing.T) {
+	// Newest first; an item arrives between the first and second page,
+	// shifting the second page by one.
+	pages := [][]item{{{id: 9}, {id: 8}}, {{id: 8}, {id: 7}}, nil}
+	call := 0
+	fetch := func(context.Context, *types.Timestamp, *types.Timestamp, int, int) ([]item, error) {
+		call++
+		return pages[call-1], nil
+	}
+	ids, err := collect(t, All(context.Background(), Query{Sort: enums.SortDesc}, []types.PageOption{types.WithPageSize(2)}, fetch, itemKey))
+	if err != nil || !reflect.DeepEqual(ids, []int{9, 8, 7}) {
+		t.Errorf("ids = %v, %v", ids, err)
+	}
+}
+
+func TestAll_Stalled(t *testing.T) {
+	// The endpoint ignores the offset and serves the first page forever.
+	calls := 0
+	fetch := func(context.Context, *types.Timestamp, *types.Timestamp, int, int) ([]item, error) {
+		if calls++; calls > 10 {
+			t.Fatal("paging did not stop")
+		}
+		return []item{{id: 1}, {id: 2}}, nil
+	}
+	ids, err := collect(t, All(context.Background(), Query{}, []types.PageOption{types.WithPageSize(2)}, fetch, itemKey))
+	if !errors.Is(err, types.ErrPagingStalled) {
+		t.Errorf("err = %v, want ErrPagingStalled", err)
+	}
+	if !reflect.DeepEqual(ids, []int{1, 2}) || calls != 2 {
+		t.Errorf("ids = %v after %d calls, want [1 2] after 2", ids, calls)
+	}
+}
+
+func TestAll_TimeWindows(t *testing.T) {
+	s := newServer(5) // times 100, 110, ..., 140
+	opts := []types.PageOption{types.WithTimeWindow(20 * time.Second), types.WithPageSize(10)}
+
+	ids, err := collect(t, All(context.Background(), Query{From: ts(100), To: ts(140)}, opts, s.fetch, itemKey))
+	if err != nil || !reflect.DeepEqual(ids, []int{0, 1, 2, 3, 4}) {
+		t.Fatalf("ids = %v, %v", ids, err)
+	}
+	want := []string{"100-120@0/10", "100-120@3/10", "120-140@0/10", "120-140@3/10"}
+	if !reflect.DeepEqual(s.calls, want) {
+		t.Errorf("calls = %v, want %v", s.calls, want)
+	}
+
+	s.calls = nil
+	ids, _ = collect(t, All(context.Background(), Query{From: ts(100), To: ts(140), Sort: enums.SortDesc}, opts, s.fetch, itemKey))
+	if s.calls[0] != "120-140@0/10" {
+		t.Errorf("descending paging started with %s", s.calls[0])
+	}
+	if len(ids) != 5 {
+		t.Errorf("ids = %v, want each item once", ids)
+	}
+}
+
+func TestAll_TimeWindowsNeedFrom(t *testing.T) {
+	s := newServer(1)
+	_, err := collect(t, All(context.Background(), Query{}, []types.PageOption{types.WithTimeWindow(time.Hour)}, s.fetch, itemKey))
+	if !errors.Is(err, types.ErrNoPagingStart) {
+		t.Errorf("err = %v, want ErrNoPagingStart", err)
+	}
+}
+
+func TestAll_Error(t *testing.T) {
+	boom := errors.New("boom")
+	fetch := func(context.Context, *types.Timestamp, *types.Timestamp, int, int) ([]item, error) {
+		return nil, boom
+	}
+	n := 0
+	for _, err := range All(context.Background(), Query{}, nil, fetch, itemKey) {
+		n++
+		if !errors.Is(err, boom) {
+			t.Errorf("err = %v", err)
+		}
+	}
+	if n != 1 {
+		t.Errorf("yielded %d times, want 1", n)
+	}
+}
@@ -0,0 +1,38 @@
+// Package pretrade runs the client-side pre-trade check and lets the kill
+// switch exempt its closing orders from it.
+package pretrade
+
+import (
+	"context"
+
+	"github.com/amiwrpremium/go-thalex/types"
+)
+
+type contextKey struct{}
+
+// WithReduceOnlyBypass returns a copy of ctx whose reduce-only orders skip
+// the pre-trade check, so a tripped dead man's switch or a risk limit
+// cannot keep a position open. Every other request is still checked.
+func WithReduceOnlyBypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextKey{}, true)
+}
+
+// Check runs check, if any, on params. It skips a reduce-only insert whose
+// ctx comes from WithReduceOnlyBypass.
+func Check(ctx context.Context, check func(params any) error, params any) error {
+	if check == nil || bypassed(ctx, params) {
+		return nil
+	}
+	return check(params)
+}
+
+func bypassed(ctx context.Context, params any) bool {
+	if ctx == nil {
+		return false
+	}
+	if skip, _ := ctx.Value(contextKey{}).(bool); !skip {
+		return false
+	}
+	p, ok := params.(*types.InsertOrderParams)
+	return ok && p != nil && p.ReduceOnly != nil && *p.ReduceOnly
+}
@@ -0,0 +1,40 @@
+package pretrade
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/amiwrpremium/go-thalex/types"
+)
+
+func TestCheck(t *testing.T) {
+	refused := errors.New("refused")
+	check := func(any) error { return refused }
+	bypass := WithReduceOnlyBypass(context.Background())
+	closing := types.NewSellOrderParams("BTC-PERPETUAL", 1).WithReduceOnly(true)
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		params any
+		want   error
+	}{
+		{"checked", context.Background(), closing, refused},
+		{"reduce-only bypass", bypass, closing, nil},
+		{"opening order", bypass, types.NewSellOrderParams("BTC-PERPETUAL", 1), refused},
+		{"not reduce-only", bypass, types.NewSellOrderParams("BTC-PERPETUAL", 1).WithReduceOnly(false), refused},
+		{"amend", bypass, types.NewAmendByOrderID("o1", 95000, 1), refused},
+		{"nil insert", bypass, (*types.InsertOrderParams)(nil), refused},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Check(tt.ctx, check, tt.params); !errors.Is(err, tt.want) {
+				t.Errorf("Check = %v, want %v", err, tt.want)
+			}
+		})
+	}
+	if err := Check(context.Background(), nil, closing); err != nil {
+		t.Errorf("Check without a check = %v", err)
+	}
+}
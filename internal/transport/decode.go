@@ -0,0 +1,159 @@
+package transport
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/amiwrpremium/go-thalex/apierr"
+)
+
+// DefaultMaxResponseBytes is the response size limit used when none is
+// configured.
+const DefaultMaxResponseBytes = 64 << 20
+
+// bodyReader reads a response body up to a size limit and remembers read
+// failures, so they can be told apart from malformed JSON.
+type bodyReader struct {
+	r         io.Reader
+	limited   bool
+	remaining int64
+	err       error // first failure other than io.EOF
+}
+
+func newBodyReader(r io.Reader, limit int64) *bodyReader {
+	return &bodyReader{r: r, limited: limit >= 0, remaining: limit}
+}
+
+func (b *bodyReader) Read(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+	if b.limited && int64(len(p)) > b.remaining+1 {
+		// One byte over the limit is enough to tell it was exceeded.
+		p = p[:b.remaining+1]
+	}
+	n, err := b.r.Read(p)
+	if b.limited {
+		if b.remaining -= int64(n); b.remaining < 0 {
+			b.err = apierr.ErrResponseTooLarge
+			return n, b.err
+		}
+	}
+	if err != nil && err != io.EOF {
+		b.err = err
+	}
+	return n, err
+}
+
+// readBody reads a whole (error) response body within the limit.
+func readBody(r io.Reader, limit int64) ([]byte, error) {
+	br := newBodyReader(r, limit)
+	body, err := io.ReadAll(br)
+	if br.err != nil {
+		err = br.err
+	}
+	return body, err
+}
+
+// decodeResponse decodes a response object, unmarshaling its result field
+// directly into result, which may be nil to discard it. A non-nil
+// *apierr.APIError is returned if the response carries an error.
+func decodeResponse(r io.Reader, result any) (*apierr.APIError, error) {
+	dec := json.NewDecoder(r)
+	if tok, err := dec.Token(); err != nil {
+		return nil, fmt.Errorf("parsing response: %w", err)
+	} else if tok != json.Delim('{') {
+		return nil, fmt.Errorf("parsing response: unexpected %v", tok)
+	}
+
+	var apiErr *apierr.APIError
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("parsing response: %w", err)
+		}
+		switch key, _ := tok.(string); key {
+		case "result":
+			if result == nil {
+				err = skipValue(dec)
+				break
+			}
+			if err := decodeResult(dec, result); err != nil {
+				var syntaxErr *json.SyntaxError
+				if errors.As(err, &syntaxErr) || errors.Is(err, io.ErrUnexpectedEOF) {
+					return nil, fmt.Errorf("parsing response: %w", err)
+				}
+				return nil, fmt.Errorf("parsing result: %w", err)
+			}
+		case "error":
+			err = dec.Decode(&apiErr)
+		default:
+			err = skipValue(dec)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parsing response: %w", err)
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, fmt.Errorf("parsing response: %w", err)
+	}
+	return apiErr, nil
+}
+
+var unmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+
+// decodeResult decodes the next value into result. A slice result, such as
+// a long trade history, is decoded one element at a time, so the decoder
+// never holds more than one element of raw JSON; anything else is decoded
+// in one go.
+func decodeResult(dec *json.Decoder, result any) error {
+	v := reflect.ValueOf(result)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Slice || v.Type().Implements(unmarshalerType) {
+		return dec.Decode(result)
+	}
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	s := v.Elem()
+	switch tok {
+	case nil:
+		s.SetZero()
+		return nil
+	case json.Delim('['):
+	default:
+		return &json.UnmarshalTypeError{Value: fmt.Sprint(tok), Type: s.Type()}
+	}
+	if s.IsNil() {
+		s.Set(reflect.MakeSlice(s.Type(), 0, 0))
+	}
+	// Like json.Unmarshal, reuse the slice's capacity.
+	s.SetLen(0)
+	for i := 0; dec.More(); i++ {
+		s.Grow(1)
+		s.SetLen(i + 1)
+		elem := s.Index(i)
+		elem.SetZero()
+		if err := dec.Decode(elem.Addr().Interface()); err != nil {
+			return err
+		}
+	}
+	_, err = dec.Token()
+	return err
+}
+
+func skipValue(dec *json.Decoder) error {
+	var v json.RawMessage
+	return dec.Decode(&v)
+}
+
+// resetResult zeroes a result that a failed attempt may have partly
+// decoded, before the next attempt decodes into it again.
+func resetResult(result any) {
+	if v := reflect.ValueOf(result); v.Kind() == reflect.Pointer && !v.IsNil() {
+		v.Elem().SetZero()
+	}
+}
@@ -0,0 +1,235 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/amiwrpremium/go-thalex/apierr"
+)
+
+func TestDecodeResponse(t *testing.T) {
+	type payload struct {
+		A int `json:"a"`
+	}
+	tests := []struct {
+		name    string
+		body    string
+		want    payload
+		wantAPI int
+		wantErr string
+	}{
+		{name: "result", body: `{"id":1,"result":{"a":5},"extra":[1,{"x":2}]}`, want: payload{A: 5}},
+		{name: "error after result", body: `{"result":null,"error":{"code":7,"message":"no"}}`, wantAPI: 7},
+		{name: "error first", body: `{"error":{"code":8,"message":"no"},"result":{"a":1}}`, want: payload{A: 1}, wantAPI: 8},
+		{name: "not an object", body: `[1]`, wantErr: "parsing response"},
+		{name: "truncated", body: `{"result":{"a":`, wantErr: "parsing response"},
+		{name: "bad result", body: `{"result":"x"}`, wantErr: "parsing result"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got payload
+			apiErr, err := decodeResponse(strings.NewReader(tt.body), &got)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("result = %+v, want %+v", got, tt.want)
+			}
+			if (apiErr == nil) != (tt.wantAPI == 0) || (apiErr != nil && apiErr.Code != tt.wantAPI) {
+				t.Errorf("apiErr = %v, want code %d", apiErr, tt.wantAPI)
+			}
+		})
+	}
+
+	if _, err := decodeResponse(strings.NewReader(`{"result":{"a":1}}`), nil); err != nil {
+		t.Errorf("nil result: %v", err)
+	}
+}
+
+func TestDecodeResponse_Slice(t *testing.T) {
+	type row struct {
+		A int `json:"a"`
+	}
+	tests := []struct {
+		name    string
+		body    string
+		want    []row
+		wantErr string
+	}{
+		{name: "rows", body: `{"result":[{"a":1},{"a":2},{"a":3}]}`, want: []row{{1}, {2}, {3}}},
+		{name: "empty", body: `{"result":[]}`, want: []row{}},
+		{name: "null", body: `{"result":null}`},
+		{name: "not an array", body: `{"result":{"a":1}}`, wantErr: "parsing result"},
+		{name: "bad row", body: `{"result":[{"a":1},{"a":"x"}]}`, wantErr: "parsing result"},
+		{name: "truncated", body: `{"result":[{"a":1},{"a"`, wantErr: "parsing response"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []row{{9}, {9}, {9}, {9}}
+			_, err := decodeResponse(strings.NewReader(tt.body), &got)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("result = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBodyReader_Limit(t *testing.T) {
+	body := strings.Repeat("x", 100)
+	if got, err := readBody(strings.NewReader(body), 100); err != nil || len(got) != 100 {
+		t.Errorf("at the limit: %d bytes, %v", len(got), err)
+	}
+	if _, err := readBody(strings.NewReader(body), 99); !errors.Is(err, apierr.ErrResponseTooLarge) {
+		t.Errorf("over the limit: err = %v", err)
+	}
+	if got, err := readBody(strings.NewReader(body), -1); err != nil || len(got) != 100 {
+		t.Errorf("unlimited: %d bytes, %v", len(got), err)
+	}
+}
+
+func TestDoWithRetry_ResponseTooLarge(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Write([]byte(`{"result":"` + strings.Repeat("x", 1000) + `"}`))
+	}))
+	defer server.Close()
+
+	tr := NewHTTPTransport(HTTPTransportConfig{BaseURL: server.URL, RetryBaseWait: time.Millisecond, MaxResponseBytes: 512})
+	var result string
+	err := tr.DoPublic(context.Background(), "/big", nil, &result)
+	if !errors.Is(err, apierr.ErrResponseTooLarge) {
+		t.Fatalf("err = %v, want ErrResponseTooLarge", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("calls = %d, want no retries", calls.Load())
+	}
+
+	tr = NewHTTPTransport(HTTPTransportConfig{BaseURL: server.URL, MaxResponseBytes: -1})
+	if err := tr.DoPublic(context.Background(), "/big", nil, &result); err != nil || len(result) != 1000 {
+		t.Errorf("unlimited: %d bytes, %v", len(result), err)
+	}
+}
+
+func TestDoWithRetry_TruncatedBodyRetried(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			// Promise more than is sent, so the client sees the body cut off.
+			w.Header().Set("Content-Length", "1000")
+			w.Write([]byte(`{"result":{"a":1,"b":`))
+			return
+		}
+		w.Write([]byte(`{"result":{"a":2}}`))
+	}))
+	defer server.Close()
+
+	tr := NewHTTPTransport(HTTPTransportConfig{BaseURL: server.URL, RetryBaseWait: time.Millisecond})
+	var result map[string]int
+	if err := tr.DoPublic(context.Background(), "/cut", nil, &result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("calls = %d, want 2", calls.Load())
+	}
+	if len(result) != 1 || result["a"] != 2 {
+		t.Errorf("result = %v, want only the second attempt's data", result)
+	}
+}
+
+// benchTrade resembles an entry of a trade history response.
+type benchTrade struct {
+	TradeID        string  `json:"trade_id"`
+	OrderID        string  `json:"order_id"`
+	InstrumentName string  `json:"instrument_name"`
+	Direction      string  `json:"direction"`
+	Price          float64 `json:"price"`
+	Amount         float64 `json:"amount"`
+	Time           float64 `json:"time"`
+	Fee            float64 `json:"fee"`
+}
+
+func benchBody(n int) []byte {
+	trades := make([]benchTrade, n)
+	for i := range trades {
+		trades[i] = benchTrade{
+			TradeID: "t" + strconv.Itoa(i), OrderID: "o" + strconv.Itoa(i), InstrumentName: "BTC-PERPETUAL",
+			Direction: "buy", Price: 50000 + float64(i), Amount: 0.1, Time: 1700000000 + float64(i), Fee: 0.5,
+		}
+	}
+	body, _ := json.Marshal(map[string]any{"id": 1, "result": trades})
+	return body
+}
+
+// decodeBuffered is the previous decoding path: read the whole body, then
+// unmarshal the envelope and the result separately.
+func decodeBuffered(r io.Reader, result any) (*apierr.APIError, error) {
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var apiResp apiResponse
+	if err := json.Unmarshal(body, &apiResp); err != nil {
+		return nil, fmt.Errorf("parsing response: %w", err)
+	}
+	if apiResp.Error != nil {
+		return apiResp.Error, nil
+	}
+	if err := json.Unmarshal(apiResp.Result, result); err != nil {
+		return nil, fmt.Errorf("parsing result: %w", err)
+	}
+	return nil, nil
+}
+
+func BenchmarkDecodeResponse(b *testing.B) {
+	for _, n := range []int{10, 10000} {
+		body := benchBody(n)
+		for _, bc := range []struct {
+			name   string
+			decode func(io.Reader, any) (*apierr.APIError, error)
+		}{
+			{"buffered", decodeBuffered},
+			{"streaming", decodeResponse},
+		} {
+			b.Run(fmt.Sprintf("%s/%d", bc.name, n), func(b *testing.B) {
+				b.ReportAllocs()
+				b.SetBytes(int64(len(body)))
+				for b.Loop() {
+					var trades []benchTrade
+					// Wrap the reader so io.ReadAll cannot size its buffer
+					// up front, as with a network body.
+					if _, err := bc.decode(struct{ io.Reader }{bytes.NewReader(body)}, &trades); err != nil || len(trades) != n {
+						b.Fatalf("decoded %d trades: %v", len(trades), err)
+					}
+				}
+			})
+		}
+	}
+}
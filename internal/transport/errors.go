@@ -0,0 +1,39 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+
+	"github.com/amiwrpremium/go-thalex/apierr"
+)
+
+// ContextError maps a context error to the SDK error model. An expired
+// deadline becomes an *apierr.TimeoutError describing op, which still
+// matches context.DeadlineExceeded via errors.Is; cancellation is returned
+// unchanged.
+func ContextError(err error, op string) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return &apierr.TimeoutError{Message: op, Err: err}
+	}
+	return err
+}
+
+// requestError maps a failed HTTP round trip to the SDK error model. Client
+// timeouts become *apierr.TimeoutError; everything else is an
+// *apierr.ConnectionError, marked as unsent only when the dial itself failed.
+// A non-empty name identifies the endpoint in the message.
+func requestError(err error, name string) error {
+	op := "HTTP request"
+	if name != "" {
+		op += " to " + strconv.Quote(name)
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return &apierr.TimeoutError{Message: op, Err: err}
+	}
+	var opErr *net.OpError
+	sent := !(errors.As(err, &opErr) && opErr.Op == "dial")
+	return &apierr.ConnectionError{Message: op + " failed", Err: err, Sent: sent}
+}
@@ -6,34 +6,45 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math"
 	"net/http"
 	"net/url"
 	"time"
+
+	"github.com/amiwrpremium/go-thalex/apierr"
+	"github.com/amiwrpremium/go-thalex/internal/account"
 )
 
 // HTTPTransport handles HTTP communication with the Thalex REST API.
 type HTTPTransport struct {
 	client        *http.Client
 	baseURL       string
+	name          string
 	userAgent     string
 	maxRetries    int
 	retryBaseWait time.Duration
 	tokenFunc     func() (string, error)
 	accountNumber string
+	maxBodyBytes  int64
 }
 
 // HTTPTransportConfig contains configuration for the HTTP transport.
 type HTTPTransportConfig struct {
-	Client        *http.Client
-	BaseURL       string
+	Client  *http.Client
+	BaseURL string
+	// Name labels the endpoint in connection errors.
+	Name          string
 	UserAgent     string
 	MaxRetries    int
 	RetryBaseWait time.Duration
 	TokenFunc     func() (string, error)
 	AccountNumber string
+	// MaxResponseBytes limits the size of a response body. Zero means
+	// DefaultMaxResponseBytes; a negative value disables the limit.
+	MaxResponseBytes int64
 }
 
 // NewHTTPTransport creates a new HTTP transport.
@@ -47,30 +58,44 @@ func NewHTTPTransport(cfg HTTPTransportConfig) *HTTPTransport {
 	if cfg.RetryBaseWait <= 0 {
 		cfg.RetryBaseWait = 500 * time.Millisecond
 	}
+	if cfg.MaxResponseBytes == 0 {
+		cfg.MaxResponseBytes = DefaultMaxResponseBytes
+	}
 	return &HTTPTransport{
 		client:        cfg.Client,
 		baseURL:       cfg.BaseURL,
+		name:          cfg.Name,
 		userAgent:     cfg.UserAgent,
 		maxRetries:    cfg.MaxRetries,
 		retryBaseWait: cfg.RetryBaseWait,
 		tokenFunc:     cfg.TokenFunc,
 		accountNumber: cfg.AccountNumber,
+		maxBodyBytes:  cfg.MaxResponseBytes,
 	}
 }
 
+// drainBytes is how much of the body left after the response object is
+// drained before closing, so the connection can be reused.
+const drainBytes = 4 << 10
+
 // apiResponse wraps the Thalex REST API response format.
 type apiResponse struct {
-	Result json.RawMessage `json:"result"`
-	Error  *apiError       `json:"error"`
+	Result json.RawMessage  `json:"result"`
+	Error  *apierr.APIError `json:"error"`
 }
 
-type apiError struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
-}
-
-func (e *apiError) Error() string {
-	return fmt.Sprintf("API error %d: %s", e.Code, e.Message)
+// responseError builds the API error for a failed response, preferring the
+// error object in the body and falling back to the HTTP status line.
+func responseError(status int, body []byte) *apierr.APIError {
+	var apiResp apiResponse
+	if err := json.Unmarshal(body, &apiResp); err == nil && apiResp.Error != nil {
+		apiResp.Error.HTTPStatus = status
+		return apiResp.Error
+	}
+	return &apierr.APIError{
+		Message:    fmt.Sprintf("HTTP %d: %s", status, string(body)),
+		HTTPStatus: status,
+	}
 }
 
 // DoPublic performs a public (unauthenticated) GET request.
@@ -145,7 +170,10 @@ func (t *HTTPTransport) setAuthHeaders(req *http.Request) error {
 		req.Header.Set("Authorization", "Bearer "+token)
 	}
 
-	if t.accountNumber != "" {
+	// A per-call account carried by the context overrides the default.
+	if acct, ok := account.FromContext(req.Context()); ok {
+		req.Header.Set("X-Thalex-Account", acct)
+	} else if t.accountNumber != "" {
 		req.Header.Set("X-Thalex-Account", t.accountNumber)
 	}
 
@@ -160,57 +188,60 @@ func (t *HTTPTransport) doWithRetry(req *http.Request, result interface{}) error
 			wait := t.retryBaseWait * time.Duration(math.Pow(2, float64(attempt-1)))
 			select {
 			case <-req.Context().Done():
-				return req.Context().Err()
+				return ContextError(req.Context().Err(), "waiting to retry "+req.URL.Path)
 			case <-time.After(wait):
 			}
 		}
 
 		resp, err := t.client.Do(req)
 		if err != nil {
-			lastErr = fmt.Errorf("HTTP request failed: %w", err)
 			// Only retry on network-level errors, not on context cancellation.
-			if req.Context().Err() != nil {
-				return lastErr
+			if ctxErr := req.Context().Err(); ctxErr != nil {
+				return ContextError(ctxErr, "HTTP request "+req.URL.Path)
 			}
+			lastErr = requestError(err, t.name)
 			continue
 		}
 
-		body, err := io.ReadAll(resp.Body)
-		_ = resp.Body.Close()
-		if err != nil {
-			lastErr = fmt.Errorf("reading response body: %w", err)
-			continue
-		}
-
-		// Retry on 5xx server errors.
-		if resp.StatusCode >= 500 {
-			lastErr = fmt.Errorf("server error: HTTP %d", resp.StatusCode)
-			continue
-		}
-
-		// Don't retry on 4xx client errors.
+		// Error responses are small; read them whole.
 		if resp.StatusCode >= 400 {
-			var apiResp apiResponse
-			if err := json.Unmarshal(body, &apiResp); err == nil && apiResp.Error != nil {
-				return apiResp.Error
+			body, err := readBody(resp.Body, t.maxBodyBytes)
+			_ = resp.Body.Close()
+			if errors.Is(err, apierr.ErrResponseTooLarge) {
+				return err
 			}
-			return fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
+			if err != nil {
+				lastErr = fmt.Errorf("reading response body: %w", err)
+				continue
+			}
+			// Retry on 5xx server errors; don't retry on 4xx client errors.
+			if resp.StatusCode >= 500 {
+				lastErr = responseError(resp.StatusCode, body)
+				continue
+			}
+			return responseError(resp.StatusCode, body)
 		}
 
-		// Parse the response.
-		var apiResp apiResponse
-		if err := json.Unmarshal(body, &apiResp); err != nil {
-			return fmt.Errorf("parsing response: %w", err)
+		// Decode the result straight from the body.
+		body := newBodyReader(resp.Body, t.maxBodyBytes)
+		apiErr, err := decodeResponse(body, result)
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, drainBytes))
+		_ = resp.Body.Close()
+		if body.err != nil {
+			if errors.Is(body.err, apierr.ErrResponseTooLarge) {
+				return body.err
+			}
+			resetResult(result)
+			lastErr = fmt.Errorf("reading response body: %w", body.err)
+			continue
 		}
-
-		if apiResp.Error != nil {
-			return apiResp.Error
+		if err != nil {
+			return err
 		}
 
-		if result != nil {
-			if err := json.Unmarshal(apiResp.Result, result); err != nil {
-				return fmt.Errorf("parsing result: %w", err)
-			}
+		if apiErr != nil {
+			apiErr.HTTPStatus = resp.StatusCode
+			return apiErr
 		}
 
 		return nil
@@ -3,6 +3,7 @@ package transport
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -12,6 +13,9 @@ import (
 	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/amiwrpremium/go-thalex/apierr"
+	"github.com/amiwrpremium/go-thalex/internal/account"
 )
 
 // ---------------------------------------------------------------------------
@@ -86,28 +90,26 @@ func TestNewHTTPTransport(t *testing.T) {
 }
 
 // ---------------------------------------------------------------------------
-// apiError
+// responseError
 // ---------------------------------------------------------------------------
 
-func TestApiError_Error(t *testing.T) {
-	tests := []struct {
-		code    int
-		message string
-		want    string
-	}{
-		{400, "Bad Request", "API error 400: Bad Request"},
-		{-1, "Unknown error", "API error -1: Unknown error"},
-		{0, "Zero", "API error 0: Zero"},
-	}
-	for _, tc := range tests {
-		t.Run(tc.want, func(t *testing.T) {
-			e := &apiError{Code: tc.code, Message: tc.message}
-			got := e.Error()
-			if got != tc.want {
-				t.Errorf("Error() = %q; want %q", got, tc.want)
-			}
-		})
-	}
+func TestResponseError(t *testing.T) {
+	t.Run("uses API error in body", func(t *testing.T) {
+		e := responseError(429, []byte(`{"error":{"code":1,"message":"slow down"}}`))
+		if e.Code != 1 || e.Message != "slow down" || e.HTTPStatus != 429 {
+			t.Errorf("got %+v", e)
+		}
+		if !errors.Is(e, apierr.ErrRateLimited) {
+			t.Error("429 should match ErrRateLimited")
+		}
+	})
+
+	t.Run("falls back to status line", func(t *testing.T) {
+		e := responseError(404, []byte("not here"))
+		if e.HTTPStatus != 404 || e.Message != "HTTP 404: not here" {
+			t.Errorf("got %+v", e)
+		}
+	})
 }
 
 // ---------------------------------------------------------------------------
@@ -234,7 +236,7 @@ func TestDoPublic(t *testing.T) {
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(400)
 			json.NewEncoder(w).Encode(apiResponse{
-				Error: &apiError{Code: 1001, Message: "Invalid parameter"},
+				Error: &apierr.APIError{Code: 1001, Message: "Invalid parameter"},
 			})
 		}))
 		defer server.Close()
@@ -247,9 +249,9 @@ func TestDoPublic(t *testing.T) {
 		if err == nil {
 			t.Fatal("expected an error")
 		}
-		apiErr, ok := err.(*apiError)
+		apiErr, ok := err.(*apierr.APIError)
 		if !ok {
-			t.Fatalf("expected *apiError, got %T: %v", err, err)
+			t.Fatalf("expected *apierr.APIError, got %T: %v", err, err)
 		}
 		if apiErr.Code != 1001 {
 			t.Errorf("Code = %d; want 1001", apiErr.Code)
@@ -428,6 +430,27 @@ func TestDoPrivateGET(t *testing.T) {
 		}
 	})
 
+	t.Run("context account overrides accountNumber", func(t *testing.T) {
+		var receivedAccount string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			receivedAccount = r.Header.Get("X-Thalex-Account")
+			w.WriteHeader(200)
+			json.NewEncoder(w).Encode(apiResponse{Result: json.RawMessage(`null`)})
+		}))
+		defer server.Close()
+
+		tr := NewHTTPTransport(HTTPTransportConfig{
+			BaseURL:       server.URL,
+			RetryBaseWait: time.Millisecond,
+			AccountNumber: "ACCT-42",
+		})
+		ctx := account.WithAccount(context.Background(), "ACCT-99")
+		tr.DoPrivateGET(ctx, "/private", nil, nil)
+		if receivedAccount != "ACCT-99" {
+			t.Errorf("X-Thalex-Account = %q; want %q", receivedAccount, "ACCT-99")
+		}
+	})
+
 	t.Run("sets User-Agent header", func(t *testing.T) {
 		var receivedUA string
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -702,7 +725,7 @@ func TestDoWithRetry(t *testing.T) {
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(200)
 			json.NewEncoder(w).Encode(apiResponse{
-				Error: &apiError{Code: 5000, Message: "rate limited"},
+				Error: &apierr.APIError{Code: 5000, Message: "rate limited"},
 			})
 		}))
 		defer server.Close()
@@ -715,9 +738,9 @@ func TestDoWithRetry(t *testing.T) {
 		if err == nil {
 			t.Fatal("expected an error")
 		}
-		apiErr, ok := err.(*apiError)
+		apiErr, ok := err.(*apierr.APIError)
 		if !ok {
-			t.Fatalf("expected *apiError, got %T: %v", err, err)
+			t.Fatalf("expected *apierr.APIError, got %T: %v", err, err)
 		}
 		if apiErr.Code != 5000 {
 			t.Errorf("Code = %d; want 5000", apiErr.Code)
@@ -789,7 +812,7 @@ func TestDoWithRetry(t *testing.T) {
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(422)
 			json.NewEncoder(w).Encode(apiResponse{
-				Error: &apiError{Code: 422, Message: "Unprocessable"},
+				Error: &apierr.APIError{Code: 422, Message: "Unprocessable"},
 			})
 		}))
 		defer server.Close()
@@ -802,9 +825,9 @@ func TestDoWithRetry(t *testing.T) {
 		if err == nil {
 			t.Fatal("expected an error")
 		}
-		apiErr, ok := err.(*apiError)
+		apiErr, ok := err.(*apierr.APIError)
 		if !ok {
-			t.Fatalf("expected *apiError, got %T: %v", err, err)
+			t.Fatalf("expected *apierr.APIError, got %T: %v", err, err)
 		}
 		if apiErr.Code != 422 {
 			t.Errorf("Code = %d; want 422", apiErr.Code)
@@ -834,3 +857,43 @@ func TestDoWithRetry(t *testing.T) {
 		}
 	})
 }
+
+func TestDoWithRetry_DeadlineReturnsTimeoutError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	tr := NewHTTPTransport(HTTPTransportConfig{BaseURL: server.URL, RetryBaseWait: time.Millisecond})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := tr.DoPublic(ctx, "/slow", nil, nil)
+	var timeoutErr *apierr.TimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("expected *apierr.TimeoutError, got %T: %v", err, err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("TimeoutError should wrap context.DeadlineExceeded")
+	}
+}
+
+func TestDoWithRetry_ConnectionErrorNamesEndpoint(t *testing.T) {
+	tr := NewHTTPTransport(HTTPTransportConfig{
+		BaseURL:       "http://127.0.0.1:1", // Nothing listening here.
+		Name:          "colo",
+		MaxRetries:    1,
+		RetryBaseWait: time.Millisecond,
+	})
+	err := tr.DoPublic(context.Background(), "/ping", nil, nil)
+	var connErr *apierr.ConnectionError
+	if !errors.As(err, &connErr) {
+		t.Fatalf("expected *apierr.ConnectionError, got %T: %v", err, err)
+	}
+	if !strings.Contains(err.Error(), `HTTP request to "colo" failed`) {
+		t.Errorf("error = %q; want the endpoint name", err)
+	}
+	if connErr.Sent {
+		t.Error("a refused dial should be marked unsent")
+	}
+}
@@ -112,8 +112,10 @@ func (r *Reconnector) reconnect(ctx context.Context) error {
 		case <-time.After(wait):
 		}
 
-		// Attempt to reconnect.
+		// Attempt to reconnect, moving on to the next failover URL if the
+		// current one cannot be reached.
 		if err := r.transport.Connect(ctx); err != nil {
+			r.transport.NextURL()
 			continue
 		}
 
@@ -122,6 +124,7 @@ func (r *Reconnector) reconnect(ctx context.Context) error {
 			if err := r.config.OnReconnect(); err != nil {
 				// Close the connection and try again.
 				_ = r.transport.Close()
+				r.transport.NextURL()
 				continue
 			}
 		}
@@ -3,6 +3,7 @@ package transport
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -15,6 +16,7 @@ import (
 
 	gorilla "github.com/gorilla/websocket"
 
+	"github.com/amiwrpremium/go-thalex/apierr"
 	"github.com/amiwrpremium/go-thalex/internal/jsonrpc"
 )
 
@@ -608,7 +610,7 @@ func TestDoPrivatePOST_ClientError400(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(400)
 		json.NewEncoder(w).Encode(apiResponse{
-			Error: &apiError{Code: 1001, Message: "Invalid"},
+			Error: &apierr.APIError{Code: 1001, Message: "Invalid"},
 		})
 	}))
 	defer server.Close()
@@ -621,9 +623,9 @@ func TestDoPrivatePOST_ClientError400(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected an error")
 	}
-	apiErr, ok := err.(*apiError)
+	apiErr, ok := err.(*apierr.APIError)
 	if !ok {
-		t.Fatalf("expected *apiError, got %T", err)
+		t.Fatalf("expected *apierr.APIError, got %T", err)
 	}
 	if apiErr.Code != 1001 {
 		t.Errorf("Code = %d; want 1001", apiErr.Code)
@@ -1113,3 +1115,148 @@ func TestDoPublic_200WithNilError(t *testing.T) {
 		t.Errorf("result = %d; want 42", result)
 	}
 }
+
+// ---------------------------------------------------------------------------
+// WSTransport – dial options
+// ---------------------------------------------------------------------------
+
+func TestWSTransport_Connect_SendsExtraHeaders(t *testing.T) {
+	gotHeader := make(chan string, 1)
+	upgrader := gorilla.Upgrader{CheckOrigin: func(r *http.Request) bool { return true }}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader <- r.Header.Get("X-Gateway-Token")
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	tr := NewWSTransport(WSTransportConfig{
+		URL:          "ws" + strings.TrimPrefix(server.URL, "http"),
+		PingInterval: time.Minute,
+		Header:       http.Header{"X-Gateway-Token": []string{"secret"}},
+	})
+	if err := tr.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect error: %v", err)
+	}
+	defer tr.Close()
+
+	if got := <-gotHeader; got != "secret" {
+		t.Errorf("X-Gateway-Token = %q; want %q", got, "secret")
+	}
+}
+
+func TestWSTransport_Connect_UsesProxy(t *testing.T) {
+	var proxied atomic.Int32
+	tr := NewWSTransport(WSTransportConfig{
+		URL:         "ws://thalex.invalid/ws",
+		DialTimeout: 200 * time.Millisecond,
+		Proxy: func(*http.Request) (*url.URL, error) {
+			proxied.Add(1)
+			return url.Parse("http://127.0.0.1:1")
+		},
+	})
+	if err := tr.Connect(context.Background()); err == nil {
+		t.Fatal("expected dial through unreachable proxy to fail")
+	}
+	if proxied.Load() == 0 {
+		t.Error("expected Proxy func to be consulted")
+	}
+}
+
+func TestReconnector_RotatesToFailoverURL(t *testing.T) {
+	server, goodURL := wsTestServer(t, func(conn *gorilla.Conn) {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	})
+	defer server.Close()
+
+	tr := NewWSTransport(WSTransportConfig{
+		URL:          "ws://127.0.0.1:1", // Nothing listening here.
+		FailoverURLs: []string{goodURL},
+		DialTimeout:  100 * time.Millisecond,
+		PingInterval: time.Minute,
+	})
+	r := NewReconnector(tr, ReconnectConfig{
+		Enabled:     true,
+		MaxAttempts: 3,
+		BaseWait:    time.Millisecond,
+		MaxWait:     time.Millisecond,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := r.TriggerReconnect(ctx); err != nil {
+		t.Fatalf("TriggerReconnect error: %v", err)
+	}
+	defer tr.Close()
+	if tr.URL() != goodURL {
+		t.Errorf("URL() = %q; want failover %q", tr.URL(), goodURL)
+	}
+	if !tr.IsConnected() {
+		t.Error("expected transport to be connected via failover")
+	}
+}
+
+func TestWSTransport_ConnectAny(t *testing.T) {
+	t.Run("falls over to a reachable URL", func(t *testing.T) {
+		server, goodURL := wsTestServer(t, func(conn *gorilla.Conn) {
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		})
+		defer server.Close()
+
+		tr := NewWSTransport(WSTransportConfig{
+			URL:          "ws://127.0.0.1:1", // Nothing listening here.
+			FailoverURLs: []string{goodURL},
+			DialTimeout:  100 * time.Millisecond,
+			PingInterval: time.Minute,
+		})
+		if err := tr.ConnectAny(context.Background()); err != nil {
+			t.Fatalf("ConnectAny error: %v", err)
+		}
+		defer tr.Close()
+		if tr.URL() != goodURL {
+			t.Errorf("URL() = %q; want failover %q", tr.URL(), goodURL)
+		}
+	})
+
+	t.Run("reports every named endpoint", func(t *testing.T) {
+		tr := NewWSTransport(WSTransportConfig{
+			URL:          "ws://127.0.0.1:1/a",
+			FailoverURLs: []string{"ws://127.0.0.1:1/b"},
+			Names:        map[string]string{"ws://127.0.0.1:1/a": "primary", "ws://127.0.0.1:1/b": "backup"},
+			DialTimeout:  100 * time.Millisecond,
+		})
+		err := tr.ConnectAny(context.Background())
+		if err == nil {
+			t.Fatal("expected ConnectAny to fail")
+		}
+		for _, want := range []string{`"primary" (ws://127.0.0.1:1/a)`, `"backup" (ws://127.0.0.1:1/b)`} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("error = %q; want to contain %s", err, want)
+			}
+		}
+		var connErr *apierr.ConnectionError
+		if !errors.As(err, &connErr) {
+			t.Errorf("error %T should wrap *apierr.ConnectionError", err)
+		}
+		if tr.URL() != "ws://127.0.0.1:1/a" {
+			t.Errorf("URL() = %q; want to wrap around to the primary", tr.URL())
+		}
+	})
+}
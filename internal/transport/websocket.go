@@ -1,15 +1,21 @@
 package transport
 
 import (
+	"bytes"
 	"compress/flate"
 	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
 	"sync"
 	"time"
 
 	gorilla "github.com/gorilla/websocket"
 
+	"github.com/amiwrpremium/go-thalex/apierr"
 	"github.com/amiwrpremium/go-thalex/internal/jsonrpc"
 )
 
@@ -27,14 +33,20 @@ type WSHandler interface {
 
 // WSTransport manages a WebSocket connection to the Thalex API.
 type WSTransport struct {
-	url          string
 	dialTimeout  time.Duration
 	pingInterval time.Duration
 	handler      WSHandler
 	idGen        jsonrpc.IDGenerator
+	tlsConfig    *tls.Config
+	proxy        func(*http.Request) (*url.URL, error)
+	header       http.Header
 
-	mu   sync.Mutex
-	conn *gorilla.Conn
+	mu     sync.Mutex
+	conn   *gorilla.Conn
+	url    string
+	urls   []string
+	urlIdx int
+	names  map[string]string
 
 	done      chan struct{}
 	closeOnce sync.Once
@@ -46,6 +58,16 @@ type WSTransportConfig struct {
 	DialTimeout  time.Duration
 	PingInterval time.Duration
 	Handler      WSHandler
+	// FailoverURLs are rotated through, after URL, by NextURL.
+	FailoverURLs []string
+	// Names labels URLs in connection errors.
+	Names map[string]string
+	// TLSConfig is passed to the gorilla dialer.
+	TLSConfig *tls.Config
+	// Proxy selects an HTTP CONNECT proxy for the dialer.
+	Proxy func(*http.Request) (*url.URL, error)
+	// Header holds extra HTTP headers sent with the handshake.
+	Header http.Header
 }
 
 // NewWSTransport creates a new WebSocket transport.
@@ -56,15 +78,60 @@ func NewWSTransport(cfg WSTransportConfig) *WSTransport {
 	if cfg.PingInterval <= 0 {
 		cfg.PingInterval = 5 * time.Second
 	}
+	urls := append([]string{cfg.URL}, cfg.FailoverURLs...)
 	return &WSTransport{
 		url:          cfg.URL,
+		urls:         urls,
+		names:        cfg.Names,
 		dialTimeout:  cfg.DialTimeout,
 		pingInterval: cfg.PingInterval,
 		handler:      cfg.Handler,
+		tlsConfig:    cfg.TLSConfig,
+		proxy:        cfg.Proxy,
+		header:       cfg.Header,
 		done:         make(chan struct{}),
 	}
 }
 
+// URL returns the URL the next Connect will dial.
+func (t *WSTransport) URL() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.url
+}
+
+// NextURL advances to the next failover URL, wrapping around to the primary,
+// and returns it. With no failover URLs it always returns the primary URL.
+func (t *WSTransport) NextURL() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.urlIdx = (t.urlIdx + 1) % len(t.urls)
+	t.url = t.urls[t.urlIdx]
+	return t.url
+}
+
+// ConnectAny connects to the current URL, moving on through the failover
+// URLs until one can be reached. Each URL is tried once; if all fail, the
+// errors of every attempt are returned.
+func (t *WSTransport) ConnectAny(ctx context.Context) error {
+	var errs []error
+	for range t.urls {
+		err := t.Connect(ctx)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
+		if ctx.Err() != nil {
+			break
+		}
+		t.NextURL()
+	}
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	return errors.Join(errs...)
+}
+
 // Connect establishes the WebSocket connection.
 func (t *WSTransport) Connect(ctx context.Context) error {
 	dialCtx, cancel := context.WithTimeout(ctx, t.dialTimeout)
@@ -72,14 +139,21 @@ func (t *WSTransport) Connect(ctx context.Context) error {
 
 	dialer := gorilla.Dialer{
 		EnableCompression: true,
+		TLSClientConfig:   t.tlsConfig,
+		Proxy:             t.proxy,
 	}
 
-	conn, resp, err := dialer.DialContext(dialCtx, t.url, nil)
+	u := t.URL()
+	conn, resp, err := dialer.DialContext(dialCtx, u, t.header)
 	if resp != nil && resp.Body != nil {
 		_ = resp.Body.Close()
 	}
 	if err != nil {
-		return fmt.Errorf("dialing WebSocket: %w", err)
+		msg := "dialing WebSocket " + u
+		if name := t.names[u]; name != "" {
+			msg = fmt.Sprintf("dialing WebSocket %q (%s)", name, u)
+		}
+		return &apierr.ConnectionError{Message: msg, Err: err}
 	}
 
 	// Enable write compression and set compression level.
@@ -138,18 +212,18 @@ func (t *WSTransport) Send(ctx context.Context, method string, params any) (uint
 	t.mu.Unlock()
 
 	if conn == nil {
-		return 0, fmt.Errorf("not connected")
+		return 0, &apierr.ConnectionError{Message: "not connected"}
 	}
 
 	// Respect context deadline if present.
 	if deadline, ok := ctx.Deadline(); ok {
 		if err := conn.SetWriteDeadline(deadline); err != nil {
-			return 0, fmt.Errorf("setting write deadline: %w", err)
+			return 0, &apierr.ConnectionError{Message: "setting write deadline", Err: err}
 		}
 	}
 
 	if err := conn.WriteMessage(gorilla.TextMessage, data); err != nil {
-		return 0, fmt.Errorf("writing to WebSocket: %w", err)
+		return 0, &apierr.ConnectionError{Message: "writing to WebSocket", Err: err}
 	}
 
 	return id, nil
@@ -191,7 +265,7 @@ func (t *WSTransport) readPump() {
 			return
 		}
 
-		_, data, err := conn.ReadMessage()
+		buf, err := readFrame(conn)
 		if err != nil {
 			select {
 			case <-done:
@@ -204,7 +278,8 @@ func (t *WSTransport) readPump() {
 			return
 		}
 
-		msg, err := jsonrpc.ParseMessage(data)
+		msg, err := jsonrpc.ParseMessage(buf.Bytes())
+		releaseFrame(buf)
 		if err != nil {
 			if t.handler != nil {
 				t.handler.OnError(fmt.Errorf("parsing message: %w", err))
@@ -224,6 +299,35 @@ func (t *WSTransport) readPump() {
 	}
 }
 
+// framePool holds read buffers. ParseMessage copies what it keeps, so a
+// buffer is free again once the frame is parsed.
+var framePool = sync.Pool{New: func() any { return new(bytes.Buffer) }}
+
+// maxPooledFrame is the largest buffer returned to framePool, so one huge
+// frame does not pin its memory.
+const maxPooledFrame = 1 << 20
+
+// readFrame reads the next message into a pooled buffer.
+func readFrame(conn *gorilla.Conn) (*bytes.Buffer, error) {
+	_, r, err := conn.NextReader()
+	if err != nil {
+		return nil, err
+	}
+	buf := framePool.Get().(*bytes.Buffer)
+	buf.Reset()
+	if _, err := buf.ReadFrom(r); err != nil {
+		releaseFrame(buf)
+		return nil, err
+	}
+	return buf, nil
+}
+
+func releaseFrame(buf *bytes.Buffer) {
+	if buf.Cap() <= maxPooledFrame {
+		framePool.Put(buf)
+	}
+}
+
 // pingPump sends periodic ping frames to keep the connection alive.
 func (t *WSTransport) pingPump() {
 	// Capture done channel under the lock so we don't race with Connect()
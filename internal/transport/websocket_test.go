@@ -2,9 +2,11 @@ package transport
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
+	"github.com/amiwrpremium/go-thalex/apierr"
 	"github.com/amiwrpremium/go-thalex/internal/jsonrpc"
 )
 
@@ -124,8 +126,12 @@ func TestWSTransport_Send(t *testing.T) {
 		if err == nil {
 			t.Fatal("expected an error when sending without connection")
 		}
-		if err.Error() != "not connected" {
-			t.Errorf("error = %q; want %q", err.Error(), "not connected")
+		var connErr *apierr.ConnectionError
+		if !errors.As(err, &connErr) || connErr.Message != "not connected" {
+			t.Errorf("error = %v; want ConnectionError %q", err, "not connected")
+		}
+		if !apierr.IsRetryable(err) {
+			t.Error("unsent request should be retryable")
 		}
 	})
 
@@ -176,3 +182,32 @@ func (m *mockWSHandler) OnResponse(resp *jsonrpc.Response)          {}
 func (m *mockWSHandler) OnNotification(notif *jsonrpc.Notification) {}
 func (m *mockWSHandler) OnError(err error)                          {}
 func (m *mockWSHandler) OnDisconnect()                              {}
+
+// ---------------------------------------------------------------------------
+// Failover URLs
+// ---------------------------------------------------------------------------
+
+func TestWSTransport_NextURL(t *testing.T) {
+	t.Run("rotates through failovers and wraps", func(t *testing.T) {
+		ws := NewWSTransport(WSTransportConfig{
+			URL:          "ws://primary",
+			FailoverURLs: []string{"ws://backup-1", "ws://backup-2"},
+		})
+		if ws.URL() != "ws://primary" {
+			t.Fatalf("URL() = %q; want primary", ws.URL())
+		}
+		want := []string{"ws://backup-1", "ws://backup-2", "ws://primary", "ws://backup-1"}
+		for i, w := range want {
+			if got := ws.NextURL(); got != w {
+				t.Errorf("NextURL() #%d = %q; want %q", i, got, w)
+			}
+		}
+	})
+
+	t.Run("no failovers stays on primary", func(t *testing.T) {
+		ws := NewWSTransport(WSTransportConfig{URL: "ws://primary"})
+		if got := ws.NextURL(); got != "ws://primary" {
+			t.Errorf("NextURL() = %q; want primary", got)
+		}
+	})
+}
@@ -0,0 +1,110 @@
+package journal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/amiwrpremium/go-thalex/apierr"
+	"github.com/amiwrpremium/go-thalex/types"
+)
+
+// Journaled API methods. Mass quotes are left out: they are sent too often
+// to journal, and their fills arrive as trades.
+const (
+	methodInsert            = "private/insert"
+	methodBuy               = "private/buy"
+	methodSell              = "private/sell"
+	methodAmend             = "private/amend"
+	methodCancel            = "private/cancel"
+	methodCancelAll         = "private/cancel_all"
+	methodCreateConditional = "private/create_conditional_order"
+	methodCancelConditional = "private/cancel_conditional_order"
+	methodCancelAllCond     = "private/cancel_all_conditional_orders"
+)
+
+var journaled = map[string]bool{
+	methodInsert: true, methodBuy: true, methodSell: true, methodAmend: true,
+	methodCancel: true, methodCancelAll: true, methodCreateConditional: true,
+	methodCancelConditional: true, methodCancelAllCond: true,
+}
+
+// OnRequest implements ws.Hook. It records order requests.
+func (j *Journal) OnRequest(method string, params any) {
+	if !journaled[method] {
+		return
+	}
+	raw, err := json.Marshal(params)
+	if err != nil {
+		j.report(fmt.Errorf("journal: %s params: %w", method, err))
+		return
+	}
+	j.record(Record{Kind: KindRequest, Method: method, Params: raw})
+}
+
+// OnResult implements ws.Hook. It records the outcome of order requests.
+func (j *Journal) OnResult(method string, params any, result any, err error) {
+	if !journaled[method] {
+		return
+	}
+	rec := Record{Kind: KindResult, Method: method}
+	var mErr error
+	if err != nil {
+		rec.Err, rec.Rejected = err.Error(), rejected(err)
+		rec.Params, mErr = json.Marshal(params)
+	} else if result != nil {
+		rec.Data, mErr = json.Marshal(result)
+	}
+	if mErr != nil {
+		j.report(fmt.Errorf("journal: %s result: %w", method, mErr))
+		return
+	}
+	j.record(rec)
+}
+
+// OnNotification implements ws.Hook. It records order, trade and
+// conditional order notifications, one record per item.
+func (j *Journal) OnNotification(channel string, v any) {
+	switch v := v.(type) {
+	case []types.OrderStatus:
+		recordAll(j, KindOrder, channel, v)
+	case []types.Trade:
+		recordAll(j, KindTrade, channel, v)
+	case []types.ConditionalOrder:
+		recordAll(j, KindConditional, channel, v)
+	}
+}
+
+func recordAll[T any](j *Journal, kind Kind, channel string, items []T) {
+	for i := range items {
+		raw, err := json.Marshal(&items[i])
+		if err != nil {
+			j.report(fmt.Errorf("journal: %s notification: %w", channel, err))
+			continue
+		}
+		j.record(Record{Kind: kind, Method: channel, Data: raw})
+	}
+}
+
+func (j *Journal) record(rec Record) {
+	if _, err := j.Append(rec); err != nil {
+		j.report(err)
+	}
+}
+
+// rejected reports whether a failed request certainly had no effect: the
+// exchange refused it, or it was never sent.
+func rejected(err error) bool {
+	if apierr.IsRetryable(err) {
+		return true
+	}
+	var apiErr *apierr.APIError
+	if errors.As(err, &apiErr) {
+		return !apierr.IsTransient(err)
+	}
+	var connErr *apierr.ConnectionError
+	var timeoutErr *apierr.TimeoutError
+	return !errors.As(err, &connErr) && !errors.As(err, &timeoutErr) &&
+		!errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,387 @@
+// Package journal keeps a durable, append-only record of the orders and
+// trades a process sees, to rebuild its state after a crash and for audits.
+//
+// A Journal writes Records to segment files in a directory. Every record
+// is one line carrying a CRC-32 checksum, and a SyncPolicy decides how
+// often the data is flushed to stable storage. A Journal is a ws.Hook:
+// added to a ws.Client it records every order request and its result, and
+// every order, trade and conditional order notification of the channels
+// the client subscribes to. Rebuild replays a journal into the last known
+// state of orders, conditional orders and positions.
+package journal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultSegmentSize is the size at which a segment is closed and a new one
+// started.
+const DefaultSegmentSize = 64 << 20
+
+// DefaultSyncInterval is the flush interval of SyncInterval.
+const DefaultSyncInterval = 100 * time.Millisecond
+
+var (
+	// ErrCorrupt reports a journal that fails its integrity checks.
+	ErrCorrupt = errors.New("journal: corrupt journal")
+	// ErrClosed reports an append to a closed Journal.
+	ErrClosed = errors.New("journal: journal is closed")
+)
+
+// Kind identifies what a Record holds.
+type Kind string
+
+const (
+	// KindRequest is an order request about to be sent; Params holds its
+	// parameters, such as types.InsertOrderParams.
+	KindRequest Kind = "request"
+	// KindResult is the outcome of an order request. Data holds the
+	// result, such as types.OrderStatus; a failed request has Err and
+	// Params instead.
+	KindResult Kind = "result"
+	// KindOrder is an order update notification; Data holds a
+	// types.OrderStatus.
+	KindOrder Kind = "order"
+	// KindTrade is a trade notification; Data holds a types.Trade.
+	KindTrade Kind = "trade"
+	// KindConditional is a conditional order notification; Data holds a
+	// types.ConditionalOrder.
+	KindConditional Kind = "conditional"
+)
+
+// Record is one journal entry.
+type Record struct {
+	// Seq numbers the records of a journal from 1 without gaps.
+	Seq  uint64    `json:"seq"`
+	Time time.Time `json:"time"`
+	Kind Kind      `json:"kind"`
+	// Method is the API method of a request or result, or the channel of
+	// a notification.
+	Method string          `json:"method,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
+	Data   json.RawMessage `json:"data,omitempty"`
+	Err    string          `json:"error,omitempty"`
+	// Rejected marks a failed request that certainly had no effect, such
+	// as one the exchange refused. Other failures, like timeouts, leave
+	// the outcome unknown.
+	Rejected bool `json:"rejected,omitempty"`
+}
+
+// Decode decodes the record's Data into v.
+func (r Record) Decode(v any) error {
+	return json.Unmarshal(r.Data, v)
+}
+
+// SyncPolicy decides when appended records are flushed to stable storage.
+// Every record is written to the operating system as it is appended, so
+// all policies survive a crash of the process; they differ on a crash of
+// the machine.
+type SyncPolicy int
+
+const (
+	// SyncAlways flushes every record before Append returns.
+	SyncAlways SyncPolicy = iota
+	// SyncInterval flushes in the background; see WithSyncInterval.
+	SyncInterval
+	// SyncNever leaves flushing to the operating system.
+	SyncNever
+)
+
+// Option configures a Journal.
+type Option func(*Journal)
+
+// WithSyncPolicy sets when records are flushed. The default is SyncAlways.
+func WithSyncPolicy(p SyncPolicy) Option {
+	return func(j *Journal) { j.policy = p }
+}
+
+// WithSyncInterval sets the flush interval of SyncInterval. The default is
+// DefaultSyncInterval.
+func WithSyncInterval(d time.Duration) Option {
+	return func(j *Journal) {
+		if d > 0 {
+			j.interval = d
+		}
+	}
+}
+
+// WithSegmentSize sets the size at which a new segment is started. The
+// default is DefaultSegmentSize.
+func WithSegmentSize(n int64) Option {
+	return func(j *Journal) { j.segmentSize = max(n, 1) }
+}
+
+// WithClock sets the time source of record timestamps. It is intended for
+// tests.
+func WithClock(now func() time.Time) Option {
+	return func(j *Journal) { j.now = now }
+}
+
+// WithErrorHandler registers a callback for errors appending the records
+// of hook calls, which have no caller to return them to. Without one they
+// are dropped.
+func WithErrorHandler(fn func(error)) Option {
+	return func(j *Journal) { j.onError = fn }
+}
+
+// Journal appends records to a directory of segment files. It is safe for
+// concurrent use.
+type Journal struct {
+	dir         string
+	policy      SyncPolicy
+	interval    time.Duration
+	segmentSize int64
+	now         func() time.Time
+	onError     func(error)
+
+	mu     sync.Mutex
+	file   segmentFile
+	size   int64
+	seq    uint64
+	dirty  bool
+	closed bool
+	// broken is set when a failed append could not be undone; the segment
+	// may end in a partial record, so nothing more is appended.
+	broken error
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// segmentFile is the open segment. It is an *os.File outside tests.
+type segmentFile interface {
+	io.Writer
+	io.Seeker
+	Truncate(size int64) error
+	Sync() error
+	Close() error
+}
+
+// Open opens the journal in dir, creating the directory if needed, and
+// continues it after its last record.
+//
+// A record cut short by a crash at the end of the journal is removed.
+// Damage anywhere else is reported as ErrCorrupt.
+func Open(dir string, opts ...Option) (*Journal, error) {
+	j := &Journal{
+		dir:         dir,
+		interval:    DefaultSyncInterval,
+		segmentSize: DefaultSegmentSize,
+		now:         time.Now,
+	}
+	for _, opt := range opts {
+		opt(j)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("journal: %w", err)
+	}
+	segs, err := segments(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(segs) == 0 {
+		err = j.startSegment(1)
+	} else {
+		err = j.resume(segs[len(segs)-1])
+	}
+	if err != nil {
+		return nil, err
+	}
+	if j.policy == SyncInterval {
+		j.stop, j.done = make(chan struct{}), make(chan struct{})
+		go j.syncLoop()
+	}
+	return j, nil
+}
+
+// Append writes a record, numbering it and, if its Time is zero,
+// timestamping it. It returns the record's sequence number.
+func (j *Journal) Append(rec Record) (uint64, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.closed {
+		return 0, ErrClosed
+	}
+	if j.broken != nil {
+		return 0, j.broken
+	}
+	if rec.Time.IsZero() {
+		rec.Time = j.now()
+	}
+	if j.size >= j.segmentSize {
+		if err := j.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	rec.Seq = j.seq + 1
+	payload, err := json.Marshal(rec)
+	if err != nil {
+		return 0, fmt.Errorf("journal: %w", err)
+	}
+	line := recordLine(payload)
+	if _, err := j.file.Write(line); err != nil {
+		j.discardTail()
+		return 0, fmt.Errorf("journal: %w", err)
+	}
+	j.seq++
+	j.size += int64(len(line))
+	j.dirty = true
+	if j.policy == SyncAlways {
+		if err := j.syncLocked(); err != nil {
+			return 0, err
+		}
+	}
+	return rec.Seq, nil
+}
+
+// Sync flushes the appended records to stable storage.
+func (j *Journal) Sync() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.closed {
+		return ErrClosed
+	}
+	return j.syncLocked()
+}
+
+// Close flushes and closes the journal.
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	if j.closed {
+		j.mu.Unlock()
+		return nil
+	}
+	j.closed = true
+	err := j.syncLocked()
+	if cerr := j.file.Close(); err == nil && cerr != nil {
+		err = fmt.Errorf("journal: %w", cerr)
+	}
+	j.mu.Unlock()
+	if j.stop != nil {
+		close(j.stop)
+		<-j.done
+	}
+	return err
+}
+
+// discardTail cuts the segment back to its last complete record after a
+// failed write, which may have left part of a record behind. If that fails
+// too, the journal is marked broken.
+func (j *Journal) discardTail() {
+	if err := j.file.Truncate(j.size); err != nil {
+		j.broken = fmt.Errorf("journal: discard partial record: %w", err)
+		return
+	}
+	if _, err := j.file.Seek(j.size, io.SeekStart); err != nil {
+		j.broken = fmt.Errorf("journal: discard partial record: %w", err)
+	}
+}
+
+func (j *Journal) syncLocked() error {
+	if !j.dirty {
+		return nil
+	}
+	if err := j.file.Sync(); err != nil {
+		return fmt.Errorf("journal: %w", err)
+	}
+	j.dirty = false
+	return nil
+}
+
+func (j *Journal) syncLoop() {
+	defer close(j.done)
+	t := time.NewTicker(j.interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-j.stop:
+			return
+		case <-t.C:
+			j.mu.Lock()
+			var err error
+			if !j.closed {
+				err = j.syncLocked()
+			}
+			j.mu.Unlock()
+			if err != nil {
+				j.report(err)
+			}
+		}
+	}
+}
+
+// rotate closes the current segment and starts the next.
+func (j *Journal) rotate() error {
+	if err := j.syncLocked(); err != nil {
+		return err
+	}
+	if err := j.file.Close(); err != nil {
+		return fmt.Errorf("journal: %w", err)
+	}
+	return j.startSegment(j.seq + 1)
+}
+
+// startSegment creates the segment whose first record is seq.
+func (j *Journal) startSegment(seq uint64) error {
+	f, err := os.OpenFile(segmentPath(j.dir, seq), os.O_WRONLY|os.O_APPEND|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return fmt.Errorf("journal: %w", err)
+	}
+	if _, err := f.WriteString(segmentHeader); err != nil {
+		f.Close()
+		return fmt.Errorf("journal: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("journal: %w", err)
+	}
+	if err := syncDir(j.dir); err != nil {
+		f.Close()
+		return err
+	}
+	j.file, j.size, j.seq = f, int64(len(segmentHeader)), seq-1
+	return nil
+}
+
+// resume reopens the last segment for appending, cutting off a torn final
+// record.
+func (j *Journal) resume(seg segment) error {
+	last, end, err := scanSegment(seg, true, nil)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(seg.path, os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("journal: %w", err)
+	}
+	if err := f.Truncate(end); err != nil {
+		f.Close()
+		return fmt.Errorf("journal: %w", err)
+	}
+	if end == 0 {
+		// The crash came before the header was complete.
+		if _, err := f.WriteString(segmentHeader); err != nil {
+			f.Close()
+			return fmt.Errorf("journal: %w", err)
+		}
+		end = int64(len(segmentHeader))
+	}
+	if _, err := f.Seek(end, 0); err != nil {
+		f.Close()
+		return fmt.Errorf("journal: %w", err)
+	}
+	j.file, j.size, j.seq = f, end, last
+	return nil
+}
+
+func (j *Journal) report(err error) {
+	if j.onError != nil {
+		j.onError(err)
+	}
+}
@@ -0,0 +1,84 @@
+package journal
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// tornFile writes half of the next record and then fails, like a disk
+// that fills up mid-write.
+type tornFile struct {
+	segmentFile
+	truncateErr error
+}
+
+func (f *tornFile) Write(p []byte) (int, error) {
+	n, _ := f.segmentFile.Write(p[:len(p)/2])
+	return n, errors.New("no space left on device")
+}
+
+func (f *tornFile) Truncate(size int64) error {
+	if f.truncateErr != nil {
+		return f.truncateErr
+	}
+	return f.segmentFile.Truncate(size)
+}
+
+func appendRecord(j *Journal) (uint64, error) {
+	return j.Append(Record{Kind: KindOrder, Method: "account.orders", Data: []byte(`{"order_id":"x"}`)})
+}
+
+func TestAppend_DiscardsPartialWrite(t *testing.T) {
+	dir := t.TempDir()
+	j, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := appendRecord(j); err != nil {
+		t.Fatal(err)
+	}
+	real := j.file
+	j.file = &tornFile{segmentFile: real}
+	if _, err := appendRecord(j); err == nil {
+		t.Fatal("torn append succeeded")
+	}
+	j.file = real
+	if seq, err := appendRecord(j); err != nil || seq != 2 {
+		t.Fatalf("Append after a failed write = %d, %v", seq, err)
+	}
+	if err := j.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []uint64
+	if err := Replay(dir, func(r Record) error {
+		got = append(got, r.Seq)
+		return nil
+	}); err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	if !reflect.DeepEqual(got, []uint64{1, 2}) {
+		t.Errorf("replayed %v, want [1 2]", got)
+	}
+	j, err = Open(dir)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	j.Close()
+}
+
+func TestAppend_BrokenAfterFailedDiscard(t *testing.T) {
+	j, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+	j.file = &tornFile{segmentFile: j.file, truncateErr: errors.New("read-only file system")}
+	if _, err := appendRecord(j); err == nil {
+		t.Fatal("torn append succeeded")
+	}
+	if _, err := appendRecord(j); err == nil || j.broken == nil {
+		t.Errorf("Append on a broken journal = %v", err)
+	}
+}
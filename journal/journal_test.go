@@ -0,0 +1,296 @@
+package journal_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/amiwrpremium/go-thalex/apierr"
+	"github.com/amiwrpremium/go-thalex/enums"
+	"github.com/amiwrpremium/go-thalex/journal"
+	"github.com/amiwrpremium/go-thalex/reconcile"
+	"github.com/amiwrpremium/go-thalex/types"
+	"github.com/amiwrpremium/go-thalex/ws"
+)
+
+var _ ws.Hook = (*journal.Journal)(nil)
+
+func clock() func() time.Time {
+	t := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	return func() time.Time { t = t.Add(time.Second); return t }
+}
+
+func open(t *testing.T, dir string, opts ...journal.Option) *journal.Journal {
+	t.Helper()
+	j, err := journal.Open(dir, append([]journal.Option{journal.WithClock(clock())}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return j
+}
+
+func appendN(t *testing.T, j *journal.Journal, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		if _, err := j.Append(journal.Record{Kind: journal.KindOrder, Method: "account.orders", Data: []byte(`{"order_id":"x"}`)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func replay(t *testing.T, dir string) []journal.Record {
+	t.Helper()
+	var out []journal.Record
+	if err := journal.Replay(dir, func(r journal.Record) error {
+		out = append(out, r)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func seqs(recs []journal.Record) []uint64 {
+	var out []uint64
+	for _, r := range recs {
+		out = append(out, r.Seq)
+	}
+	return out
+}
+
+func lastSegment(t *testing.T, dir string) string {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(dir, "*.journal"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no segments: %v", err)
+	}
+	return files[len(files)-1]
+}
+
+func TestJournal_AppendReplay(t *testing.T) {
+	dir := t.TempDir()
+	j := open(t, dir)
+	appendN(t, j, 3)
+	if err := j.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := j.Append(journal.Record{Kind: journal.KindOrder}); !errors.Is(err, journal.ErrClosed) {
+		t.Errorf("Append after Close = %v, want ErrClosed", err)
+	}
+
+	j = open(t, dir)
+	seq, err := j.Append(journal.Record{Kind: journal.KindTrade, Time: time.Unix(5, 0).UTC()})
+	if err != nil || seq != 4 {
+		t.Fatalf("Append after reopen = %d, %v; want 4", seq, err)
+	}
+	j.Close()
+
+	recs := replay(t, dir)
+	if got := seqs(recs); !reflect.DeepEqual(got, []uint64{1, 2, 3, 4}) {
+		t.Fatalf("replayed %v", got)
+	}
+	if recs[0].Kind != journal.KindOrder || string(recs[0].Data) != `{"order_id":"x"}` || recs[0].Time.IsZero() {
+		t.Errorf("record 1 = %+v", recs[0])
+	}
+	if !recs[3].Time.Equal(time.Unix(5, 0)) {
+		t.Errorf("record 4 time = %v, want the one given", recs[3].Time)
+	}
+}
+
+func TestJournal_Segments(t *testing.T) {
+	dir := t.TempDir()
+	j := open(t, dir, journal.WithSegmentSize(200))
+	appendN(t, j, 10)
+	j.Close()
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.journal"))
+	if len(files) < 3 {
+		t.Fatalf("%d segments, want several", len(files))
+	}
+	j = open(t, dir, journal.WithSegmentSize(200))
+	appendN(t, j, 2)
+	j.Close()
+	if got := seqs(replay(t, dir)); len(got) != 12 || got[11] != 12 {
+		t.Errorf("replayed %v", got)
+	}
+
+	// A missing segment is a gap.
+	if err := os.Remove(files[1]); err != nil {
+		t.Fatal(err)
+	}
+	if err := journal.Replay(dir, func(journal.Record) error { return nil }); !errors.Is(err, journal.ErrCorrupt) {
+		t.Errorf("Replay with a missing segment = %v, want ErrCorrupt", err)
+	}
+}
+
+func TestJournal_TornTail(t *testing.T) {
+	dir := t.TempDir()
+	j := open(t, dir)
+	appendN(t, j, 2)
+	j.Close()
+
+	// A crash in the middle of the third record.
+	path := lastSegment(t, dir)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`0badc0de	{"seq":3,"ki`)
+	f.Close()
+
+	if got := seqs(replay(t, dir)); !reflect.DeepEqual(got, []uint64{1, 2}) {
+		t.Fatalf("replayed %v before reopening", got)
+	}
+	j = open(t, dir)
+	appendN(t, j, 1)
+	j.Close()
+	if got := seqs(replay(t, dir)); !reflect.DeepEqual(got, []uint64{1, 2, 3}) {
+		t.Errorf("replayed %v after reopening", got)
+	}
+}
+
+func TestJournal_Corrupt(t *testing.T) {
+	dir := t.TempDir()
+	j := open(t, dir)
+	appendN(t, j, 3)
+	j.Close()
+
+	path := lastSegment(t, dir)
+	data, _ := os.ReadFile(path)
+	i := len("#thalex-journal v1\n") + 20
+	data[i] ^= 1
+	os.WriteFile(path, data, 0o644)
+
+	if err := journal.Replay(dir, func(journal.Record) error { return nil }); !errors.Is(err, journal.ErrCorrupt) {
+		t.Errorf("Replay = %v, want ErrCorrupt", err)
+	}
+	if _, err := journal.Open(dir); !errors.Is(err, journal.ErrCorrupt) {
+		t.Errorf("Open = %v, want ErrCorrupt", err)
+	}
+}
+
+func TestJournal_SyncPolicies(t *testing.T) {
+	for _, p := range []journal.SyncPolicy{journal.SyncAlways, journal.SyncInterval, journal.SyncNever} {
+		dir := t.TempDir()
+		j := open(t, dir, journal.WithSyncPolicy(p), journal.WithSyncInterval(time.Millisecond))
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				appendN(t, j, 25)
+			}()
+		}
+		wg.Wait()
+		if err := j.Sync(); err != nil {
+			t.Errorf("policy %d: Sync = %v", p, err)
+		}
+		if err := j.Close(); err != nil {
+			t.Errorf("policy %d: Close = %v", p, err)
+		}
+		if n := len(replay(t, dir)); n != 100 {
+			t.Errorf("policy %d: replayed %d records, want 100", p, n)
+		}
+	}
+}
+
+func ptr[T any](v T) *T { return &v }
+
+func TestRebuild(t *testing.T) {
+	dir := t.TempDir()
+	j := open(t, dir)
+
+	// An order that is accepted, then partially filled. The stale "open"
+	// status arrives last.
+	p1 := types.NewBuyOrderParams("BTC-PERPETUAL", 2).WithPrice(50000).WithClientOrderID(1).WithLabel("mm")
+	j.OnRequest("private/insert", p1)
+	open1 := types.OrderStatus{OrderID: "o1", ClientOrderID: ptr[uint64](1), Label: "mm", Amount: 2, Status: enums.OrderStatusOpen}
+	j.OnResult("private/insert", p1, &open1, nil)
+	part := open1
+	part.FilledAmount, part.Status = 1, enums.OrderStatusPartiallyFilled
+	j.OnNotification("account.orders", []types.OrderStatus{part})
+	j.OnNotification("account.orders", []types.OrderStatus{open1})
+
+	// An insert that timed out and one that was refused.
+	p2 := types.NewSellOrderParams("BTC-PERPETUAL", 1).WithPrice(51000).WithClientOrderID(2)
+	j.OnRequest("private/insert", p2)
+	j.OnResult("private/insert", p2, nil, &apierr.TimeoutError{Message: "timeout"})
+	p3 := types.NewSellOrderParams("BTC-PERPETUAL", 1).WithPrice(52000).WithClientOrderID(3)
+	j.OnRequest("private/insert", p3)
+	j.OnResult("private/insert", p3, nil, &apierr.APIError{Code: 1, Message: "insufficient margin"})
+
+	// An order that was filled.
+	j.OnNotification("account.orders", []types.OrderStatus{{OrderID: "o4", Amount: 1, FilledAmount: 1, Status: enums.OrderStatusFilled}})
+	j.OnNotification("account.orders", []types.OrderStatus{{OrderID: "o4", Amount: 1, Status: enums.OrderStatusOpen}})
+
+	// Trades; the position follows the latest one.
+	j.OnNotification("account.trades", []types.Trade{
+		{TradeID: "t2", InstrumentName: "BTC-PERPETUAL", Time: 20, PositionAfter: 1},
+		{TradeID: "t1", InstrumentName: "BTC-PERPETUAL", Time: 10, PositionAfter: 0.5},
+		{TradeID: "t3", InstrumentName: "ETH-PERPETUAL", Time: 15, PositionAfter: 0},
+	})
+
+	// Conditional orders.
+	c1 := types.ConditionalOrder{OrderID: "c1", Label: "stop", Status: enums.ConditionalOrderStatusActive}
+	j.OnResult("private/create_conditional_order", &types.CreateConditionalOrderParams{}, &c1, nil)
+	j.OnNotification("account.conditional_orders", []types.ConditionalOrder{
+		{OrderID: "c2", Status: enums.ConditionalOrderStatusCancelled},
+	})
+
+	// Unrelated traffic is not journaled.
+	j.OnRequest("public/ticker", nil)
+	j.OnNotification("ticker.BTC-PERPETUAL.100ms", types.Ticker{})
+	if err := j.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := journal.Rebuild(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.LastSeq != 15 {
+		t.Errorf("LastSeq = %d, want 15", s.LastSeq)
+	}
+	if o := s.Orders["o1"]; o.FilledAmount != 1 || o.Status != enums.OrderStatusPartiallyFilled {
+		t.Errorf("o1 = %+v, want partially filled", o)
+	}
+	if o := s.Orders["o4"]; o.Status != enums.OrderStatusFilled {
+		t.Errorf("o4 = %+v, want filled", o)
+	}
+	if open := s.OpenOrders(); len(open) != 1 || open[0].OrderID != "o1" {
+		t.Errorf("OpenOrders = %+v", open)
+	}
+	if len(s.Unresolved) != 1 || *s.Unresolved[0].ClientOrderID != 2 {
+		t.Errorf("Unresolved = %+v, want client order 2", s.Unresolved)
+	}
+	if !reflect.DeepEqual(s.Positions, map[string]float64{"BTC-PERPETUAL": 1, "ETH-PERPETUAL": 0}) {
+		t.Errorf("Positions = %v", s.Positions)
+	}
+	if c := s.ActiveConditionals(); len(c) != 1 || c[0].OrderID != "c1" {
+		t.Errorf("ActiveConditionals = %+v", c)
+	}
+
+	want := []reconcile.Intent{
+		{Kind: reconcile.KindOrder, ID: "o1", ClientOrderID: ptr[uint64](1), Label: "mm"},
+		{Kind: reconcile.KindOrder, ClientOrderID: ptr[uint64](2)},
+		{Kind: reconcile.KindConditional, ID: "c1", Label: "stop"},
+		{Kind: reconcile.KindPosition, Instrument: "BTC-PERPETUAL", Position: 1},
+	}
+	if got := s.Intents(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Intents = %+v\nwant %+v", got, want)
+	}
+}
+
+func TestHook_ErrorHandler(t *testing.T) {
+	var errs []error
+	j := open(t, t.TempDir(), journal.WithErrorHandler(func(err error) { errs = append(errs, err) }))
+	j.Close()
+	j.OnRequest("private/insert", types.NewBuyOrderParams("BTC-PERPETUAL", 1))
+	if len(errs) != 1 || !errors.Is(errs[0], journal.ErrClosed) {
+		t.Errorf("errors = %v, want ErrClosed", errs)
+	}
+}
@@ -0,0 +1,157 @@
+package journal
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	segmentHeader = "#thalex-journal v1\n"
+	segmentExt    = ".journal"
+)
+
+// A segment is one file of a journal. Its name is the sequence number of
+// its first record.
+//
+// The format is a header line followed by one line per record of
+// "<crc32 hex>\t<record JSON>".
+type segment struct {
+	path  string
+	first uint64
+}
+
+func segmentPath(dir string, first uint64) string {
+	return filepath.Join(dir, fmt.Sprintf("%020d%s", first, segmentExt))
+}
+
+// segments lists the segments of the journal in dir in order.
+func segments(dir string) ([]segment, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("journal: %w", err)
+	}
+	var segs []segment
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), segmentExt)
+		if !ok || e.IsDir() {
+			continue
+		}
+		first, err := strconv.ParseUint(name, 10, 64)
+		if err != nil || first == 0 {
+			continue
+		}
+		segs = append(segs, segment{path: filepath.Join(dir, e.Name()), first: first})
+	}
+	slices.SortFunc(segs, func(a, b segment) int { return cmp.Compare(a.first, b.first) })
+	return segs, nil
+}
+
+func recordLine(payload []byte) []byte {
+	line := make([]byte, 0, len(payload)+10)
+	line = fmt.Appendf(line, "%08x\t", crc32.ChecksumIEEE(payload))
+	line = append(line, payload...)
+	return append(line, '\n')
+}
+
+// scanSegment reads a segment, passing each record to fn if it is non-nil.
+// It returns the sequence number of the last record and the offset just
+// past it.
+//
+// In the last segment of a journal a damaged final line is the record a
+// crash interrupted, and it is skipped. Any other damage is ErrCorrupt.
+func scanSegment(seg segment, last bool, fn func(Record) error) (uint64, int64, error) {
+	data, err := os.ReadFile(seg.path)
+	if err != nil {
+		return 0, 0, fmt.Errorf("journal: %w", err)
+	}
+	seq := seg.first - 1
+	if !bytes.HasPrefix(data, []byte(segmentHeader)) {
+		if last && bytes.HasPrefix([]byte(segmentHeader), data) {
+			return seq, 0, nil
+		}
+		return 0, 0, fmt.Errorf("%w: %s: bad header", ErrCorrupt, seg.path)
+	}
+	off := int64(len(segmentHeader))
+	for lineNo := 2; off < int64(len(data)); lineNo++ {
+		rest := data[off:]
+		n := bytes.IndexByte(rest, '\n')
+		final := n < 0 || n == len(rest)-1
+		var rec Record
+		var ok bool
+		if n >= 0 {
+			rec, ok = parseLine(rest[:n])
+		}
+		if !ok {
+			if last && final {
+				break
+			}
+			return 0, 0, fmt.Errorf("%w: %s: line %d fails checksum", ErrCorrupt, seg.path, lineNo)
+		}
+		if rec.Seq != seq+1 {
+			return 0, 0, fmt.Errorf("%w: %s: line %d: record %d out of sequence", ErrCorrupt, seg.path, lineNo, rec.Seq)
+		}
+		if fn != nil {
+			if err := fn(rec); err != nil {
+				return 0, 0, err
+			}
+		}
+		seq = rec.Seq
+		off += int64(n) + 1
+	}
+	return seq, off, nil
+}
+
+func parseLine(line []byte) (Record, bool) {
+	var rec Record
+	sum, payload, ok := bytes.Cut(line, []byte("\t"))
+	if !ok || string(sum) != fmt.Sprintf("%08x", crc32.ChecksumIEEE(payload)) {
+		return rec, false
+	}
+	if err := json.Unmarshal(payload, &rec); err != nil {
+		return rec, false
+	}
+	return rec, true
+}
+
+// syncDir flushes a directory entry, so a new segment survives a crash of
+// the machine. Some platforms cannot sync directories; that is ignored.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("journal: %w", err)
+	}
+	_ = d.Sync()
+	return d.Close()
+}
+
+// Replay passes every record of the journal in dir to fn, in order. It
+// stops at the first error fn returns and returns it.
+//
+// A record cut short by a crash at the end of the journal is skipped.
+// Damage anywhere else, or a gap in the sequence numbers, is ErrCorrupt.
+// Replay may run while a Journal appends to dir; it sees the records
+// appended before it reached the last segment's end.
+func Replay(dir string, fn func(Record) error) error {
+	segs, err := segments(dir)
+	if err != nil {
+		return err
+	}
+	for i, seg := range segs {
+		last, _, err := scanSegment(seg, i == len(segs)-1, fn)
+		if err != nil {
+			return err
+		}
+		if i+1 < len(segs) && segs[i+1].first != last+1 {
+			return fmt.Errorf("%w: %s: records %d to %d missing", ErrCorrupt, dir, last+1, segs[i+1].first-1)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package journal
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/amiwrpremium/go-thalex/reconcile"
+	"github.com/amiwrpremium/go-thalex/types"
+)
+
+// State is the state of orders and positions rebuilt from a journal.
+type State struct {
+	// Orders holds the latest status of every order, by order ID.
+	Orders map[string]types.OrderStatus
+	// Conditionals holds the latest state of every conditional order, by
+	// order ID.
+	Conditionals map[string]types.ConditionalOrder
+	// Positions holds the position per instrument after the last journaled
+	// trade in it.
+	Positions map[string]float64
+	// Unresolved lists the inserts with a client order ID whose outcome the
+	// journal does not show: no status of the order was journaled, and the
+	// insert was not refused. Such an order may be live.
+	Unresolved []types.InsertOrderParams
+	// LastSeq is the sequence number of the last record replayed.
+	LastSeq uint64
+
+	pending    map[uint64]types.InsertOrderParams
+	tradeTimes map[string]types.Timestamp
+}
+
+// Rebuild replays the journal in dir into a State.
+func Rebuild(dir string) (*State, error) {
+	s := &State{
+		Orders:       make(map[string]types.OrderStatus),
+		Conditionals: make(map[string]types.ConditionalOrder),
+		Positions:    make(map[string]float64),
+		pending:      make(map[uint64]types.InsertOrderParams),
+		tradeTimes:   make(map[string]types.Timestamp),
+	}
+	if err := Replay(dir, s.apply); err != nil {
+		return nil, err
+	}
+	for _, p := range s.pending {
+		s.Unresolved = append(s.Unresolved, p)
+	}
+	slices.SortFunc(s.Unresolved, func(a, b types.InsertOrderParams) int {
+		return cmp.Compare(*a.ClientOrderID, *b.ClientOrderID)
+	})
+	return s, nil
+}
+
+// OpenOrders returns the orders whose last status is open or partially
+// filled, ordered by order ID.
+func (s *State) OpenOrders() []types.OrderStatus {
+	var out []types.OrderStatus
+	for _, o := range s.Orders {
+		if o.Status.IsActive() {
+			out = append(out, o)
+		}
+	}
+	slices.SortFunc(out, func(a, b types.OrderStatus) int { return cmp.Compare(a.OrderID, b.OrderID) })
+	return out
+}
+
+// ActiveConditionals returns the conditional orders whose last state is
+// active, ordered by order ID.
+func (s *State) ActiveConditionals() []types.ConditionalOrder {
+	var out []types.ConditionalOrder
+	for _, c := range s.Conditionals {
+		if c.Status.IsActive() {
+			out = append(out, c)
+		}
+	}
+	slices.SortFunc(out, func(a, b types.ConditionalOrder) int { return cmp.Compare(a.OrderID, b.OrderID) })
+	return out
+}
+
+// Intents describes the open orders, unresolved inserts, active
+// conditional orders and non-zero positions as intents to check against
+// the exchange with a reconcile.Reconciler.
+func (s *State) Intents() []reconcile.Intent {
+	var out []reconcile.Intent
+	for _, o := range s.OpenOrders() {
+		out = append(out, reconcile.Intent{Kind: reconcile.KindOrder, ID: o.OrderID, ClientOrderID: o.ClientOrderID, Label: o.Label})
+	}
+	for _, p := range s.Unresolved {
+		out = append(out, reconcile.Intent{Kind: reconcile.KindOrder, ClientOrderID: p.ClientOrderID, Label: p.Label})
+	}
+	for _, c := range s.ActiveConditionals() {
+		out = append(out, reconcile.Intent{Kind: reconcile.KindConditional, ID: c.OrderID, Label: c.Label})
+	}
+	instruments := make([]string, 0, len(s.Positions))
+	for name, pos := range s.Positions {
+		if pos != 0 {
+			instruments = append(instruments, name)
+		}
+	}
+	slices.Sort(instruments)
+	for _, name := range instruments {
+		out = append(out, reconcile.Intent{Kind: reconcile.KindPosition, Instrument: name, Position: s.Positions[name]})
+	}
+	return out
+}
+
+func (s *State) apply(rec Record) error {
+	s.LastSeq = rec.Seq
+	var err error
+	switch rec.Kind {
+	case KindRequest:
+		if rec.Method == methodInsert {
+			err = s.insert(rec.Params)
+		}
+	case KindResult:
+		err = s.result(rec)
+	case KindOrder:
+		var o types.OrderStatus
+		if err = rec.Decode(&o); err == nil {
+			s.order(o)
+		}
+	case KindTrade:
+		var t types.Trade
+		if err = rec.Decode(&t); err == nil {
+			s.trade(t)
+		}
+	case KindConditional:
+		var c types.ConditionalOrder
+		if err = rec.Decode(&c); err == nil {
+			s.conditional(c)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("journal: record %d: %w", rec.Seq, err)
+	}
+	return nil
+}
+
+func (s *State) insert(raw json.RawMessage) error {
+	var p types.InsertOrderParams
+	if err := json.Unmarshal(raw, &p); err != nil {
+		return err
+	}
+	if p.ClientOrderID != nil {
+		s.pending[*p.ClientOrderID] = p
+	}
+	return nil
+}
+
+func (s *State) result(rec Record) error {
+	if rec.Err != "" {
+		if rec.Rejected && rec.Method == methodInsert {
+			var p types.InsertOrderParams
+			if err := json.Unmarshal(rec.Params, &p); err != nil {
+				return err
+			}
+			if p.ClientOrderID != nil {
+				delete(s.pending, *p.ClientOrderID)
+			}
+		}
+		return nil
+	}
+	switch rec.Method {
+	case methodInsert, methodBuy, methodSell, methodAmend, methodCancel:
+		var o types.OrderStatus
+		if err := rec.Decode(&o); err != nil {
+			return err
+		}
+		s.order(o)
+	case methodCreateConditional:
+		var c types.ConditionalOrder
+		if err := rec.Decode(&c); err != nil {
+			return err
+		}
+		s.conditional(c)
+	}
+	return nil
+}
+
+// order merges an order status. Responses and notifications can be
+// journaled out of order, so a status that would move an order out of a
+// final state or to a smaller filled amount is stale.
+func (s *State) order(o types.OrderStatus) {
+	if o.ClientOrderID != nil {
+		delete(s.pending, *o.ClientOrderID)
+	}
+	if o.OrderID == "" {
+		return
+	}
+	prev, ok := s.Orders[o.OrderID]
+	if ok && prev.Status != "" && (prev.Status.IsFinal() || o.FilledAmount < prev.FilledAmount) {
+		return
+	}
+	s.Orders[o.OrderID] = o
+}
+
+func (s *State) conditional(c types.ConditionalOrder) {
+	if c.OrderID == "" {
+		return
+	}
+	if prev, ok := s.Conditionals[c.OrderID]; ok && prev.Status != "" && !prev.Status.IsActive() {
+		return
+	}
+	s.Conditionals[c.OrderID] = c
+}
+
+// trade records the position after a trade, unless a later trade in the
+// instrument was already seen.
+func (s *State) trade(t types.Trade) {
+	if last, ok := s.tradeTimes[t.InstrumentName]; ok && t.Time < last {
+		return
+	}
+	s.tradeTimes[t.InstrumentName] = t.Time
+	s.Positions[t.InstrumentName] = t.PositionAfter
+}
@@ -0,0 +1,18 @@
+package thalex
+
+import (
+	"context"
+
+	"github.com/amiwrpremium/go-thalex/killswitch"
+)
+
+// KillSwitch cancels all orders, mass quotes, conditional orders, bots and
+// RFQ quotes of an account at once, over WebSocket with a REST fallback,
+// and optionally closes its positions. See [killswitch.Run].
+//
+//	report, err := thalex.KillSwitch(ctx, killswitch.Options{
+//	    WS: wsClient, REST: restClient, Flatten: true,
+//	})
+func KillSwitch(ctx context.Context, opts killswitch.Options) (*killswitch.Report, error) {
+	return killswitch.Run(ctx, opts)
+}
@@ -0,0 +1,383 @@
+// Package killswitch stops all trading activity of an account in one call.
+//
+// Run cancels every order, mass quote, conditional order, bot and RFQ
+// quote concurrently, and can then close the open positions with
+// reduce-only market orders. Each step goes to a WebSocket client first
+// and falls back to a REST client, retrying transient failures. The
+// returned Report lists what was cancelled and what failed.
+package killswitch
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/amiwrpremium/go-thalex/apierr"
+	"github.com/amiwrpremium/go-thalex/enums"
+	"github.com/amiwrpremium/go-thalex/internal/pretrade"
+	"github.com/amiwrpremium/go-thalex/types"
+)
+
+// DefaultAttempts is the number of times a step is tried on each client.
+const DefaultAttempts = 3
+
+// DefaultBackoff is the delay before the first retry. It doubles with
+// every further retry.
+const DefaultBackoff = 100 * time.Millisecond
+
+// ErrUnsupported reports a step that none of the clients can perform, such
+// as cancelling mass quotes without a WebSocket client.
+var ErrUnsupported = errors.New("killswitch: no client supports this step")
+
+// Client is the part of a client the kill switch uses. Both ws.Client and
+// rest.Client implement it.
+type Client interface {
+	CancelAll(ctx context.Context) (int, error)
+	CancelAllConditionalOrders(ctx context.Context) (int, error)
+	CancelAllBots(ctx context.Context) (int, error)
+	MMRfqQuotes(ctx context.Context) ([]types.RfqOrder, error)
+	MMRfqDeleteQuote(ctx context.Context, params *types.RfqQuoteDeleteParams) error
+	Portfolio(ctx context.Context) ([]types.PortfolioEntry, error)
+	Insert(ctx context.Context, params *types.InsertOrderParams) (types.OrderStatus, error)
+}
+
+// MassQuoteCanceller is implemented by clients that can cancel mass
+// quotes. ws.Client does; mass quotes are not available over REST.
+type MassQuoteCanceller interface {
+	CancelMassQuote(ctx context.Context) error
+}
+
+// Options configures Run. At least one of WS and REST must be set.
+type Options struct {
+	// WS is tried first for every step.
+	WS Client
+	// REST is used when WS is nil or a step fails on it.
+	REST Client
+	// Flatten closes every open position with a reduce-only market order
+	// once everything else is cancelled.
+	Flatten bool
+	// Label is set on the orders that flatten positions.
+	Label string
+	// Attempts is the number of times a step is tried on each client
+	// while it fails transiently. The default is DefaultAttempts.
+	Attempts int
+	// Backoff is the delay before the first retry. The default is
+	// DefaultBackoff.
+	Backoff time.Duration
+}
+
+// Step names a part of the kill switch.
+type Step string
+
+const (
+	StepOrders            Step = "orders"
+	StepMassQuotes        Step = "mass_quotes"
+	StepConditionalOrders Step = "conditional_orders"
+	StepBots              Step = "bots"
+	StepRfqQuotes         Step = "rfq_quotes"
+	StepFlatten           Step = "flatten"
+)
+
+// steps lists the steps in report order.
+var steps = []Step{StepOrders, StepMassQuotes, StepConditionalOrders, StepBots, StepRfqQuotes, StepFlatten}
+
+// Via names the client that performed a step.
+type Via string
+
+const (
+	ViaWS   Via = "ws"
+	ViaREST Via = "rest"
+)
+
+// Result is the outcome of one step, or of one RFQ quote deletion or
+// position flattening.
+type Result struct {
+	Step Step
+	// Target is the order ID of a deleted RFQ quote or the instrument of
+	// a flattened position. It is empty for the cancel-all steps and when
+	// listing quotes or positions failed.
+	Target string
+	// Count is the number of items cancelled: what a cancel-all call
+	// reported, or 1 for a deleted RFQ quote.
+	Count int
+	// Order is the order that flattened a position.
+	Order *types.OrderStatus
+	// Via is the client that completed the step, or that failed last.
+	Via Via
+	// Attempts is the number of calls made, over both clients.
+	Attempts int
+	Err      error
+}
+
+// Report lists the outcome of every step.
+type Report struct {
+	// Results are ordered by step, then by target.
+	Results []Result
+}
+
+// Failures returns the results with an error.
+func (r *Report) Failures() []Result {
+	var out []Result
+	for _, res := range r.Results {
+		if res.Err != nil {
+			out = append(out, res)
+		}
+	}
+	return out
+}
+
+// Cancelled returns the number of items a step cancelled.
+func (r *Report) Cancelled(step Step) int {
+	n := 0
+	for _, res := range r.Results {
+		if res.Step == step && res.Err == nil {
+			n += res.Count
+		}
+	}
+	return n
+}
+
+// Run cancels all orders, mass quotes, conditional orders, bots and RFQ
+// quotes concurrently and, with Options.Flatten, then closes the open
+// positions. Every step runs even if others fail. The returned error joins
+// the failures of the report, which is returned either way.
+//
+// A cancel that fails transiently is retried, and one that still fails is
+// sent to the REST client. A flattening order is only retried, or sent
+// again over REST, when it certainly had no effect, so a position is never
+// closed twice; being reduce-only, a duplicate could not reverse it anyway.
+// Flattening orders bypass the clients' pre-trade checks.
+func Run(ctx context.Context, opts Options) (*Report, error) {
+	if opts.WS == nil && opts.REST == nil {
+		return nil, errors.New("killswitch: no client")
+	}
+	if opts.Attempts <= 0 {
+		opts.Attempts = DefaultAttempts
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = DefaultBackoff
+	}
+	r := &runner{opts: opts, report: &Report{}}
+
+	var wg sync.WaitGroup
+	for _, fn := range []func(context.Context){r.cancelOrders, r.cancelMassQuotes, r.cancelConditionals, r.cancelBots, r.deleteRfqQuotes} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fn(ctx)
+		}()
+	}
+	wg.Wait()
+	if opts.Flatten {
+		r.flatten(ctx)
+	}
+
+	slices.SortStableFunc(r.report.Results, func(a, b Result) int {
+		return cmp.Or(
+			cmp.Compare(slices.Index(steps, a.Step), slices.Index(steps, b.Step)),
+			cmp.Compare(a.Target, b.Target),
+		)
+	})
+	var errs []error
+	for _, res := range r.report.Failures() {
+		target := ""
+		if res.Target != "" {
+			target = " " + res.Target
+		}
+		errs = append(errs, fmt.Errorf("killswitch: %s%s: %w", res.Step, target, res.Err))
+	}
+	return r.report, errors.Join(errs...)
+}
+
+type runner struct {
+	opts   Options
+	mu     sync.Mutex
+	report *Report
+}
+
+func (r *runner) add(res Result) {
+	r.mu.Lock()
+	r.report.Results = append(r.report.Results, res)
+	r.mu.Unlock()
+}
+
+func (r *runner) cancelOrders(ctx context.Context) {
+	r.cancelAll(ctx, StepOrders, Client.CancelAll)
+}
+
+func (r *runner) cancelConditionals(ctx context.Context) {
+	r.cancelAll(ctx, StepConditionalOrders, Client.CancelAllConditionalOrders)
+}
+
+func (r *runner) cancelBots(ctx context.Context) {
+	r.cancelAll(ctx, StepBots, Client.CancelAllBots)
+}
+
+// cancelAll runs a cancel-all call of the clients.
+func (r *runner) cancelAll(ctx context.Context, step Step, call func(Client, context.Context) (int, error)) {
+	res := Result{Step: step}
+	res.Err = r.each(ctx, &res, r.clients(), apierr.IsTransient, true, func(c Client) error {
+		n, err := call(c, ctx)
+		res.Count = n
+		return err
+	})
+	r.add(res)
+}
+
+func (r *runner) cancelMassQuotes(ctx context.Context) {
+	res := Result{Step: StepMassQuotes}
+	var clients []target
+	for _, c := range r.clients() {
+		if _, ok := c.client.(MassQuoteCanceller); ok {
+			clients = append(clients, c)
+		}
+	}
+	res.Err = r.each(ctx, &res, clients, apierr.IsTransient, true, func(c Client) error {
+		return c.(MassQuoteCanceller).CancelMassQuote(ctx)
+	})
+	r.add(res)
+}
+
+// deleteRfqQuotes lists the open RFQ quotes and deletes each of them.
+func (r *runner) deleteRfqQuotes(ctx context.Context) {
+	var quotes []types.RfqOrder
+	list := Result{Step: StepRfqQuotes}
+	list.Err = r.each(ctx, &list, r.clients(), apierr.IsTransient, true, func(c Client) error {
+		var err error
+		quotes, err = c.MMRfqQuotes(ctx)
+		return err
+	})
+	if list.Err != nil {
+		r.add(list)
+		return
+	}
+	var wg sync.WaitGroup
+	for _, q := range quotes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res := Result{Step: StepRfqQuotes, Target: q.OrderID}
+			res.Err = r.each(ctx, &res, r.clients(), apierr.IsTransient, true, func(c Client) error {
+				return c.MMRfqDeleteQuote(ctx, &types.RfqQuoteDeleteParams{OrderID: q.OrderID})
+			})
+			if res.Err == nil {
+				res.Count = 1
+			}
+			r.add(res)
+		}()
+	}
+	wg.Wait()
+}
+
+// flatten closes every open position with a reduce-only market order.
+func (r *runner) flatten(ctx context.Context) {
+	var positions []types.PortfolioEntry
+	list := Result{Step: StepFlatten}
+	list.Err = r.each(ctx, &list, r.clients(), apierr.IsTransient, true, func(c Client) error {
+		var err error
+		positions, err = c.Portfolio(ctx)
+		return err
+	})
+	if list.Err != nil {
+		r.add(list)
+		return
+	}
+	var wg sync.WaitGroup
+	for _, p := range positions {
+		if p.Position == 0 {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			dir := enums.DirectionSell
+			if p.Position < 0 {
+				dir = enums.DirectionBuy
+			}
+			params := types.NewInsertOrderParams(dir, p.InstrumentName, math.Abs(p.Position)).
+				WithOrderType(enums.OrderTypeMarket).
+				WithReduceOnly(true)
+			if r.opts.Label != "" {
+				params.WithLabel(r.opts.Label)
+			}
+			res := Result{Step: StepFlatten, Target: p.InstrumentName}
+			// The pre-trade check is skipped: a tripped dead man's switch
+			// or a risk limit must not keep a position open.
+			ictx := pretrade.WithReduceOnlyBypass(ctx)
+			res.Err = r.each(ctx, &res, r.clients(), apierr.IsRetryable, false, func(c Client) error {
+				o, err := c.Insert(ictx, params)
+				if err == nil {
+					res.Order = &o
+				}
+				return err
+			})
+			r.add(res)
+		}()
+	}
+	wg.Wait()
+}
+
+// target is a client with the name reported in results.
+type target struct {
+	client Client
+	via    Via
+}
+
+// clients returns the configured clients, WebSocket first.
+func (r *runner) clients() []target {
+	var out []target
+	if r.opts.WS != nil {
+		out = append(out, target{r.opts.WS, ViaWS})
+	}
+	if r.opts.REST != nil {
+		out = append(out, target{r.opts.REST, ViaREST})
+	}
+	return out
+}
+
+// each calls fn with each client in turn until one succeeds, retrying
+// each while retry accepts the error. With fallback false, the next client
+// is only tried after an error retry accepts. Without clients it returns
+// ErrUnsupported.
+func (r *runner) each(ctx context.Context, res *Result, clients []target, retry func(error) bool, fallback bool, fn func(Client) error) error {
+	if len(clients) == 0 {
+		return ErrUnsupported
+	}
+	var err error
+	for _, c := range clients {
+		if err != nil && !fallback && !retry(err) {
+			break
+		}
+		res.Via = c.via
+		if err = r.attempt(ctx, res, retry, func() error { return fn(c.client) }); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// attempt calls fn up to Options.Attempts times while it fails with an
+// error retry accepts, backing off between calls.
+func (r *runner) attempt(ctx context.Context, res *Result, retry func(error) bool, fn func() error) error {
+	delay := r.opts.Backoff
+	for i := 1; ; i++ {
+		res.Attempts++
+		err := fn()
+		if err == nil || i >= r.opts.Attempts || !retry(err) {
+			return err
+		}
+		t := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return err
+		case <-t.C:
+		}
+		delay *= 2
+	}
+}
@@ -0,0 +1,333 @@
+package killswitch_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/amiwrpremium/go-thalex/apierr"
+	"github.com/amiwrpremium/go-thalex/config"
+	"github.com/amiwrpremium/go-thalex/deadman"
+	"github.com/amiwrpremium/go-thalex/enums"
+	"github.com/amiwrpremium/go-thalex/killswitch"
+	"github.com/amiwrpremium/go-thalex/rest"
+	"github.com/amiwrpremium/go-thalex/types"
+	"github.com/amiwrpremium/go-thalex/ws"
+)
+
+var (
+	_ killswitch.Client             = (*ws.Client)(nil)
+	_ killswitch.Client             = (*rest.Client)(nil)
+	_ killswitch.MassQuoteCanceller = (*ws.Client)(nil)
+)
+
+// fakeClient is a REST-like client. Each method fails with the queued
+// errors, in order, before succeeding.
+type fakeClient struct {
+	mu        sync.Mutex
+	errs      map[string][]error
+	calls     map[string]int
+	quotes    []types.RfqOrder
+	positions []types.PortfolioEntry
+	deleted   []string
+	inserted  []types.InsertOrderParams
+}
+
+func newFake() *fakeClient {
+	return &fakeClient{errs: make(map[string][]error), calls: make(map[string]int)}
+}
+
+func (f *fakeClient) fail(method string, errs ...error) {
+	f.errs[method] = append(f.errs[method], errs...)
+}
+
+func (f *fakeClient) call(method string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls[method]++
+	if q := f.errs[method]; len(q) > 0 {
+		f.errs[method] = q[1:]
+		return q[0]
+	}
+	return nil
+}
+
+func (f *fakeClient) CancelAll(context.Context) (int, error) {
+	return 3, f.call("CancelAll")
+}
+
+func (f *fakeClient) CancelAllConditionalOrders(context.Context) (int, error) {
+	return 2, f.call("CancelAllConditionalOrders")
+}
+
+func (f *fakeClient) CancelAllBots(context.Context) (int, error) {
+	return 1, f.call("CancelAllBots")
+}
+
+func (f *fakeClient) MMRfqQuotes(context.Context) ([]types.RfqOrder, error) {
+	return f.quotes, f.call("MMRfqQuotes")
+}
+
+func (f *fakeClient) MMRfqDeleteQuote(_ context.Context, p *types.RfqQuoteDeleteParams) error {
+	if err := f.call("MMRfqDeleteQuote:" + p.OrderID); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	f.deleted = append(f.deleted, p.OrderID)
+	f.mu.Unlock()
+	return nil
+}
+
+func (f *fakeClient) Portfolio(context.Context) ([]types.PortfolioEntry, error) {
+	return f.positions, f.call("Portfolio")
+}
+
+func (f *fakeClient) Insert(_ context.Context, p *types.InsertOrderParams) (types.OrderStatus, error) {
+	if err := f.call("Insert:" + p.InstrumentName); err != nil {
+		return types.OrderStatus{}, err
+	}
+	f.mu.Lock()
+	f.inserted = append(f.inserted, *p)
+	f.mu.Unlock()
+	return types.OrderStatus{OrderID: "flat-" + p.InstrumentName, InstrumentName: p.InstrumentName, Status: enums.OrderStatusFilled}, nil
+}
+
+// fakeWS is a fakeClient that can also cancel mass quotes.
+type fakeWS struct{ *fakeClient }
+
+func (f fakeWS) CancelMassQuote(context.Context) error {
+	return f.call("CancelMassQuote")
+}
+
+var (
+	errTransient = &apierr.ConnectionError{Message: "connection lost"}
+	errTimeout   = &apierr.TimeoutError{Message: "timeout"}
+	errRejected  = &apierr.APIError{Code: 1, Message: "no position"}
+)
+
+func run(t *testing.T, opts killswitch.Options) (*killswitch.Report, error) {
+	t.Helper()
+	opts.Backoff = time.Millisecond
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return killswitch.Run(ctx, opts)
+}
+
+func TestRun_WS(t *testing.T) {
+	w := newFake()
+	w.quotes = []types.RfqOrder{{OrderID: "q2"}, {OrderID: "q1"}}
+	w.positions = []types.PortfolioEntry{
+		{InstrumentName: "BTC-PERPETUAL", Position: 0.5},
+		{InstrumentName: "ETH-PERPETUAL", Position: -2},
+		{InstrumentName: "SOL-PERPETUAL"},
+	}
+	report, err := run(t, killswitch.Options{WS: fakeWS{w}, REST: newFake(), Flatten: true, Label: "kill"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for step, want := range map[killswitch.Step]int{
+		killswitch.StepOrders:            3,
+		killswitch.StepConditionalOrders: 2,
+		killswitch.StepBots:              1,
+		killswitch.StepRfqQuotes:         2,
+	} {
+		if got := report.Cancelled(step); got != want {
+			t.Errorf("Cancelled(%s) = %d, want %d", step, got, want)
+		}
+	}
+	var steps []string
+	for _, r := range report.Results {
+		steps = append(steps, string(r.Step)+":"+r.Target)
+		if r.Via != killswitch.ViaWS || r.Attempts != 1 {
+			t.Errorf("%s %s: via %s after %d attempts", r.Step, r.Target, r.Via, r.Attempts)
+		}
+	}
+	want := []string{"orders:", "mass_quotes:", "conditional_orders:", "bots:", "rfq_quotes:q1", "rfq_quotes:q2",
+		"flatten:BTC-PERPETUAL", "flatten:ETH-PERPETUAL"}
+	if len(steps) != len(want) {
+		t.Fatalf("results %v, want %v", steps, want)
+	}
+	for i := range want {
+		if steps[i] != want[i] {
+			t.Fatalf("results %v, want %v", steps, want)
+		}
+	}
+
+	if len(w.inserted) != 2 {
+		t.Fatalf("inserted %+v", w.inserted)
+	}
+	for _, p := range w.inserted {
+		wantDir, wantAmount := enums.DirectionSell, 0.5
+		if p.InstrumentName == "ETH-PERPETUAL" {
+			wantDir, wantAmount = enums.DirectionBuy, 2
+		}
+		if p.Direction != wantDir || p.Amount != wantAmount || p.OrderType != enums.OrderTypeMarket ||
+			p.ReduceOnly == nil || !*p.ReduceOnly || p.Label != "kill" {
+			t.Errorf("flatten order %+v", p)
+		}
+	}
+	if o := report.Results[6].Order; o == nil || o.OrderID != "flat-BTC-PERPETUAL" {
+		t.Errorf("flatten result order = %+v", o)
+	}
+}
+
+func TestRun_RetryAndFallback(t *testing.T) {
+	w, r := newFake(), newFake()
+	w.fail("CancelAll", errTransient)                                 // retried on WS
+	w.fail("CancelAllBots", errTransient, errTransient, errTransient) // falls back to REST
+	w.fail("CancelAllConditionalOrders", errRejected)                 // not retried, but sent to REST
+	w.fail("MMRfqQuotes", errTransient, errTransient, errTransient)
+	r.quotes = []types.RfqOrder{{OrderID: "q1"}}
+	w.fail("MMRfqDeleteQuote:q1", errRejected)
+	r.fail("MMRfqDeleteQuote:q1", errRejected)
+
+	report, err := run(t, killswitch.Options{WS: fakeWS{w}, REST: r})
+	if err == nil {
+		t.Fatal("Run succeeded, want the RFQ quote failure")
+	}
+	if !errors.Is(err, errRejected) || len(report.Failures()) != 1 {
+		t.Fatalf("err = %v, failures %+v", err, report.Failures())
+	}
+	if f := report.Failures()[0]; f.Step != killswitch.StepRfqQuotes || f.Target != "q1" || f.Via != killswitch.ViaREST || f.Attempts != 2 {
+		t.Errorf("failure = %+v", f)
+	}
+	byStep := make(map[killswitch.Step]killswitch.Result)
+	for _, res := range report.Results {
+		byStep[res.Step] = res
+	}
+	if res := byStep[killswitch.StepOrders]; res.Via != killswitch.ViaWS || res.Attempts != 2 {
+		t.Errorf("orders = %+v", res)
+	}
+	if res := byStep[killswitch.StepBots]; res.Via != killswitch.ViaREST || res.Attempts != 4 || res.Err != nil {
+		t.Errorf("bots = %+v", res)
+	}
+	if res := byStep[killswitch.StepConditionalOrders]; res.Via != killswitch.ViaREST || res.Attempts != 2 {
+		t.Errorf("conditional orders = %+v", res)
+	}
+	if r.calls["MMRfqQuotes"] != 1 {
+		t.Errorf("RFQ quotes listed %d times over REST", r.calls["MMRfqQuotes"])
+	}
+}
+
+func TestRun_RESTOnly(t *testing.T) {
+	r := newFake()
+	report, err := run(t, killswitch.Options{REST: r})
+	if !errors.Is(err, killswitch.ErrUnsupported) {
+		t.Fatalf("err = %v, want ErrUnsupported for mass quotes", err)
+	}
+	if f := report.Failures(); len(f) != 1 || f[0].Step != killswitch.StepMassQuotes {
+		t.Errorf("failures = %+v", f)
+	}
+	if report.Cancelled(killswitch.StepOrders) != 3 {
+		t.Errorf("orders not cancelled over REST: %+v", report.Results)
+	}
+}
+
+func TestRun_FlattenRetries(t *testing.T) {
+	w, r := newFake(), newFake()
+	w.positions = []types.PortfolioEntry{
+		{InstrumentName: "BTC-PERPETUAL", Position: 1},
+		{InstrumentName: "ETH-PERPETUAL", Position: 1},
+		{InstrumentName: "SOL-PERPETUAL", Position: 1},
+	}
+	// A timeout may have placed the order: it is neither retried nor sent
+	// over REST.
+	w.fail("Insert:BTC-PERPETUAL", errTimeout)
+	// Rate limiting certainly had no effect.
+	w.fail("Insert:ETH-PERPETUAL", &apierr.APIError{HTTPStatus: http.StatusTooManyRequests, Message: "slow down"})
+	// A connection that failed before sending falls back to REST.
+	w.fail("Insert:SOL-PERPETUAL", errTransient, errTransient, errTransient)
+
+	report, err := run(t, killswitch.Options{WS: fakeWS{w}, REST: r, Flatten: true})
+	if !errors.Is(err, errTimeout) {
+		t.Fatalf("err = %v, want the timeout", err)
+	}
+	results := make(map[string]killswitch.Result)
+	for _, res := range report.Results {
+		if res.Step == killswitch.StepFlatten {
+			results[res.Target] = res
+		}
+	}
+	if res := results["BTC-PERPETUAL"]; res.Err == nil || res.Attempts != 1 || r.calls["Insert:BTC-PERPETUAL"] != 0 {
+		t.Errorf("BTC = %+v", res)
+	}
+	if res := results["ETH-PERPETUAL"]; res.Err != nil || res.Attempts != 2 || res.Via != killswitch.ViaWS {
+		t.Errorf("ETH = %+v", res)
+	}
+	if res := results["SOL-PERPETUAL"]; res.Err != nil || res.Attempts != 4 || res.Via != killswitch.ViaREST {
+		t.Errorf("SOL = %+v", res)
+	}
+}
+
+func TestRun_FlattenBypassesPreTradeCheck(t *testing.T) {
+	var mu sync.Mutex
+	var inserted []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var result any
+		switch r.URL.Path {
+		case "/private/cancel_all", "/private/cancel_all_conditional_orders", "/private/cancel_all_bots":
+			result = map[string]int{"n_cancelled": 0}
+		case "/private/mm_rfq_quotes":
+			result = []types.RfqOrder{}
+		case "/private/portfolio":
+			result = []types.PortfolioEntry{{InstrumentName: "BTC-PERPETUAL", Position: 1}}
+		case "/private/insert":
+			var p types.InsertOrderParams
+			_ = json.NewDecoder(r.Body).Decode(&p)
+			mu.Lock()
+			inserted = append(inserted, p.InstrumentName)
+			mu.Unlock()
+			result = types.OrderStatus{OrderID: "flat", InstrumentName: p.InstrumentName, Status: enums.OrderStatusFilled}
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"result": result})
+	}))
+	defer srv.Close()
+
+	// A latched dead man's switch that has tripped refuses every order.
+	now := time.Unix(1000, 0)
+	sw := deadman.NewSwitch(nopDeadmanClient{}, time.Second, deadman.WithLatch(),
+		deadman.WithClock(func() time.Time { return now }))
+	now = now.Add(time.Minute)
+	if !sw.Poll(context.Background()) {
+		t.Fatal("switch did not trip")
+	}
+	client := rest.NewClient(
+		config.WithEndpoint(config.Endpoint{BaseURL: srv.URL}),
+		config.WithMaxRetries(0),
+		config.WithPreTradeCheck(sw.Check),
+	)
+	if _, err := client.Insert(context.Background(), types.NewBuyOrderParams("BTC-PERPETUAL", 1)); !errors.Is(err, deadman.ErrTripped) {
+		t.Fatalf("Insert err = %v, want ErrTripped", err)
+	}
+
+	report, _ := run(t, killswitch.Options{REST: client, Flatten: true})
+	for _, res := range report.Results {
+		if res.Step == killswitch.StepFlatten && (res.Err != nil || res.Order == nil) {
+			t.Errorf("flatten = %+v", res)
+		}
+	}
+	if len(inserted) != 1 || inserted[0] != "BTC-PERPETUAL" {
+		t.Errorf("inserted %v", inserted)
+	}
+}
+
+// nopDeadmanClient cancels nothing.
+type nopDeadmanClient struct{}
+
+func (nopDeadmanClient) CancelSession(context.Context) (int, error) { return 0, nil }
+func (nopDeadmanClient) CancelAll(context.Context) (int, error)     { return 0, nil }
+func (nopDeadmanClient) CancelMassQuote(context.Context) error      { return nil }
+
+func TestRun_NoClient(t *testing.T) {
+	if _, err := killswitch.Run(context.Background(), killswitch.Options{}); err == nil {
+		t.Error("Run without clients succeeded")
+	}
+}
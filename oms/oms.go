@@ -0,0 +1,349 @@
+// Package oms tracks the lifecycle of orders placed through a trading
+// client such as ws.Client.
+//
+// A Manager assigns every order a unique client order ID, follows it
+// through insert, amend and cancel responses and the account.orders or
+// session.orders streams, and collects its fills. Updates may arrive out of
+// order; the Manager never moves an order out of a final state or to a
+// smaller filled amount.
+package oms
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/amiwrpremium/go-thalex/apierr"
+	"github.com/amiwrpremium/go-thalex/enums"
+	"github.com/amiwrpremium/go-thalex/types"
+)
+
+var (
+	// ErrUnknownOrder reports a client order ID the Manager does not track.
+	ErrUnknownOrder = errors.New("oms: unknown client order ID")
+	// ErrDuplicateClientID reports an insert reusing a tracked client
+	// order ID.
+	ErrDuplicateClientID = errors.New("oms: client order ID already in use")
+	// ErrOrderClosed reports an amend or cancel of an order in a final
+	// state.
+	ErrOrderClosed = errors.New("oms: order is closed")
+	// ErrNotFilled is returned by Order.WaitFilled for an order that was
+	// cancelled before it was filled.
+	ErrNotFilled = errors.New("oms: order closed without being filled")
+)
+
+// Trader sends order requests. ws.Client satisfies it.
+type Trader interface {
+	Insert(ctx context.Context, params *types.InsertOrderParams) (types.OrderStatus, error)
+	Amend(ctx context.Context, params *types.AmendOrderParams) (types.OrderStatus, error)
+	Cancel(ctx context.Context, params *types.CancelOrderParams) (types.OrderStatus, error)
+}
+
+// Subscriber delivers order stream notifications. ws.Client satisfies it.
+type Subscriber interface {
+	OnOrders(fn func([]types.OrderStatus))
+	OnSessionOrders(fn func([]types.OrderStatus))
+	SubscribePrivate(ctx context.Context, channels ...string) error
+}
+
+// Option configures a Manager.
+type Option func(*Manager)
+
+// WithClientIDs sets the generator of client order IDs. It must not repeat
+// IDs still in use on the account. The default counts up from the current
+// time in nanoseconds, so IDs do not repeat across restarts.
+func WithClientIDs(next func() uint64) Option {
+	return func(m *Manager) { m.nextID = next }
+}
+
+// WithEventHandler registers a callback for the events of every order.
+// Callbacks for one order are never called concurrently.
+func WithEventHandler(fn func(Event)) Option {
+	return func(m *Manager) { m.onEvent = fn }
+}
+
+// Filter selects orders for Open. Zero fields match any order.
+type Filter struct {
+	Instrument string
+	Direction  enums.Direction
+	Label      string
+}
+
+func (f Filter) match(s types.OrderStatus) bool {
+	return (f.Instrument == "" || s.InstrumentName == f.Instrument) &&
+		(f.Direction == "" || s.Direction == f.Direction) &&
+		(f.Label == "" || s.Label == f.Label)
+}
+
+// Manager tracks the orders it places. It is safe for concurrent use.
+type Manager struct {
+	trader  Trader
+	nextID  func() uint64
+	onEvent func(Event)
+
+	mu        sync.Mutex
+	orders    map[uint64]*Order
+	byOrderID map[string]*Order
+}
+
+// NewManager returns a Manager that places orders through trader.
+func NewManager(trader Trader, opts ...Option) *Manager {
+	m := &Manager{
+		trader:    trader,
+		orders:    make(map[uint64]*Order),
+		byOrderID: make(map[string]*Order),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	if m.nextID == nil {
+		var n atomic.Uint64
+		n.Store(uint64(time.Now().UnixNano()))
+		m.nextID = func() uint64 { return n.Add(1) }
+	}
+	return m
+}
+
+// Subscribe feeds the Manager from the order channels of sub:
+// types.ChannelAccountOrders, types.ChannelSessionOrders or both. Without
+// channels it subscribes to types.ChannelAccountOrders.
+//
+// The Manager registers the channels' handlers on sub, replacing any
+// registered before; use WithEventHandler to observe the orders instead.
+func (m *Manager) Subscribe(ctx context.Context, sub Subscriber, channels ...string) error {
+	if len(channels) == 0 {
+		channels = []string{types.ChannelAccountOrders}
+	}
+	for _, ch := range channels {
+		switch ch {
+		case types.ChannelAccountOrders:
+			sub.OnOrders(m.Apply)
+		case types.ChannelSessionOrders:
+			sub.OnSessionOrders(m.Apply)
+		default:
+			return fmt.Errorf("oms: %q is not an order channel", ch)
+		}
+	}
+	if err := sub.SubscribePrivate(ctx, channels...); err != nil {
+		return fmt.Errorf("oms: subscribe: %w", err)
+	}
+	return nil
+}
+
+// Apply merges order updates, typically an order stream notification, into
+// the tracked orders. Updates of orders the Manager does not track are
+// ignored.
+func (m *Manager) Apply(updates []types.OrderStatus) {
+	for _, s := range updates {
+		m.mu.Lock()
+		var o *Order
+		if s.ClientOrderID != nil {
+			o = m.orders[*s.ClientOrderID]
+		}
+		if o == nil && s.OrderID != "" {
+			o = m.byOrderID[s.OrderID]
+		}
+		if o != nil && s.OrderID != "" {
+			m.byOrderID[s.OrderID] = o
+		}
+		m.mu.Unlock()
+		if o != nil {
+			o.apply(s)
+		}
+	}
+}
+
+// applyTo merges the response to a request about o.
+func (m *Manager) applyTo(o *Order, s types.OrderStatus) {
+	if s.OrderID != "" {
+		m.mu.Lock()
+		m.byOrderID[s.OrderID] = o
+		m.mu.Unlock()
+	}
+	o.apply(s)
+}
+
+// Insert places an order with a new client order ID, or with the one in
+// params if set, and tracks it. The returned Order is non-nil unless the
+// ID is already in use, including when the insert fails: a refused order is
+// StateRejected, and one whose outcome is unknown, after a timeout for
+// example, is StateUnknown until an order stream update arrives.
+func (m *Manager) Insert(ctx context.Context, params *types.InsertOrderParams, opts ...OrderOption) (*Order, error) {
+	p := *params
+	if p.ClientOrderID == nil {
+		id := m.nextID()
+		p.ClientOrderID = &id
+	}
+	id := *p.ClientOrderID
+
+	o := newOrder(m, id, types.OrderStatus{
+		OrderType:       p.OrderType,
+		TimeInForce:     p.TimeInForce,
+		InstrumentName:  p.InstrumentName,
+		Direction:       p.Direction,
+		Price:           p.Price,
+		Amount:          p.Amount,
+		RemainingAmount: p.Amount,
+		Label:           p.Label,
+		ClientOrderID:   &id,
+	})
+	for _, opt := range opts {
+		opt(o)
+	}
+	m.mu.Lock()
+	if _, ok := m.orders[id]; ok {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("%w: %d", ErrDuplicateClientID, id)
+	}
+	m.orders[id] = o
+	m.mu.Unlock()
+
+	status, err := m.trader.Insert(ctx, &p)
+	if err != nil {
+		if placementUnknown(err) {
+			o.unknown(err)
+		} else {
+			o.reject(err)
+		}
+		return o, fmt.Errorf("oms: insert %d: %w", id, err)
+	}
+	m.applyTo(o, status)
+	return o, nil
+}
+
+// Track starts tracking an order placed earlier, for example by the process
+// before a restart. The status must carry the order's client order ID.
+func (m *Manager) Track(status types.OrderStatus, opts ...OrderOption) (*Order, error) {
+	if status.ClientOrderID == nil {
+		return nil, fmt.Errorf("oms: track %s: order has no client order ID", status.OrderID)
+	}
+	id := *status.ClientOrderID
+	o := newOrder(m, id, types.OrderStatus{})
+	for _, opt := range opts {
+		opt(o)
+	}
+	m.mu.Lock()
+	if _, ok := m.orders[id]; ok {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("%w: %d", ErrDuplicateClientID, id)
+	}
+	m.orders[id] = o
+	m.mu.Unlock()
+
+	m.applyTo(o, status)
+	return o, nil
+}
+
+// placementUnknown reports whether an insert that failed with err may still
+// have been placed.
+func placementUnknown(err error) bool {
+	if apierr.IsRetryable(err) {
+		return false
+	}
+	var apiErr *apierr.APIError
+	if errors.As(err, &apiErr) {
+		return apierr.IsTransient(err)
+	}
+	var connErr *apierr.ConnectionError
+	var timeoutErr *apierr.TimeoutError
+	return errors.As(err, &connErr) || errors.As(err, &timeoutErr) ||
+		errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// Amend changes the price and amount of a tracked order.
+func (m *Manager) Amend(ctx context.Context, clientID uint64, price, amount float64) error {
+	o, err := m.live(clientID)
+	if err != nil {
+		return err
+	}
+	s := o.Status()
+	params := types.NewAmendByClientOrderID(clientID, price, amount)
+	params.InstrumentName, params.Direction = s.InstrumentName, s.Direction
+	status, err := m.trader.Amend(ctx, params)
+	if err != nil {
+		return fmt.Errorf("oms: amend %d: %w", clientID, err)
+	}
+	o.applyAmend(status)
+	return nil
+}
+
+// Cancel cancels a tracked order.
+func (m *Manager) Cancel(ctx context.Context, clientID uint64) error {
+	o, err := m.live(clientID)
+	if err != nil {
+		return err
+	}
+	status, err := m.trader.Cancel(ctx, types.CancelByClientOrderID(clientID))
+	if err != nil {
+		return fmt.Errorf("oms: cancel %d: %w", clientID, err)
+	}
+	m.applyTo(o, status)
+	return nil
+}
+
+// live returns a tracked order that is not final.
+func (m *Manager) live(clientID uint64) (*Order, error) {
+	o, ok := m.Get(clientID)
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownOrder, clientID)
+	}
+	if o.State().IsFinal() {
+		return nil, fmt.Errorf("%w: %d", ErrOrderClosed, clientID)
+	}
+	return o, nil
+}
+
+// Get returns the tracked order with a client order ID.
+func (m *Manager) Get(clientID uint64) (*Order, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	o, ok := m.orders[clientID]
+	return o, ok
+}
+
+// Open returns the tracked orders that are not final and match filter,
+// ordered by client order ID.
+func (m *Manager) Open(filter Filter) []*Order {
+	m.mu.Lock()
+	orders := make([]*Order, 0, len(m.orders))
+	for _, o := range m.orders {
+		orders = append(orders, o)
+	}
+	m.mu.Unlock()
+
+	var out []*Order
+	for _, o := range orders {
+		o.mu.Lock()
+		ok := !o.state.IsFinal() && filter.match(o.status)
+		o.mu.Unlock()
+		if ok {
+			out = append(out, o)
+		}
+	}
+	slices.SortFunc(out, func(a, b *Order) int { return cmp.Compare(a.clientID, b.clientID) })
+	return out
+}
+
+// Prune stops tracking orders in a final state and returns how many were
+// removed.
+func (m *Manager) Prune() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := 0
+	for id, o := range m.orders {
+		if !o.State().IsFinal() {
+			continue
+		}
+		delete(m.orders, id)
+		if s := o.Status(); s.OrderID != "" {
+			delete(m.byOrderID, s.OrderID)
+		}
+		n++
+	}
+	return n
+}
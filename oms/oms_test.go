@@ -0,0 +1,398 @@
+package oms_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/amiwrpremium/go-thalex/apierr"
+	"github.com/amiwrpremium/go-thalex/enums"
+	"github.com/amiwrpremium/go-thalex/oms"
+	"github.com/amiwrpremium/go-thalex/types"
+	"github.com/amiwrpremium/go-thalex/ws"
+)
+
+var (
+	_ oms.Trader     = (*ws.Client)(nil)
+	_ oms.Subscriber = (*ws.Client)(nil)
+)
+
+// fakeTrader acknowledges every request as an open order unless err is set.
+type fakeTrader struct {
+	mu      sync.Mutex
+	err     error
+	inserts []types.InsertOrderParams
+	amends  []types.AmendOrderParams
+	cancels []types.CancelOrderParams
+}
+
+func (f *fakeTrader) Insert(_ context.Context, p *types.InsertOrderParams) (types.OrderStatus, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.inserts = append(f.inserts, *p)
+	if f.err != nil {
+		return types.OrderStatus{}, f.err
+	}
+	return types.OrderStatus{
+		OrderID: "o" + strconv.Itoa(len(f.inserts)), InstrumentName: p.InstrumentName, Direction: p.Direction,
+		Price: p.Price, Amount: p.Amount, RemainingAmount: p.Amount, Label: p.Label,
+		ClientOrderID: p.ClientOrderID, Status: enums.OrderStatusOpen,
+	}, nil
+}
+
+func (f *fakeTrader) Amend(_ context.Context, p *types.AmendOrderParams) (types.OrderStatus, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.amends = append(f.amends, *p)
+	if f.err != nil {
+		return types.OrderStatus{}, f.err
+	}
+	return types.OrderStatus{
+		InstrumentName: p.InstrumentName, Direction: p.Direction, Price: &p.Price, Amount: p.Amount,
+		RemainingAmount: p.Amount, ClientOrderID: p.ClientOrderID, Status: enums.OrderStatusOpen,
+	}, nil
+}
+
+func (f *fakeTrader) Cancel(_ context.Context, p *types.CancelOrderParams) (types.OrderStatus, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.cancels = append(f.cancels, *p)
+	if f.err != nil {
+		return types.OrderStatus{}, f.err
+	}
+	return types.OrderStatus{ClientOrderID: p.ClientOrderID, Status: enums.OrderStatusCancelled}, nil
+}
+
+type fakeSubscriber struct {
+	orders, session func([]types.OrderStatus)
+	subscribed      []string
+}
+
+func (s *fakeSubscriber) OnOrders(fn func([]types.OrderStatus))        { s.orders = fn }
+func (s *fakeSubscriber) OnSessionOrders(fn func([]types.OrderStatus)) { s.session = fn }
+func (s *fakeSubscriber) SubscribePrivate(_ context.Context, channels ...string) error {
+	s.subscribed = append(s.subscribed, channels...)
+	return nil
+}
+
+func ids() func() uint64 {
+	n := uint64(100)
+	return func() uint64 { n++; return n }
+}
+
+func limitBuy(price float64) *types.InsertOrderParams {
+	return types.NewBuyOrderParams("BTC-PERPETUAL", 1).WithPrice(price)
+}
+
+func update(clientID uint64, status enums.OrderStatusValue, filled float64, fills ...types.OrderFill) types.OrderStatus {
+	return types.OrderStatus{
+		ClientOrderID: &clientID, InstrumentName: "BTC-PERPETUAL", Direction: enums.DirectionBuy,
+		Amount: 1, FilledAmount: filled, RemainingAmount: 1 - filled, Status: status, Fills: fills,
+	}
+}
+
+func TestManager_InsertAndFill(t *testing.T) {
+	trader := &fakeTrader{}
+	var (
+		mu     sync.Mutex
+		events []oms.EventType
+	)
+	m := oms.NewManager(trader, oms.WithClientIDs(ids()))
+	o, err := m.Insert(context.Background(), limitBuy(50000), oms.OnEvent(func(e oms.Event) {
+		mu.Lock()
+		events = append(events, e.Type)
+		mu.Unlock()
+	}))
+	if err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	if o.ClientID() != 101 || *trader.inserts[0].ClientOrderID != 101 {
+		t.Fatalf("client ID = %d, sent %v", o.ClientID(), trader.inserts[0].ClientOrderID)
+	}
+	if o.State() != oms.StateOpen {
+		t.Errorf("State = %v, want open", o.State())
+	}
+
+	fill1 := types.OrderFill{TradeID: "t1", Price: 50000, Amount: 0.4}
+	fill2 := types.OrderFill{TradeID: "t2", Price: 50000, Amount: 0.6}
+	m.Apply([]types.OrderStatus{update(101, enums.OrderStatusPartiallyFilled, 0.4, fill1)})
+	m.Apply([]types.OrderStatus{update(101, enums.OrderStatusFilled, 1, fill1, fill2)})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	s, err := o.WaitFilled(ctx)
+	if err != nil || s.FilledAmount != 1 {
+		t.Fatalf("WaitFilled = %+v, %v", s, err)
+	}
+	if got := o.Fills(); !reflect.DeepEqual(got, []types.OrderFill{fill1, fill2}) {
+		t.Errorf("Fills = %+v", got)
+	}
+	want := []oms.EventType{oms.EventAccepted, oms.EventFill, oms.EventFill, oms.EventFilled}
+	mu.Lock()
+	defer mu.Unlock()
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("events = %v, want %v", events, want)
+	}
+}
+
+func TestManager_OutOfOrderUpdates(t *testing.T) {
+	var amends int
+	m := oms.NewManager(&fakeTrader{}, oms.WithClientIDs(ids()))
+	o, _ := m.Insert(context.Background(), limitBuy(50000), oms.OnEvent(func(e oms.Event) {
+		if e.Type == oms.EventAmended {
+			amends++
+		}
+	}))
+
+	if err := m.Amend(context.Background(), 101, 50100, 1); err != nil {
+		t.Fatal(err)
+	}
+	// The stream update of the insert arrives after the amend response.
+	price := func(v float64) *float64 { return &v }
+	stale := update(101, enums.OrderStatusOpen, 0)
+	stale.Price = price(50000)
+	m.Apply([]types.OrderStatus{stale})
+	if p := o.Status().Price; p == nil || *p != 50100 {
+		t.Errorf("Price = %v after a stale update, want 50100", p)
+	}
+	amendedUpdate := update(101, enums.OrderStatusOpen, 0)
+	amendedUpdate.Price = price(50100)
+	m.Apply([]types.OrderStatus{amendedUpdate})
+	if amends != 1 {
+		t.Errorf("%d amended events, want 1", amends)
+	}
+
+	fill := types.OrderFill{TradeID: "t1", Amount: 0.5}
+	late := types.OrderFill{TradeID: "t0", Amount: 0.2}
+	m.Apply([]types.OrderStatus{update(101, enums.OrderStatusPartiallyFilled, 0.5, fill)})
+	// An older snapshot arrives late, carrying a fill not seen before.
+	m.Apply([]types.OrderStatus{update(101, enums.OrderStatusPartiallyFilled, 0.2, late)})
+	if got := o.FilledAmount(); got != 0.5 {
+		t.Errorf("FilledAmount = %v after a stale update, want 0.5", got)
+	}
+	if len(o.Fills()) != 2 {
+		t.Errorf("Fills = %+v, want the late fill kept", o.Fills())
+	}
+
+	m.Apply([]types.OrderStatus{update(101, enums.OrderStatusCancelledPartiallyFilled, 0.5)})
+	m.Apply([]types.OrderStatus{update(101, enums.OrderStatusOpen, 0.5)})
+	if o.State() != oms.StateCancelled {
+		t.Errorf("State = %v, want the order to stay cancelled", o.State())
+	}
+	if _, err := o.WaitFilled(context.Background()); !errors.Is(err, oms.ErrNotFilled) {
+		t.Errorf("WaitFilled err = %v, want ErrNotFilled", err)
+	}
+}
+
+func TestManager_StreamBeforeResponse(t *testing.T) {
+	// The stream reports the fill while the insert response is in flight;
+	// the older response must not reopen the order.
+	trader := &streamFirst{fakeTrader: &fakeTrader{}}
+	m := oms.NewManager(trader, oms.WithClientIDs(ids()))
+	trader.m = m
+	o, err := m.Insert(context.Background(), limitBuy(50000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.State() != oms.StateFilled {
+		t.Errorf("State = %v, want filled", o.State())
+	}
+}
+
+// streamFirst delivers a fill on the order stream before answering an
+// insert.
+type streamFirst struct {
+	*fakeTrader
+	m *oms.Manager
+}
+
+func (s *streamFirst) Insert(ctx context.Context, p *types.InsertOrderParams) (types.OrderStatus, error) {
+	s.m.Apply([]types.OrderStatus{update(*p.ClientOrderID, enums.OrderStatusFilled, 1)})
+	return s.fakeTrader.Insert(ctx, p)
+}
+
+func TestManager_InsertErrors(t *testing.T) {
+	trader := &fakeTrader{err: &apierr.APIError{Code: 1, Message: "insufficient margin"}}
+	m := oms.NewManager(trader, oms.WithClientIDs(ids()))
+
+	var rejected []oms.Event
+	o, err := m.Insert(context.Background(), limitBuy(50000), oms.OnEvent(func(e oms.Event) { rejected = append(rejected, e) }))
+	if err == nil || o == nil || o.State() != oms.StateRejected {
+		t.Fatalf("Insert = %v, %v", o, err)
+	}
+	if _, err := o.WaitFinal(context.Background()); err == nil {
+		t.Error("WaitFinal of a rejected order returned no error")
+	}
+	if len(rejected) != 1 || rejected[0].Type != oms.EventRejected || rejected[0].Err == nil {
+		t.Errorf("events = %+v", rejected)
+	}
+
+	trader.err = &apierr.TimeoutError{Message: "waiting for response"}
+	o, err = m.Insert(context.Background(), limitBuy(50000))
+	if err == nil || o.State() != oms.StateUnknown {
+		t.Fatalf("State = %v, %v, want unknown", o.State(), err)
+	}
+	m.Apply([]types.OrderStatus{update(o.ClientID(), enums.OrderStatusOpen, 0)})
+	if o.State() != oms.StateOpen || o.Err() != nil {
+		t.Errorf("State = %v, Err = %v after a stream update", o.State(), o.Err())
+	}
+
+	p := limitBuy(50000)
+	p.ClientOrderID = new(uint64)
+	*p.ClientOrderID = o.ClientID()
+	if _, err := m.Insert(context.Background(), p); !errors.Is(err, oms.ErrDuplicateClientID) {
+		t.Errorf("err = %v, want ErrDuplicateClientID", err)
+	}
+}
+
+func TestManager_AmendCancel(t *testing.T) {
+	trader := &fakeTrader{}
+	var events []oms.EventType
+	m := oms.NewManager(trader, oms.WithClientIDs(ids()), oms.WithEventHandler(func(e oms.Event) { events = append(events, e.Type) }))
+	o, _ := m.Insert(context.Background(), limitBuy(50000))
+
+	if err := m.Amend(context.Background(), o.ClientID(), 50100, 2); err != nil {
+		t.Fatalf("Amend: %v", err)
+	}
+	a := trader.amends[0]
+	if *a.ClientOrderID != o.ClientID() || a.InstrumentName != "BTC-PERPETUAL" || a.Direction != enums.DirectionBuy {
+		t.Errorf("amend params = %+v", a)
+	}
+	if s := o.Status(); *s.Price != 50100 || s.Amount != 2 {
+		t.Errorf("Status after amend = %+v", s)
+	}
+
+	if err := m.Cancel(context.Background(), o.ClientID()); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	if o.State() != oms.StateCancelled {
+		t.Errorf("State = %v, want cancelled", o.State())
+	}
+	if err := m.Cancel(context.Background(), o.ClientID()); !errors.Is(err, oms.ErrOrderClosed) {
+		t.Errorf("second Cancel err = %v, want ErrOrderClosed", err)
+	}
+	if err := m.Amend(context.Background(), 1, 1, 1); !errors.Is(err, oms.ErrUnknownOrder) {
+		t.Errorf("Amend of an unknown order err = %v", err)
+	}
+	want := []oms.EventType{oms.EventAccepted, oms.EventAmended, oms.EventCancelled}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("events = %v, want %v", events, want)
+	}
+}
+
+func TestManager_OpenAndPrune(t *testing.T) {
+	m := oms.NewManager(&fakeTrader{}, oms.WithClientIDs(ids()))
+	a, _ := m.Insert(context.Background(), limitBuy(50000).WithLabel("mm"))
+	b, _ := m.Insert(context.Background(), types.NewSellOrderParams("BTC-PERPETUAL", 1).WithPrice(51000))
+	c, _ := m.Insert(context.Background(), types.NewBuyOrderParams("ETH-PERPETUAL", 1).WithPrice(3000))
+	m.Apply([]types.OrderStatus{update(c.ClientID(), enums.OrderStatusFilled, 1)})
+
+	clientIDs := func(orders []*oms.Order) []uint64 {
+		var out []uint64
+		for _, o := range orders {
+			out = append(out, o.ClientID())
+		}
+		return out
+	}
+	if got := clientIDs(m.Open(oms.Filter{})); !reflect.DeepEqual(got, []uint64{a.ClientID(), b.ClientID()}) {
+		t.Errorf("Open = %v", got)
+	}
+	if got := clientIDs(m.Open(oms.Filter{Direction: enums.DirectionSell})); !reflect.DeepEqual(got, []uint64{b.ClientID()}) {
+		t.Errorf("Open(sell) = %v", got)
+	}
+	if got := clientIDs(m.Open(oms.Filter{Label: "mm", Instrument: "BTC-PERPETUAL"})); !reflect.DeepEqual(got, []uint64{a.ClientID()}) {
+		t.Errorf("Open(mm) = %v", got)
+	}
+
+	if n := m.Prune(); n != 1 {
+		t.Errorf("Prune = %d, want 1", n)
+	}
+	if _, ok := m.Get(c.ClientID()); ok {
+		t.Error("pruned order is still tracked")
+	}
+	if got, ok := m.Get(a.ClientID()); !ok || got != a {
+		t.Error("Get did not return the open order")
+	}
+}
+
+func TestManager_Track(t *testing.T) {
+	trader := &fakeTrader{}
+	m := oms.NewManager(trader)
+	s := update(7, enums.OrderStatusOpen, 0)
+	s.OrderID = "x7"
+	o, err := m.Track(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.State() != oms.StateOpen || o.Status().OrderID != "x7" {
+		t.Errorf("tracked order = %s %+v", o.State(), o.Status())
+	}
+	if _, err := m.Track(s); !errors.Is(err, oms.ErrDuplicateClientID) {
+		t.Errorf("second Track error = %v, want ErrDuplicateClientID", err)
+	}
+	if _, err := m.Track(types.OrderStatus{OrderID: "x8"}); err == nil {
+		t.Error("Track without a client order ID succeeded")
+	}
+
+	// Updates by order ID reach the tracked order.
+	m.Apply([]types.OrderStatus{{OrderID: "x7", Amount: 1, FilledAmount: 1, Status: enums.OrderStatusFilled}})
+	if o.State() != oms.StateFilled {
+		t.Errorf("state = %s, want filled", o.State())
+	}
+	if err := m.Cancel(context.Background(), 7); !errors.Is(err, oms.ErrOrderClosed) {
+		t.Errorf("Cancel error = %v, want ErrOrderClosed", err)
+	}
+}
+
+func TestManager_Subscribe(t *testing.T) {
+	m := oms.NewManager(&fakeTrader{}, oms.WithClientIDs(ids()))
+	sub := &fakeSubscriber{}
+	if err := m.Subscribe(context.Background(), sub, types.ChannelSessionOrders); err != nil {
+		t.Fatal(err)
+	}
+	if sub.session == nil || sub.orders != nil || !reflect.DeepEqual(sub.subscribed, []string{types.ChannelSessionOrders}) {
+		t.Fatalf("subscriber = %+v", sub)
+	}
+	o, _ := m.Insert(context.Background(), limitBuy(50000))
+	sub.session([]types.OrderStatus{update(o.ClientID(), enums.OrderStatusFilled, 1)})
+	if o.State() != oms.StateFilled {
+		t.Errorf("State = %v after a session.orders update", o.State())
+	}
+
+	sub = &fakeSubscriber{}
+	if err := m.Subscribe(context.Background(), sub); err != nil || sub.orders == nil {
+		t.Errorf("default subscribe: %v, %+v", err, sub)
+	}
+	if err := m.Subscribe(context.Background(), sub, "ticker.BTC-PERPETUAL.raw"); err == nil {
+		t.Error("Subscribe accepted a non-order channel")
+	}
+}
+
+func TestManager_DefaultClientIDsUnique(t *testing.T) {
+	m := oms.NewManager(&fakeTrader{})
+	seen := make(map[uint64]bool)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			o, err := m.Insert(context.Background(), limitBuy(50000))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			mu.Lock()
+			seen[o.ClientID()] = true
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	if len(seen) != 50 {
+		t.Errorf("%d unique client IDs, want 50", len(seen))
+	}
+}
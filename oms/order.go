@@ -0,0 +1,367 @@
+package oms
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	"github.com/amiwrpremium/go-thalex/enums"
+	"github.com/amiwrpremium/go-thalex/types"
+)
+
+// State is the lifecycle state of a tracked order.
+type State int
+
+const (
+	// StatePending means the insert was sent and not yet acknowledged.
+	StatePending State = iota
+	// StateUnknown means the insert failed in a way that leaves its outcome
+	// unknown, such as a timeout. An order stream update resolves it.
+	StateUnknown
+	// StateOpen means the order is open or partially filled.
+	StateOpen
+	// StateFilled means the order was filled completely.
+	StateFilled
+	// StateCancelled means the order was cancelled, possibly after partial
+	// fills.
+	StateCancelled
+	// StateRejected means the insert was refused and no order exists.
+	StateRejected
+)
+
+// String returns the name of the state.
+func (s State) String() string {
+	switch s {
+	case StatePending:
+		return "pending"
+	case StateUnknown:
+		return "unknown"
+	case StateOpen:
+		return "open"
+	case StateFilled:
+		return "filled"
+	case StateCancelled:
+		return "cancelled"
+	case StateRejected:
+		return "rejected"
+	}
+	return "State(" + strconv.Itoa(int(s)) + ")"
+}
+
+// IsFinal reports whether the order can no longer change.
+func (s State) IsFinal() bool {
+	return s == StateFilled || s == StateCancelled || s == StateRejected
+}
+
+// stateOf maps an exchange order status to a State.
+func stateOf(s enums.OrderStatusValue) State {
+	switch s {
+	case enums.OrderStatusFilled:
+		return StateFilled
+	case enums.OrderStatusCancelled, enums.OrderStatusCancelledPartiallyFilled:
+		return StateCancelled
+	}
+	return StateOpen
+}
+
+// EventType identifies an order Event.
+type EventType int
+
+const (
+	// EventAccepted is sent when the exchange first acknowledges the order.
+	EventAccepted EventType = iota
+	// EventAmended is sent when the order's price or amount changes.
+	EventAmended
+	// EventFill is sent once for every new fill.
+	EventFill
+	// EventFilled is sent when the order is filled completely.
+	EventFilled
+	// EventCancelled is sent when the order is cancelled.
+	EventCancelled
+	// EventRejected is sent when the insert is refused.
+	EventRejected
+)
+
+// String returns the name of the event type.
+func (t EventType) String() string {
+	switch t {
+	case EventAccepted:
+		return "accepted"
+	case EventAmended:
+		return "amended"
+	case EventFill:
+		return "fill"
+	case EventFilled:
+		return "filled"
+	case EventCancelled:
+		return "cancelled"
+	case EventRejected:
+		return "rejected"
+	}
+	return "EventType(" + strconv.Itoa(int(t)) + ")"
+}
+
+// Event describes a change to a tracked order.
+type Event struct {
+	Type     EventType
+	ClientID uint64
+	// Status is the order as of the event.
+	Status types.OrderStatus
+	// Fill is the new fill of an EventFill.
+	Fill types.OrderFill
+	// Err is the reason of an EventRejected.
+	Err error
+}
+
+// OrderOption configures a single order.
+type OrderOption func(*Order)
+
+// OnEvent registers a callback for the events of one order. It runs before
+// the Manager's handler; see WithEventHandler.
+func OnEvent(fn func(Event)) OrderOption {
+	return func(o *Order) { o.onEvent = fn }
+}
+
+// fillKey identifies a fill; a trade fills each leg of an order once.
+type fillKey struct {
+	tradeID  string
+	legIndex int
+}
+
+// Order is a tracked order. It is safe for concurrent use.
+type Order struct {
+	clientID uint64
+	onEvent  func(Event)
+	manager  *Manager
+
+	mu     sync.Mutex
+	status types.OrderStatus
+	state  State
+	err    error
+	fills  []types.OrderFill
+	seen   map[fillKey]bool
+	final  chan struct{}
+	// amend is the last amend response until an update carries its price
+	// and amount.
+	amend *types.OrderStatus
+
+	// Events are queued and delivered by one goroutine at a time, so the
+	// callbacks of an order see its events in order and never concurrently.
+	queue      []Event
+	delivering bool
+}
+
+func newOrder(m *Manager, clientID uint64, status types.OrderStatus) *Order {
+	return &Order{
+		clientID: clientID,
+		manager:  m,
+		status:   status,
+		seen:     make(map[fillKey]bool),
+		final:    make(chan struct{}),
+	}
+}
+
+// ClientID returns the order's client order ID.
+func (o *Order) ClientID() uint64 { return o.clientID }
+
+// Status returns the latest known state of the order. Before the exchange
+// acknowledges the order it is built from the insert parameters.
+func (o *Order) Status() types.OrderStatus {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.status
+}
+
+// State returns the lifecycle state of the order.
+func (o *Order) State() State {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.state
+}
+
+// Err returns the insert error of a rejected order, or of an order whose
+// state is unknown.
+func (o *Order) Err() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.err
+}
+
+// Fills returns every fill seen for the order, in the order they arrived.
+func (o *Order) Fills() []types.OrderFill {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]types.OrderFill(nil), o.fills...)
+}
+
+// FilledAmount returns the filled amount of the latest known status.
+func (o *Order) FilledAmount() float64 {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.status.FilledAmount
+}
+
+// WaitFinal blocks until the order is filled, cancelled or rejected, or ctx
+// is done. For a rejected order it returns the rejection error.
+func (o *Order) WaitFinal(ctx context.Context) (types.OrderStatus, error) {
+	select {
+	case <-o.final:
+	case <-ctx.Done():
+		return o.Status(), ctx.Err()
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.status, o.err
+}
+
+// WaitFilled blocks until the order is filled completely. It returns
+// ErrNotFilled if the order is cancelled first.
+func (o *Order) WaitFilled(ctx context.Context) (types.OrderStatus, error) {
+	s, err := o.WaitFinal(ctx)
+	if err == nil && s.Status != enums.OrderStatusFilled {
+		err = ErrNotFilled
+	}
+	return s, err
+}
+
+// isNewer reports whether s may replace the current status. Updates can
+// arrive out of order, so one that would move the order backwards, out of
+// a final state or to a smaller filled amount, is stale. So is one that
+// would undo an amend: after an amend response, an update with the same
+// filled amount but the old price or amount predates it.
+func (o *Order) isNewer(s types.OrderStatus, amend bool) bool {
+	switch {
+	case o.state == StatePending || o.state == StateUnknown:
+		return true
+	case o.state.IsFinal():
+		return false
+	case s.FilledAmount < o.status.FilledAmount:
+		return false
+	case amend || o.amend == nil || s.FilledAmount > o.status.FilledAmount:
+		return true
+	}
+	return !amended(*o.amend, s)
+}
+
+// apply merges an exchange update into the order and delivers the
+// resulting events.
+func (o *Order) apply(s types.OrderStatus) {
+	o.mu.Lock()
+	o.merge(s, false)
+}
+
+// applyAmend merges the response to an amend of the order.
+func (o *Order) applyAmend(s types.OrderStatus) {
+	o.mu.Lock()
+	o.merge(s, true)
+}
+
+// merge merges s, an update or, if amend is set, an amend response, and
+// delivers the resulting events. It is called with o.mu held and releases
+// it.
+func (o *Order) merge(s types.OrderStatus, amend bool) {
+	var events []Event
+	// Fills are facts, so they are kept even from a stale update.
+	for _, f := range s.Fills {
+		key := fillKey{f.TradeID, f.LegIndex}
+		if o.seen[key] {
+			continue
+		}
+		o.seen[key] = true
+		o.fills = append(o.fills, f)
+		events = append(events, Event{Type: EventFill, Fill: f})
+	}
+	if o.isNewer(s, amend) {
+		switch {
+		case amend:
+			o.amend = &s
+		case o.amend != nil && !amended(*o.amend, s):
+			o.amend = nil
+		}
+		prev, prevState := o.status, o.state
+		o.status, o.state, o.err = s, stateOf(s.Status), nil
+		switch {
+		case prevState == StatePending || prevState == StateUnknown:
+			events = append(events, Event{Type: EventAccepted})
+		case amended(prev, s):
+			events = append(events, Event{Type: EventAmended})
+		}
+		switch o.state {
+		case StateFilled:
+			events = append(events, Event{Type: EventFilled})
+		case StateCancelled:
+			events = append(events, Event{Type: EventCancelled})
+		}
+		if o.state.IsFinal() {
+			close(o.final)
+		}
+	}
+	o.publish(events)
+}
+
+// reject marks an order that was never placed.
+func (o *Order) reject(err error) {
+	o.mu.Lock()
+	if o.state != StatePending {
+		// A stream update got here first.
+		o.mu.Unlock()
+		return
+	}
+	o.state, o.err = StateRejected, err
+	close(o.final)
+	o.publish([]Event{{Type: EventRejected, Err: err}})
+}
+
+// unknown records an insert error that leaves the order's fate open.
+func (o *Order) unknown(err error) {
+	o.mu.Lock()
+	if o.state == StatePending {
+		o.state, o.err = StateUnknown, err
+	}
+	o.mu.Unlock()
+}
+
+// publish queues events and delivers them. It is called with o.mu held and
+// releases it.
+func (o *Order) publish(events []Event) {
+	for i := range events {
+		events[i].ClientID = o.clientID
+		events[i].Status = o.status
+	}
+	o.queue = append(o.queue, events...)
+	if o.delivering || len(o.queue) == 0 {
+		o.mu.Unlock()
+		return
+	}
+	o.delivering = true
+	o.mu.Unlock()
+
+	for {
+		o.mu.Lock()
+		if len(o.queue) == 0 {
+			o.queue = nil
+			o.delivering = false
+			o.mu.Unlock()
+			return
+		}
+		ev := o.queue[0]
+		o.queue = o.queue[1:]
+		o.mu.Unlock()
+
+		if o.onEvent != nil {
+			o.onEvent(ev)
+		}
+		if o.manager.onEvent != nil {
+			o.manager.onEvent(ev)
+		}
+	}
+}
+
+// amended reports whether s changes the price or amount of prev. Fields
+// missing from s are not a change.
+func amended(prev, s types.OrderStatus) bool {
+	if s.Price != nil && (prev.Price == nil || *prev.Price != *s.Price) {
+		return true
+	}
+	return s.Amount != 0 && s.Amount != prev.Amount
+}
@@ -0,0 +1,340 @@
+// Package orderbook maintains local order books from the book channel.
+//
+// A [Book] holds the sorted bids and asks of one instrument and answers
+// top-of-book, depth, cumulative size, VWAP, mid and microprice queries.
+// A [Manager] keeps a Book per subscribed instrument current: it seeds each
+// book from a Book() snapshot, applies channel notifications, and resyncs
+// when a book is crossed, receives updates out of order, or the WebSocket
+// reconnects.
+//
+//	m := orderbook.NewManager(wsClient)
+//	book, err := m.Subscribe(ctx, wsClient, types.BookChannel("BTC-PERPETUAL", 1, 20, enums.Delay100ms))
+//	if mid, ok := book.Mid(); ok && !book.IsStale(5*time.Second) {
+//		fmt.Println(book.Instrument(), mid)
+//	}
+package orderbook
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/amiwrpremium/go-thalex/enums"
+	"github.com/amiwrpremium/go-thalex/types"
+)
+
+var (
+	// ErrOutOfOrder is returned when an update is older than the book.
+	ErrOutOfOrder = errors.New("orderbook: update older than book")
+	// ErrCrossed is returned when an update leaves the best bid at or
+	// above the best ask.
+	ErrCrossed = errors.New("orderbook: book is crossed")
+	// ErrNotSynced is returned when an update arrives before a snapshot.
+	ErrNotSynced = errors.New("orderbook: update before snapshot")
+)
+
+// Level is one price level of a book.
+type Level struct {
+	Price float64
+	// Amount is the total amount at the price, including implied
+	// liquidity from combinations.
+	Amount float64
+	// Outright is the part of Amount resting in outright orders.
+	Outright float64
+}
+
+func levelFrom(l types.BookLevel) Level {
+	return Level{Price: l.Price(), Amount: l.Amount(), Outright: l.OutrightAmount()}
+}
+
+// Book is the local order book of one instrument. It is safe for
+// concurrent use.
+type Book struct {
+	instrument string
+	now        func() time.Time
+
+	mu       sync.RWMutex
+	bids     []Level // best (highest) first
+	asks     []Level // best (lowest) first
+	last     *float64
+	time     types.Timestamp
+	received time.Time
+	synced   bool
+}
+
+// NewBook returns an empty, unsynced book for instrument.
+func NewBook(instrument string) *Book {
+	return &Book{instrument: instrument, now: time.Now}
+}
+
+// Instrument returns the instrument name.
+func (b *Book) Instrument() string { return b.instrument }
+
+// ApplySnapshot replaces the book contents and marks it synced. A snapshot
+// older than a synced book is rejected with ErrOutOfOrder and leaves it
+// unchanged. A crossed snapshot is applied, but the book is marked unsynced
+// and ErrCrossed is returned.
+func (b *Book) ApplySnapshot(s types.Book) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.synced && s.Time != 0 && s.Time < b.time {
+		return fmt.Errorf("%w: %s < %s", ErrOutOfOrder, s.Time, b.time)
+	}
+	b.bids = b.bids[:0]
+	b.asks = b.asks[:0]
+	for _, l := range s.Bids {
+		if l.Amount() > 0 {
+			b.bids = append(b.bids, levelFrom(l))
+		}
+	}
+	for _, l := range s.Asks {
+		if l.Amount() > 0 {
+			b.asks = append(b.asks, levelFrom(l))
+		}
+	}
+	sort.Slice(b.bids, func(i, j int) bool { return b.bids[i].Price > b.bids[j].Price })
+	sort.Slice(b.asks, func(i, j int) bool { return b.asks[i].Price < b.asks[j].Price })
+	b.last = s.Last
+	b.time = s.Time
+	b.received = b.now()
+	b.synced = true
+	return b.checkCrossed()
+}
+
+// ApplyUpdate applies an incremental update: each level replaces the level
+// at its price, and a zero amount removes it. Updates older than the book
+// are rejected with ErrOutOfOrder and leave it unchanged. An update that
+// crosses the book is applied, but the book is marked unsynced and
+// ErrCrossed is returned.
+func (b *Book) ApplyUpdate(u types.BookUpdate) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.synced {
+		return ErrNotSynced
+	}
+	if u.Time != 0 && u.Time < b.time {
+		return fmt.Errorf("%w: %s < %s", ErrOutOfOrder, u.Time, b.time)
+	}
+	for _, l := range u.Bids {
+		b.bids = upsert(b.bids, levelFrom(l), func(a, b float64) bool { return a > b })
+	}
+	for _, l := range u.Asks {
+		b.asks = upsert(b.asks, levelFrom(l), func(a, b float64) bool { return a < b })
+	}
+	if u.Last != nil {
+		b.last = u.Last
+	}
+	if u.Time != 0 {
+		b.time = u.Time
+	}
+	b.received = b.now()
+	return b.checkCrossed()
+}
+
+// checkCrossed marks a crossed book unsynced. b.mu must be held.
+func (b *Book) checkCrossed() error {
+	if len(b.bids) > 0 && len(b.asks) > 0 && b.bids[0].Price >= b.asks[0].Price {
+		b.synced = false
+		return fmt.Errorf("%w: bid %v >= ask %v", ErrCrossed, b.bids[0].Price, b.asks[0].Price)
+	}
+	return nil
+}
+
+// upsert sets, inserts or removes l in levels, which are ordered by better.
+func upsert(levels []Level, l Level, better func(a, b float64) bool) []Level {
+	i := sort.Search(len(levels), func(i int) bool { return !better(levels[i].Price, l.Price) })
+	exists := i < len(levels) && levels[i].Price == l.Price
+	switch {
+	case l.Amount <= 0 && exists:
+		return append(levels[:i], levels[i+1:]...)
+	case l.Amount <= 0:
+		return levels
+	case exists:
+		levels[i] = l
+		return levels
+	}
+	levels = append(levels, Level{})
+	copy(levels[i+1:], levels[i:])
+	levels[i] = l
+	return levels
+}
+
+// Invalidate marks the book unsynced, so updates are refused until the
+// next snapshot.
+func (b *Book) Invalidate() {
+	b.mu.Lock()
+	b.synced = false
+	b.mu.Unlock()
+}
+
+// Synced reports whether the book has a snapshot and has not been
+// invalidated since.
+func (b *Book) Synced() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.synced
+}
+
+// Time returns the exchange timestamp of the latest snapshot or update.
+func (b *Book) Time() types.Timestamp {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.time
+}
+
+// Last returns the last traded price, if known.
+func (b *Book) Last() (float64, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.last == nil {
+		return 0, false
+	}
+	return *b.last, true
+}
+
+// Age returns how long ago the book last changed locally.
+func (b *Book) Age() time.Duration {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.received.IsZero() {
+		return 0
+	}
+	return b.now().Sub(b.received)
+}
+
+// IsStale reports whether the book is unsynced or has not changed for
+// longer than maxAge.
+func (b *Book) IsStale(maxAge time.Duration) bool {
+	return !b.Synced() || b.Age() > maxAge
+}
+
+// IsCrossed reports whether the best bid is at or above the best ask.
+func (b *Book) IsCrossed() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.bids) > 0 && len(b.asks) > 0 && b.bids[0].Price >= b.asks[0].Price
+}
+
+// BestBid returns the highest bid.
+func (b *Book) BestBid() (Level, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if len(b.bids) == 0 {
+		return Level{}, false
+	}
+	return b.bids[0], true
+}
+
+// BestAsk returns the lowest ask.
+func (b *Book) BestAsk() (Level, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if len(b.asks) == 0 {
+		return Level{}, false
+	}
+	return b.asks[0], true
+}
+
+// Bids returns up to n bids, best first. n <= 0 returns every level.
+func (b *Book) Bids(n int) []Level {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return top(b.bids, n)
+}
+
+// Asks returns up to n asks, best first. n <= 0 returns every level.
+func (b *Book) Asks(n int) []Level {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return top(b.asks, n)
+}
+
+func top(levels []Level, n int) []Level {
+	if n <= 0 || n > len(levels) {
+		n = len(levels)
+	}
+	out := make([]Level, n)
+	copy(out, levels)
+	return out
+}
+
+// Spread returns best ask minus best bid.
+func (b *Book) Spread() (float64, bool) {
+	bid, ask, ok := b.top()
+	return ask.Price - bid.Price, ok
+}
+
+// Mid returns the midpoint of the best bid and ask.
+func (b *Book) Mid() (float64, bool) {
+	bid, ask, ok := b.top()
+	return (bid.Price + ask.Price) / 2, ok
+}
+
+// Microprice returns the size-weighted mid, which leans toward the side
+// with less size: bid*askSize/(bidSize+askSize) + ask*bidSize/(bidSize+askSize).
+func (b *Book) Microprice() (float64, bool) {
+	bid, ask, ok := b.top()
+	if !ok {
+		return 0, false
+	}
+	total := bid.Amount + ask.Amount
+	return (bid.Price*ask.Amount + ask.Price*bid.Amount) / total, true
+}
+
+func (b *Book) top() (bid, ask Level, ok bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if len(b.bids) == 0 || len(b.asks) == 0 {
+		return Level{}, Level{}, false
+	}
+	return b.bids[0], b.asks[0], true
+}
+
+// levelsFor returns the side a taker order in direction would trade
+// against. b.mu must be held.
+func (b *Book) levelsFor(direction enums.Direction) []Level {
+	if direction == enums.DirectionBuy {
+		return b.asks
+	}
+	return b.bids
+}
+
+// CumulativeSize returns the amount available to a taker order in
+// direction with the given limit price: the asks at or below price for a
+// buy, the bids at or above it for a sell.
+func (b *Book) CumulativeSize(direction enums.Direction, price float64) float64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	var size float64
+	for _, l := range b.levelsFor(direction) {
+		if (direction == enums.DirectionBuy && l.Price > price) || (direction != enums.DirectionBuy && l.Price < price) {
+			break
+		}
+		size += l.Amount
+	}
+	return size
+}
+
+// VWAP returns the average price a taker order in direction would pay to
+// fill amount by sweeping the book, and the amount it could fill. When the
+// book is too thin, filled is less than amount and the price covers what
+// could be filled. ok is false if the opposite side is empty.
+func (b *Book) VWAP(direction enums.Direction, amount float64) (price, filled float64, ok bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	var notional float64
+	for _, l := range b.levelsFor(direction) {
+		if filled >= amount {
+			break
+		}
+		take := min(l.Amount, amount-filled)
+		notional += take * l.Price
+		filled += take
+	}
+	if filled == 0 {
+		return 0, 0, false
+	}
+	return notional / filled, filled, true
+}
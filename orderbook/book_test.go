@@ -0,0 +1,165 @@
+package orderbook_test
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/amiwrpremium/go-thalex/enums"
+	"github.com/amiwrpremium/go-thalex/orderbook"
+	"github.com/amiwrpremium/go-thalex/types"
+)
+
+func lvl(price, amount float64) types.BookLevel { return types.BookLevel{price, amount, amount} }
+
+func seeded(t *testing.T) *orderbook.Book {
+	t.Helper()
+	b := orderbook.NewBook("BTC-PERPETUAL")
+	err := b.ApplySnapshot(types.Book{
+		Bids: []types.BookLevel{lvl(99, 2), lvl(100, 1), lvl(98, 3)},
+		Asks: []types.BookLevel{lvl(102, 2), lvl(101, 3), lvl(103, 0)},
+		Time: 10,
+	})
+	if err != nil {
+		t.Fatalf("ApplySnapshot error: %v", err)
+	}
+	return b
+}
+
+func prices(levels []orderbook.Level) []float64 {
+	out := make([]float64, len(levels))
+	for i, l := range levels {
+		out[i] = l.Price
+	}
+	return out
+}
+
+func TestBook_Snapshot(t *testing.T) {
+	b := seeded(t)
+	if !b.Synced() {
+		t.Error("book not synced")
+	}
+	if got := prices(b.Bids(0)); !reflect.DeepEqual(got, []float64{100, 99, 98}) {
+		t.Errorf("Bids = %v", got)
+	}
+	if got := prices(b.Asks(0)); !reflect.DeepEqual(got, []float64{101, 102}) {
+		t.Errorf("Asks = %v, zero levels should be dropped", got)
+	}
+	if got := prices(b.Bids(2)); !reflect.DeepEqual(got, []float64{100, 99}) {
+		t.Errorf("Bids(2) = %v", got)
+	}
+	if bid, ok := b.BestBid(); !ok || bid.Price != 100 || bid.Amount != 1 {
+		t.Errorf("BestBid = %+v, %v", bid, ok)
+	}
+	if ask, ok := b.BestAsk(); !ok || ask.Price != 101 || ask.Amount != 3 {
+		t.Errorf("BestAsk = %+v, %v", ask, ok)
+	}
+	if mid, _ := b.Mid(); mid != 100.5 {
+		t.Errorf("Mid = %v, want 100.5", mid)
+	}
+	if spread, _ := b.Spread(); spread != 1 {
+		t.Errorf("Spread = %v, want 1", spread)
+	}
+	// 100*3/4 + 101*1/4: the thin bid pulls the price toward the bid.
+	if mp, _ := b.Microprice(); mp != 100.25 {
+		t.Errorf("Microprice = %v, want 100.25", mp)
+	}
+}
+
+func TestBook_ApplyUpdate(t *testing.T) {
+	b := seeded(t)
+	last := 100.0
+	err := b.ApplyUpdate(types.BookUpdate{
+		Bids: []types.BookLevel{lvl(100, 0), lvl(99.5, 4), lvl(98, 1)},
+		Asks: []types.BookLevel{lvl(104, 1), lvl(105, 0)},
+		Last: &last,
+		Time: 11,
+	})
+	if err != nil {
+		t.Fatalf("ApplyUpdate error: %v", err)
+	}
+	if got := prices(b.Bids(0)); !reflect.DeepEqual(got, []float64{99.5, 99, 98}) {
+		t.Errorf("Bids = %v", got)
+	}
+	if got := b.Bids(0)[2].Amount; got != 1 {
+		t.Errorf("98 amount = %v, want 1", got)
+	}
+	if got := prices(b.Asks(0)); !reflect.DeepEqual(got, []float64{101, 102, 104}) {
+		t.Errorf("Asks = %v", got)
+	}
+	if p, ok := b.Last(); !ok || p != 100 {
+		t.Errorf("Last = %v, %v", p, ok)
+	}
+	if b.Time() != 11 {
+		t.Errorf("Time = %v, want 11", b.Time())
+	}
+}
+
+func TestBook_OutOfOrder(t *testing.T) {
+	b := seeded(t)
+	err := b.ApplyUpdate(types.BookUpdate{Bids: []types.BookLevel{lvl(100, 9)}, Time: 9})
+	if !errors.Is(err, orderbook.ErrOutOfOrder) {
+		t.Fatalf("err = %v, want ErrOutOfOrder", err)
+	}
+	if bid, _ := b.BestBid(); bid.Amount != 1 {
+		t.Errorf("out-of-order update applied: %+v", bid)
+	}
+	if err := b.ApplySnapshot(types.Book{Time: 9}); !errors.Is(err, orderbook.ErrOutOfOrder) {
+		t.Errorf("ApplySnapshot err = %v, want ErrOutOfOrder", err)
+	}
+}
+
+func TestBook_Crossed(t *testing.T) {
+	b := seeded(t)
+	err := b.ApplyUpdate(types.BookUpdate{Bids: []types.BookLevel{lvl(101, 1)}, Time: 11})
+	if !errors.Is(err, orderbook.ErrCrossed) {
+		t.Fatalf("err = %v, want ErrCrossed", err)
+	}
+	if !b.IsCrossed() || b.Synced() {
+		t.Errorf("IsCrossed = %v, Synced = %v", b.IsCrossed(), b.Synced())
+	}
+	if err := b.ApplyUpdate(types.BookUpdate{Time: 12}); !errors.Is(err, orderbook.ErrNotSynced) {
+		t.Errorf("update on unsynced book: err = %v, want ErrNotSynced", err)
+	}
+}
+
+func TestBook_NotSynced(t *testing.T) {
+	b := orderbook.NewBook("BTC-PERPETUAL")
+	if err := b.ApplyUpdate(types.BookUpdate{Time: 1}); !errors.Is(err, orderbook.ErrNotSynced) {
+		t.Errorf("err = %v, want ErrNotSynced", err)
+	}
+	if _, ok := b.Mid(); ok {
+		t.Error("Mid on empty book should not be ok")
+	}
+	if !b.IsStale(0) {
+		t.Error("unsynced book should be stale")
+	}
+}
+
+func TestBook_CumulativeSizeAndVWAP(t *testing.T) {
+	b := seeded(t)
+	if got := b.CumulativeSize(enums.DirectionBuy, 101.5); got != 3 {
+		t.Errorf("buy size to 101.5 = %v, want 3", got)
+	}
+	if got := b.CumulativeSize(enums.DirectionBuy, 102); got != 5 {
+		t.Errorf("buy size to 102 = %v, want 5", got)
+	}
+	if got := b.CumulativeSize(enums.DirectionSell, 99); got != 3 {
+		t.Errorf("sell size to 99 = %v, want 3", got)
+	}
+
+	price, filled, ok := b.VWAP(enums.DirectionBuy, 4)
+	if !ok || filled != 4 || math.Abs(price-(101*3+102)/4.0) > 1e-9 {
+		t.Errorf("VWAP(buy, 4) = %v, %v, %v", price, filled, ok)
+	}
+	price, filled, ok = b.VWAP(enums.DirectionSell, 10)
+	if !ok || filled != 6 || math.Abs(price-(100+99*2+98*3)/6.0) > 1e-9 {
+		t.Errorf("VWAP(sell, 10) = %v, %v, %v", price, filled, ok)
+	}
+
+	empty := orderbook.NewBook("X")
+	if _, _, ok := empty.VWAP(enums.DirectionBuy, 1); ok {
+		t.Error("VWAP on empty book should not be ok")
+	}
+}
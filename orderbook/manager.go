@@ -0,0 +1,296 @@
+package orderbook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/amiwrpremium/go-thalex/types"
+)
+
+// ErrReconnected is the cause reported for resyncs triggered by a
+// WebSocket reconnect.
+var ErrReconnected = errors.New("orderbook: connection re-established")
+
+// Source fetches book snapshots. Both rest.Client and ws.Client satisfy it.
+type Source interface {
+	Book(ctx context.Context, instrumentName string) (types.Book, error)
+}
+
+// Subscriber delivers book channel notifications. ws.Client satisfies it.
+type Subscriber interface {
+	OnBook(channel string, fn func(types.BookUpdate))
+	Subscribe(ctx context.Context, channels ...string) error
+}
+
+// reconnectNotifier is implemented by subscribers that report reconnects,
+// such as ws.Client.
+type reconnectNotifier interface {
+	OnReconnectHandler(fn func())
+}
+
+// ResyncEvent describes a book being reseeded from its Source.
+type ResyncEvent struct {
+	Instrument string
+	// Cause is why the book was resynced: ErrCrossed, ErrOutOfOrder,
+	// ErrReconnected, or ErrNotSynced for an update to a book whose
+	// earlier resync failed.
+	Cause error
+	// Err is the result of fetching the new snapshot, nil on success.
+	Err error
+}
+
+// Option configures a Manager.
+type Option func(*Manager)
+
+// WithIncrementalUpdates treats channel notifications as changes to the
+// seeded book rather than as snapshots of its top levels. Levels with a
+// zero amount are removed.
+func WithIncrementalUpdates() Option {
+	return func(m *Manager) { m.incremental = true }
+}
+
+// WithResyncHandler registers a callback invoked after every automatic
+// resync.
+func WithResyncHandler(fn func(ResyncEvent)) Option {
+	return func(m *Manager) { m.onResync = fn }
+}
+
+// WithResyncTimeout bounds each snapshot fetch made by an automatic
+// resync. The default is 10 seconds.
+func WithResyncTimeout(d time.Duration) Option {
+	return func(m *Manager) { m.timeout = d }
+}
+
+// WithClock sets the time source used for book ages. It is intended for
+// tests.
+func WithClock(now func() time.Time) Option {
+	return func(m *Manager) { m.now = now }
+}
+
+// Manager keeps one Book per subscribed book channel current. It is safe
+// for concurrent use.
+//
+// By default every notification is applied as a snapshot of the channel's
+// top levels, and notifications older than the book are dropped, since
+// notifications may be delivered out of order. With WithIncrementalUpdates
+// an out-of-order notification instead triggers a resync.
+type Manager struct {
+	source      Source
+	incremental bool
+	onResync    func(ResyncEvent)
+	timeout     time.Duration
+	now         func() time.Time
+
+	mu        sync.Mutex
+	entries   map[string]*entry // by channel
+	notifiers map[reconnectNotifier]bool
+}
+
+type entry struct {
+	book      *Book
+	sub       Subscriber
+	resyncing atomic.Bool
+}
+
+// NewManager returns a Manager that seeds books from source.
+func NewManager(source Source, opts ...Option) *Manager {
+	m := &Manager{
+		source:    source,
+		timeout:   10 * time.Second,
+		now:       time.Now,
+		entries:   make(map[string]*entry),
+		notifiers: make(map[reconnectNotifier]bool),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Subscribe seeds a book for the instrument of channel, a channel built
+// with types.BookChannel, registers a handler for it on sub and subscribes.
+// Subscribing to a channel twice returns the existing book.
+//
+// If sub reports reconnects, as ws.Client does, every book it feeds is
+// resynced after the connection is re-established.
+func (m *Manager) Subscribe(ctx context.Context, sub Subscriber, channel string) (*Book, error) {
+	instrument, err := ParseBookChannel(channel)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	if e, ok := m.entries[channel]; ok {
+		m.mu.Unlock()
+		return e.book, nil
+	}
+	book := NewBook(instrument)
+	book.now = m.now
+	e := &entry{book: book, sub: sub}
+	m.entries[channel] = e
+	if rn, ok := sub.(reconnectNotifier); ok && !m.notifiers[rn] {
+		m.notifiers[rn] = true
+		rn.OnReconnectHandler(func() { m.reconnected(sub) })
+	}
+	m.mu.Unlock()
+
+	snapshot, err := m.source.Book(ctx, instrument)
+	if err == nil {
+		err = book.ApplySnapshot(snapshot)
+	}
+	if err == nil {
+		sub.OnBook(channel, func(u types.BookUpdate) { m.handle(e, u) })
+		err = sub.Subscribe(ctx, channel)
+	}
+	if err != nil {
+		m.mu.Lock()
+		delete(m.entries, channel)
+		m.mu.Unlock()
+		return nil, fmt.Errorf("orderbook: subscribe %s: %w", channel, err)
+	}
+	return book, nil
+}
+
+// ParseBookChannel returns the instrument name of a book channel:
+//
+//	ParseBookChannel("book.BTC-PERPETUAL.1.20.100ms") => "BTC-PERPETUAL"
+func ParseBookChannel(channel string) (string, error) {
+	rest, ok := strings.CutPrefix(channel, "book.")
+	if !ok {
+		return "", fmt.Errorf("orderbook: %q is not a book channel", channel)
+	}
+	// Strip grouping, nlevels and delay from the right, since instrument
+	// names may contain dots.
+	for range 3 {
+		i := strings.LastIndexByte(rest, '.')
+		if i <= 0 {
+			return "", fmt.Errorf("orderbook: %q is not a book channel", channel)
+		}
+		rest = rest[:i]
+	}
+	return rest, nil
+}
+
+// Book returns the book of instrument. If the instrument is subscribed on
+// several channels, any one of them is returned.
+func (m *Manager) Book(instrument string) (*Book, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, e := range m.entries {
+		if e.book.instrument == instrument {
+			return e.book, true
+		}
+	}
+	return nil, false
+}
+
+// Channel returns the book fed by channel.
+func (m *Manager) Channel(channel string) (*Book, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[channel]
+	if !ok {
+		return nil, false
+	}
+	return e.book, true
+}
+
+// Stale returns the channels whose books are unsynced or have not changed
+// for longer than maxAge.
+func (m *Manager) Stale(maxAge time.Duration) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []string
+	for ch, e := range m.entries {
+		if e.book.IsStale(maxAge) {
+			out = append(out, ch)
+		}
+	}
+	return out
+}
+
+// Resync reseeds the book of channel from the Source.
+func (m *Manager) Resync(ctx context.Context, channel string) error {
+	m.mu.Lock()
+	e, ok := m.entries[channel]
+	m.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("orderbook: channel %q is not subscribed", channel)
+	}
+	return m.resync(ctx, e)
+}
+
+func (m *Manager) resync(ctx context.Context, e *entry) error {
+	snapshot, err := m.source.Book(ctx, e.book.instrument)
+	if err != nil {
+		return err
+	}
+	err = e.book.ApplySnapshot(snapshot)
+	if errors.Is(err, ErrOutOfOrder) {
+		// A notification newer than the snapshot already resynced the book.
+		return nil
+	}
+	return err
+}
+
+// handle applies a channel notification to its book.
+func (m *Manager) handle(e *entry, u types.BookUpdate) {
+	var err error
+	if m.incremental {
+		err = e.book.ApplyUpdate(u)
+	} else {
+		err = e.book.ApplySnapshot(types.Book{Bids: u.Bids, Asks: u.Asks, Last: u.Last, Time: u.Time})
+	}
+	switch {
+	case err == nil:
+	case errors.Is(err, ErrNotSynced):
+		// Usually a resync is already running and this is a no-op, but
+		// one that failed leaves the book unsynced until it is retried.
+		m.resyncAsync(e, ErrNotSynced)
+	case errors.Is(err, ErrOutOfOrder) && !m.incremental:
+		// A newer snapshot has already been applied.
+	case errors.Is(err, ErrOutOfOrder):
+		e.book.Invalidate()
+		m.resyncAsync(e, ErrOutOfOrder)
+	case errors.Is(err, ErrCrossed):
+		m.resyncAsync(e, ErrCrossed)
+	}
+}
+
+// reconnected resyncs every book fed by sub.
+func (m *Manager) reconnected(sub Subscriber) {
+	m.mu.Lock()
+	var entries []*entry
+	for _, e := range m.entries {
+		if e.sub == sub {
+			entries = append(entries, e)
+		}
+	}
+	m.mu.Unlock()
+	for _, e := range entries {
+		e.book.Invalidate()
+		m.resyncAsync(e, ErrReconnected)
+	}
+}
+
+// resyncAsync reseeds e in the background unless a resync is already
+// running.
+func (m *Manager) resyncAsync(e *entry, cause error) {
+	if !e.resyncing.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+		defer cancel()
+		err := m.resync(ctx, e)
+		e.resyncing.Store(false)
+		if m.onResync != nil {
+			m.onResync(ResyncEvent{Instrument: e.book.instrument, Cause: cause, Err: err})
+		}
+	}()
+}
@@ -0,0 +1,258 @@
+package orderbook_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/amiwrpremium/go-thalex/enums"
+	"github.com/amiwrpremium/go-thalex/orderbook"
+	"github.com/amiwrpremium/go-thalex/rest"
+	"github.com/amiwrpremium/go-thalex/types"
+	"github.com/amiwrpremium/go-thalex/ws"
+)
+
+var (
+	_ orderbook.Source     = (*rest.Client)(nil)
+	_ orderbook.Source     = (*ws.Client)(nil)
+	_ orderbook.Subscriber = (*ws.Client)(nil)
+)
+
+type fakeSource struct {
+	mu   sync.Mutex
+	book types.Book
+	err  error
+}
+
+func (s *fakeSource) Book(context.Context, string) (types.Book, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.book, s.err
+}
+
+func (s *fakeSource) set(b types.Book) {
+	s.mu.Lock()
+	s.book = b
+	s.mu.Unlock()
+}
+
+type fakeSubscriber struct {
+	handlers   map[string]func(types.BookUpdate)
+	subscribed []string
+	reconnect  []func()
+}
+
+func newFakeSubscriber() *fakeSubscriber {
+	return &fakeSubscriber{handlers: make(map[string]func(types.BookUpdate))}
+}
+
+func (s *fakeSubscriber) OnBook(channel string, fn func(types.BookUpdate)) { s.handlers[channel] = fn }
+
+func (s *fakeSubscriber) Subscribe(_ context.Context, channels ...string) error {
+	s.subscribed = append(s.subscribed, channels...)
+	return nil
+}
+
+func (s *fakeSubscriber) OnReconnectHandler(fn func()) { s.reconnect = append(s.reconnect, fn) }
+
+var channel = types.BookChannel("BTC-PERPETUAL", 1, 10, enums.Delay100ms)
+
+func snapshot(bid, ask float64, ts types.Timestamp) types.Book {
+	return types.Book{Bids: []types.BookLevel{lvl(bid, 1)}, Asks: []types.BookLevel{lvl(ask, 1)}, Time: ts}
+}
+
+func TestParseBookChannel(t *testing.T) {
+	for ch, want := range map[string]string{
+		"book.BTC-PERPETUAL.1.20.100ms":      "BTC-PERPETUAL",
+		"book.BTC-28MAR25-100000-C.5.10.raw": "BTC-28MAR25-100000-C",
+		"book.ETH-28MAR25-2.5-C.1.10.1000ms": "ETH-28MAR25-2.5-C",
+	} {
+		if got, err := orderbook.ParseBookChannel(ch); err != nil || got != want {
+			t.Errorf("ParseBookChannel(%q) = %q, %v; want %q", ch, got, err, want)
+		}
+	}
+	for _, ch := range []string{"ticker.BTC-PERPETUAL.100ms", "book.1.20.100ms", "book."} {
+		if _, err := orderbook.ParseBookChannel(ch); err == nil {
+			t.Errorf("ParseBookChannel(%q) should fail", ch)
+		}
+	}
+}
+
+func TestManager_SubscribeSeedsAndApplies(t *testing.T) {
+	src := &fakeSource{book: snapshot(100, 101, 10)}
+	sub := newFakeSubscriber()
+	m := orderbook.NewManager(src)
+
+	book, err := m.Subscribe(context.Background(), sub, channel)
+	if err != nil {
+		t.Fatalf("Subscribe error: %v", err)
+	}
+	if !reflect.DeepEqual(sub.subscribed, []string{channel}) {
+		t.Errorf("subscribed = %v", sub.subscribed)
+	}
+	if bid, _ := book.BestBid(); bid.Price != 100 {
+		t.Errorf("seeded bid = %v, want 100", bid.Price)
+	}
+
+	sub.handlers[channel](types.BookUpdate{Bids: []types.BookLevel{lvl(100.5, 2)}, Asks: []types.BookLevel{lvl(101.5, 2)}, Time: 11})
+	if bid, _ := book.BestBid(); bid.Price != 100.5 {
+		t.Errorf("bid after notification = %v, want 100.5", bid.Price)
+	}
+	if len(book.Bids(0)) != 1 {
+		t.Errorf("snapshot notification should replace the book, got %v", book.Bids(0))
+	}
+
+	// A late notification from before the current book is dropped.
+	sub.handlers[channel](types.BookUpdate{Bids: []types.BookLevel{lvl(90, 1)}, Time: 10.5})
+	if bid, _ := book.BestBid(); bid.Price != 100.5 {
+		t.Errorf("late notification applied: bid = %v", bid.Price)
+	}
+
+	again, err := m.Subscribe(context.Background(), sub, channel)
+	if err != nil || again != book {
+		t.Errorf("second Subscribe = %p, %v; want the same book", again, err)
+	}
+	if got, ok := m.Book("BTC-PERPETUAL"); !ok || got != book {
+		t.Error("Book lookup failed")
+	}
+}
+
+func TestManager_SubscribeSeedError(t *testing.T) {
+	src := &fakeSource{err: errors.New("boom")}
+	m := orderbook.NewManager(src)
+	if _, err := m.Subscribe(context.Background(), newFakeSubscriber(), channel); err == nil {
+		t.Fatal("expected error")
+	}
+	if _, ok := m.Channel(channel); ok {
+		t.Error("failed subscription should not be kept")
+	}
+}
+
+func TestManager_IncrementalResyncsOnCrossed(t *testing.T) {
+	src := &fakeSource{book: snapshot(100, 101, 10)}
+	sub := newFakeSubscriber()
+	events := make(chan orderbook.ResyncEvent, 1)
+	m := orderbook.NewManager(src, orderbook.WithIncrementalUpdates(),
+		orderbook.WithResyncHandler(func(ev orderbook.ResyncEvent) { events <- ev }))
+
+	book, err := m.Subscribe(context.Background(), sub, channel)
+	if err != nil {
+		t.Fatalf("Subscribe error: %v", err)
+	}
+	sub.handlers[channel](types.BookUpdate{Bids: []types.BookLevel{lvl(99, 3)}, Time: 11})
+	if got := len(book.Bids(0)); got != 2 {
+		t.Fatalf("incremental update should add a level, got %d bids", got)
+	}
+
+	src.set(snapshot(102, 103, 12))
+	sub.handlers[channel](types.BookUpdate{Bids: []types.BookLevel{lvl(101, 1)}, Time: 12})
+	select {
+	case ev := <-events:
+		if ev.Instrument != "BTC-PERPETUAL" || !errors.Is(ev.Cause, orderbook.ErrCrossed) || ev.Err != nil {
+			t.Errorf("event = %+v", ev)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no resync")
+	}
+	if bid, _ := book.BestBid(); !book.Synced() || bid.Price != 102 {
+		t.Errorf("after resync: synced = %v, bid = %v", book.Synced(), bid.Price)
+	}
+}
+
+func TestManager_ResyncsOnReconnect(t *testing.T) {
+	src := &fakeSource{book: snapshot(100, 101, 10)}
+	sub := newFakeSubscriber()
+	events := make(chan orderbook.ResyncEvent, 1)
+	m := orderbook.NewManager(src, orderbook.WithIncrementalUpdates(),
+		orderbook.WithResyncHandler(func(ev orderbook.ResyncEvent) { events <- ev }))
+
+	book, err := m.Subscribe(context.Background(), sub, channel)
+	if err != nil {
+		t.Fatalf("Subscribe error: %v", err)
+	}
+	if len(sub.reconnect) != 1 {
+		t.Fatalf("registered %d reconnect handlers, want 1", len(sub.reconnect))
+	}
+
+	src.set(snapshot(200, 201, 20))
+	sub.reconnect[0]()
+	select {
+	case ev := <-events:
+		if !errors.Is(ev.Cause, orderbook.ErrReconnected) || ev.Err != nil {
+			t.Errorf("event = %+v", ev)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no resync")
+	}
+	if bid, _ := book.BestBid(); bid.Price != 200 {
+		t.Errorf("bid after reconnect = %v, want 200", bid.Price)
+	}
+}
+
+func TestManager_RetriesFailedResync(t *testing.T) {
+	src := &fakeSource{book: snapshot(100, 101, 10)}
+	sub := newFakeSubscriber()
+	events := make(chan orderbook.ResyncEvent, 1)
+	m := orderbook.NewManager(src, orderbook.WithIncrementalUpdates(),
+		orderbook.WithResyncHandler(func(ev orderbook.ResyncEvent) { events <- ev }))
+
+	book, err := m.Subscribe(context.Background(), sub, channel)
+	if err != nil {
+		t.Fatalf("Subscribe error: %v", err)
+	}
+	next := func() orderbook.ResyncEvent {
+		t.Helper()
+		select {
+		case ev := <-events:
+			return ev
+		case <-time.After(2 * time.Second):
+			t.Fatal("no resync")
+		}
+		return orderbook.ResyncEvent{}
+	}
+
+	src.mu.Lock()
+	src.err = errors.New("unavailable")
+	src.mu.Unlock()
+	sub.reconnect[0]()
+	if ev := next(); ev.Err == nil {
+		t.Fatalf("event = %+v, want the fetch error", ev)
+	}
+	if book.Synced() {
+		t.Fatal("book synced after a failed fetch")
+	}
+
+	src.mu.Lock()
+	src.book, src.err = snapshot(200, 201, 20), nil
+	src.mu.Unlock()
+	sub.handlers[channel](types.BookUpdate{Bids: []types.BookLevel{lvl(199, 1)}, Time: 21})
+	if ev := next(); !errors.Is(ev.Cause, orderbook.ErrNotSynced) || ev.Err != nil {
+		t.Errorf("event = %+v", ev)
+	}
+	if bid, _ := book.BestBid(); !book.Synced() || bid.Price != 200 {
+		t.Errorf("after resync: synced = %v, bid = %v", book.Synced(), bid.Price)
+	}
+}
+
+func TestManager_Stale(t *testing.T) {
+	now := time.Unix(1000, 0)
+	src := &fakeSource{book: snapshot(100, 101, 10)}
+	m := orderbook.NewManager(src, orderbook.WithClock(func() time.Time { return now }))
+	book, err := m.Subscribe(context.Background(), newFakeSubscriber(), channel)
+	if err != nil {
+		t.Fatalf("Subscribe error: %v", err)
+	}
+	if book.IsStale(time.Second) || len(m.Stale(time.Second)) != 0 {
+		t.Error("fresh book reported stale")
+	}
+	now = now.Add(2 * time.Second)
+	if !book.IsStale(time.Second) {
+		t.Error("old book not reported stale")
+	}
+	if got := m.Stale(time.Second); !reflect.DeepEqual(got, []string{channel}) {
+		t.Errorf("Stale = %v", got)
+	}
+}
@@ -0,0 +1,516 @@
+// Package quoting keeps an account's mass quotes in line with the quotes a
+// strategy wants, sending only what changed.
+//
+// A Manager holds the desired bid and ask levels of each instrument, set
+// with Set, and tracks what is live from MassQuote results and the order
+// streams. Flush compares the two and sends a mass quote with only the
+// instrument sides that differ. Rejected levels are reported with their
+// instrument, side and price. Flushes are throttled to a configured rate,
+// and all quotes are pulled when MM protection trips.
+package quoting
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/amiwrpremium/go-thalex/enums"
+	"github.com/amiwrpremium/go-thalex/types"
+)
+
+// DefaultPullTimeout bounds the cancel request sent when MM protection
+// trips.
+const DefaultPullTimeout = 5 * time.Second
+
+var (
+	// ErrThrottled is returned by Flush when the rate limit is reached.
+	// Nothing is sent; the changes stay pending for the next Flush.
+	ErrThrottled = errors.New("quoting: rate limit reached")
+	// ErrPulled is returned by Flush after the quotes were pulled, until
+	// Resume is called.
+	ErrPulled = errors.New("quoting: quotes pulled, resume to quote again")
+)
+
+// Client is the part of a client a Manager uses. ws.Client implements it.
+type Client interface {
+	MassQuote(ctx context.Context, params *types.MassQuoteParams) (types.DoubleSidedQuoteResult, error)
+	CancelMassQuote(ctx context.Context) error
+}
+
+// Side is a side of an instrument's quote.
+type Side string
+
+const (
+	SideBid Side = "bid"
+	SideAsk Side = "ask"
+)
+
+// Rejection is a QuoteError tied back to the level it rejected.
+type Rejection struct {
+	// Instrument is empty when the error matches no level that was sent.
+	Instrument string
+	Side       Side
+	// Price is nil when the error concerns the whole side.
+	Price   *float64
+	Code    int
+	Message string
+}
+
+func (r Rejection) Error() string {
+	if r.Price == nil {
+		return fmt.Sprintf("quoting: %s %s rejected: %s (code %d)", r.Instrument, r.Side, r.Message, r.Code)
+	}
+	return fmt.Sprintf("quoting: %s %s at %g rejected: %s (code %d)", r.Instrument, r.Side, *r.Price, r.Message, r.Code)
+}
+
+// Result is the outcome of a Flush.
+type Result struct {
+	// Quotes are the instrument quotes sent. It is empty when nothing
+	// changed.
+	Quotes     []types.DoubleSidedQuote
+	NSuccess   int
+	NFail      int
+	Rejections []Rejection
+}
+
+// Option configures a Manager.
+type Option func(*Manager)
+
+// WithParams sets the label, post-only and self-trade prevention settings
+// of every mass quote. The quotes of p are ignored. Order notifications
+// are only matched to quotes when they carry the same label.
+func WithParams(p types.MassQuoteParams) Option {
+	return func(m *Manager) {
+		p.Quotes = nil
+		m.params = p
+	}
+}
+
+// WithRate limits Flush to count mass quotes per period. The default is
+// no limit.
+func WithRate(count int, per time.Duration) Option {
+	return func(m *Manager) { m.rateCount, m.ratePer = count, per }
+}
+
+// WithPullHandler registers a callback for MM protection updates. When
+// protection trips, it is called once the quotes are pulled, with the
+// error of the cancel request; a reset is passed on with a nil error.
+func WithPullHandler(fn func(types.MMProtectionUpdate, error)) Option {
+	return func(m *Manager) { m.onPull = fn }
+}
+
+// WithPullTimeout bounds the cancel request sent when MM protection
+// trips. The default is DefaultPullTimeout.
+func WithPullTimeout(d time.Duration) Option {
+	return func(m *Manager) {
+		if d > 0 {
+			m.pullTimeout = d
+		}
+	}
+}
+
+// WithClock sets the time source of the rate limit. It is intended for
+// tests.
+func WithClock(now func() time.Time) Option {
+	return func(m *Manager) { m.now = now }
+}
+
+// key identifies one side of an instrument's quote.
+type key struct {
+	instrument string
+	side       Side
+}
+
+// Manager diffs desired quotes against live mass quotes. It is safe for
+// concurrent use, and implements ws.Hook to follow the order streams and
+// MM protection.
+type Manager struct {
+	client      Client
+	params      types.MassQuoteParams
+	rateCount   int
+	ratePer     time.Duration
+	onPull      func(types.MMProtectionUpdate, error)
+	pullTimeout time.Duration
+	now         func() time.Time
+
+	// flushMu serializes requests, so a pull is sent after a mass quote in
+	// flight.
+	flushMu sync.Mutex
+
+	mu      sync.Mutex
+	desired map[key][]types.QuoteLevel
+	live    map[key][]types.QuoteLevel
+	// unknown holds sides whose last mass quote failed, so their state is
+	// not known. They are sent again by the next Flush.
+	unknown map[key]bool
+	sent    []time.Time
+	pulled  bool
+}
+
+// NewManager creates a manager that quotes through client.
+func NewManager(client Client, opts ...Option) *Manager {
+	m := &Manager{
+		client:      client,
+		pullTimeout: DefaultPullTimeout,
+		now:         time.Now,
+		desired:     make(map[key][]types.QuoteLevel),
+		live:        make(map[key][]types.QuoteLevel),
+		unknown:     make(map[key]bool),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Set replaces the desired quotes of an instrument. Levels are sent in the
+// given order. An empty side is pulled. Nothing is sent until Flush.
+func (m *Manager) Set(instrument string, bids, asks []types.QuoteLevel) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.setSide(key{instrument, SideBid}, bids)
+	m.setSide(key{instrument, SideAsk}, asks)
+}
+
+func (m *Manager) setSide(k key, levels []types.QuoteLevel) {
+	if len(levels) == 0 {
+		delete(m.desired, k)
+		return
+	}
+	m.desired[k] = slices.Clone(levels)
+}
+
+// Remove pulls both sides of an instrument on the next Flush.
+func (m *Manager) Remove(instrument string) {
+	m.Set(instrument, nil, nil)
+}
+
+// Live returns the levels of an instrument that are believed to be live.
+func (m *Manager) Live(instrument string) (bids, asks []types.QuoteLevel) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.Clone(m.live[key{instrument, SideBid}]), slices.Clone(m.live[key{instrument, SideAsk}])
+}
+
+// Pending reports whether the next Flush has anything to send.
+func (m *Manager) Pending() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.changes()) > 0
+}
+
+// Flush sends a mass quote with the instrument sides whose desired levels
+// differ from the live ones. A side is sent in full, replacing its live
+// levels; an unchanged side is left out. It returns an empty Result when
+// nothing changed, ErrThrottled when the rate limit is reached and
+// ErrPulled while the quotes are pulled.
+//
+// Levels that are rejected are not live, so the next Flush sends their
+// side again. When the request itself fails, the sides it carried are
+// sent again by the next Flush.
+func (m *Manager) Flush(ctx context.Context) (Result, error) {
+	m.flushMu.Lock()
+	defer m.flushMu.Unlock()
+
+	m.mu.Lock()
+	if m.pulled {
+		m.mu.Unlock()
+		return Result{}, ErrPulled
+	}
+	changes := m.changes()
+	if len(changes) == 0 {
+		m.mu.Unlock()
+		return Result{}, nil
+	}
+	if !m.allow() {
+		m.mu.Unlock()
+		return Result{}, ErrThrottled
+	}
+	levels := make(map[key][]types.QuoteLevel, len(changes))
+	for _, k := range changes {
+		levels[k] = m.desired[k]
+	}
+	m.mu.Unlock()
+
+	params := m.params
+	params.Quotes = quotes(changes, levels)
+	res, err := m.client.MassQuote(ctx, &params)
+	out := Result{Quotes: params.Quotes, NSuccess: res.NSuccess, NFail: res.NFail}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err != nil {
+		for _, k := range changes {
+			m.unknown[k] = true
+		}
+		return out, err
+	}
+	var rejected map[key][]bool
+	out.Rejections, rejected = match(changes, levels, res.Errors)
+	for _, k := range changes {
+		delete(m.unknown, k)
+		var live []types.QuoteLevel
+		for i, l := range levels[k] {
+			if rejected[k] == nil || !rejected[k][i] {
+				live = append(live, l)
+			}
+		}
+		if len(live) == 0 {
+			delete(m.live, k)
+		} else {
+			m.live[k] = live
+		}
+	}
+	return out, nil
+}
+
+// changes returns the sides to send, ordered by instrument with the bid
+// first. The caller holds m.mu.
+func (m *Manager) changes() []key {
+	seen := make(map[key]bool)
+	var out []key
+	for _, src := range []map[key][]types.QuoteLevel{m.desired, m.live} {
+		for k := range src {
+			if !seen[k] && !slices.Equal(m.desired[k], m.live[k]) {
+				seen[k] = true
+				out = append(out, k)
+			}
+		}
+	}
+	for k := range m.unknown {
+		if !seen[k] {
+			seen[k] = true
+			out = append(out, k)
+		}
+	}
+	slices.SortFunc(out, func(a, b key) int {
+		return cmp.Or(cmp.Compare(a.instrument, b.instrument), cmp.Compare(a.side, b.side))
+	})
+	return out
+}
+
+// allow records a mass quote if the rate limit permits one. The caller
+// holds m.mu.
+func (m *Manager) allow() bool {
+	if m.rateCount <= 0 {
+		return true
+	}
+	now := m.now()
+	cut := 0
+	for cut < len(m.sent) && now.Sub(m.sent[cut]) >= m.ratePer {
+		cut++
+	}
+	m.sent = m.sent[cut:]
+	if len(m.sent) >= m.rateCount {
+		return false
+	}
+	m.sent = append(m.sent, now)
+	return true
+}
+
+// quotes builds the instrument quotes for the sides in changes. A side
+// without levels is sent as an empty list, which pulls it.
+func quotes(changes []key, levels map[key][]types.QuoteLevel) []types.DoubleSidedQuote {
+	var out []types.DoubleSidedQuote
+	for _, k := range changes {
+		if len(out) == 0 || out[len(out)-1].I != k.instrument {
+			out = append(out, types.DoubleSidedQuote{I: k.instrument})
+		}
+		side := make([][2]float64, len(levels[k]))
+		for i, l := range levels[k] {
+			side[i] = [2]float64{l.Price, l.Amount}
+		}
+		if k.side == SideBid {
+			out[len(out)-1].B = side
+		} else {
+			out[len(out)-1].A = side
+		}
+	}
+	return out
+}
+
+// match ties quote errors back to the levels sent. The errors carry no
+// instrument, so each is matched to the next level sent with its side and
+// price, in the order of the request. It returns the rejections and, for
+// each side, which of its levels were rejected.
+func match(changes []key, levels map[key][]types.QuoteLevel, errs []types.QuoteError) ([]Rejection, map[key][]bool) {
+	type level struct {
+		k key
+		i int // index in levels[k], -1 for a side sent without levels
+	}
+	var sent []level
+	for _, k := range changes {
+		if len(levels[k]) == 0 {
+			sent = append(sent, level{k, -1})
+		}
+		for i := range levels[k] {
+			sent = append(sent, level{k, i})
+		}
+	}
+	matches := func(l level, e types.QuoteError) bool {
+		if string(l.k.side) != e.Side {
+			return false
+		}
+		if e.Price == nil {
+			return true
+		}
+		return l.i >= 0 && levels[l.k][l.i].Price == *e.Price
+	}
+
+	var out []Rejection
+	rejected := make(map[key][]bool)
+	next := 0
+	for _, e := range errs {
+		r := Rejection{Side: Side(e.Side), Price: e.Price, Code: e.Code, Message: e.Message}
+		for n := range sent {
+			j := (next + n) % len(sent)
+			if l := sent[j]; matches(l, e) {
+				r.Instrument, next = l.k.instrument, j+1
+				if rejected[l.k] == nil {
+					rejected[l.k] = make([]bool, len(levels[l.k]))
+				}
+				switch {
+				case e.Price == nil:
+					for i := range rejected[l.k] {
+						rejected[l.k][i] = true
+					}
+				case l.i >= 0:
+					rejected[l.k][l.i] = true
+				}
+				break
+			}
+		}
+		out = append(out, r)
+	}
+	return out, rejected
+}
+
+// Pull cancels all mass quotes and stops Flush until Resume is called. A
+// mass quote in flight completes before the cancel is sent.
+func (m *Manager) Pull(ctx context.Context) error {
+	m.mu.Lock()
+	m.pulled = true
+	m.mu.Unlock()
+
+	m.flushMu.Lock()
+	defer m.flushMu.Unlock()
+	if err := m.client.CancelMassQuote(ctx); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	m.clearLive()
+	m.mu.Unlock()
+	return nil
+}
+
+// Pulled reports whether the quotes are pulled.
+func (m *Manager) Pulled() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.pulled
+}
+
+// Resume lets Flush quote again after a pull. The next Flush sends every
+// desired quote.
+func (m *Manager) Resume() {
+	m.mu.Lock()
+	m.pulled = false
+	m.mu.Unlock()
+}
+
+// clearLive forgets all live quotes. The caller holds m.mu.
+func (m *Manager) clearLive() {
+	clear(m.live)
+	clear(m.unknown)
+}
+
+// UpdateOrders records order statuses, as received from an order
+// notification. Orders with the manager's label update the live level at
+// their instrument, side and price: a partial fill lowers its amount, and
+// a fill or a deletion by the exchange removes it. The next Flush then
+// restores the desired level.
+func (m *Manager) UpdateOrders(orders []types.OrderStatus) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, o := range orders {
+		if o.Label != m.params.Label || o.Persistent || o.Price == nil {
+			continue
+		}
+		k := key{o.InstrumentName, SideAsk}
+		if o.Direction == enums.DirectionBuy {
+			k.side = SideBid
+		}
+		levels := m.live[k]
+		i := slices.IndexFunc(levels, func(l types.QuoteLevel) bool { return l.Price == *o.Price })
+		if i < 0 {
+			continue
+		}
+		switch {
+		case o.Status.IsActive():
+			levels = slices.Clone(levels)
+			levels[i].Amount = o.RemainingAmount
+		case o.DeleteReason == enums.DeleteReasonClientCancel, o.DeleteReason == enums.DeleteReasonClientBulkCancel:
+			// Replaced or cancelled by a request, which the manager
+			// already accounts for.
+			continue
+		default:
+			levels = slices.Delete(slices.Clone(levels), i, i+1)
+		}
+		if len(levels) == 0 {
+			delete(m.live, k)
+		} else {
+			m.live[k] = levels
+		}
+	}
+}
+
+// OnRequest implements ws.Hook. It does nothing.
+func (m *Manager) OnRequest(string, any) {}
+
+// OnResult implements ws.Hook. A successful cancel_mass_quote or
+// cancel_session request, sent by anyone on the client, clears the live
+// quotes.
+func (m *Manager) OnResult(method string, _, _ any, err error) {
+	if err != nil {
+		return
+	}
+	switch method {
+	case "private/cancel_mass_quote", "private/cancel_session":
+		m.mu.Lock()
+		m.clearLive()
+		m.mu.Unlock()
+	}
+}
+
+// OnNotification implements ws.Hook. It records order notifications and
+// pulls all quotes when MM protection trips. The pull runs in the
+// background, since notifications are delivered on the connection's read
+// loop.
+func (m *Manager) OnNotification(_ string, v any) {
+	switch n := v.(type) {
+	case []types.OrderStatus:
+		m.UpdateOrders(n)
+	case types.MMProtectionUpdate:
+		if n.Reason != enums.MMProtectionReasonTriggered {
+			if m.onPull != nil {
+				m.onPull(n, nil)
+			}
+			return
+		}
+		m.mu.Lock()
+		m.pulled = true
+		m.mu.Unlock()
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), m.pullTimeout)
+			defer cancel()
+			err := m.Pull(ctx)
+			if m.onPull != nil {
+				m.onPull(n, err)
+			}
+		}()
+	}
+}
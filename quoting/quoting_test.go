@@ -0,0 +1,284 @@
+package quoting_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/amiwrpremium/go-thalex/enums"
+	"github.com/amiwrpremium/go-thalex/quoting"
+	"github.com/amiwrpremium/go-thalex/types"
+	"github.com/amiwrpremium/go-thalex/ws"
+)
+
+var (
+	_ quoting.Client = (*ws.Client)(nil)
+	_ ws.Hook        = (*quoting.Manager)(nil)
+)
+
+type fakeClient struct {
+	mu        sync.Mutex
+	sent      []*types.MassQuoteParams
+	result    types.DoubleSidedQuoteResult
+	err       error
+	cancelled int
+}
+
+func (f *fakeClient) MassQuote(_ context.Context, p *types.MassQuoteParams) (types.DoubleSidedQuoteResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent = append(f.sent, p)
+	res, err := f.result, f.err
+	f.result, f.err = types.DoubleSidedQuoteResult{}, nil
+	return res, err
+}
+
+func (f *fakeClient) CancelMassQuote(context.Context) error {
+	f.mu.Lock()
+	f.cancelled++
+	f.mu.Unlock()
+	return nil
+}
+
+func (f *fakeClient) last(t *testing.T) []types.DoubleSidedQuote {
+	t.Helper()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.sent) == 0 {
+		t.Fatal("nothing sent")
+	}
+	return f.sent[len(f.sent)-1].Quotes
+}
+
+func levels(pairs ...float64) []types.QuoteLevel {
+	var out []types.QuoteLevel
+	for i := 0; i < len(pairs); i += 2 {
+		out = append(out, types.QuoteLevel{Price: pairs[i], Amount: pairs[i+1]})
+	}
+	return out
+}
+
+func side(pairs ...float64) [][2]float64 {
+	out := [][2]float64{}
+	for i := 0; i < len(pairs); i += 2 {
+		out = append(out, [2]float64{pairs[i], pairs[i+1]})
+	}
+	return out
+}
+
+func flush(t *testing.T, m *quoting.Manager) quoting.Result {
+	t.Helper()
+	res, err := m.Flush(context.Background())
+	if err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	return res
+}
+
+func TestFlush_SendsOnlyChanges(t *testing.T) {
+	f := &fakeClient{}
+	label := types.NewMassQuoteParams(nil).WithLabel("mm").WithPostOnly(true)
+	m := quoting.NewManager(f, quoting.WithParams(*label))
+
+	m.Set("ETH-PERPETUAL", levels(3480, 1), levels(3520, 1))
+	m.Set("BTC-PERPETUAL", levels(95000, 0.1, 94900, 0.2), levels(95100, 0.1))
+	flush(t, m)
+	want := []types.DoubleSidedQuote{
+		{I: "BTC-PERPETUAL", B: side(95000, 0.1, 94900, 0.2), A: side(95100, 0.1)},
+		{I: "ETH-PERPETUAL", B: side(3480, 1), A: side(3520, 1)},
+	}
+	if got := f.last(t); !reflect.DeepEqual(got, want) {
+		t.Fatalf("sent %+v, want %+v", got, want)
+	}
+	if p := f.sent[0]; p.Label != "mm" || p.PostOnly == nil || !*p.PostOnly {
+		t.Errorf("params = %+v", p)
+	}
+
+	m.Set("ETH-PERPETUAL", levels(3480, 1), levels(3520, 1))
+	if m.Pending() {
+		t.Error("unchanged quotes pending")
+	}
+	if res := flush(t, m); len(res.Quotes) != 0 || len(f.sent) != 1 {
+		t.Fatalf("unchanged quotes sent: %+v", res.Quotes)
+	}
+
+	m.Set("BTC-PERPETUAL", levels(95000, 0.1, 94900, 0.2), levels(95200, 0.1))
+	m.Remove("ETH-PERPETUAL")
+	flush(t, m)
+	want = []types.DoubleSidedQuote{
+		{I: "BTC-PERPETUAL", A: side(95200, 0.1)},
+		{I: "ETH-PERPETUAL", B: side(), A: side()},
+	}
+	if got := f.last(t); !reflect.DeepEqual(got, want) {
+		t.Fatalf("sent %+v, want %+v", got, want)
+	}
+	if bids, asks := m.Live("ETH-PERPETUAL"); bids != nil || asks != nil {
+		t.Errorf("ETH still live: %v %v", bids, asks)
+	}
+	if bids, asks := m.Live("BTC-PERPETUAL"); len(bids) != 2 || asks[0].Price != 95200 {
+		t.Errorf("BTC live = %v %v", bids, asks)
+	}
+}
+
+func TestFlush_Rejections(t *testing.T) {
+	f := &fakeClient{}
+	m := quoting.NewManager(f)
+	m.Set("BTC-27JUN25-100000-C", levels(500, 1, 450, 1), nil)
+	m.Set("BTC-27JUN25-90000-P", levels(500, 1), levels(600, 1))
+
+	price := func(v float64) *float64 { return &v }
+	f.result = types.DoubleSidedQuoteResult{NSuccess: 2, NFail: 2, Errors: []types.QuoteError{
+		{Code: 1, Message: "collar", Side: "bid", Price: price(500)},
+		{Code: 1, Message: "collar", Side: "bid", Price: price(500)},
+	}}
+	res := flush(t, m)
+	if len(res.Rejections) != 2 || res.NFail != 2 {
+		t.Fatalf("rejections = %+v", res.Rejections)
+	}
+	for i, instr := range []string{"BTC-27JUN25-100000-C", "BTC-27JUN25-90000-P"} {
+		r := res.Rejections[i]
+		if r.Instrument != instr || r.Side != quoting.SideBid || *r.Price != 500 {
+			t.Errorf("rejection %d = %+v", i, r)
+		}
+	}
+	if bids, _ := m.Live("BTC-27JUN25-100000-C"); !reflect.DeepEqual(bids, levels(450, 1)) {
+		t.Errorf("call bids live = %v", bids)
+	}
+
+	// Only the sides with rejected levels are sent again.
+	flush(t, m)
+	want := []types.DoubleSidedQuote{
+		{I: "BTC-27JUN25-100000-C", B: side(500, 1, 450, 1)},
+		{I: "BTC-27JUN25-90000-P", B: side(500, 1)},
+	}
+	if got := f.last(t); !reflect.DeepEqual(got, want) {
+		t.Fatalf("sent %+v, want %+v", got, want)
+	}
+}
+
+func TestFlush_Throttle(t *testing.T) {
+	now := time.Unix(1000, 0)
+	f := &fakeClient{}
+	m := quoting.NewManager(f, quoting.WithRate(2, time.Second), quoting.WithClock(func() time.Time { return now }))
+
+	for i := range 2 {
+		m.Set("BTC-PERPETUAL", levels(95000+float64(i), 1), nil)
+		flush(t, m)
+	}
+	m.Set("BTC-PERPETUAL", levels(95005, 1), nil)
+	if _, err := m.Flush(context.Background()); !errors.Is(err, quoting.ErrThrottled) {
+		t.Fatalf("err = %v, want ErrThrottled", err)
+	}
+	// Changes made while throttled are coalesced.
+	m.Set("BTC-PERPETUAL", levels(95006, 1), nil)
+	now = now.Add(time.Second)
+	flush(t, m)
+	if len(f.sent) != 3 || !reflect.DeepEqual(f.last(t)[0].B, side(95006, 1)) {
+		t.Errorf("sent %d requests, last %+v", len(f.sent), f.last(t))
+	}
+}
+
+func TestFlush_RequestError(t *testing.T) {
+	f := &fakeClient{}
+	m := quoting.NewManager(f)
+	m.Set("BTC-PERPETUAL", levels(95000, 1), levels(95100, 1))
+	f.err = errors.New("connection lost")
+	if _, err := m.Flush(context.Background()); err == nil {
+		t.Fatal("Flush succeeded")
+	}
+	if !m.Pending() {
+		t.Fatal("failed quotes not pending")
+	}
+	flush(t, m)
+	if got := f.last(t); len(got) != 1 || got[0].B == nil || got[0].A == nil {
+		t.Errorf("resent %+v", got)
+	}
+	if m.Pending() {
+		t.Error("quotes pending after success")
+	}
+}
+
+func TestUpdateOrders(t *testing.T) {
+	f := &fakeClient{}
+	m := quoting.NewManager(f, quoting.WithParams(types.MassQuoteParams{Label: "mm"}))
+	m.Set("BTC-PERPETUAL", levels(95000, 1, 94900, 1), levels(95100, 1))
+	flush(t, m)
+
+	price := func(v float64) *float64 { return &v }
+	m.OnNotification(types.ChannelSessionOrders, []types.OrderStatus{
+		// Partially filled.
+		{InstrumentName: "BTC-PERPETUAL", Label: "mm", Direction: enums.DirectionBuy, Price: price(95000),
+			Amount: 1, RemainingAmount: 0.4, Status: enums.OrderStatusPartiallyFilled},
+		// Replaced by the manager.
+		{InstrumentName: "BTC-PERPETUAL", Label: "mm", Direction: enums.DirectionBuy, Price: price(94900),
+			Status: enums.OrderStatusCancelled, DeleteReason: enums.DeleteReasonClientCancel},
+		// Another strategy's order.
+		{InstrumentName: "BTC-PERPETUAL", Label: "other", Direction: enums.DirectionSell, Price: price(95100),
+			Status: enums.OrderStatusFilled},
+	})
+	bids, asks := m.Live("BTC-PERPETUAL")
+	if !reflect.DeepEqual(bids, levels(95000, 0.4, 94900, 1)) || len(asks) != 1 {
+		t.Fatalf("live = %v %v", bids, asks)
+	}
+
+	m.OnNotification(types.ChannelSessionOrders, []types.OrderStatus{
+		{InstrumentName: "BTC-PERPETUAL", Label: "mm", Direction: enums.DirectionSell, Price: price(95100),
+			Amount: 1, Status: enums.OrderStatusFilled, DeleteReason: enums.DeleteReasonFilled},
+	})
+	if _, asks := m.Live("BTC-PERPETUAL"); asks != nil {
+		t.Fatalf("filled ask still live: %v", asks)
+	}
+	flush(t, m)
+	want := []types.DoubleSidedQuote{{I: "BTC-PERPETUAL", B: side(95000, 1, 94900, 1), A: side(95100, 1)}}
+	if got := f.last(t); !reflect.DeepEqual(got, want) {
+		t.Fatalf("sent %+v, want %+v", got, want)
+	}
+
+	m.OnResult("private/cancel_session", nil, nil, nil)
+	if bids, asks := m.Live("BTC-PERPETUAL"); bids != nil || asks != nil {
+		t.Errorf("live after cancel_session = %v %v", bids, asks)
+	}
+}
+
+func TestMMProtection(t *testing.T) {
+	f := &fakeClient{}
+	pulled := make(chan error, 1)
+	m := quoting.NewManager(f, quoting.WithPullHandler(func(u types.MMProtectionUpdate, err error) {
+		if u.Reason == enums.MMProtectionReasonTriggered {
+			pulled <- err
+		}
+	}))
+	m.Set("BTC-PERPETUAL", levels(95000, 1), levels(95100, 1))
+	flush(t, m)
+
+	m.OnNotification(types.ChannelSessionMMProtection, types.MMProtectionUpdate{
+		Product: "FBTCUSD", Reason: enums.MMProtectionReasonTriggered,
+	})
+	select {
+	case err := <-pulled:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("quotes not pulled")
+	}
+	if f.cancelled != 1 || !m.Pulled() {
+		t.Fatalf("cancelled %d times, pulled %v", f.cancelled, m.Pulled())
+	}
+	if bids, asks := m.Live("BTC-PERPETUAL"); bids != nil || asks != nil {
+		t.Errorf("live after pull = %v %v", bids, asks)
+	}
+	if _, err := m.Flush(context.Background()); !errors.Is(err, quoting.ErrPulled) {
+		t.Fatalf("err = %v, want ErrPulled", err)
+	}
+
+	m.Resume()
+	flush(t, m)
+	want := []types.DoubleSidedQuote{{I: "BTC-PERPETUAL", B: side(95000, 1), A: side(95100, 1)}}
+	if got := f.last(t); !reflect.DeepEqual(got, want) {
+		t.Fatalf("sent %+v, want %+v", got, want)
+	}
+}
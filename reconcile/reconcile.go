@@ -0,0 +1,285 @@
+// Package reconcile compares what a process believes it owns on the exchange
+// with what is actually live, typically right after a restart.
+//
+// A Reconciler pulls the open orders, conditional orders, bots, market
+// maker RFQ quotes and positions of the account and matches them to the
+// intents the process persisted, by ID, client order ID and label. Live
+// items no intent refers to are orphans; intents with nothing live are
+// ghosts. A Policy per kind decides whether orphans are adopted, cancelled
+// or fail the reconciliation.
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/amiwrpremium/go-thalex/types"
+)
+
+// ErrOrphans is returned by Run when an orphan, or a position that differs
+// from its intent, has PolicyFail.
+var ErrOrphans = errors.New("reconcile: unexpected live items")
+
+// Client reads and cancels the account's live items. rest.Client and
+// ws.Client satisfy it.
+type Client interface {
+	OpenOrders(ctx context.Context, instrumentName string) ([]types.OrderStatus, error)
+	ConditionalOrders(ctx context.Context) ([]types.ConditionalOrder, error)
+	Bots(ctx context.Context, includeInactive bool) ([]types.Bot, error)
+	MMRfqQuotes(ctx context.Context) ([]types.RfqOrder, error)
+	Portfolio(ctx context.Context) ([]types.PortfolioEntry, error)
+
+	Cancel(ctx context.Context, params *types.CancelOrderParams) (types.OrderStatus, error)
+	CancelConditionalOrder(ctx context.Context, orderID string) error
+	CancelBot(ctx context.Context, botID string) error
+	MMRfqDeleteQuote(ctx context.Context, params *types.RfqQuoteDeleteParams) error
+}
+
+// Policy decides what Run does with orphans.
+type Policy int
+
+const (
+	// PolicyFail makes Run return ErrOrphans without acting on anything.
+	PolicyFail Policy = iota
+	// PolicyAdopt accepts orphans as the process's own and passes them to
+	// the handler set with WithAdoptHandler.
+	PolicyAdopt
+	// PolicyCancel cancels orphans. Positions cannot be cancelled.
+	PolicyCancel
+)
+
+// positionTolerance is the largest difference between a position and its
+// intent that still counts as a match.
+const positionTolerance = 1e-9
+
+// Option configures a Reconciler.
+type Option func(*Reconciler)
+
+// WithPolicy sets the policy for orphans of a kind. The default is
+// PolicyFail for every kind. A position that differs from its intent is
+// treated as an orphan.
+func WithPolicy(kind Kind, p Policy) Option {
+	return func(r *Reconciler) { r.policies[kind] = p }
+}
+
+// WithAdoptHandler registers a callback for every item adopted under
+// PolicyAdopt, for example to track adopted orders with oms.Manager.Track.
+// An error leaves the item out of Report.Adopted and is returned by Run.
+func WithAdoptHandler(fn func(Item) error) Option {
+	return func(r *Reconciler) { r.onAdopt = fn }
+}
+
+// Reconciler matches the account's live items to persisted intents.
+type Reconciler struct {
+	client   Client
+	policies map[Kind]Policy
+	onAdopt  func(Item) error
+}
+
+// NewReconciler returns a Reconciler that reads the account through client.
+func NewReconciler(client Client, opts ...Option) *Reconciler {
+	r := &Reconciler{client: client, policies: make(map[Kind]Policy)}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Run fetches the account's live items, matches them to intents and applies
+// the policies to the orphans.
+//
+// If any orphan has PolicyFail, Run returns the report with ErrOrphans
+// before adopting or cancelling anything. Failed adoptions and
+// cancellations do not stop the others; their errors are joined. The
+// report is non-nil unless fetching fails.
+func (r *Reconciler) Run(ctx context.Context, intents []Intent) (*Report, error) {
+	if r.policies[KindPosition] == PolicyCancel {
+		return nil, errors.New("reconcile: positions cannot be cancelled")
+	}
+	live, inactive, err := r.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rep := &Report{}
+	matched := make([]bool, len(intents))
+	var unexpected []Item
+	for _, it := range live {
+		i := find(intents, matched, it)
+		if i < 0 {
+			rep.Orphans = append(rep.Orphans, it)
+			unexpected = append(unexpected, it)
+			continue
+		}
+		matched[i] = true
+		m := Match{Intent: intents[i], Item: it}
+		if it.Kind == KindPosition && math.Abs(it.Position.Position-intents[i].Position) > positionTolerance {
+			rep.Mismatched = append(rep.Mismatched, m)
+			unexpected = append(unexpected, it)
+			continue
+		}
+		rep.Matched = append(rep.Matched, m)
+	}
+	for i, in := range intents {
+		if matched[i] || (in.Kind == KindPosition && in.Position == 0) {
+			continue
+		}
+		g := Ghost{Intent: in}
+		for _, it := range inactive {
+			if in.matches(it) {
+				g.Item = &it
+				break
+			}
+		}
+		rep.Ghosts = append(rep.Ghosts, g)
+	}
+
+	failed := 0
+	for _, it := range unexpected {
+		if r.policies[it.Kind] == PolicyFail {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return rep, fmt.Errorf("%w: %d", ErrOrphans, failed)
+	}
+
+	var errs []error
+	for _, it := range unexpected {
+		switch r.policies[it.Kind] {
+		case PolicyAdopt:
+			if r.onAdopt != nil {
+				if err := r.onAdopt(it); err != nil {
+					errs = append(errs, fmt.Errorf("reconcile: adopt %s %s: %w", it.Kind, it.ID, err))
+					continue
+				}
+			}
+			rep.Adopted = append(rep.Adopted, it)
+		case PolicyCancel:
+			if err := r.cancel(ctx, it); err != nil {
+				errs = append(errs, fmt.Errorf("reconcile: cancel %s %s: %w", it.Kind, it.ID, err))
+				continue
+			}
+			rep.Cancelled = append(rep.Cancelled, it)
+		}
+	}
+	return rep, errors.Join(errs...)
+}
+
+// find returns the index of the intent that it belongs to, or -1. Intents
+// naming a single item take precedence over label-only intents, and match
+// at most one item.
+func find(intents []Intent, matched []bool, it Item) int {
+	for i, in := range intents {
+		if (in.specific() || in.Kind == KindPosition) && !matched[i] && in.matches(it) {
+			return i
+		}
+	}
+	for i, in := range intents {
+		if !in.specific() && in.Kind != KindPosition && in.matches(it) {
+			return i
+		}
+	}
+	return -1
+}
+
+// matches reports whether the intent refers to it.
+func (in Intent) matches(it Item) bool {
+	switch {
+	case in.Kind != it.Kind:
+		return false
+	case in.Kind == KindPosition:
+		return in.Instrument == it.Instrument
+	case in.specific():
+		return (in.ID != "" && in.ID == it.ID) ||
+			(in.ClientOrderID != nil && it.ClientOrderID != nil && *in.ClientOrderID == *it.ClientOrderID)
+	}
+	return in.Label != "" && in.Label == it.Label
+}
+
+// fetch returns the account's live items, and the inactive conditional
+// orders and bots the exchange still reports.
+func (r *Reconciler) fetch(ctx context.Context) (live, inactive []Item, err error) {
+	orders, err := r.client.OpenOrders(ctx, "")
+	if err != nil {
+		return nil, nil, fmt.Errorf("reconcile: open orders: %w", err)
+	}
+	conditionals, err := r.client.ConditionalOrders(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reconcile: conditional orders: %w", err)
+	}
+	bots, err := r.client.Bots(ctx, true)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reconcile: bots: %w", err)
+	}
+	quotes, err := r.client.MMRfqQuotes(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reconcile: quotes: %w", err)
+	}
+	positions, err := r.client.Portfolio(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reconcile: portfolio: %w", err)
+	}
+
+	for i := range orders {
+		o := &orders[i]
+		live = append(live, Item{
+			Kind: KindOrder, ID: o.OrderID, ClientOrderID: o.ClientOrderID,
+			Label: o.Label, Instrument: o.InstrumentName, Order: o,
+		})
+	}
+	for i := range conditionals {
+		c := &conditionals[i]
+		it := Item{
+			Kind: KindConditional, ID: c.OrderID, Label: c.Label,
+			Instrument: c.InstrumentName, Conditional: c,
+		}
+		if c.Status.IsActive() {
+			live = append(live, it)
+		} else {
+			inactive = append(inactive, it)
+		}
+	}
+	for i := range bots {
+		b := &bots[i]
+		it := Item{Kind: KindBot, ID: b.BotID, Label: b.Label, Instrument: b.InstrumentName, Bot: b}
+		if b.Status.IsActive() {
+			live = append(live, it)
+		} else {
+			inactive = append(inactive, it)
+		}
+	}
+	for i := range quotes {
+		q := &quotes[i]
+		live = append(live, Item{
+			Kind: KindQuote, ID: q.OrderID, ClientOrderID: q.ClientOrderID,
+			Label: q.Label, Quote: q,
+		})
+	}
+	for i := range positions {
+		p := &positions[i]
+		if p.Position == 0 {
+			continue
+		}
+		live = append(live, Item{Kind: KindPosition, ID: p.InstrumentName, Instrument: p.InstrumentName, Position: p})
+	}
+	return live, inactive, nil
+}
+
+// cancel cancels a live item.
+func (r *Reconciler) cancel(ctx context.Context, it Item) error {
+	switch it.Kind {
+	case KindOrder:
+		_, err := r.client.Cancel(ctx, types.CancelByOrderID(it.ID))
+		return err
+	case KindConditional:
+		return r.client.CancelConditionalOrder(ctx, it.ID)
+	case KindBot:
+		return r.client.CancelBot(ctx, it.ID)
+	case KindQuote:
+		return r.client.MMRfqDeleteQuote(ctx, &types.RfqQuoteDeleteParams{OrderID: it.ID})
+	}
+	return fmt.Errorf("%s cannot be cancelled", it.Kind)
+}
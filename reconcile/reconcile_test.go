@@ -0,0 +1,289 @@
+package reconcile_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/amiwrpremium/go-thalex/enums"
+	"github.com/amiwrpremium/go-thalex/oms"
+	"github.com/amiwrpremium/go-thalex/reconcile"
+	"github.com/amiwrpremium/go-thalex/rest"
+	"github.com/amiwrpremium/go-thalex/types"
+	"github.com/amiwrpremium/go-thalex/ws"
+)
+
+var (
+	_ reconcile.Client = (*rest.Client)(nil)
+	_ reconcile.Client = (*ws.Client)(nil)
+)
+
+// fakeClient serves fixed account state and records cancellations.
+type fakeClient struct {
+	orders       []types.OrderStatus
+	conditionals []types.ConditionalOrder
+	bots         []types.Bot
+	quotes       []types.RfqOrder
+	positions    []types.PortfolioEntry
+	fetchErr     error
+	cancelErr    error
+
+	inactiveBots bool
+	cancelled    []string
+}
+
+func (f *fakeClient) OpenOrders(_ context.Context, instrument string) ([]types.OrderStatus, error) {
+	if instrument != "" {
+		panic("OpenOrders called for a single instrument")
+	}
+	return f.orders, nil
+}
+
+func (f *fakeClient) ConditionalOrders(context.Context) ([]types.ConditionalOrder, error) {
+	return f.conditionals, nil
+}
+
+func (f *fakeClient) Bots(_ context.Context, includeInactive bool) ([]types.Bot, error) {
+	f.inactiveBots = includeInactive
+	return f.bots, f.fetchErr
+}
+
+func (f *fakeClient) MMRfqQuotes(context.Context) ([]types.RfqOrder, error) {
+	return f.quotes, nil
+}
+
+func (f *fakeClient) Portfolio(context.Context) ([]types.PortfolioEntry, error) {
+	return f.positions, nil
+}
+
+func (f *fakeClient) Cancel(_ context.Context, p *types.CancelOrderParams) (types.OrderStatus, error) {
+	f.cancelled = append(f.cancelled, "order "+p.OrderID)
+	return types.OrderStatus{}, f.cancelErr
+}
+
+func (f *fakeClient) CancelConditionalOrder(_ context.Context, orderID string) error {
+	f.cancelled = append(f.cancelled, "conditional "+orderID)
+	return nil
+}
+
+func (f *fakeClient) CancelBot(_ context.Context, botID string) error {
+	f.cancelled = append(f.cancelled, "bot "+botID)
+	return nil
+}
+
+func (f *fakeClient) MMRfqDeleteQuote(_ context.Context, p *types.RfqQuoteDeleteParams) error {
+	f.cancelled = append(f.cancelled, "quote "+p.OrderID)
+	return nil
+}
+
+func ptr[T any](v T) *T { return &v }
+
+// account has one item of each kind the intents below account for, and one
+// of each they do not.
+func account() *fakeClient {
+	return &fakeClient{
+		orders: []types.OrderStatus{
+			{OrderID: "o1", ClientOrderID: ptr[uint64](1), Label: "mm", Status: enums.OrderStatusOpen},
+			{OrderID: "o2", ClientOrderID: ptr[uint64](2), Label: "grid", Status: enums.OrderStatusOpen},
+			{OrderID: "o3", ClientOrderID: ptr[uint64](3), Label: "grid", Status: enums.OrderStatusOpen},
+			{OrderID: "o4", Label: "manual", Status: enums.OrderStatusOpen},
+		},
+		conditionals: []types.ConditionalOrder{
+			{OrderID: "c1", Label: "stop", Status: enums.ConditionalOrderStatusActive},
+			{OrderID: "c2", Status: enums.ConditionalOrderStatusCreated},
+			{OrderID: "c3", Label: "gone", Status: enums.ConditionalOrderStatusConverted},
+		},
+		bots: []types.Bot{
+			{BotID: "b1", Status: enums.BotStatusActive},
+			{BotID: "b2", Status: enums.BotStatusStopped, StopReason: enums.BotStopReason("end_time")},
+		},
+		quotes: []types.RfqOrder{
+			{OrderID: "q1", ClientOrderID: ptr[uint64](10)},
+		},
+		positions: []types.PortfolioEntry{
+			{InstrumentName: "BTC-PERPETUAL", Position: 1},
+			{InstrumentName: "ETH-PERPETUAL", Position: -2},
+			{InstrumentName: "SOL-PERPETUAL", Position: 3},
+			{InstrumentName: "XRP-PERPETUAL"},
+		},
+	}
+}
+
+func intents() []reconcile.Intent {
+	return []reconcile.Intent{
+		{Kind: reconcile.KindOrder, ClientOrderID: ptr[uint64](1)},
+		{Kind: reconcile.KindOrder, Label: "grid"},
+		{Kind: reconcile.KindOrder, ClientOrderID: ptr[uint64](99)},
+		{Kind: reconcile.KindConditional, ID: "c1"},
+		{Kind: reconcile.KindConditional, Label: "gone"},
+		{Kind: reconcile.KindBot, ID: "b2"},
+		{Kind: reconcile.KindPosition, Instrument: "BTC-PERPETUAL", Position: 1},
+		{Kind: reconcile.KindPosition, Instrument: "ETH-PERPETUAL", Position: -1},
+		{Kind: reconcile.KindPosition, Instrument: "XRP-PERPETUAL", Position: 0},
+	}
+}
+
+func ids(items []reconcile.Item) []string {
+	var out []string
+	for _, it := range items {
+		out = append(out, it.Kind.String()+" "+it.ID)
+	}
+	return out
+}
+
+func TestRun_Report(t *testing.T) {
+	client := account()
+	in := intents()
+	rep, err := reconcile.NewReconciler(client, reconcile.WithPolicy(reconcile.KindOrder, reconcile.PolicyAdopt),
+		reconcile.WithPolicy(reconcile.KindConditional, reconcile.PolicyAdopt),
+		reconcile.WithPolicy(reconcile.KindBot, reconcile.PolicyAdopt),
+		reconcile.WithPolicy(reconcile.KindQuote, reconcile.PolicyAdopt),
+		reconcile.WithPolicy(reconcile.KindPosition, reconcile.PolicyAdopt),
+	).Run(context.Background(), in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !client.inactiveBots {
+		t.Error("Bots was not asked for inactive bots")
+	}
+
+	var matched []string
+	for _, m := range rep.Matched {
+		matched = append(matched, m.Item.Kind.String()+" "+m.Item.ID)
+	}
+	wantMatched := []string{"order o1", "order o2", "order o3", "conditional order c1", "position BTC-PERPETUAL"}
+	if !reflect.DeepEqual(matched, wantMatched) {
+		t.Errorf("Matched = %q, want %q", matched, wantMatched)
+	}
+	if len(rep.Mismatched) != 1 || rep.Mismatched[0].Item.ID != "ETH-PERPETUAL" || rep.Mismatched[0].Intent.Position != -1 {
+		t.Errorf("Mismatched = %+v", rep.Mismatched)
+	}
+	wantOrphans := []string{"order o4", "conditional order c2", "bot b1", "quote q1", "position SOL-PERPETUAL"}
+	if got := ids(rep.Orphans); !reflect.DeepEqual(got, wantOrphans) {
+		t.Errorf("Orphans = %q, want %q", got, wantOrphans)
+	}
+	if len(rep.Ghosts) != 3 {
+		t.Fatalf("Ghosts = %+v, want 3", rep.Ghosts)
+	}
+	if g := rep.Ghosts[0]; g.Intent.ClientOrderID == nil || *g.Intent.ClientOrderID != 99 || g.Item != nil {
+		t.Errorf("Ghosts[0] = %+v", g)
+	}
+	if g := rep.Ghosts[1]; g.Item == nil || g.Item.Conditional.Status != enums.ConditionalOrderStatusConverted {
+		t.Errorf("Ghosts[1] = %+v, want the converted conditional order", g)
+	}
+	if g := rep.Ghosts[2]; g.Item == nil || g.Item.Bot.StopReason != "end_time" {
+		t.Errorf("Ghosts[2] = %+v, want the stopped bot", g)
+	}
+	if rep.Clean() {
+		t.Error("Clean() = true")
+	}
+	// Mismatched positions are adopted with the orphans, in exchange order.
+	wantAdopted := []string{"order o4", "conditional order c2", "bot b1", "quote q1", "position ETH-PERPETUAL", "position SOL-PERPETUAL"}
+	if got := ids(rep.Adopted); !reflect.DeepEqual(got, wantAdopted) {
+		t.Errorf("Adopted = %q, want %q", got, wantAdopted)
+	}
+	if len(client.cancelled) != 0 {
+		t.Errorf("cancelled %q under PolicyAdopt", client.cancelled)
+	}
+}
+
+func TestRun_Clean(t *testing.T) {
+	client := &fakeClient{orders: []types.OrderStatus{{OrderID: "o1", Label: "mm"}}}
+	rep, err := reconcile.NewReconciler(client).Run(context.Background(),
+		[]reconcile.Intent{{Kind: reconcile.KindOrder, ID: "o1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rep.Clean() || len(rep.Matched) != 1 {
+		t.Errorf("report = %+v, want one clean match", rep)
+	}
+}
+
+func TestRun_Fail(t *testing.T) {
+	client := account()
+	rep, err := reconcile.NewReconciler(client,
+		reconcile.WithPolicy(reconcile.KindOrder, reconcile.PolicyCancel),
+	).Run(context.Background(), intents())
+	if !errors.Is(err, reconcile.ErrOrphans) {
+		t.Fatalf("error = %v, want ErrOrphans", err)
+	}
+	if rep == nil || len(rep.Orphans) != 5 {
+		t.Fatalf("report = %+v", rep)
+	}
+	if len(client.cancelled) != 0 || len(rep.Cancelled) != 0 {
+		t.Errorf("cancelled %q despite failing", client.cancelled)
+	}
+}
+
+func TestRun_Cancel(t *testing.T) {
+	client := account()
+	client.positions = nil
+	r := reconcile.NewReconciler(client,
+		reconcile.WithPolicy(reconcile.KindOrder, reconcile.PolicyCancel),
+		reconcile.WithPolicy(reconcile.KindConditional, reconcile.PolicyCancel),
+		reconcile.WithPolicy(reconcile.KindBot, reconcile.PolicyCancel),
+		reconcile.WithPolicy(reconcile.KindQuote, reconcile.PolicyCancel),
+	)
+	rep, err := r.Run(context.Background(), intents())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"order o4", "conditional c2", "bot b1", "quote q1"}
+	if !reflect.DeepEqual(client.cancelled, want) {
+		t.Errorf("cancelled %q, want %q", client.cancelled, want)
+	}
+	if len(rep.Cancelled) != 4 {
+		t.Errorf("Cancelled = %q", ids(rep.Cancelled))
+	}
+
+	client.cancelled = nil
+	client.cancelErr = errors.New("boom")
+	rep, err = r.Run(context.Background(), intents())
+	if err == nil || !errors.Is(err, client.cancelErr) {
+		t.Fatalf("error = %v, want the cancel error", err)
+	}
+	if got := ids(rep.Cancelled); !reflect.DeepEqual(got, []string{"conditional order c2", "bot b1", "quote q1"}) {
+		t.Errorf("Cancelled = %q after a failed order cancel", got)
+	}
+}
+
+func TestRun_Errors(t *testing.T) {
+	_, err := reconcile.NewReconciler(account(),
+		reconcile.WithPolicy(reconcile.KindPosition, reconcile.PolicyCancel),
+	).Run(context.Background(), nil)
+	if err == nil {
+		t.Error("PolicyCancel for positions was accepted")
+	}
+
+	client := account()
+	client.fetchErr = errors.New("down")
+	rep, err := reconcile.NewReconciler(client).Run(context.Background(), nil)
+	if !errors.Is(err, client.fetchErr) || rep != nil {
+		t.Errorf("Run = %v, %v; want the fetch error", rep, err)
+	}
+}
+
+func TestRun_AdoptIntoOMS(t *testing.T) {
+	client := account()
+	client.conditionals, client.bots, client.quotes, client.positions = nil, nil, nil, nil
+	m := oms.NewManager(nil)
+	rep, err := reconcile.NewReconciler(client,
+		reconcile.WithPolicy(reconcile.KindOrder, reconcile.PolicyAdopt),
+		reconcile.WithAdoptHandler(func(it reconcile.Item) error {
+			_, err := m.Track(*it.Order)
+			return err
+		}),
+	).Run(context.Background(), []reconcile.Intent{{Kind: reconcile.KindOrder, Label: "grid"}})
+
+	// o4 has no client order ID, so the OMS refuses it.
+	if err == nil {
+		t.Error("adopting an order without a client order ID succeeded")
+	}
+	if got := ids(rep.Adopted); !reflect.DeepEqual(got, []string{"order o1"}) {
+		t.Errorf("Adopted = %q", got)
+	}
+	if _, ok := m.Get(1); !ok {
+		t.Error("adopted order is not tracked")
+	}
+}
@@ -0,0 +1,118 @@
+package reconcile
+
+import (
+	"strconv"
+
+	"github.com/amiwrpremium/go-thalex/types"
+)
+
+// Kind identifies what an Intent or Item refers to.
+type Kind int
+
+const (
+	// KindOrder is a resting order.
+	KindOrder Kind = iota
+	// KindConditional is a conditional (stop, stop limit or bracket) order.
+	KindConditional
+	// KindBot is a bot.
+	KindBot
+	// KindQuote is a market maker RFQ quote.
+	KindQuote
+	// KindPosition is a position in an instrument.
+	KindPosition
+)
+
+// String returns the name of the kind.
+func (k Kind) String() string {
+	switch k {
+	case KindOrder:
+		return "order"
+	case KindConditional:
+		return "conditional order"
+	case KindBot:
+		return "bot"
+	case KindQuote:
+		return "quote"
+	case KindPosition:
+		return "position"
+	}
+	return "Kind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// Intent is something the process believes it owns, as persisted before a
+// restart.
+//
+// An intent with an ID or ClientOrderID refers to one exchange item. An
+// intent with only a Label claims every item of its kind with that label.
+// A KindPosition intent refers to the position in Instrument.
+type Intent struct {
+	Kind Kind
+	// ID is the exchange ID: the order ID of an order, conditional order or
+	// quote, or the bot ID of a bot.
+	ID string
+	// ClientOrderID identifies an order or quote.
+	ClientOrderID *uint64
+	// Label is the label the item was created with.
+	Label string
+	// Instrument and Position describe the expected position of a
+	// KindPosition intent.
+	Instrument string
+	Position   float64
+}
+
+// specific reports whether the intent refers to a single item.
+func (in Intent) specific() bool {
+	return in.ID != "" || in.ClientOrderID != nil
+}
+
+// Item is something live on the exchange. The field matching its Kind holds
+// the exchange record.
+type Item struct {
+	Kind Kind
+	// ID is the exchange ID of the item, or the instrument of a position.
+	ID            string
+	ClientOrderID *uint64
+	Label         string
+	Instrument    string
+
+	Order       *types.OrderStatus
+	Conditional *types.ConditionalOrder
+	Bot         *types.Bot
+	Quote       *types.RfqOrder
+	Position    *types.PortfolioEntry
+}
+
+// Match pairs an intent with the exchange item it refers to.
+type Match struct {
+	Intent Intent
+	Item   Item
+}
+
+// Ghost is an intent with nothing live on the exchange.
+type Ghost struct {
+	Intent Intent
+	// Item is the inactive exchange record of the intent, such as a stopped
+	// bot or a converted conditional order, if the exchange still reports it.
+	Item *Item
+}
+
+// Report is the outcome of a reconciliation.
+type Report struct {
+	// Matched lists the live items that belong to an intent.
+	Matched []Match
+	// Mismatched lists positions whose size differs from the intent.
+	Mismatched []Match
+	// Orphans lists the live items no intent refers to.
+	Orphans []Item
+	// Ghosts lists the intents with nothing live on the exchange.
+	Ghosts []Ghost
+	// Adopted and Cancelled list the orphans, and mismatched positions, that
+	// the policies acted on.
+	Adopted   []Item
+	Cancelled []Item
+}
+
+// Clean reports whether the exchange matched the intents exactly.
+func (r *Report) Clean() bool {
+	return len(r.Mismatched) == 0 && len(r.Orphans) == 0 && len(r.Ghosts) == 0
+}
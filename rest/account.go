@@ -5,6 +5,7 @@ import (
 	"net/url"
 	"strconv"
 
+	"github.com/amiwrpremium/go-thalex/internal/account"
 	"github.com/amiwrpremium/go-thalex/types"
 )
 
@@ -46,3 +47,22 @@ func (c *Client) RequiredMarginForOrder(ctx context.Context, instrumentName stri
 	err := c.transport.DoPrivateGET(ctx, "/private/required_margin_for_order", q, &result)
 	return result, err
 }
+
+// AccountSummaries retrieves the account summary of each given account
+// concurrently. Results are keyed by account number; accounts whose request
+// failed are omitted and their errors joined into the returned error.
+// Use [types.MergeAccountSummaries] to aggregate the results.
+func (c *Client) AccountSummaries(ctx context.Context, accounts ...string) (map[string]types.AccountSummary, error) {
+	return account.FanOut(ctx, accounts, func(ctx context.Context, _ string) (types.AccountSummary, error) {
+		return c.AccountSummary(ctx)
+	})
+}
+
+// Portfolios retrieves the portfolio of each given account concurrently.
+// Results are keyed by account number; accounts whose request failed are
+// omitted and their errors joined into the returned error.
+func (c *Client) Portfolios(ctx context.Context, accounts ...string) (map[string][]types.PortfolioEntry, error) {
+	return account.FanOut(ctx, accounts, func(ctx context.Context, _ string) ([]types.PortfolioEntry, error) {
+		return c.Portfolio(ctx)
+	})
+}
@@ -255,3 +255,49 @@ func TestAccountSummary_WithAccountNumber(t *testing.T) {
 		t.Errorf("expected margin=50000, got %f", result.Margin)
 	}
 }
+
+func TestAccountSummaries_RoutesPerAccount(t *testing.T) {
+	c := newTestClientWithAuth(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/private/account_summary" {
+			t.Errorf("expected path /private/account_summary, got %s", r.URL.Path)
+		}
+		switch r.Header.Get("X-Thalex-Account") {
+		case "A1":
+			w.Write(wrapResult(t, types.AccountSummary{Margin: 100}))
+		case "A2":
+			w.Write(wrapResult(t, types.AccountSummary{Margin: 200}))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write(apiErrorJSON(10001, "unknown account"))
+		}
+	})
+
+	result, err := c.AccountSummaries(context.Background(), "A1", "A2", "A3")
+	if err == nil {
+		t.Fatal("expected error for unknown account A3")
+	}
+	if len(result) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(result))
+	}
+	if result["A1"].Margin != 100 || result["A2"].Margin != 200 {
+		t.Errorf("unexpected results: %+v", result)
+	}
+	if merged := types.MergeAccountSummaries(result["A1"], result["A2"]); merged.Margin != 300 {
+		t.Errorf("merged margin = %v, want 300", merged.Margin)
+	}
+}
+
+func TestPortfolios_RoutesPerAccount(t *testing.T) {
+	c := newTestClientWithAuth(t, func(w http.ResponseWriter, r *http.Request) {
+		acct := r.Header.Get("X-Thalex-Account")
+		w.Write(wrapResult(t, []types.PortfolioEntry{{InstrumentName: acct + "-PERP", Position: 1}}))
+	})
+
+	result, err := c.Portfolios(context.Background(), "A1", "A2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := result["A2"][0].InstrumentName; got != "A2-PERP" {
+		t.Errorf("A2 portfolio instrument = %q, want A2-PERP", got)
+	}
+}
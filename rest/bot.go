@@ -21,6 +21,9 @@ func (c *Client) Bots(ctx context.Context, includeInactive bool) ([]types.Bot, e
 // CreateSGSLBot creates a new SGSL bot.
 func (c *Client) CreateSGSLBot(ctx context.Context, params *types.SGSLBotParams) (types.Bot, error) {
 	var result types.Bot
+	if err := c.preTradeCheck(ctx, params); err != nil {
+		return result, err
+	}
 	err := c.transport.DoPrivatePOST(ctx, "/private/create_bot", params, &result)
 	return result, err
 }
@@ -28,6 +31,9 @@ func (c *Client) CreateSGSLBot(ctx context.Context, params *types.SGSLBotParams)
 // CreateOCQBot creates a new OCQ bot.
 func (c *Client) CreateOCQBot(ctx context.Context, params *types.OCQBotParams) (types.Bot, error) {
 	var result types.Bot
+	if err := c.preTradeCheck(ctx, params); err != nil {
+		return result, err
+	}
 	err := c.transport.DoPrivatePOST(ctx, "/private/create_bot", params, &result)
 	return result, err
 }
@@ -35,6 +41,9 @@ func (c *Client) CreateOCQBot(ctx context.Context, params *types.OCQBotParams) (
 // CreateLevelsBot creates a new Levels bot.
 func (c *Client) CreateLevelsBot(ctx context.Context, params *types.LevelsBotParams) (types.Bot, error) {
 	var result types.Bot
+	if err := c.preTradeCheck(ctx, params); err != nil {
+		return result, err
+	}
 	err := c.transport.DoPrivatePOST(ctx, "/private/create_bot", params, &result)
 	return result, err
 }
@@ -42,6 +51,9 @@ func (c *Client) CreateLevelsBot(ctx context.Context, params *types.LevelsBotPar
 // CreateGridBot creates a new Grid bot.
 func (c *Client) CreateGridBot(ctx context.Context, params *types.GridBotParams) (types.Bot, error) {
 	var result types.Bot
+	if err := c.preTradeCheck(ctx, params); err != nil {
+		return result, err
+	}
 	err := c.transport.DoPrivatePOST(ctx, "/private/create_bot", params, &result)
 	return result, err
 }
@@ -49,6 +61,9 @@ func (c *Client) CreateGridBot(ctx context.Context, params *types.GridBotParams)
 // CreateDHedgeBot creates a new Delta Hedger bot.
 func (c *Client) CreateDHedgeBot(ctx context.Context, params *types.DHedgeBotParams) (types.Bot, error) {
 	var result types.Bot
+	if err := c.preTradeCheck(ctx, params); err != nil {
+		return result, err
+	}
 	err := c.transport.DoPrivatePOST(ctx, "/private/create_bot", params, &result)
 	return result, err
 }
@@ -56,6 +71,9 @@ func (c *Client) CreateDHedgeBot(ctx context.Context, params *types.DHedgeBotPar
 // CreateDFollowBot creates a new Delta Follower bot.
 func (c *Client) CreateDFollowBot(ctx context.Context, params *types.DFollowBotParams) (types.Bot, error) {
 	var result types.Bot
+	if err := c.preTradeCheck(ctx, params); err != nil {
+		return result, err
+	}
 	err := c.transport.DoPrivatePOST(ctx, "/private/create_bot", params, &result)
 	return result, err
 }
@@ -1,7 +1,10 @@
 package rest
 
 import (
+	"context"
+
 	"github.com/amiwrpremium/go-thalex/config"
+	"github.com/amiwrpremium/go-thalex/internal/pretrade"
 	"github.com/amiwrpremium/go-thalex/internal/transport"
 )
 
@@ -22,13 +25,20 @@ func NewClient(opts ...config.ClientOption) *Client {
 		tokenFunc = cfg.Credentials.GenerateToken
 	}
 	t := transport.NewHTTPTransport(transport.HTTPTransportConfig{
-		Client:        cfg.HTTPClient,
-		BaseURL:       cfg.Network.BaseURL(),
-		UserAgent:     cfg.UserAgent,
-		MaxRetries:    cfg.MaxRetries,
-		RetryBaseWait: cfg.RetryBaseWait,
-		TokenFunc:     tokenFunc,
-		AccountNumber: cfg.AccountNumber,
+		Client:           cfg.HTTPClient,
+		BaseURL:          cfg.BaseURL(),
+		Name:             cfg.BaseURLName(),
+		UserAgent:        cfg.UserAgent,
+		MaxRetries:       cfg.MaxRetries,
+		RetryBaseWait:    cfg.RetryBaseWait,
+		TokenFunc:        tokenFunc,
+		AccountNumber:    cfg.AccountNumber,
+		MaxResponseBytes: cfg.MaxResponseBytes,
 	})
 	return &Client{transport: t, cfg: cfg}
 }
+
+// preTradeCheck runs the configured pre-trade check, if any, on params.
+func (c *Client) preTradeCheck(ctx context.Context, params any) error {
+	return pretrade.Check(ctx, c.cfg.PreTradeCheck, params)
+}
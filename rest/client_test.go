@@ -9,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/amiwrpremium/go-thalex/apierr"
 	"github.com/amiwrpremium/go-thalex/config"
 	"github.com/amiwrpremium/go-thalex/internal/transport"
 )
@@ -131,6 +132,21 @@ func TestNewClient_WithAllOptions(t *testing.T) {
 	}
 }
 
+func TestNewClient_WithEndpoint(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/public/system_info" {
+			t.Errorf("expected path /api/v2/public/system_info, got %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"result":{}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	c := NewClient(config.WithEndpoint(config.Endpoint{Name: "local", BaseURL: server.URL + "/api/v2"}))
+	if _, err := c.SystemInfo(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 func TestNewClient_WithCredentials(t *testing.T) {
 	// We cannot easily test with real credentials, but we can verify the
 	// config stores them.
@@ -152,8 +168,12 @@ func TestClient_APIError(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected error")
 	}
-	if err.Error() != "API error 10001: invalid parameter" {
-		t.Errorf("unexpected error message: %v", err)
+	apiErr, ok := apierr.IsAPIError(err)
+	if !ok {
+		t.Fatalf("expected *apierr.APIError, got %T: %v", err, err)
+	}
+	if apiErr.Code != 10001 || apiErr.Message != "invalid parameter" || apiErr.HTTPStatus != http.StatusBadRequest {
+		t.Errorf("unexpected error: %+v", apiErr)
 	}
 }
 
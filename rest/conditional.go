@@ -16,6 +16,9 @@ func (c *Client) ConditionalOrders(ctx context.Context) ([]types.ConditionalOrde
 // CreateConditionalOrder creates a new conditional order.
 func (c *Client) CreateConditionalOrder(ctx context.Context, params *types.CreateConditionalOrderParams) (types.ConditionalOrder, error) {
 	var result types.ConditionalOrder
+	if err := c.preTradeCheck(ctx, params); err != nil {
+		return result, err
+	}
 	err := c.transport.DoPrivatePOST(ctx, "/private/create_conditional_order", params, &result)
 	return result, err
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/amiwrpremium/go-thalex/enums"
 	"github.com/amiwrpremium/go-thalex/types"
@@ -32,3 +33,15 @@ func (c *Client) IndexPriceHistoricalData(ctx context.Context, indexName string,
 	err := c.transport.DoPublic(ctx, "/public/index_price_historical_data", q, &result)
 	return result, err
 }
+
+// MarkPriceHistoricalDataBetween is [Client.MarkPriceHistoricalData] with a
+// time range instead of epoch seconds.
+func (c *Client) MarkPriceHistoricalDataBetween(ctx context.Context, instrumentName string, from, to time.Time, resolution enums.Resolution) (types.MarkPriceHistoricalResult, error) {
+	return c.MarkPriceHistoricalData(ctx, instrumentName, types.NewTimestamp(from).Seconds(), types.NewTimestamp(to).Seconds(), resolution)
+}
+
+// IndexPriceHistoricalDataBetween is [Client.IndexPriceHistoricalData] with a
+// time range instead of epoch seconds.
+func (c *Client) IndexPriceHistoricalDataBetween(ctx context.Context, indexName string, from, to time.Time, resolution enums.Resolution) (types.IndexPriceHistoricalResult, error) {
+	return c.IndexPriceHistoricalData(ctx, indexName, types.NewTimestamp(from).Seconds(), types.NewTimestamp(to).Seconds(), resolution)
+}
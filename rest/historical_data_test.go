@@ -2,6 +2,7 @@ package rest
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"testing"
 
@@ -50,6 +51,26 @@ func TestMarkPriceHistoricalData_Success(t *testing.T) {
 	if len(result.Mark) != 1 {
 		t.Fatalf("expected 1 data point, got %d", len(result.Mark))
 	}
+	if len(result.Perpetual) != 1 || result.Perpetual[0].FundingPayment != 0.001 {
+		t.Errorf("expected typed perpetual row, got %+v", result.Perpetual)
+	}
+}
+
+func TestMarkPriceHistoricalData_MalformedRow(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"result":{"instrument_type":"future","mark":[[1,2,3,1,2],[2,2,3]]}}`))
+	})
+
+	result, err := c.MarkPriceHistoricalData(context.Background(), "BTC-28MAR25", 0, 10, enums.Resolution1m)
+	if err != nil {
+		t.Fatalf("a malformed row failed the call: %v", err)
+	}
+	if errs := result.RowErrors(); len(errs) != 1 || !errors.Is(errs[0], types.ErrMalformedRow) {
+		t.Errorf("RowErrors = %v, want one ErrMalformedRow", errs)
+	}
+	if len(result.Future) != 1 {
+		t.Errorf("expected the valid row to be kept, got %+v", result.Future)
+	}
 }
 
 func TestIndexPriceHistoricalData_Success(t *testing.T) {
@@ -3,6 +3,7 @@ package rest
 import (
 	"context"
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/amiwrpremium/go-thalex/enums"
@@ -298,3 +299,40 @@ func TestTransactionHistory_WithParams(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
+
+func TestTradeHistoryAll_Pages(t *testing.T) {
+	var offsets []string
+	c := newTestClientWithAuth(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		offsets = append(offsets, q.Get("offset"))
+		if q.Get("limit") != "2" || q.Get("sort") != "asc" || q.Get("instrument_names") != "BTC-PERPETUAL" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		var page []types.Trade
+		switch q.Get("offset") {
+		case "0":
+			page = []types.Trade{{TradeID: "t1"}, {TradeID: "t2"}}
+		case "2":
+			page = []types.Trade{{TradeID: "t3"}}
+		}
+		w.Write(wrapResult(t, page))
+	})
+
+	params := &types.TradeHistoryParams{Sort: enums.SortAsc, InstrumentNames: []string{"BTC-PERPETUAL"}}
+	var ids []string
+	for trade, err := range c.TradeHistoryAll(context.Background(), params, types.WithPageSize(2)) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		ids = append(ids, trade.TradeID)
+	}
+	if strings.Join(ids, ",") != "t1,t2,t3" {
+		t.Errorf("trades = %v", ids)
+	}
+	if strings.Join(offsets, ",") != "0,2,3" {
+		t.Errorf("offsets = %v, want 0,2,3", offsets)
+	}
+	if params.Offset != nil || params.Limit != nil {
+		t.Error("params modified")
+	}
+}
@@ -3,6 +3,7 @@ package rest
 import (
 	"context"
 	"net/http"
+	"os"
 	"testing"
 
 	"github.com/amiwrpremium/go-thalex/enums"
@@ -107,6 +108,54 @@ func TestAllInstruments_Success(t *testing.T) {
 	}
 }
 
+func TestAllInstruments_NameRoundTrip(t *testing.T) {
+	body, err := os.ReadFile("testdata/all_instruments.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write(body)
+	})
+
+	result, err := c.AllInstruments(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result) == 0 {
+		t.Fatal("no instruments in testdata")
+	}
+	for _, inst := range result {
+		if inst.IsCombination() {
+			for _, leg := range inst.Legs {
+				if _, err := types.ParseInstrumentName(leg.InstrumentName); err != nil {
+					t.Errorf("leg of %s: %v", inst.InstrumentName, err)
+				}
+			}
+			continue
+		}
+		n, err := inst.ParsedName()
+		if err != nil {
+			t.Errorf("ParsedName(%s): %v", inst.InstrumentName, err)
+			continue
+		}
+		if n.Underlying != inst.BaseCurrency {
+			t.Errorf("%s: Underlying = %q, want %q", inst.InstrumentName, n.Underlying, inst.BaseCurrency)
+		}
+		var rebuilt types.InstrumentName
+		switch {
+		case n.IsPerpetual():
+			rebuilt = types.NewPerpetualName(n.Underlying)
+		case n.IsFuture():
+			rebuilt = types.NewFutureName(n.Underlying, n.Expiry)
+		default:
+			rebuilt = types.NewOptionName(n.Underlying, n.Expiry, *inst.StrikePrice, inst.OptionType)
+		}
+		if rebuilt.String() != inst.InstrumentName {
+			t.Errorf("rebuilt name %q, want %q", rebuilt, inst.InstrumentName)
+		}
+	}
+}
+
 func TestInstrument_Single(t *testing.T) {
 	inst := types.Instrument{
 		InstrumentName: "BTC-PERPETUAL",
@@ -2,9 +2,11 @@ package rest
 
 import (
 	"context"
+	"iter"
 	"net/url"
 	"strconv"
 
+	"github.com/amiwrpremium/go-thalex/internal/paging"
 	"github.com/amiwrpremium/go-thalex/types"
 )
 
@@ -57,6 +59,15 @@ func (c *Client) RfqHistory(ctx context.Context, from, to *float64, offset, limi
 	return result, err
 }
 
+// RfqHistoryAll iterates over historical RFQs in [from, to], fetching
+// pages as needed.
+func (c *Client) RfqHistoryAll(ctx context.Context, from, to *float64, opts ...types.PageOption) iter.Seq2[types.Rfq, error] {
+	q := paging.Query{From: paging.Timestamp(from), To: paging.Timestamp(to)}
+	return paging.All(ctx, q, opts, func(ctx context.Context, from, to *types.Timestamp, offset, limit int) ([]types.Rfq, error) {
+		return c.RfqHistory(ctx, paging.Seconds(from), paging.Seconds(to), &offset, &limit)
+	}, paging.RfqKey)
+}
+
 // MMRfqs retrieves all market maker RFQ opportunities.
 func (c *Client) MMRfqs(ctx context.Context) ([]types.Rfq, error) {
 	var result []types.Rfq
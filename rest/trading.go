@@ -4,12 +4,16 @@ import (
 	"context"
 	"net/url"
 
+	"github.com/amiwrpremium/go-thalex/enums"
 	"github.com/amiwrpremium/go-thalex/types"
 )
 
 // Insert places a new order.
 func (c *Client) Insert(ctx context.Context, params *types.InsertOrderParams) (types.OrderStatus, error) {
 	var result types.OrderStatus
+	if err := c.preTradeCheck(ctx, params); err != nil {
+		return result, err
+	}
 	err := c.transport.DoPrivatePOST(ctx, "/private/insert", params, &result)
 	return result, err
 }
@@ -17,10 +21,14 @@ func (c *Client) Insert(ctx context.Context, params *types.InsertOrderParams) (t
 // Buy places a market buy order.
 func (c *Client) Buy(ctx context.Context, instrumentName string, amount float64) (types.OrderStatus, error) {
 	var result types.OrderStatus
+	params := types.NewBuyOrderParams(instrumentName, amount).WithOrderType(enums.OrderTypeMarket)
+	if err := c.preTradeCheck(ctx, params); err != nil {
+		return result, err
+	}
 	body := struct {
 		InstrumentName string  `json:"instrument_name"`
 		Amount         float64 `json:"amount"`
-	}{InstrumentName: instrumentName, Amount: amount}
+	}{InstrumentName: params.InstrumentName, Amount: params.Amount}
 	err := c.transport.DoPrivatePOST(ctx, "/private/buy", body, &result)
 	return result, err
 }
@@ -28,10 +36,14 @@ func (c *Client) Buy(ctx context.Context, instrumentName string, amount float64)
 // Sell places a market sell order.
 func (c *Client) Sell(ctx context.Context, instrumentName string, amount float64) (types.OrderStatus, error) {
 	var result types.OrderStatus
+	params := types.NewSellOrderParams(instrumentName, amount).WithOrderType(enums.OrderTypeMarket)
+	if err := c.preTradeCheck(ctx, params); err != nil {
+		return result, err
+	}
 	body := struct {
 		InstrumentName string  `json:"instrument_name"`
 		Amount         float64 `json:"amount"`
-	}{InstrumentName: instrumentName, Amount: amount}
+	}{InstrumentName: params.InstrumentName, Amount: params.Amount}
 	err := c.transport.DoPrivatePOST(ctx, "/private/sell", body, &result)
 	return result, err
 }
@@ -39,6 +51,9 @@ func (c *Client) Sell(ctx context.Context, instrumentName string, amount float64
 // Amend modifies an existing order.
 func (c *Client) Amend(ctx context.Context, params *types.AmendOrderParams) (types.OrderStatus, error) {
 	var result types.OrderStatus
+	if err := c.preTradeCheck(ctx, params); err != nil {
+		return result, err
+	}
 	err := c.transport.DoPrivatePOST(ctx, "/private/amend", params, &result)
 	return result, err
 }
@@ -3,11 +3,14 @@ package rest
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"testing"
 
+	"github.com/amiwrpremium/go-thalex/apierr"
 	"github.com/amiwrpremium/go-thalex/enums"
+	"github.com/amiwrpremium/go-thalex/internal/pretrade"
 	"github.com/amiwrpremium/go-thalex/types"
 )
 
@@ -90,7 +93,7 @@ func TestInsert_APIError(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected error")
 	}
-	if err.Error() != "API error 10010: insufficient margin" {
+	if !errors.Is(err, apierr.ErrInsufficientMargin) {
 		t.Errorf("unexpected error: %v", err)
 	}
 }
@@ -382,3 +385,56 @@ func TestOpenOrders_APIError(t *testing.T) {
 		t.Fatal("expected error")
 	}
 }
+
+func TestInsert_PreTradeCheck(t *testing.T) {
+	inst := types.Instrument{InstrumentName: "BTC-PERPETUAL", TickSize: 0.5, VolumeTickSize: 0.001, MinOrderAmount: 0.001}
+	lookup := func(name string) (types.Instrument, bool) { return inst, name == inst.InstrumentName }
+
+	var sent map[string]any
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &sent)
+		w.Write(wrapResult(t, types.OrderStatus{OrderID: "ord-001"}))
+	})
+
+	c.cfg.PreTradeCheck = types.NewValidator(lookup).Validate
+	_, err := c.Insert(context.Background(), types.NewBuyOrderParams("BTC-PERPETUAL", 0.01).WithPrice(95000.3))
+	var fe *types.FieldError
+	if !errors.As(err, &fe) || fe.Field != "price" || !errors.Is(err, types.ErrOffTick) {
+		t.Fatalf("expected price FieldError, got %v", err)
+	}
+	if sent != nil {
+		t.Fatal("request should not be sent when the pre-trade check fails")
+	}
+
+	c.cfg.PreTradeCheck = types.NewValidator(lookup).WithPriceRounding(types.TickRoundPassive).Validate
+	if _, err := c.Insert(context.Background(), types.NewBuyOrderParams("BTC-PERPETUAL", 0.01).WithPrice(95000.3)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sent["price"] != 95000.0 {
+		t.Errorf("sent price = %v, want 95000", sent["price"])
+	}
+
+	sent = nil
+	if _, err := c.Buy(context.Background(), "BTC-PERPETUAL", 0); !errors.Is(err, types.ErrInvalidParam) {
+		t.Errorf("Buy with zero amount error = %v", err)
+	}
+	if sent != nil {
+		t.Error("Buy should not be sent when the pre-trade check fails")
+	}
+
+	c.cfg.PreTradeCheck = func(any) error { return errors.New("refused") }
+	ctx := pretrade.WithReduceOnlyBypass(context.Background())
+	if _, err := c.Insert(ctx, types.NewBuyOrderParams("BTC-PERPETUAL", 0.01).WithPrice(95000.3)); err == nil {
+		t.Error("bypass context skipped the check of an opening order")
+	}
+	if sent != nil {
+		t.Error("opening order sent despite the failed pre-trade check")
+	}
+	if _, err := c.Insert(ctx, types.NewSellOrderParams("BTC-PERPETUAL", 0.01).WithReduceOnly(true)); err != nil {
+		t.Fatalf("reduce-only bypass: unexpected error: %v", err)
+	}
+	if sent == nil {
+		t.Error("reduce-only order with a bypassed pre-trade check was not sent")
+	}
+}
@@ -0,0 +1,113 @@
+package risk
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limits configures the checks of an Engine. A zero value disables a
+// limit, so the zero Limits allows everything.
+type Limits struct {
+	// MaxOrderNotional caps the notional, amount times price, of a single
+	// order, conditional order or quote level. Market orders are valued at
+	// the mark price.
+	MaxOrderNotional float64 `json:"max_order_notional,omitempty"`
+	// MaxPosition caps the absolute position per instrument, by instrument
+	// name. An order is checked as if it and every open order on its side
+	// in the instrument filled.
+	MaxPosition map[string]float64 `json:"max_position,omitempty"`
+	// MaxUnderlyingPosition caps the absolute sum of the positions in all
+	// instruments of an underlying, by underlying. Options count at their
+	// amount, not their delta. It needs WithInstruments.
+	MaxUnderlyingPosition map[string]float64 `json:"max_underlying_position,omitempty"`
+	// MaxOpenOrders caps the number of open orders. Mass quotes do not
+	// count.
+	MaxOpenOrders int `json:"max_open_orders,omitempty"`
+	// MaxPriceDeviation caps how far a limit price may be from the mark
+	// price, as a fraction of the mark price; 0.05 allows 5%.
+	MaxPriceDeviation float64 `json:"max_price_deviation,omitempty"`
+	// CheckCollars rejects bids above the ticker's collar high and asks
+	// below its collar low, which the exchange would refuse anyway.
+	CheckCollars bool `json:"check_collars,omitempty"`
+	// MaxOrderRate caps the number of checked requests per period.
+	MaxOrderRate Rate `json:"max_order_rate,omitzero"`
+	// Labels holds the budgets of strategies, by order label.
+	Labels map[string]LabelBudget `json:"labels,omitempty"`
+}
+
+// LabelBudget limits the requests carrying one label.
+type LabelBudget struct {
+	// MaxOpenOrders caps the number of open orders with the label.
+	MaxOpenOrders int `json:"max_open_orders,omitempty"`
+	// MaxNotional caps the total notional of the open orders with the
+	// label, including the one being checked.
+	MaxNotional float64 `json:"max_notional,omitempty"`
+	// MaxOrderRate caps the number of requests with the label per period.
+	MaxOrderRate Rate `json:"max_order_rate,omitzero"`
+}
+
+// Rate is a number of requests per period. In JSON it is a string such
+// as "10/1s".
+type Rate struct {
+	Count int
+	Per   time.Duration
+}
+
+// enabled reports whether the rate limits anything.
+func (r Rate) enabled() bool { return r.Count > 0 && r.Per > 0 }
+
+// String returns the rate in the form "10/1s".
+func (r Rate) String() string {
+	return fmt.Sprintf("%d/%s", r.Count, r.Per)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (r Rate) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It accepts the form
+// "10/1s".
+func (r *Rate) UnmarshalText(text []byte) error {
+	count, per, ok := strings.Cut(string(text), "/")
+	if !ok {
+		return fmt.Errorf("risk: invalid rate %q: want count/duration", text)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(count))
+	if err != nil || n < 0 {
+		return fmt.Errorf("risk: invalid rate count %q", count)
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(per))
+	if err != nil || d <= 0 {
+		return fmt.Errorf("risk: invalid rate period %q", per)
+	}
+	r.Count, r.Per = n, d
+	return nil
+}
+
+// window counts events in a sliding time window.
+type window []time.Time
+
+// allows reports whether one more event at now stays within r.
+func (w *window) allows(r Rate, now time.Time) bool {
+	w.trim(r.Per, now)
+	return len(*w) < r.Count
+}
+
+// add records an event, forgetting those older than keep.
+func (w *window) add(now time.Time, keep time.Duration) {
+	w.trim(keep, now)
+	*w = append(*w, now)
+}
+
+func (w *window) trim(per time.Duration, now time.Time) {
+	cut := 0
+	for cut < len(*w) && now.Sub((*w)[cut]) >= per {
+		cut++
+	}
+	if cut > 0 {
+		*w = append((*w)[:0], (*w)[cut:]...)
+	}
+}
@@ -0,0 +1,606 @@
+// Package risk checks order, quote, conditional order and bot requests
+// against configurable limits before they are sent.
+//
+// An Engine is installed as a client's pre-trade check, so a request that
+// breaks a limit returns a *Violation and never reaches the wire. It
+// checks order notional, position per instrument and underlying, open
+// order counts, the price against the mark price and collars, per-label
+// budgets, request rates and reduce-only orders. Limits can be replaced at
+// any time with SetLimits.
+//
+// Position, open order and price checks need to know the account's state.
+// An Engine is a ws.Hook, so added to a ws.Client it follows the order,
+// portfolio, trade and ticker notifications the client handles, and the
+// results of its requests. It can also be fed by hand.
+package risk
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/amiwrpremium/go-thalex/enums"
+	"github.com/amiwrpremium/go-thalex/types"
+)
+
+// DefaultReservationTTL is how long an order that passed the check counts
+// as open before its status is known.
+const DefaultReservationTTL = 5 * time.Second
+
+// Sentinel errors wrapped by [Violation]. Match them with errors.Is.
+var (
+	ErrOrderNotional      = errors.New("order notional over limit")
+	ErrPosition           = errors.New("position over limit")
+	ErrUnderlyingPosition = errors.New("underlying position over limit")
+	ErrOpenOrders         = errors.New("too many open orders")
+	ErrLabelNotional      = errors.New("label notional over budget")
+	ErrPriceDeviation     = errors.New("price too far from mark price")
+	ErrCollar             = errors.New("price outside collar")
+	ErrNoMarkPrice        = errors.New("no mark price")
+	ErrOrderRate          = errors.New("order rate over limit")
+	ErrReduceOnly         = errors.New("reduce-only order would increase position")
+	ErrUnknownInstrument  = errors.New("unknown instrument")
+	ErrQuoteType          = errors.New("unsupported mass quote side")
+)
+
+// Violation describes a request refused by an Engine.
+type Violation struct {
+	// Err is one of the sentinel errors above.
+	Err error
+	// Instrument is the instrument concerned, if any.
+	Instrument string
+	// Label is set when the limit is the budget of a label.
+	Label string
+	// Value is the value that broke the limit, such as the notional or the
+	// projected position, and Limit the limit it broke.
+	Value, Limit float64
+}
+
+// Error implements the error interface.
+func (v *Violation) Error() string {
+	var b strings.Builder
+	b.WriteString("risk: ")
+	b.WriteString(v.Err.Error())
+	if v.Instrument != "" {
+		fmt.Fprintf(&b, " in %s", v.Instrument)
+	}
+	if v.Label != "" {
+		fmt.Fprintf(&b, " for label %q", v.Label)
+	}
+	if v.Value != 0 || v.Limit != 0 {
+		fmt.Fprintf(&b, ": %v, limit %v", v.Value, v.Limit)
+	}
+	return b.String()
+}
+
+// Unwrap returns the sentinel error.
+func (v *Violation) Unwrap() error {
+	return v.Err
+}
+
+// Option configures an Engine.
+type Option func(*Engine)
+
+// WithLimits sets the initial limits.
+func WithLimits(l Limits) Option {
+	return func(e *Engine) { e.SetLimits(l) }
+}
+
+// WithInstruments sets how instruments are resolved to their underlying,
+// which MaxUnderlyingPosition needs. The Get method of an
+// instruments.Registry has this signature.
+func WithInstruments(lookup types.InstrumentLookup) Option {
+	return func(e *Engine) { e.lookup = lookup }
+}
+
+// WithReservationTTL sets how long an order that passed the check counts
+// as open before its status is known. The default is
+// DefaultReservationTTL.
+func WithReservationTTL(d time.Duration) Option {
+	return func(e *Engine) {
+		if d > 0 {
+			e.ttl = d
+		}
+	}
+}
+
+// WithClock sets the time source of rate limits and reservations. It is
+// intended for tests.
+func WithClock(now func() time.Time) Option {
+	return func(e *Engine) { e.now = now }
+}
+
+// Engine checks requests against Limits. It is safe for concurrent use.
+type Engine struct {
+	limits atomic.Pointer[Limits]
+	lookup types.InstrumentLookup
+	ttl    time.Duration
+	now    func() time.Time
+
+	mu           sync.Mutex
+	orders       map[string]types.OrderStatus
+	reservations map[*types.InsertOrderParams]reservation
+	positions    map[string]float64
+	tickers      map[string]types.Ticker
+	requests     window
+	labelReqs    map[string]*window
+}
+
+// reservation stands for an order that passed the check until its status
+// arrives, so a burst of inserts cannot slip past the limits.
+type reservation struct {
+	order   types.OrderStatus
+	expires time.Time
+}
+
+// NewEngine creates an engine. Without WithLimits nothing is limited.
+func NewEngine(opts ...Option) *Engine {
+	e := &Engine{
+		ttl:          DefaultReservationTTL,
+		now:          time.Now,
+		orders:       make(map[string]types.OrderStatus),
+		reservations: make(map[*types.InsertOrderParams]reservation),
+		positions:    make(map[string]float64),
+		tickers:      make(map[string]types.Ticker),
+		labelReqs:    make(map[string]*window),
+	}
+	e.limits.Store(&Limits{})
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Limits returns the current limits.
+func (e *Engine) Limits() Limits {
+	return *e.limits.Load()
+}
+
+// SetLimits replaces the limits. It takes effect for the next check and
+// may be called while requests are being checked.
+func (e *Engine) SetLimits(l Limits) {
+	e.limits.Store(&l)
+}
+
+// Check checks any supported params value: *types.InsertOrderParams,
+// *types.AmendOrderParams, *types.MassQuoteParams,
+// *types.CreateConditionalOrderParams or any bot params type. Other
+// values are allowed. A refused request returns a *Violation.
+//
+// Install it on a client with config.WithPreTradeCheck(e.Check). Every
+// allowed request counts toward the rate limits.
+func (e *Engine) Check(params any) error {
+	lim := e.limits.Load()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	now := e.now()
+	e.expire(now)
+
+	var (
+		label string
+		err   error
+	)
+	switch p := params.(type) {
+	case *types.InsertOrderParams:
+		label, err = p.Label, e.checkInsert(lim, p)
+	case *types.AmendOrderParams:
+		label, err = e.checkAmend(lim, p)
+	case *types.MassQuoteParams:
+		label, err = p.Label, e.checkMassQuote(lim, p)
+	case *types.CreateConditionalOrderParams:
+		label, err = p.Label, e.checkConditional(lim, p)
+	case *types.SGSLBotParams:
+		label, err = p.Label, e.checkBotPositions(lim, p.InstrumentName, p.TargetPosition, p.ExitPosition)
+	case *types.OCQBotParams:
+		positions := []float64{p.MinPosition, p.MaxPosition}
+		if p.TargetPosition != nil {
+			positions = append(positions, *p.TargetPosition)
+		}
+		label, err = p.Label, e.checkBotPositions(lim, p.InstrumentName, positions...)
+	case *types.LevelsBotParams:
+		base := deref(p.BasePosition)
+		label, err = p.Label, e.checkBotPositions(lim, p.InstrumentName,
+			base+p.StepSize*float64(len(p.Bids)), base-p.StepSize*float64(len(p.Asks)))
+	case *types.GridBotParams:
+		base, reach := deref(p.BasePosition), p.StepSize*float64(len(p.Grid))
+		label, err = p.Label, e.checkBotPositions(lim, p.InstrumentName, base+reach, base-reach)
+	case *types.DHedgeBotParams:
+		label = p.Label
+	case *types.DFollowBotParams:
+		label, err = p.Label, e.checkBotPositions(lim, p.InstrumentName, p.TargetAmount)
+	default:
+		return nil
+	}
+	if err == nil {
+		err = e.checkRate(lim, label, now)
+	}
+	if err != nil {
+		return err
+	}
+	e.recordRequest(lim, label, now)
+	if p, ok := params.(*types.InsertOrderParams); ok && resting(p) {
+		e.reservations[p] = reservation{order: reservedOrder(p), expires: now.Add(e.ttl)}
+	}
+	return nil
+}
+
+func (e *Engine) checkInsert(lim *Limits, p *types.InsertOrderParams) error {
+	if len(p.Legs) > 0 {
+		return e.checkCombo(lim, p)
+	}
+	amount := math.Abs(p.Amount)
+	price, err := e.orderPrice(lim, p.InstrumentName, p.Label, p.Price)
+	if err != nil {
+		return err
+	}
+	if p.Price != nil {
+		if err := e.checkPrice(lim, p.InstrumentName, p.Direction, *p.Price); err != nil {
+			return err
+		}
+	}
+	notional := amount * math.Abs(price)
+	if err := e.checkNotional(lim, p.InstrumentName, p.Label, notional, 0); err != nil {
+		return err
+	}
+	if resting(p) {
+		if err := e.checkOpenOrders(lim, p.Label); err != nil {
+			return err
+		}
+	}
+	if p.ReduceOnly != nil && *p.ReduceOnly {
+		if err := e.checkReduceOnly(p.InstrumentName, p.Direction, amount); err != nil {
+			return err
+		}
+	}
+	projected := e.positions[p.InstrumentName] + sign(p.Direction)*(e.exposure(p.InstrumentName, p.Direction)+amount)
+	return e.checkPosition(lim, p.InstrumentName, projected)
+}
+
+// checkCombo checks a combination order. Its price is the net price of
+// the legs, so it is only checked for notional; positions are checked per
+// leg.
+func (e *Engine) checkCombo(lim *Limits, p *types.InsertOrderParams) error {
+	amount := math.Abs(p.Amount)
+	if p.Price != nil {
+		if err := e.checkNotional(lim, "", p.Label, amount*math.Abs(*p.Price), 0); err != nil {
+			return err
+		}
+	}
+	if resting(p) {
+		if err := e.checkOpenOrders(lim, p.Label); err != nil {
+			return err
+		}
+	}
+	for _, leg := range p.Legs {
+		dir := p.Direction
+		if leg.Quantity < 0 {
+			dir = opposite(dir)
+		}
+		size := amount * math.Abs(leg.Quantity)
+		projected := e.positions[leg.InstrumentName] + sign(dir)*(e.exposure(leg.InstrumentName, dir)+size)
+		if err := e.checkPosition(lim, leg.InstrumentName, projected); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkAmend checks an amend as a replacement of the amended order. An
+// order the Engine does not know is checked on the InstrumentName and
+// Direction of the params, if set, and otherwise for notional only.
+func (e *Engine) checkAmend(lim *Limits, p *types.AmendOrderParams) (string, error) {
+	prev, known := e.findOrder(p.OrderID, p.ClientOrderID)
+	instrument, dir := p.InstrumentName, p.Direction
+	if known {
+		instrument, dir = prev.InstrumentName, prev.Direction
+	}
+	label := prev.Label
+	if instrument != "" && dir != "" {
+		if err := e.checkPrice(lim, instrument, dir, p.Price); err != nil {
+			return label, err
+		}
+	}
+	notional := math.Abs(p.Amount * p.Price)
+	if err := e.checkNotional(lim, instrument, label, notional, orderNotional(prev)); err != nil {
+		return label, err
+	}
+	if instrument == "" || dir == "" {
+		return label, nil
+	}
+	remaining := max(math.Abs(p.Amount)-prev.FilledAmount, 0)
+	projected := e.positions[instrument] + sign(dir)*(e.exposure(instrument, dir)-prev.RemainingAmount+remaining)
+	return label, e.checkPosition(lim, instrument, projected)
+}
+
+// checkMassQuote checks every level of a mass quote. Quotes replace the
+// earlier quotes in an instrument, so each side is checked against the
+// position alone, as if all its levels filled. The label budget is checked
+// on the notional of all levels together, on top of the label's open
+// orders.
+func (e *Engine) checkMassQuote(lim *Limits, p *types.MassQuoteParams) error {
+	var quoted float64
+	for _, q := range p.Quotes {
+		var bought, sold float64
+		for _, side := range []struct {
+			dir   enums.Direction
+			quote any
+			total *float64
+		}{
+			{enums.DirectionBuy, q.B, &bought},
+			{enums.DirectionSell, q.A, &sold},
+		} {
+			levels, ok := quoteLevels(side.quote)
+			if !ok {
+				return &Violation{Err: ErrQuoteType, Instrument: q.I}
+			}
+			for _, l := range levels {
+				price, amount := l[0], math.Abs(l[1])
+				if amount == 0 {
+					continue
+				}
+				if err := e.checkPrice(lim, q.I, side.dir, price); err != nil {
+					return err
+				}
+				notional := amount * math.Abs(price)
+				if err := e.checkNotional(lim, q.I, "", notional, 0); err != nil {
+					return err
+				}
+				quoted += notional
+				*side.total += amount
+			}
+		}
+		pos := e.positions[q.I]
+		if err := e.checkPosition(lim, q.I, pos+bought); err != nil {
+			return err
+		}
+		if err := e.checkPosition(lim, q.I, pos-sold); err != nil {
+			return err
+		}
+	}
+	return e.checkLabelNotional(lim, "", p.Label, quoted, 0)
+}
+
+// checkConditional checks a conditional order as the order it would
+// become. It is valued at its limit price, or else its stop price; only
+// the limit price is checked against the mark price.
+func (e *Engine) checkConditional(lim *Limits, p *types.CreateConditionalOrderParams) error {
+	amount := math.Abs(p.Amount)
+	price := p.StopPrice
+	if p.LimitPrice != nil {
+		price = *p.LimitPrice
+		if err := e.checkPrice(lim, p.InstrumentName, p.Direction, price); err != nil {
+			return err
+		}
+	}
+	if err := e.checkNotional(lim, p.InstrumentName, "", amount*math.Abs(price), 0); err != nil {
+		return err
+	}
+	if p.ReduceOnly != nil && *p.ReduceOnly {
+		if err := e.checkReduceOnly(p.InstrumentName, p.Direction, amount); err != nil {
+			return err
+		}
+	}
+	projected := e.positions[p.InstrumentName] + sign(p.Direction)*(e.exposure(p.InstrumentName, p.Direction)+amount)
+	return e.checkPosition(lim, p.InstrumentName, projected)
+}
+
+// checkBotPositions checks the positions a bot may take.
+func (e *Engine) checkBotPositions(lim *Limits, instrument string, positions ...float64) error {
+	for _, pos := range positions {
+		if err := e.checkPosition(lim, instrument, pos); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// orderPrice returns the price an order is valued at: its limit price, or
+// the mark price for a market order.
+func (e *Engine) orderPrice(lim *Limits, instrument, label string, price *float64) (float64, error) {
+	if price != nil {
+		return *price, nil
+	}
+	t, ok := e.tickers[instrument]
+	if ok && t.MarkPrice != 0 {
+		return t.MarkPrice, nil
+	}
+	if lim.MaxOrderNotional > 0 || (label != "" && lim.Labels[label].MaxNotional > 0) {
+		return 0, &Violation{Err: ErrNoMarkPrice, Instrument: instrument}
+	}
+	return 0, nil
+}
+
+func (e *Engine) checkPrice(lim *Limits, instrument string, dir enums.Direction, price float64) error {
+	t, ok := e.tickers[instrument]
+	if lim.MaxPriceDeviation > 0 {
+		if !ok || t.MarkPrice == 0 {
+			return &Violation{Err: ErrNoMarkPrice, Instrument: instrument}
+		}
+		if dev := math.Abs(price-t.MarkPrice) / math.Abs(t.MarkPrice); dev > lim.MaxPriceDeviation {
+			return &Violation{Err: ErrPriceDeviation, Instrument: instrument, Value: dev, Limit: lim.MaxPriceDeviation}
+		}
+	}
+	if lim.CheckCollars && ok {
+		if dir == enums.DirectionBuy && t.CollarHigh != nil && price > *t.CollarHigh {
+			return &Violation{Err: ErrCollar, Instrument: instrument, Value: price, Limit: *t.CollarHigh}
+		}
+		if dir == enums.DirectionSell && t.CollarLow != nil && price < *t.CollarLow {
+			return &Violation{Err: ErrCollar, Instrument: instrument, Value: price, Limit: *t.CollarLow}
+		}
+	}
+	return nil
+}
+
+// checkNotional checks the notional of one order, and the label's total
+// with replaced, the notional of an order being amended, taken out.
+func (e *Engine) checkNotional(lim *Limits, instrument, label string, notional, replaced float64) error {
+	if lim.MaxOrderNotional > 0 && notional > lim.MaxOrderNotional {
+		return &Violation{Err: ErrOrderNotional, Instrument: instrument, Value: notional, Limit: lim.MaxOrderNotional}
+	}
+	return e.checkLabelNotional(lim, instrument, label, notional, replaced)
+}
+
+// checkLabelNotional checks the label's total open notional with notional
+// added and replaced taken out.
+func (e *Engine) checkLabelNotional(lim *Limits, instrument, label string, notional, replaced float64) error {
+	if b, ok := lim.Labels[label]; ok && label != "" && b.MaxNotional > 0 {
+		total := notional - replaced
+		e.eachOpen(func(o types.OrderStatus) {
+			if o.Label == label {
+				total += orderNotional(o)
+			}
+		})
+		if total > b.MaxNotional {
+			return &Violation{Err: ErrLabelNotional, Instrument: instrument, Label: label, Value: total, Limit: b.MaxNotional}
+		}
+	}
+	return nil
+}
+
+// checkOpenOrders checks that one more order stays within the open order
+// limits.
+func (e *Engine) checkOpenOrders(lim *Limits, label string) error {
+	var total, labelled int
+	e.eachOpen(func(o types.OrderStatus) {
+		total++
+		if label != "" && o.Label == label {
+			labelled++
+		}
+	})
+	if lim.MaxOpenOrders > 0 && total >= lim.MaxOpenOrders {
+		return &Violation{Err: ErrOpenOrders, Value: float64(total + 1), Limit: float64(lim.MaxOpenOrders)}
+	}
+	if b, ok := lim.Labels[label]; ok && label != "" && b.MaxOpenOrders > 0 && labelled >= b.MaxOpenOrders {
+		return &Violation{Err: ErrOpenOrders, Label: label, Value: float64(labelled + 1), Limit: float64(b.MaxOpenOrders)}
+	}
+	return nil
+}
+
+// checkReduceOnly checks that an order can only reduce the position.
+func (e *Engine) checkReduceOnly(instrument string, dir enums.Direction, amount float64) error {
+	reducible := max(-sign(dir)*e.positions[instrument], 0)
+	if amount > reducible+epsilon {
+		return &Violation{Err: ErrReduceOnly, Instrument: instrument, Value: amount, Limit: reducible}
+	}
+	return nil
+}
+
+// checkPosition checks a projected position in an instrument against the
+// instrument and underlying limits.
+func (e *Engine) checkPosition(lim *Limits, instrument string, projected float64) error {
+	if l, ok := lim.MaxPosition[instrument]; ok && math.Abs(projected) > l+epsilon {
+		return &Violation{Err: ErrPosition, Instrument: instrument, Value: projected, Limit: l}
+	}
+	if len(lim.MaxUnderlyingPosition) == 0 {
+		return nil
+	}
+	underlying, ok := e.underlying(instrument)
+	if !ok {
+		return &Violation{Err: ErrUnknownInstrument, Instrument: instrument}
+	}
+	l, ok := lim.MaxUnderlyingPosition[underlying]
+	if !ok {
+		return nil
+	}
+	total := projected
+	for name, pos := range e.positions {
+		if u, ok := e.underlying(name); name != instrument && ok && u == underlying {
+			total += pos
+		}
+	}
+	if math.Abs(total) > l+epsilon {
+		return &Violation{Err: ErrUnderlyingPosition, Instrument: instrument, Value: total, Limit: l}
+	}
+	return nil
+}
+
+func (e *Engine) checkRate(lim *Limits, label string, now time.Time) error {
+	if r := lim.MaxOrderRate; r.enabled() && !e.requests.allows(r, now) {
+		return &Violation{Err: ErrOrderRate, Value: float64(r.Count + 1), Limit: float64(r.Count)}
+	}
+	if b, ok := lim.Labels[label]; ok && label != "" && b.MaxOrderRate.enabled() {
+		w := e.labelReqs[label]
+		if w != nil && !w.allows(b.MaxOrderRate, now) {
+			r := b.MaxOrderRate
+			return &Violation{Err: ErrOrderRate, Label: label, Value: float64(r.Count + 1), Limit: float64(r.Count)}
+		}
+	}
+	return nil
+}
+
+// recordRequest counts an allowed request toward the rates that limit it.
+func (e *Engine) recordRequest(lim *Limits, label string, now time.Time) {
+	if r := lim.MaxOrderRate; r.enabled() {
+		e.requests.add(now, r.Per)
+	}
+	if b, ok := lim.Labels[label]; ok && label != "" && b.MaxOrderRate.enabled() {
+		w := e.labelReqs[label]
+		if w == nil {
+			w = new(window)
+			e.labelReqs[label] = w
+		}
+		w.add(now, b.MaxOrderRate.Per)
+	}
+}
+
+func (e *Engine) underlying(instrument string) (string, bool) {
+	if e.lookup == nil {
+		return "", false
+	}
+	inst, ok := e.lookup(instrument)
+	return inst.Underlying, ok
+}
+
+// epsilon absorbs floating point error in position and amount comparisons.
+const epsilon = 1e-9
+
+// resting reports whether an insert may rest on the book, so it counts as
+// an open order.
+func resting(p *types.InsertOrderParams) bool {
+	return p.Price != nil && p.OrderType != enums.OrderTypeMarket && p.TimeInForce != enums.TimeInForceImmediateOrCancel
+}
+
+func sign(dir enums.Direction) float64 {
+	if dir == enums.DirectionSell {
+		return -1
+	}
+	return 1
+}
+
+func opposite(dir enums.Direction) enums.Direction {
+	if dir == enums.DirectionSell {
+		return enums.DirectionBuy
+	}
+	return enums.DirectionSell
+}
+
+func deref(v *float64) float64 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
+
+// quoteLevels returns the [price, amount] levels of a mass quote side,
+// which is a SingleLevelQuote or a list of [price, amount] pairs. It
+// reports false for a side of any other type.
+func quoteLevels(side any) ([][2]float64, bool) {
+	switch s := side.(type) {
+	case nil:
+		return nil, true
+	case types.SingleLevelQuote:
+		return [][2]float64{{s.P, s.A}}, true
+	case *types.SingleLevelQuote:
+		if s == nil {
+			return nil, true
+		}
+		return [][2]float64{{s.P, s.A}}, true
+	case [][2]float64:
+		return s, true
+	}
+	return nil, false
+}
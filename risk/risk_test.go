@@ -0,0 +1,423 @@
+package risk_test
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/amiwrpremium/go-thalex/apierr"
+	"github.com/amiwrpremium/go-thalex/enums"
+	"github.com/amiwrpremium/go-thalex/risk"
+	"github.com/amiwrpremium/go-thalex/types"
+	"github.com/amiwrpremium/go-thalex/ws"
+)
+
+var _ ws.Hook = (*risk.Engine)(nil)
+
+func ptr[T any](v T) *T { return &v }
+
+// clock is a manually advanced time source.
+type clock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func (c *clock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+func (c *clock) advance(d time.Duration) {
+	c.mu.Lock()
+	c.t = c.t.Add(d)
+	c.mu.Unlock()
+}
+
+func newEngine(l risk.Limits, opts ...risk.Option) (*risk.Engine, *clock) {
+	c := &clock{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	e := risk.NewEngine(append([]risk.Option{risk.WithLimits(l), risk.WithClock(c.now)}, opts...)...)
+	e.OnNotification("ticker.BTC-PERPETUAL.100ms", types.Ticker{MarkPrice: 50000, CollarLow: ptr(49000.0), CollarHigh: ptr(51000.0)})
+	return e, c
+}
+
+func bid(amount, price float64) *types.InsertOrderParams {
+	return types.NewBuyOrderParams("BTC-PERPETUAL", amount).WithPrice(price)
+}
+
+func ask(amount, price float64) *types.InsertOrderParams {
+	return types.NewSellOrderParams("BTC-PERPETUAL", amount).WithPrice(price)
+}
+
+func wantViolation(t *testing.T, err, sentinel error) *risk.Violation {
+	t.Helper()
+	var v *risk.Violation
+	if !errors.As(err, &v) || !errors.Is(err, sentinel) {
+		t.Fatalf("err = %v, want a violation of %v", err, sentinel)
+	}
+	return v
+}
+
+func TestEngine_NoLimits(t *testing.T) {
+	e := risk.NewEngine()
+	for _, p := range []any{
+		bid(1000, 1),
+		types.NewBuyOrderParams("ETH-PERPETUAL", 5),
+		&types.AmendOrderParams{OrderID: "x", Price: 1, Amount: 1},
+		types.NewMassQuoteParams(nil),
+		types.NewDHedgeBotParams("BTC-PERPETUAL", 60),
+		"unrelated",
+	} {
+		if err := e.Check(p); err != nil {
+			t.Errorf("Check(%T) = %v", p, err)
+		}
+	}
+}
+
+func TestEngine_Notional(t *testing.T) {
+	e, _ := newEngine(risk.Limits{MaxOrderNotional: 100000})
+	if err := e.Check(bid(2, 50000)); err != nil {
+		t.Fatal(err)
+	}
+	v := wantViolation(t, e.Check(bid(3, 50000)), risk.ErrOrderNotional)
+	if v.Instrument != "BTC-PERPETUAL" || v.Value != 150000 || v.Limit != 100000 {
+		t.Errorf("violation = %+v", v)
+	}
+	if got := v.Error(); got != "risk: order notional over limit in BTC-PERPETUAL: 150000, limit 100000" {
+		t.Errorf("Error() = %q", got)
+	}
+
+	// Market orders are valued at the mark price, which must be known.
+	market := types.NewSellOrderParams("BTC-PERPETUAL", 3).WithOrderType(enums.OrderTypeMarket)
+	wantViolation(t, e.Check(market), risk.ErrOrderNotional)
+	wantViolation(t, e.Check(types.NewBuyOrderParams("ETH-PERPETUAL", 1)), risk.ErrNoMarkPrice)
+
+	stop := types.NewStopOrder(enums.DirectionSell, "BTC-PERPETUAL", 3, 45000)
+	wantViolation(t, e.Check(stop), risk.ErrOrderNotional)
+}
+
+func TestEngine_Position(t *testing.T) {
+	e, _ := newEngine(risk.Limits{MaxPosition: map[string]float64{"BTC-PERPETUAL": 5}})
+	e.UpdatePortfolio([]types.PortfolioEntry{{InstrumentName: "BTC-PERPETUAL", Position: 2}})
+	e.UpdateOrders([]types.OrderStatus{{OrderID: "o1", InstrumentName: "BTC-PERPETUAL", Direction: enums.DirectionBuy,
+		Price: ptr(49000.0), Amount: 2, RemainingAmount: 2, Status: enums.OrderStatusOpen}})
+
+	// 2 held, 2 resting and 1 more is 5.
+	if err := e.Check(bid(1, 49000)); err != nil {
+		t.Fatal(err)
+	}
+	v := wantViolation(t, e.Check(bid(0.5, 49000)), risk.ErrPosition)
+	if v.Value != 5.5 || v.Limit != 5 {
+		t.Errorf("violation = %+v", v)
+	}
+	// Sells reduce the position, down to -5.
+	if err := e.Check(ask(7, 50000)); err != nil {
+		t.Error(err)
+	}
+	wantViolation(t, e.Check(ask(0.5, 50000)), risk.ErrPosition)
+
+	// Trades move the position.
+	e.UpdateTrades([]types.Trade{{InstrumentName: "BTC-PERPETUAL", PositionAfter: -5}})
+	if got := e.Position("BTC-PERPETUAL"); got != -5 {
+		t.Errorf("Position = %v, want -5", got)
+	}
+
+	// Bots are checked on the positions they may take.
+	wantViolation(t, e.Check(types.NewSGSLBotParams("BTC-PERPETUAL", enums.TargetMark, 50000, 6, 48000, 0, 0)), risk.ErrPosition)
+	if err := e.Check(types.NewOCQBotParams("BTC-PERPETUAL", enums.TargetMark, 5, 5, 1, -5, 5, 0)); err != nil {
+		t.Error(err)
+	}
+	grid := types.NewGridBotParams("BTC-PERPETUAL", []float64{49000, 50000, 51000}, 2, 0)
+	wantViolation(t, e.Check(grid), risk.ErrPosition)
+}
+
+func TestEngine_UnderlyingPosition(t *testing.T) {
+	instruments := map[string]types.Instrument{
+		"BTC-PERPETUAL": {InstrumentName: "BTC-PERPETUAL", Underlying: "BTCUSD"},
+		"BTC-29MAR24":   {InstrumentName: "BTC-29MAR24", Underlying: "BTCUSD"},
+		"ETH-PERPETUAL": {InstrumentName: "ETH-PERPETUAL", Underlying: "ETHUSD"},
+	}
+	lookup := func(name string) (types.Instrument, bool) {
+		i, ok := instruments[name]
+		return i, ok
+	}
+	e, _ := newEngine(risk.Limits{MaxUnderlyingPosition: map[string]float64{"BTCUSD": 10}}, risk.WithInstruments(lookup))
+	e.UpdatePortfolio([]types.PortfolioEntry{
+		{InstrumentName: "BTC-29MAR24", Position: 8},
+		{InstrumentName: "ETH-PERPETUAL", Position: 100},
+	})
+	if err := e.Check(bid(2, 50000)); err != nil {
+		t.Fatal(err)
+	}
+	v := wantViolation(t, e.Check(bid(1, 50000)), risk.ErrUnderlyingPosition)
+	if v.Value != 11 {
+		t.Errorf("violation = %+v", v)
+	}
+	// Hedging across instruments is allowed.
+	if err := e.Check(ask(15, 50000)); err != nil {
+		t.Error(err)
+	}
+	wantViolation(t, e.Check(types.NewBuyOrderParams("XRP-PERPETUAL", 1).WithPrice(1)), risk.ErrUnknownInstrument)
+
+	// Combination orders are checked per leg.
+	combo := types.NewComboInsertOrderParams(enums.DirectionSell, []types.InsertLeg{
+		{InstrumentName: "BTC-PERPETUAL", Quantity: -1},
+		{InstrumentName: "BTC-29MAR24", Quantity: 1},
+	}, 3).WithPrice(100)
+	wantViolation(t, e.Check(combo), risk.ErrUnderlyingPosition)
+}
+
+func TestEngine_OpenOrders(t *testing.T) {
+	e, c := newEngine(risk.Limits{MaxOpenOrders: 2}, risk.WithReservationTTL(time.Second))
+	e.UpdateOrders([]types.OrderStatus{{OrderID: "o1", InstrumentName: "BTC-PERPETUAL", Direction: enums.DirectionBuy,
+		Price: ptr(49000.0), Amount: 1, RemainingAmount: 1, Status: enums.OrderStatusOpen}})
+
+	// An allowed insert counts as open until its outcome is known.
+	p := bid(1, 49000).WithClientOrderID(7)
+	if err := e.Check(p); err != nil {
+		t.Fatal(err)
+	}
+	wantViolation(t, e.Check(bid(1, 49000)), risk.ErrOpenOrders)
+	// Market and immediate-or-cancel orders do not rest.
+	if err := e.Check(bid(1, 49000).WithTimeInForce(enums.TimeInForceImmediateOrCancel)); err != nil {
+		t.Error(err)
+	}
+
+	// A refused insert releases its reservation.
+	e.OnResult("private/insert", p, nil, &apierr.APIError{Code: 1, Message: "refused"})
+	q := bid(1, 49000).WithClientOrderID(8)
+	if err := e.Check(q); err != nil {
+		t.Fatal(err)
+	}
+	// So does the status of the order, or the reservation expiring.
+	e.OnNotification("account.orders", []types.OrderStatus{{OrderID: "o1", Status: enums.OrderStatusCancelled}})
+	e.OnNotification("account.orders", []types.OrderStatus{{OrderID: "o2", ClientOrderID: ptr[uint64](8), InstrumentName: "BTC-PERPETUAL",
+		Direction: enums.DirectionBuy, Price: ptr(49000.0), Amount: 1, RemainingAmount: 1, Status: enums.OrderStatusOpen}})
+	if err := e.Check(bid(1, 49000)); err != nil {
+		t.Fatal(err)
+	}
+	wantViolation(t, e.Check(bid(1, 49000)), risk.ErrOpenOrders)
+	c.advance(time.Second)
+	if err := e.Check(bid(1, 49000)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestEngine_Price(t *testing.T) {
+	e, _ := newEngine(risk.Limits{MaxPriceDeviation: 0.05, CheckCollars: true})
+	if err := e.Check(bid(1, 50500)); err != nil {
+		t.Fatal(err)
+	}
+	v := wantViolation(t, e.Check(bid(1, 53000)), risk.ErrPriceDeviation)
+	if v.Value != 0.06 || v.Limit != 0.05 {
+		t.Errorf("violation = %+v", v)
+	}
+	v = wantViolation(t, e.Check(bid(1, 51500)), risk.ErrCollar)
+	if v.Value != 51500 || v.Limit != 51000 {
+		t.Errorf("violation = %+v", v)
+	}
+	if err := e.Check(ask(1, 51500)); err != nil {
+		t.Errorf("ask above the collar high = %v", err)
+	}
+	wantViolation(t, e.Check(ask(1, 48500)), risk.ErrCollar)
+	wantViolation(t, e.Check(types.NewBuyOrderParams("ETH-PERPETUAL", 1).WithPrice(3000)), risk.ErrNoMarkPrice)
+
+	// A ticker fetched with a request counts too.
+	e.OnResult("public/ticker", map[string]any{"instrument_name": "ETH-PERPETUAL"}, &types.Ticker{MarkPrice: 3000}, nil)
+	if err := e.Check(types.NewBuyOrderParams("ETH-PERPETUAL", 1).WithPrice(3000)); err != nil {
+		t.Error(err)
+	}
+
+	// Mass quote levels and the limit price of conditional orders.
+	mq := types.NewMassQuoteParams([]types.DoubleSidedQuote{
+		types.NewDoubleSidedQuote("BTC-PERPETUAL",
+			[]types.QuoteLevel{{Price: 49900, Amount: 1}, {Price: 40000, Amount: 1}},
+			[]types.QuoteLevel{{Price: 50100, Amount: 1}}),
+	})
+	wantViolation(t, e.Check(mq), risk.ErrPriceDeviation)
+	stop := types.NewStopLimitOrder(enums.DirectionSell, "BTC-PERPETUAL", 1, 45000, 44000)
+	wantViolation(t, e.Check(stop), risk.ErrPriceDeviation)
+	if err := e.Check(types.NewStopOrder(enums.DirectionSell, "BTC-PERPETUAL", 1, 45000)); err != nil {
+		t.Errorf("stop order = %v; only limit prices are checked", err)
+	}
+}
+
+func TestEngine_MassQuotePosition(t *testing.T) {
+	e, _ := newEngine(risk.Limits{MaxPosition: map[string]float64{"BTC-PERPETUAL": 3}})
+	e.UpdatePortfolio([]types.PortfolioEntry{{InstrumentName: "BTC-PERPETUAL", Position: 1}})
+	quote := func(bids ...types.QuoteLevel) *types.MassQuoteParams {
+		return types.NewMassQuoteParams([]types.DoubleSidedQuote{{I: "BTC-PERPETUAL", B: types.SingleLevelQuote{P: 49900, A: bids[0].Amount}}})
+	}
+	if err := e.Check(quote(types.QuoteLevel{Amount: 2})); err != nil {
+		t.Fatal(err)
+	}
+	wantViolation(t, e.Check(quote(types.QuoteLevel{Amount: 2.5})), risk.ErrPosition)
+}
+
+func TestEngine_MassQuoteType(t *testing.T) {
+	e, _ := newEngine(risk.Limits{MaxPosition: map[string]float64{"BTC-PERPETUAL": 3}})
+	// A side the engine cannot read is refused rather than sent unchecked.
+	mq := types.NewMassQuoteParams([]types.DoubleSidedQuote{
+		{I: "BTC-PERPETUAL", B: []types.QuoteLevel{{Price: 49900, Amount: 100}}},
+	})
+	v := wantViolation(t, e.Check(mq), risk.ErrQuoteType)
+	if v.Instrument != "BTC-PERPETUAL" {
+		t.Errorf("violation = %+v", v)
+	}
+	mq = types.NewMassQuoteParams([]types.DoubleSidedQuote{{I: "BTC-PERPETUAL", A: (*types.SingleLevelQuote)(nil)}})
+	if err := e.Check(mq); err != nil {
+		t.Errorf("nil side = %v", err)
+	}
+}
+
+func TestEngine_MassQuoteLabel(t *testing.T) {
+	e, _ := newEngine(risk.Limits{Labels: map[string]risk.LabelBudget{"mm": {MaxNotional: 150000}}})
+	e.UpdateOrders([]types.OrderStatus{{OrderID: "o1", Label: "mm", InstrumentName: "BTC-PERPETUAL", Direction: enums.DirectionSell,
+		Price: ptr(50000.0), Amount: 1, RemainingAmount: 1, Status: enums.OrderStatusOpen}})
+	quote := func(amount float64) *types.MassQuoteParams {
+		return types.NewMassQuoteParams([]types.DoubleSidedQuote{
+			types.NewDoubleSidedQuote("BTC-PERPETUAL",
+				[]types.QuoteLevel{{Price: 49900, Amount: amount}},
+				[]types.QuoteLevel{{Price: 50100, Amount: amount}}),
+		})
+	}
+	if err := e.Check(quote(1).WithLabel("mm")); err != nil {
+		t.Fatal(err)
+	}
+	v := wantViolation(t, e.Check(quote(1.5).WithLabel("mm")), risk.ErrLabelNotional)
+	if v.Label != "mm" || v.Value != 200000 {
+		t.Errorf("violation = %+v", v)
+	}
+	if err := e.Check(quote(1.5)); err != nil {
+		t.Errorf("unlabelled quote = %v", err)
+	}
+}
+
+func TestEngine_ReduceOnly(t *testing.T) {
+	e, _ := newEngine(risk.Limits{})
+	wantViolation(t, e.Check(ask(1, 50000).WithReduceOnly(true)), risk.ErrReduceOnly)
+	e.UpdatePortfolio([]types.PortfolioEntry{{InstrumentName: "BTC-PERPETUAL", Position: 2}})
+	if err := e.Check(ask(2, 50000).WithReduceOnly(true)); err != nil {
+		t.Fatal(err)
+	}
+	v := wantViolation(t, e.Check(ask(3, 50000).WithReduceOnly(true)), risk.ErrReduceOnly)
+	if v.Value != 3 || v.Limit != 2 {
+		t.Errorf("violation = %+v", v)
+	}
+	wantViolation(t, e.Check(bid(1, 50000).WithReduceOnly(true)), risk.ErrReduceOnly)
+	stop := types.NewStopOrder(enums.DirectionBuy, "BTC-PERPETUAL", 1, 52000)
+	stop.ReduceOnly = ptr(true)
+	wantViolation(t, e.Check(stop), risk.ErrReduceOnly)
+}
+
+func TestEngine_Labels(t *testing.T) {
+	e, c := newEngine(risk.Limits{Labels: map[string]risk.LabelBudget{
+		"mm": {MaxOpenOrders: 2, MaxNotional: 150000, MaxOrderRate: risk.Rate{Count: 3, Per: time.Second}},
+	}})
+	e.UpdateOrders([]types.OrderStatus{{OrderID: "o1", Label: "mm", InstrumentName: "BTC-PERPETUAL", Direction: enums.DirectionSell,
+		Price: ptr(50000.0), Amount: 1, RemainingAmount: 1, Status: enums.OrderStatusOpen}})
+
+	v := wantViolation(t, e.Check(bid(2.5, 50000).WithLabel("mm")), risk.ErrLabelNotional)
+	if v.Label != "mm" || v.Value != 175000 {
+		t.Errorf("violation = %+v", v)
+	}
+	if err := e.Check(bid(1, 50000).WithLabel("mm")); err != nil {
+		t.Fatal(err)
+	}
+	v = wantViolation(t, e.Check(bid(0.1, 50000).WithLabel("mm")), risk.ErrOpenOrders)
+	if v.Label != "mm" {
+		t.Errorf("violation = %+v", v)
+	}
+	// Other labels are not limited.
+	if err := e.Check(bid(10, 50000).WithLabel("other")); err != nil {
+		t.Error(err)
+	}
+
+	// Amending an order replaces its notional.
+	if err := e.Check(types.NewAmendByOrderID("o1", 49000, 1)); err != nil {
+		t.Errorf("amend = %v", err)
+	}
+	wantViolation(t, e.Check(types.NewAmendByOrderID("o1", 50000, 2.5)), risk.ErrLabelNotional)
+
+	// Two requests so far; the label allows three per second.
+	if err := e.Check(types.NewDHedgeBotParams("BTC-PERPETUAL", 60).WithLabel("mm")); err != nil {
+		t.Fatal(err)
+	}
+	wantViolation(t, e.Check(types.NewDHedgeBotParams("BTC-PERPETUAL", 60).WithLabel("mm")), risk.ErrOrderRate)
+	c.advance(time.Second)
+	if err := e.Check(types.NewDHedgeBotParams("BTC-PERPETUAL", 60).WithLabel("mm")); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestEngine_OrderRate(t *testing.T) {
+	e, c := newEngine(risk.Limits{MaxOrderRate: risk.Rate{Count: 2, Per: time.Second}})
+	for i := 0; i < 2; i++ {
+		if err := e.Check(bid(1, 50000)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wantViolation(t, e.Check(bid(1, 50000)), risk.ErrOrderRate)
+	c.advance(500 * time.Millisecond)
+	wantViolation(t, e.Check(bid(1, 50000)), risk.ErrOrderRate)
+	c.advance(500 * time.Millisecond)
+	if err := e.Check(bid(1, 50000)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestEngine_SetLimits(t *testing.T) {
+	e, _ := newEngine(risk.Limits{MaxOrderNotional: 100000})
+	wantViolation(t, e.Check(bid(3, 50000)), risk.ErrOrderNotional)
+
+	var l risk.Limits
+	if err := json.Unmarshal([]byte(`{"max_order_notional": 200000, "max_order_rate": "1/1m"}`), &l); err != nil {
+		t.Fatal(err)
+	}
+	if l.MaxOrderRate != (risk.Rate{Count: 1, Per: time.Minute}) {
+		t.Fatalf("MaxOrderRate = %v", l.MaxOrderRate)
+	}
+	e.SetLimits(l)
+	if err := e.Check(bid(3, 50000)); err != nil {
+		t.Fatal(err)
+	}
+	wantViolation(t, e.Check(bid(1, 50000)), risk.ErrOrderRate)
+	if got := e.Limits(); got.MaxOrderNotional != 200000 {
+		t.Errorf("Limits = %+v", got)
+	}
+
+	if err := json.Unmarshal([]byte(`{"max_order_rate": "fast"}`), &l); err == nil {
+		t.Error("invalid rate accepted")
+	}
+}
+
+func TestEngine_Concurrent(t *testing.T) {
+	e, _ := newEngine(risk.Limits{MaxOpenOrders: 10})
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		allowed int
+	)
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if e.Check(bid(1, 50000)) == nil {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			e.SetLimits(risk.Limits{MaxOpenOrders: 10})
+			e.OnNotification("account.portfolio", []types.PortfolioEntry{{InstrumentName: "BTC-PERPETUAL", Position: 1}})
+		}()
+	}
+	wg.Wait()
+	if allowed != 10 {
+		t.Errorf("%d orders allowed, want 10", allowed)
+	}
+}
@@ -0,0 +1,219 @@
+package risk
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"github.com/amiwrpremium/go-thalex/enums"
+	"github.com/amiwrpremium/go-thalex/types"
+)
+
+// UpdateOrders records order statuses, as received from an order
+// notification or request. Orders that are no longer open are forgotten.
+func (e *Engine) UpdateOrders(orders []types.OrderStatus) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, o := range orders {
+		e.updateOrder(o)
+	}
+}
+
+func (e *Engine) updateOrder(o types.OrderStatus) {
+	if o.ClientOrderID != nil {
+		for p, r := range e.reservations {
+			if r.order.ClientOrderID != nil && *r.order.ClientOrderID == *o.ClientOrderID {
+				delete(e.reservations, p)
+			}
+		}
+	}
+	if o.OrderID == "" {
+		return
+	}
+	if o.Status.IsActive() {
+		e.orders[o.OrderID] = o
+	} else {
+		delete(e.orders, o.OrderID)
+	}
+}
+
+// UpdatePortfolio records positions. Instruments missing from entries
+// keep their position.
+func (e *Engine) UpdatePortfolio(entries []types.PortfolioEntry) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, p := range entries {
+		e.positions[p.InstrumentName] = p.Position
+	}
+}
+
+// UpdateTrades records the positions after trades.
+func (e *Engine) UpdateTrades(trades []types.Trade) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, t := range trades {
+		e.positions[t.InstrumentName] = t.PositionAfter
+	}
+}
+
+// UpdateTicker records the ticker of an instrument, for its mark price and
+// collars.
+func (e *Engine) UpdateTicker(instrument string, t types.Ticker) {
+	e.mu.Lock()
+	e.tickers[instrument] = t
+	e.mu.Unlock()
+}
+
+// Position returns the last known position in an instrument.
+func (e *Engine) Position(instrument string) float64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.positions[instrument]
+}
+
+// OnRequest implements ws.Hook. It does nothing: requests are checked by
+// Check.
+func (e *Engine) OnRequest(string, any) {}
+
+// OnResult implements ws.Hook. It records the orders, positions and
+// tickers returned by requests, and releases the reservation of an insert
+// once its outcome is known.
+func (e *Engine) OnResult(method string, params, result any, err error) {
+	if p, ok := params.(*types.InsertOrderParams); ok {
+		e.mu.Lock()
+		delete(e.reservations, p)
+		e.mu.Unlock()
+	}
+	if err != nil {
+		return
+	}
+	switch r := result.(type) {
+	case *types.OrderStatus:
+		e.UpdateOrders([]types.OrderStatus{*r})
+	case *[]types.PortfolioEntry:
+		e.UpdatePortfolio(*r)
+	case *types.Ticker:
+		if p, ok := params.(map[string]any); ok && method == "public/ticker" {
+			if name, ok := p["instrument_name"].(string); ok {
+				e.UpdateTicker(name, *r)
+			}
+		}
+	}
+}
+
+// OnNotification implements ws.Hook. It records order, portfolio, trade
+// and ticker notifications.
+func (e *Engine) OnNotification(channel string, v any) {
+	switch n := v.(type) {
+	case []types.OrderStatus:
+		e.UpdateOrders(n)
+	case []types.PortfolioEntry:
+		e.UpdatePortfolio(n)
+	case []types.Trade:
+		e.UpdateTrades(n)
+	case types.Ticker:
+		if name, ok := tickerInstrument(channel); ok {
+			e.UpdateTicker(name, n)
+		}
+	}
+}
+
+// tickerInstrument extracts the instrument from a channel such as
+// "ticker.BTC-PERPETUAL.100ms".
+func tickerInstrument(channel string) (string, bool) {
+	rest, ok := strings.CutPrefix(channel, "ticker.")
+	if !ok {
+		return "", false
+	}
+	i := strings.LastIndexByte(rest, '.')
+	if i <= 0 {
+		return "", false
+	}
+	return rest[:i], true
+}
+
+// expire drops the reservations whose order status never arrived.
+func (e *Engine) expire(now time.Time) {
+	for p, r := range e.reservations {
+		if !now.Before(r.expires) {
+			delete(e.reservations, p)
+		}
+	}
+}
+
+// eachOpen calls fn for every open and reserved order.
+func (e *Engine) eachOpen(fn func(types.OrderStatus)) {
+	for _, o := range e.orders {
+		fn(o)
+	}
+	for _, r := range e.reservations {
+		fn(r.order)
+	}
+}
+
+// exposure returns the unfilled amount of the open and reserved orders on
+// one side of an instrument, counting the legs of combination orders.
+func (e *Engine) exposure(instrument string, dir enums.Direction) float64 {
+	var total float64
+	e.eachOpen(func(o types.OrderStatus) {
+		if o.InstrumentName == instrument {
+			if o.Direction == dir {
+				total += o.RemainingAmount
+			}
+			return
+		}
+		for _, leg := range o.Legs {
+			legDir := o.Direction
+			if leg.Quantity < 0 {
+				legDir = opposite(legDir)
+			}
+			if leg.InstrumentName == instrument && legDir == dir {
+				total += o.RemainingAmount * math.Abs(leg.Quantity)
+			}
+		}
+	})
+	return total
+}
+
+// findOrder finds an open order by order ID or client order ID.
+func (e *Engine) findOrder(orderID string, clientOrderID *uint64) (types.OrderStatus, bool) {
+	if orderID != "" {
+		o, ok := e.orders[orderID]
+		return o, ok
+	}
+	if clientOrderID == nil {
+		return types.OrderStatus{}, false
+	}
+	for _, o := range e.orders {
+		if o.ClientOrderID != nil && *o.ClientOrderID == *clientOrderID {
+			return o, true
+		}
+	}
+	return types.OrderStatus{}, false
+}
+
+// orderNotional returns the notional of the unfilled part of an order.
+func orderNotional(o types.OrderStatus) float64 {
+	if o.Price == nil {
+		return 0
+	}
+	return o.RemainingAmount * math.Abs(*o.Price)
+}
+
+// reservedOrder describes an insert that passed the check as an open
+// order.
+func reservedOrder(p *types.InsertOrderParams) types.OrderStatus {
+	o := types.OrderStatus{
+		InstrumentName:  p.InstrumentName,
+		Direction:       p.Direction,
+		Price:           p.Price,
+		Amount:          math.Abs(p.Amount),
+		RemainingAmount: math.Abs(p.Amount),
+		Label:           p.Label,
+		ClientOrderID:   p.ClientOrderID,
+	}
+	for _, leg := range p.Legs {
+		o.Legs = append(o.Legs, types.Leg{InstrumentName: leg.InstrumentName, Quantity: leg.Quantity})
+	}
+	return o
+}
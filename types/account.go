@@ -28,6 +28,33 @@ func (s *AccountSummary) MarginUtilization() float64 {
 	return s.RequiredMargin / s.Margin
 }
 
+// MergeAccountSummaries aggregates summaries from several accounts into one.
+// Numeric totals are summed and cash holdings are combined per currency; the
+// collateral factor and index price of the first holding seen for a currency
+// are kept.
+func MergeAccountSummaries(summaries ...AccountSummary) AccountSummary {
+	var out AccountSummary
+	cashIdx := make(map[string]int)
+	for _, s := range summaries {
+		out.UnrealisedPnl += s.UnrealisedPnl
+		out.CashCollateral += s.CashCollateral
+		out.Margin += s.Margin
+		out.RequiredMargin += s.RequiredMargin
+		out.RemainingMargin += s.RemainingMargin
+		out.SessionRealisedPnl += s.SessionRealisedPnl
+		for _, c := range s.Cash {
+			if i, ok := cashIdx[c.Currency]; ok {
+				out.Cash[i].Balance += c.Balance
+				out.Cash[i].Transactable = out.Cash[i].Transactable || c.Transactable
+				continue
+			}
+			cashIdx[c.Currency] = len(out.Cash)
+			out.Cash = append(out.Cash, c)
+		}
+	}
+	return out
+}
+
 // PortfolioEntry represents a single position in the portfolio.
 type PortfolioEntry struct {
 	InstrumentName             string   `json:"instrument_name"`
@@ -79,6 +79,56 @@ func TestAccountSummary_MarginUtilization(t *testing.T) {
 	}
 }
 
+// ---------- MergeAccountSummaries ----------
+
+func TestMergeAccountSummaries(t *testing.T) {
+	a := types.AccountSummary{
+		Cash: []types.CashHolding{
+			{Currency: "BTC", Balance: 1, CollateralFactor: 0.9},
+			{Currency: "USD", Balance: 1000, Transactable: true},
+		},
+		UnrealisedPnl:  10,
+		Margin:         5000,
+		RequiredMargin: 1000,
+	}
+	b := types.AccountSummary{
+		Cash: []types.CashHolding{
+			{Currency: "BTC", Balance: 0.5, CollateralFactor: 0.8},
+		},
+		UnrealisedPnl:  -4,
+		Margin:         3000,
+		RequiredMargin: 1000,
+	}
+
+	got := types.MergeAccountSummaries(a, b)
+	if got.UnrealisedPnl != 6 {
+		t.Errorf("UnrealisedPnl = %v, want 6", got.UnrealisedPnl)
+	}
+	if got.Margin != 8000 || got.RequiredMargin != 2000 {
+		t.Errorf("Margin/RequiredMargin = %v/%v, want 8000/2000", got.Margin, got.RequiredMargin)
+	}
+	if len(got.Cash) != 2 {
+		t.Fatalf("len(Cash) = %d, want 2", len(got.Cash))
+	}
+	if got.Cash[0].Currency != "BTC" || got.Cash[0].Balance != 1.5 {
+		t.Errorf("Cash[0] = %+v, want BTC 1.5", got.Cash[0])
+	}
+	if got.Cash[0].CollateralFactor != 0.9 {
+		t.Errorf("Cash[0].CollateralFactor = %v, want first-seen 0.9", got.Cash[0].CollateralFactor)
+	}
+	if got.MarginUtilization() != 0.25 {
+		t.Errorf("MarginUtilization() = %v, want 0.25", got.MarginUtilization())
+	}
+	// Merging must not alias the inputs.
+	if a.Cash[0].Balance != 1 {
+		t.Errorf("input mutated: a.Cash[0].Balance = %v", a.Cash[0].Balance)
+	}
+
+	if empty := types.MergeAccountSummaries(); empty.Cash != nil || empty.Margin != 0 {
+		t.Errorf("MergeAccountSummaries() = %+v, want zero value", empty)
+	}
+}
+
 // ---------- PortfolioEntry.IsLong ----------
 
 func TestPortfolioEntry_IsLong(t *testing.T) {
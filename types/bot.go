@@ -1,6 +1,10 @@
 package types
 
-import "github.com/amiwrpremium/go-thalex/enums"
+import (
+	"time"
+
+	"github.com/amiwrpremium/go-thalex/enums"
+)
 
 // Bot represents a bot instance. Use the Strategy field to determine
 // which strategy-specific fields are populated.
@@ -10,9 +14,9 @@ type Bot struct {
 	Status          enums.BotStatus     `json:"status"`
 	StopReason      enums.BotStopReason `json:"stop_reason,omitempty"`
 	InstrumentName  string              `json:"instrument_name"`
-	EndTime         *float64            `json:"end_time,omitempty"`
-	StartTime       float64             `json:"start_time,omitempty"`
-	StopTime        *float64            `json:"stop_time,omitempty"`
+	EndTime         *Timestamp          `json:"end_time,omitempty"`
+	StartTime       Timestamp           `json:"start_time,omitempty"`
+	StopTime        *Timestamp          `json:"stop_time,omitempty"`
 	Label           string              `json:"label,omitempty"`
 	RealisedPnl     float64             `json:"realized_pnl,omitempty"`
 	Fee             float64             `json:"fee,omitempty"`
@@ -79,6 +83,11 @@ func NewSGSLBotParams(instrumentName string, signal enums.Target, entryPrice, ta
 	}
 }
 
+// NewSGSLBotParamsUntil creates SGSL bot parameters that run until endTime.
+func NewSGSLBotParamsUntil(instrumentName string, signal enums.Target, entryPrice, targetPosition, exitPrice, exitPosition float64, endTime time.Time) *SGSLBotParams {
+	return NewSGSLBotParams(instrumentName, signal, entryPrice, targetPosition, exitPrice, exitPosition, 0).WithEndTimeAt(endTime)
+}
+
 // WithMaxSlippage sets the maximum slippage per trade.
 func (p *SGSLBotParams) WithMaxSlippage(v float64) *SGSLBotParams { p.MaxSlippage = &v; return p }
 
@@ -110,6 +119,11 @@ func NewOCQBotParams(instrumentName string, signal enums.Target, bidOffset, askO
 	}
 }
 
+// NewOCQBotParamsUntil creates OCQ bot parameters that run until endTime.
+func NewOCQBotParamsUntil(instrumentName string, signal enums.Target, bidOffset, askOffset, quoteSize, minPos, maxPos float64, endTime time.Time) *OCQBotParams {
+	return NewOCQBotParams(instrumentName, signal, bidOffset, askOffset, quoteSize, minPos, maxPos, 0).WithEndTimeAt(endTime)
+}
+
 // WithExitOffset sets the exit offset.
 func (p *OCQBotParams) WithExitOffset(v float64) *OCQBotParams { p.ExitOffset = &v; return p }
 
@@ -143,6 +157,11 @@ func NewLevelsBotParams(instrumentName string, bids, asks []float64, stepSize, e
 	}
 }
 
+// NewLevelsBotParamsUntil creates Levels bot parameters that run until endTime.
+func NewLevelsBotParamsUntil(instrumentName string, bids, asks []float64, stepSize float64, endTime time.Time) *LevelsBotParams {
+	return NewLevelsBotParams(instrumentName, bids, asks, stepSize, 0).WithEndTimeAt(endTime)
+}
+
 func (p *LevelsBotParams) WithBasePosition(v float64) *LevelsBotParams { p.BasePosition = &v; return p }
 func (p *LevelsBotParams) WithTargetMeanPrice(v float64) *LevelsBotParams {
 	p.TargetMeanPrice = &v
@@ -182,6 +201,11 @@ func NewGridBotParams(instrumentName string, grid []float64, stepSize, endTime f
 	}
 }
 
+// NewGridBotParamsUntil creates Grid bot parameters that run until endTime.
+func NewGridBotParamsUntil(instrumentName string, grid []float64, stepSize float64, endTime time.Time) *GridBotParams {
+	return NewGridBotParams(instrumentName, grid, stepSize, 0).WithEndTimeAt(endTime)
+}
+
 func (p *GridBotParams) WithBasePosition(v float64) *GridBotParams { p.BasePosition = &v; return p }
 func (p *GridBotParams) WithTargetMeanPrice(v float64) *GridBotParams {
 	p.TargetMeanPrice = &v
@@ -208,7 +232,7 @@ type DHedgeBotParams struct {
 	Threshold      *float64          `json:"threshold,omitempty"`
 	Tolerance      *float64          `json:"tolerance,omitempty"`
 	MaxSlippage    *float64          `json:"max_slippage,omitempty"`
-	EndTime        *float64          `json:"end_time,omitempty"`
+	EndTime        *Timestamp        `json:"end_time,omitempty"`
 	Label          string            `json:"label,omitempty"`
 }
 
@@ -224,8 +248,12 @@ func (p *DHedgeBotParams) WithTargetDelta(v float64) *DHedgeBotParams { p.Target
 func (p *DHedgeBotParams) WithThreshold(v float64) *DHedgeBotParams   { p.Threshold = &v; return p }
 func (p *DHedgeBotParams) WithTolerance(v float64) *DHedgeBotParams   { p.Tolerance = &v; return p }
 func (p *DHedgeBotParams) WithMaxSlippage(v float64) *DHedgeBotParams { p.MaxSlippage = &v; return p }
-func (p *DHedgeBotParams) WithEndTime(v float64) *DHedgeBotParams     { p.EndTime = &v; return p }
-func (p *DHedgeBotParams) WithLabel(v string) *DHedgeBotParams        { p.Label = v; return p }
+func (p *DHedgeBotParams) WithEndTime(v float64) *DHedgeBotParams {
+	t := Timestamp(v)
+	p.EndTime = &t
+	return p
+}
+func (p *DHedgeBotParams) WithLabel(v string) *DHedgeBotParams { p.Label = v; return p }
 
 // DFollowBotParams contains parameters for creating a Delta Follower bot.
 type DFollowBotParams struct {
@@ -250,7 +278,83 @@ func NewDFollowBotParams(instrumentName, targetInstrument string, targetAmount,
 	}
 }
 
+// NewDFollowBotParamsUntil creates DFollow bot parameters that run until endTime.
+func NewDFollowBotParamsUntil(instrumentName, targetInstrument string, targetAmount, period float64, endTime time.Time) *DFollowBotParams {
+	return NewDFollowBotParams(instrumentName, targetInstrument, targetAmount, period, 0).WithEndTimeAt(endTime)
+}
+
 func (p *DFollowBotParams) WithThreshold(v float64) *DFollowBotParams   { p.Threshold = &v; return p }
 func (p *DFollowBotParams) WithTolerance(v float64) *DFollowBotParams   { p.Tolerance = &v; return p }
 func (p *DFollowBotParams) WithMaxSlippage(v float64) *DFollowBotParams { p.MaxSlippage = &v; return p }
 func (p *DFollowBotParams) WithLabel(v string) *DFollowBotParams        { p.Label = v; return p }
+
+// WithEndTimeAt sets the time at which the bot stops.
+func (p *SGSLBotParams) WithEndTimeAt(t time.Time) *SGSLBotParams {
+	p.EndTime = NewTimestamp(t).Seconds()
+	return p
+}
+
+// WithEndTimeIn sets the bot to stop d from now.
+func (p *SGSLBotParams) WithEndTimeIn(d time.Duration) *SGSLBotParams {
+	p.EndTime = TimestampIn(d).Seconds()
+	return p
+}
+
+// WithEndTimeAt sets the time at which the bot stops.
+func (p *OCQBotParams) WithEndTimeAt(t time.Time) *OCQBotParams {
+	p.EndTime = NewTimestamp(t).Seconds()
+	return p
+}
+
+// WithEndTimeIn sets the bot to stop d from now.
+func (p *OCQBotParams) WithEndTimeIn(d time.Duration) *OCQBotParams {
+	p.EndTime = TimestampIn(d).Seconds()
+	return p
+}
+
+// WithEndTimeAt sets the time at which the bot stops.
+func (p *LevelsBotParams) WithEndTimeAt(t time.Time) *LevelsBotParams {
+	p.EndTime = NewTimestamp(t).Seconds()
+	return p
+}
+
+// WithEndTimeIn sets the bot to stop d from now.
+func (p *LevelsBotParams) WithEndTimeIn(d time.Duration) *LevelsBotParams {
+	p.EndTime = TimestampIn(d).Seconds()
+	return p
+}
+
+// WithEndTimeAt sets the time at which the bot stops.
+func (p *GridBotParams) WithEndTimeAt(t time.Time) *GridBotParams {
+	p.EndTime = NewTimestamp(t).Seconds()
+	return p
+}
+
+// WithEndTimeIn sets the bot to stop d from now.
+func (p *GridBotParams) WithEndTimeIn(d time.Duration) *GridBotParams {
+	p.EndTime = TimestampIn(d).Seconds()
+	return p
+}
+
+// WithEndTimeAt sets the time at which the bot stops.
+func (p *DFollowBotParams) WithEndTimeAt(t time.Time) *DFollowBotParams {
+	p.EndTime = NewTimestamp(t).Seconds()
+	return p
+}
+
+// WithEndTimeIn sets the bot to stop d from now.
+func (p *DFollowBotParams) WithEndTimeIn(d time.Duration) *DFollowBotParams {
+	p.EndTime = TimestampIn(d).Seconds()
+	return p
+}
+
+// WithEndTimeAt sets the time at which the bot stops.
+func (p *DHedgeBotParams) WithEndTimeAt(t time.Time) *DHedgeBotParams {
+	p.EndTime = TimestampPtr(t)
+	return p
+}
+
+// WithEndTimeIn sets the bot to stop d from now.
+func (p *DHedgeBotParams) WithEndTimeIn(d time.Duration) *DHedgeBotParams {
+	return p.WithEndTimeAt(time.Now().Add(d))
+}
@@ -523,8 +523,8 @@ func TestDFollowBotParams_JSONRoundTrip(t *testing.T) {
 // ========== Bot JSON round-trip ==========
 
 func TestBot_JSONRoundTrip(t *testing.T) {
-	endTime := 1700003600.0
-	stopTime := 1700002000.0
+	endTime := types.Timestamp(1700003600.0)
+	stopTime := types.Timestamp(1700002000.0)
 	avgPrice := 50000.0
 	posSize := 2.5
 	markPriceStop := 49500.0
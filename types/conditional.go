@@ -15,9 +15,9 @@ type ConditionalOrder struct {
 	TrailingStopCallbackRate *float64                     `json:"trailing_stop_callback_rate,omitempty"`
 	Label                    string                       `json:"label,omitempty"`
 	Status                   enums.ConditionalOrderStatus `json:"status"`
-	CreateTime               float64                      `json:"create_time"`
-	UpdateTime               float64                      `json:"update_time"`
-	ConvertTime              *float64                     `json:"convert_time,omitempty"`
+	CreateTime               Timestamp                    `json:"create_time"`
+	UpdateTime               Timestamp                    `json:"update_time"`
+	ConvertTime              *Timestamp                   `json:"convert_time,omitempty"`
 	ConvertedOrderID         string                       `json:"converted_order_id,omitempty"`
 	RejectReason             string                       `json:"reject_reason"`
 	ReduceOnly               bool                         `json:"reduce_only"`
@@ -73,7 +73,7 @@ func TestConditionalOrder_JSONRoundTrip(t *testing.T) {
 	limitPrice := 51000.0
 	bracketPrice := 45000.0
 	callbackRate := 0.05
-	convertTime := 1700001000.0
+	convertTime := types.Timestamp(1700001000.0)
 
 	o := types.ConditionalOrder{
 		OrderID:                  "co-123",
@@ -0,0 +1,548 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// MaxDecimalScale is the largest number of fractional digits a Decimal holds.
+const MaxDecimalScale = 18
+
+// RoundingMode selects how a Decimal is rounded to fewer digits or to a tick.
+type RoundingMode int
+
+const (
+	// RoundNearest rounds to the nearest value, with ties away from zero.
+	RoundNearest RoundingMode = iota
+	// RoundFloor rounds toward negative infinity.
+	RoundFloor
+	// RoundCeil rounds toward positive infinity.
+	RoundCeil
+	// RoundTowardZero truncates toward zero.
+	RoundTowardZero
+)
+
+// String returns the name of the rounding mode.
+func (m RoundingMode) String() string {
+	switch m {
+	case RoundNearest:
+		return "nearest"
+	case RoundFloor:
+		return "floor"
+	case RoundCeil:
+		return "ceil"
+	case RoundTowardZero:
+		return "toward_zero"
+	default:
+		return fmt.Sprintf("RoundingMode(%d)", int(m))
+	}
+}
+
+// Decimal is an exact fixed-point decimal number for prices and amounts.
+//
+// A Decimal decoded from JSON keeps the digits Thalex sent, so 0.1 + 0.2
+// equals 0.3 and FilledAmount.Equal(Amount) behaves as expected. The zero
+// value is 0. Values differing only in trailing zeros (1.5 and 1.50) are
+// equal under [Decimal.Equal] and [Decimal.Cmp] but not under ==.
+//
+// Values are stored as a 64-bit coefficient with up to [MaxDecimalScale]
+// fractional digits. Results that need more digits than fit are rounded
+// to fewer fractional digits. Only a result whose integer part exceeds
+// about 9.2e18 cannot be represented: the Err variants of the arithmetic
+// methods return an error for it, and the others panic.
+type Decimal struct {
+	coef  int64
+	scale uint8
+}
+
+var pow10 = func() [MaxDecimalScale + 1]int64 {
+	var p [MaxDecimalScale + 1]int64
+	p[0] = 1
+	for i := 1; i < len(p); i++ {
+		p[i] = p[i-1] * 10
+	}
+	return p
+}()
+
+var errDecimalOverflow = errors.New("decimal overflow")
+
+// NewDecimal returns coef × 10^-scale, e.g. NewDecimal(12345, 2) is 123.45.
+// It panics if scale is outside [0, MaxDecimalScale].
+func NewDecimal(coef int64, scale int) Decimal {
+	if scale < 0 || scale > MaxDecimalScale {
+		panic(fmt.Sprintf("types: decimal scale %d out of range", scale))
+	}
+	return Decimal{coef: coef, scale: uint8(scale)}
+}
+
+// DecimalFromInt returns the integer n as a Decimal.
+func DecimalFromInt(n int64) Decimal {
+	return Decimal{coef: n}
+}
+
+// DecimalFromFloat converts f using its shortest decimal representation, so
+// DecimalFromFloat(0.1) is exactly 0.1. Digits beyond MaxDecimalScale are
+// rounded. It panics if f is NaN, infinite or too large to represent.
+func DecimalFromFloat(f float64) Decimal {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		panic(fmt.Sprintf("types: cannot convert %v to Decimal", f))
+	}
+	d, err := parseDecimal(strconv.FormatFloat(f, 'f', -1, 64), true)
+	if err != nil {
+		panic(fmt.Sprintf("types: cannot convert %v to Decimal: %v", f, err))
+	}
+	return d
+}
+
+// ParseDecimal parses a decimal string such as "95000.5", "-0.001" or "1e-3".
+func ParseDecimal(s string) (Decimal, error) {
+	return parseDecimal(s, false)
+}
+
+// MustParseDecimal is like ParseDecimal but panics on error. It is intended
+// for constants in code and tests.
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func parseDecimal(s string, roundExcess bool) (Decimal, error) {
+	orig := s
+	fail := func(reason string) (Decimal, error) {
+		return Decimal{}, fmt.Errorf("types: invalid decimal %q: %s", orig, reason)
+	}
+
+	neg := false
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+	exp := 0
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		e, err := strconv.Atoi(s[i+1:])
+		if err != nil {
+			return fail("bad exponent")
+		}
+		// Clamp the exponent so the scale arithmetic cannot overflow;
+		// anything beyond the clamp overflows or rounds to zero anyway.
+		exp = max(min(e, 1<<30), -1<<30)
+		s = s[:i]
+	}
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	if intPart == "" && fracPart == "" {
+		return fail("no digits")
+	}
+	digits := intPart + fracPart
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return fail("unexpected character")
+		}
+	}
+	scale := len(fracPart) - exp
+
+	// Drop leading zeros, then trailing fractional zeros beyond the scale
+	// limit, so values like 1.000…0 with many zeros still fit.
+	digits = strings.TrimLeft(digits, "0")
+	for scale > MaxDecimalScale && strings.HasSuffix(digits, "0") {
+		digits = digits[:len(digits)-1]
+		scale--
+	}
+	var roundUp bool
+	if scale > MaxDecimalScale {
+		if !roundExcess {
+			return fail("too many decimal places")
+		}
+		drop := scale - MaxDecimalScale
+		if drop > len(digits) {
+			digits, drop = "", 0
+		} else {
+			roundUp = digits[len(digits)-drop] >= '5'
+			digits = digits[:len(digits)-drop]
+		}
+		scale = MaxDecimalScale
+	}
+	if scale < 0 {
+		if digits == "" {
+			scale = 0
+		} else if len(digits)-scale > 19 {
+			return fail(errDecimalOverflow.Error())
+		}
+	}
+	for scale < 0 {
+		digits += "0"
+		scale++
+	}
+
+	var coef int64
+	for _, c := range digits {
+		if coef > (math.MaxInt64-int64(c-'0'))/10 {
+			return fail(errDecimalOverflow.Error())
+		}
+		coef = coef*10 + int64(c-'0')
+	}
+	if roundUp {
+		if coef == math.MaxInt64 {
+			return fail(errDecimalOverflow.Error())
+		}
+		coef++
+	}
+	if neg {
+		coef = -coef
+	}
+	return Decimal{coef: coef, scale: uint8(scale)}, nil
+}
+
+// String returns the decimal in plain notation, keeping its scale.
+func (d Decimal) String() string {
+	var digits string
+	if d.coef < 0 {
+		digits = new(big.Int).Neg(big.NewInt(d.coef)).String()
+	} else {
+		digits = strconv.FormatInt(d.coef, 10)
+	}
+	if d.scale > 0 {
+		if pad := int(d.scale) + 1 - len(digits); pad > 0 {
+			digits = strings.Repeat("0", pad) + digits
+		}
+		cut := len(digits) - int(d.scale)
+		digits = digits[:cut] + "." + digits[cut:]
+	}
+	if d.coef < 0 {
+		return "-" + digits
+	}
+	return digits
+}
+
+// Float64 returns the nearest float64 value.
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// Scale returns the number of fractional digits.
+func (d Decimal) Scale() int { return int(d.scale) }
+
+// IsZero reports whether d is zero.
+func (d Decimal) IsZero() bool { return d.coef == 0 }
+
+// Sign returns -1, 0 or +1 depending on the sign of d.
+func (d Decimal) Sign() int {
+	switch {
+	case d.coef < 0:
+		return -1
+	case d.coef > 0:
+		return 1
+	}
+	return 0
+}
+
+// Neg returns -d.
+func (d Decimal) Neg() Decimal {
+	if d.coef == math.MinInt64 {
+		panic("types: " + errDecimalOverflow.Error())
+	}
+	return Decimal{coef: -d.coef, scale: d.scale}
+}
+
+// Abs returns |d|.
+func (d Decimal) Abs() Decimal {
+	if d.coef < 0 {
+		return d.Neg()
+	}
+	return d
+}
+
+// Add returns d + e. It panics if the integer part of the result
+// overflows; see [Decimal.AddErr].
+func (d Decimal) Add(e Decimal) Decimal {
+	return must(d.AddErr(e))
+}
+
+// AddErr returns d + e, or an error if the integer part of the result
+// overflows.
+func (d Decimal) AddErr(e Decimal) (Decimal, error) {
+	a, b, scale := align(d, e)
+	return fromBig(new(big.Int).Add(a, b), scale)
+}
+
+// Sub returns d - e. It panics if the integer part of the result
+// overflows; see [Decimal.SubErr].
+func (d Decimal) Sub(e Decimal) Decimal {
+	return must(d.SubErr(e))
+}
+
+// SubErr returns d - e, or an error if the integer part of the result
+// overflows.
+func (d Decimal) SubErr(e Decimal) (Decimal, error) {
+	a, b, scale := align(d, e)
+	return fromBig(new(big.Int).Sub(a, b), scale)
+}
+
+// Mul returns d × e, rounded to nearest if the exact product needs more
+// fractional digits than fit. It panics if the integer part of the
+// result overflows; see [Decimal.MulErr].
+func (d Decimal) Mul(e Decimal) Decimal {
+	return must(d.MulErr(e))
+}
+
+// MulErr returns d × e like Mul, or an error if the integer part of the
+// result overflows.
+func (d Decimal) MulErr(e Decimal) (Decimal, error) {
+	scale := int(d.scale) + int(e.scale)
+	prod := new(big.Int).Mul(big.NewInt(d.coef), big.NewInt(e.coef))
+	if scale > MaxDecimalScale {
+		prod = divRound(prod, bigPow10(scale-MaxDecimalScale), RoundNearest)
+		scale = MaxDecimalScale
+	}
+	return fromBig(prod, scale)
+}
+
+// Div returns d ÷ e rounded to scale fractional digits with the given mode.
+// It panics if e is zero, if scale is out of range or if the integer part
+// of the result overflows; see [Decimal.DivErr].
+func (d Decimal) Div(e Decimal, scale int, mode RoundingMode) Decimal {
+	if e.coef == 0 {
+		panic("types: decimal division by zero")
+	}
+	if scale < 0 || scale > MaxDecimalScale {
+		panic(fmt.Sprintf("types: decimal scale %d out of range", scale))
+	}
+	return must(d.DivErr(e, scale, mode))
+}
+
+// DivErr returns d ÷ e like Div, but returns an error instead of
+// panicking. A result that needs fewer integer digits but more
+// fractional digits than fit is rounded to nearest at a smaller scale.
+func (d Decimal) DivErr(e Decimal, scale int, mode RoundingMode) (Decimal, error) {
+	if e.coef == 0 {
+		return Decimal{}, errors.New("types: decimal division by zero")
+	}
+	if scale < 0 || scale > MaxDecimalScale {
+		return Decimal{}, fmt.Errorf("types: decimal scale %d out of range", scale)
+	}
+	// d/e = (dc/10^ds) / (ec/10^es); scale the numerator so the integer
+	// quotient has the requested number of fractional digits.
+	num := big.NewInt(d.coef)
+	den := big.NewInt(e.coef)
+	if shift := scale + int(e.scale) - int(d.scale); shift >= 0 {
+		num.Mul(num, bigPow10(shift))
+	} else {
+		den.Mul(den, bigPow10(-shift))
+	}
+	return fromBig(divRound(num, den, mode), scale)
+}
+
+// Cmp compares d and e and returns -1, 0 or +1.
+func (d Decimal) Cmp(e Decimal) int {
+	if d.scale == e.scale {
+		switch {
+		case d.coef < e.coef:
+			return -1
+		case d.coef > e.coef:
+			return 1
+		}
+		return 0
+	}
+	a, b, _ := align(d, e)
+	return a.Cmp(b)
+}
+
+// Equal reports whether d and e are numerically equal.
+func (d Decimal) Equal(e Decimal) bool { return d.Cmp(e) == 0 }
+
+// LessThan reports whether d < e.
+func (d Decimal) LessThan(e Decimal) bool { return d.Cmp(e) < 0 }
+
+// GreaterThan reports whether d > e.
+func (d Decimal) GreaterThan(e Decimal) bool { return d.Cmp(e) > 0 }
+
+// Round returns d rounded to scale fractional digits. Values that already
+// have no more than scale digits are returned unchanged.
+func (d Decimal) Round(scale int, mode RoundingMode) Decimal {
+	if scale < 0 {
+		scale = 0
+	}
+	if scale >= int(d.scale) {
+		return d
+	}
+	q := divRound(big.NewInt(d.coef), bigPow10(int(d.scale)-scale), mode)
+	// The rounded value has no more digits than d, so it always fits.
+	return must(fromBig(q, scale))
+}
+
+// RoundToTick returns the multiple of tick nearest to d in the given mode,
+// e.g. 100.37 rounded to tick 0.5 with RoundFloor is 100.0. A non-positive
+// tick returns d unchanged. It panics if the number of ticks overflows;
+// see [Decimal.RoundToTickErr].
+func (d Decimal) RoundToTick(tick Decimal, mode RoundingMode) Decimal {
+	return must(d.RoundToTickErr(tick, mode))
+}
+
+// RoundToTickErr is like RoundToTick but returns an error if the number
+// of ticks in d does not fit in 64 bits.
+func (d Decimal) RoundToTickErr(tick Decimal, mode RoundingMode) (Decimal, error) {
+	if tick.Sign() <= 0 {
+		return d, nil
+	}
+	n, err := d.DivErr(tick, 0, mode)
+	if err != nil {
+		return Decimal{}, err
+	}
+	return n.MulErr(tick)
+}
+
+// IsMultipleOf reports whether d is an exact multiple of tick. Every value
+// is a multiple of a non-positive tick.
+func (d Decimal) IsMultipleOf(tick Decimal) bool {
+	if tick.Sign() <= 0 {
+		return true
+	}
+	a, b, _ := align(d, tick)
+	return new(big.Int).Rem(a, b).Sign() == 0
+}
+
+// Min returns the smaller of d and e.
+func (d Decimal) Min(e Decimal) Decimal {
+	if e.LessThan(d) {
+		return e
+	}
+	return d
+}
+
+// Max returns the larger of d and e.
+func (d Decimal) Max(e Decimal) Decimal {
+	if e.GreaterThan(d) {
+		return e
+	}
+	return d
+}
+
+// MarshalJSON encodes d as a JSON number with its exact digits.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON decodes a JSON number or numeric string. Null leaves d
+// unchanged.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		s = s[1 : len(s)-1]
+	}
+	// Thalex may send floats with more digits than fit; round them rather
+	// than failing the whole response.
+	v, err := parseDecimal(s, true)
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler, for use as a map key.
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Decimal) UnmarshalText(text []byte) error {
+	v, err := ParseDecimal(string(text))
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// align returns the coefficients of d and e at their common scale.
+func align(d, e Decimal) (*big.Int, *big.Int, int) {
+	a, b := big.NewInt(d.coef), big.NewInt(e.coef)
+	switch {
+	case d.scale < e.scale:
+		a.Mul(a, bigPow10(int(e.scale-d.scale)))
+		return a, b, int(e.scale)
+	case e.scale < d.scale:
+		b.Mul(b, bigPow10(int(d.scale-e.scale)))
+	}
+	return a, b, int(d.scale)
+}
+
+func bigPow10(n int) *big.Int {
+	if n <= MaxDecimalScale {
+		return big.NewInt(pow10[n])
+	}
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// fromBig builds a Decimal from a coefficient. A coefficient that does not
+// fit is rounded to nearest at the largest scale where it does; only an
+// integer part that overflows returns an error.
+func fromBig(coef *big.Int, scale int) (Decimal, error) {
+	if !coef.IsInt64() && scale > 0 {
+		// Find how many digits to drop, then round once, so the result is
+		// not rounded twice.
+		drop := 0
+		for q := new(big.Int).Set(coef); !q.IsInt64() && drop < scale; drop++ {
+			q.Quo(q, big.NewInt(10))
+		}
+		coef = divRound(coef, bigPow10(drop), RoundNearest)
+		scale -= drop
+		if !coef.IsInt64() && scale > 0 {
+			// Rounding up carried past the largest coefficient.
+			coef = divRound(coef, big.NewInt(10), RoundNearest)
+			scale--
+		}
+	}
+	if !coef.IsInt64() {
+		return Decimal{}, fmt.Errorf("types: %w", errDecimalOverflow)
+	}
+	return Decimal{coef: coef.Int64(), scale: uint8(scale)}, nil
+}
+
+// must panics with err, for the arithmetic methods without an Err variant
+// in their name.
+func must(d Decimal, err error) Decimal {
+	if err != nil {
+		panic(err.Error())
+	}
+	return d
+}
+
+// divRound returns num/den rounded to an integer with the given mode.
+func divRound(num, den *big.Int, mode RoundingMode) *big.Int {
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	if r.Sign() == 0 {
+		return q
+	}
+	// Sign of the exact quotient; QuoRem truncates toward zero.
+	neg := (num.Sign() < 0) != (den.Sign() < 0)
+	awayFromZero := false
+	switch mode {
+	case RoundFloor:
+		awayFromZero = neg
+	case RoundCeil:
+		awayFromZero = !neg
+	case RoundNearest:
+		twice := new(big.Int).Abs(r)
+		twice.Lsh(twice, 1)
+		awayFromZero = twice.Cmp(new(big.Int).Abs(den)) >= 0
+	}
+	if awayFromZero {
+		if neg {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return q
+}
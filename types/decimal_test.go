@@ -0,0 +1,288 @@
+package types_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/amiwrpremium/go-thalex/types"
+)
+
+// ---------- Decimal parsing and formatting ----------
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"0", "0"},
+		{"95000.5", "95000.5"},
+		{"95000.50", "95000.50"},
+		{"-0.001", "-0.001"},
+		{"+12", "12"},
+		{".5", "0.5"},
+		{"1e-3", "0.001"},
+		{"1.5E2", "150"},
+		{"0.000000000000000001", "0.000000000000000001"},
+		{"1.0000000000000000000000", "1.000000000000000000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			d, err := types.ParseDecimal(tt.in)
+			if err != nil {
+				t.Fatalf("ParseDecimal(%q) error: %v", tt.in, err)
+			}
+			if got := d.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDecimal_Invalid(t *testing.T) {
+	for _, in := range []string{"", "-", "abc", "1.2.3", "1e", "0.0000000000000000001", "99999999999999999999",
+		"1e99999999999", "1e19", "1e-99999999999"} {
+		if _, err := types.ParseDecimal(in); err == nil {
+			t.Errorf("ParseDecimal(%q) should fail", in)
+		}
+	}
+}
+
+func TestDecimalFromFloat(t *testing.T) {
+	tests := []struct {
+		in   float64
+		want string
+	}{
+		{0.1, "0.1"},
+		{95000.5, "95000.5"},
+		{-0.001, "-0.001"},
+		{1e-20, "0"},
+		{6e-19, "0.000000000000000001"},
+	}
+	for _, tt := range tests {
+		if got := types.DecimalFromFloat(tt.in); !got.Equal(types.MustParseDecimal(tt.want)) {
+			t.Errorf("DecimalFromFloat(%v) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+// ---------- Decimal arithmetic ----------
+
+func TestDecimal_Arithmetic(t *testing.T) {
+	d := types.MustParseDecimal
+	if got := d("0.1").Add(d("0.2")); !got.Equal(d("0.3")) {
+		t.Errorf("0.1 + 0.2 = %s, want 0.3", got)
+	}
+	if got := d("1").Sub(d("0.999")); got.String() != "0.001" {
+		t.Errorf("1 - 0.999 = %s", got)
+	}
+	if got := d("95000.5").Mul(d("0.002")); got.String() != "190.0010" {
+		t.Errorf("95000.5 * 0.002 = %s", got)
+	}
+	if got := d("1").Div(d("3"), 4, types.RoundNearest); got.String() != "0.3333" {
+		t.Errorf("1 / 3 = %s", got)
+	}
+	if got := d("2").Div(d("3"), 2, types.RoundCeil); got.String() != "0.67" {
+		t.Errorf("2 / 3 ceil = %s", got)
+	}
+	if got := d("-1.5").Neg().Abs(); got.String() != "1.5" {
+		t.Errorf("|-(-1.5)| = %s", got)
+	}
+}
+
+func TestDecimal_ArithmeticRoundsScale(t *testing.T) {
+	d := types.MustParseDecimal
+	if got := d("95000.123456789").Mul(d("1.123456789")); !got.Equal(d("106728.5336533677502")) {
+		t.Errorf("95000.123456789 * 1.123456789 = %s", got)
+	}
+	if got := d("95000").Add(d("0.000000000000000001")); !got.Equal(d("95000")) {
+		t.Errorf("95000 + 1e-18 = %s", got)
+	}
+	if got, err := d("9000000000000000000").MulErr(d("2")); err == nil {
+		t.Errorf("MulErr overflow = %s, want error", got)
+	}
+	if got, err := d("9000000000000000000").AddErr(d("9000000000000000000")); err == nil {
+		t.Errorf("AddErr overflow = %s, want error", got)
+	}
+	if _, err := d("1000000000000").RoundToTickErr(d("0.00000001"), types.RoundNearest); err == nil {
+		t.Error("RoundToTickErr with too many ticks should fail")
+	}
+}
+
+func TestDecimal_Compare(t *testing.T) {
+	d := types.MustParseDecimal
+	if !d("1.5").Equal(d("1.50")) {
+		t.Error("1.5 should equal 1.50")
+	}
+	if !d("-2").LessThan(d("0.001")) || !d("10").GreaterThan(d("9.99")) {
+		t.Error("unexpected ordering")
+	}
+	if d("1").Cmp(d("1.000000000000000001")) != -1 {
+		t.Error("Cmp should see the last digit")
+	}
+	if got := d("3").Min(d("2.5")); got.String() != "2.5" {
+		t.Errorf("Min = %s", got)
+	}
+	if got := d("3").Max(d("2.5")); got.String() != "3" {
+		t.Errorf("Max = %s", got)
+	}
+}
+
+// ---------- Decimal rounding ----------
+
+func TestDecimal_Round(t *testing.T) {
+	d := types.MustParseDecimal
+	tests := []struct {
+		in    string
+		scale int
+		mode  types.RoundingMode
+		want  string
+	}{
+		{"1.25", 1, types.RoundNearest, "1.3"},
+		{"-1.25", 1, types.RoundNearest, "-1.3"},
+		{"1.29", 1, types.RoundFloor, "1.2"},
+		{"-1.21", 1, types.RoundFloor, "-1.3"},
+		{"1.21", 1, types.RoundCeil, "1.3"},
+		{"-1.29", 1, types.RoundTowardZero, "-1.2"},
+		{"1.2", 3, types.RoundNearest, "1.2"},
+	}
+	for _, tt := range tests {
+		if got := d(tt.in).Round(tt.scale, tt.mode); got.String() != tt.want {
+			t.Errorf("Round(%s, %d, %s) = %s, want %s", tt.in, tt.scale, tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestDecimal_RoundToTick(t *testing.T) {
+	d := types.MustParseDecimal
+	tests := []struct {
+		in, tick string
+		mode     types.RoundingMode
+		want     string
+	}{
+		{"100.37", "0.5", types.RoundFloor, "100.0"},
+		{"100.37", "0.5", types.RoundCeil, "100.5"},
+		{"100.24", "0.5", types.RoundNearest, "100.0"},
+		{"100.25", "0.5", types.RoundNearest, "100.5"},
+		{"0.0123", "0.001", types.RoundFloor, "0.012"},
+		{"-3.7", "1", types.RoundFloor, "-4"},
+		{"95012", "5", types.RoundNearest, "95010"},
+	}
+	for _, tt := range tests {
+		got := d(tt.in).RoundToTick(d(tt.tick), tt.mode)
+		if !got.Equal(d(tt.want)) {
+			t.Errorf("RoundToTick(%s, %s, %s) = %s, want %s", tt.in, tt.tick, tt.mode, got, tt.want)
+		}
+		if !got.IsMultipleOf(d(tt.tick)) {
+			t.Errorf("%s is not a multiple of %s", got, tt.tick)
+		}
+	}
+	if d("100.3").IsMultipleOf(d("0.5")) {
+		t.Error("100.3 should not be a multiple of 0.5")
+	}
+}
+
+// ---------- Decimal JSON ----------
+
+func TestDecimal_JSONRoundTrip(t *testing.T) {
+	in := `{"price":95000.50,"amount":"0.001","last":null}`
+	var v struct {
+		Price  types.Decimal  `json:"price"`
+		Amount types.Decimal  `json:"amount"`
+		Last   *types.Decimal `json:"last"`
+	}
+	if err := json.Unmarshal([]byte(in), &v); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	if v.Price.String() != "95000.50" || v.Amount.String() != "0.001" || v.Last != nil {
+		t.Errorf("decoded %+v", v)
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	if string(out) != `{"price":95000.50,"amount":0.001,"last":null}` {
+		t.Errorf("Marshal = %s", out)
+	}
+}
+
+func TestDecimal_UnmarshalJSONRoundsExcessDigits(t *testing.T) {
+	var v types.Decimal
+	if err := json.Unmarshal([]byte(`1.2345678901234567e-5`), &v); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	if want := types.MustParseDecimal("0.000012345678901235"); !v.Equal(want) {
+		t.Errorf("decoded %s, want %s", v, want)
+	}
+	if err := json.Unmarshal([]byte(`1e99999999999`), &v); err == nil {
+		t.Error("Unmarshal of a huge exponent should fail")
+	}
+}
+
+// ---------- Decimal variants ----------
+
+func TestDecimalOrderStatus_Decode(t *testing.T) {
+	data := `{"order_id":"o1","amount":0.3,"filled_amount":0.3,"price":95000.5,
+		"fills":[{"trade_id":"t1","price":95000.5,"amount":0.1},{"trade_id":"t2","price":95000.5,"amount":0.2}]}`
+	var o types.DecimalOrderStatus
+	if err := json.Unmarshal([]byte(data), &o); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	if !o.IsFullyFilled() {
+		t.Error("order should be fully filled")
+	}
+	sum := o.Fills[0].Amount.Add(o.Fills[1].Amount)
+	if !sum.Equal(o.FilledAmount) {
+		t.Errorf("sum of fills %s != filled amount %s", sum, o.FilledAmount)
+	}
+}
+
+func TestOrderStatus_Decimal(t *testing.T) {
+	price := 95000.5
+	o := types.OrderStatus{OrderID: "o1", Price: &price, Amount: 0.3, FilledAmount: 0.1,
+		Fills: []types.OrderFill{{TradeID: "t1", Price: price, Amount: 0.1}}}
+	d := o.Decimal()
+	if d.OrderID != "o1" || d.Price.String() != "95000.5" || d.Amount.String() != "0.3" {
+		t.Errorf("unexpected conversion: %+v", d)
+	}
+	if len(d.Fills) != 1 || d.Fills[0].Amount.String() != "0.1" {
+		t.Errorf("unexpected fills: %+v", d.Fills)
+	}
+}
+
+func TestDecimalBook_Decode(t *testing.T) {
+	var b types.DecimalBook
+	if err := json.Unmarshal([]byte(`{"bids":[[95000.5,1.25,0.5]],"asks":[[95001,0.1,0]],"time":1.5}`), &b); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	if b.Bids[0].Price().String() != "95000.5" || b.Bids[0].Amount().String() != "1.25" || b.Asks[0].OutrightAmount().String() != "0" {
+		t.Errorf("unexpected book: %+v", b)
+	}
+	fb := types.Book{Bids: []types.BookLevel{{95000.5, 1.25, 0.5}}}
+	if got := fb.Decimal().Bids[0]; !got.Price().Equal(b.Bids[0].Price()) {
+		t.Errorf("converted level %v != decoded %v", got, b.Bids[0])
+	}
+}
+
+func TestTrade_Decimal(t *testing.T) {
+	fee := 0.0001
+	tr := types.Trade{TradeID: "t1", Price: 95000.5, Amount: 0.1, Fee: 1.5, Index: &fee}
+	d := tr.Decimal()
+	if d.Price.String() != "95000.5" || d.Amount.String() != "0.1" || d.Index.String() != "0.0001" {
+		t.Errorf("unexpected conversion: %+v", d)
+	}
+}
+
+func TestInsertOrderParams_WithDecimal(t *testing.T) {
+	p := types.NewBuyOrderParams("BTC-PERPETUAL", 0).
+		WithDecimalPrice(types.MustParseDecimal("95000.5")).
+		WithDecimalAmount(types.MustParseDecimal("0.3"))
+	data, err := json.Marshal(p)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	var m map[string]json.RawMessage
+	_ = json.Unmarshal(data, &m)
+	if string(m["price"]) != "95000.5" || string(m["amount"]) != "0.3" {
+		t.Errorf("price/amount = %s/%s", m["price"], m["amount"])
+	}
+}
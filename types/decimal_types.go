@@ -0,0 +1,278 @@
+package types
+
+import "github.com/amiwrpremium/go-thalex/enums"
+
+// Decimal-typed variants of the order, trade and book types. They decode
+// from the same JSON as their float64 counterparts but keep prices and
+// amounts exact; decode a raw payload into them directly, or convert an
+// already-decoded value with its Decimal method.
+
+// DecimalBookLevel is a [BookLevel] with exact values:
+// [price, amount, outright_amount].
+type DecimalBookLevel [3]Decimal
+
+// Price returns the price of this book level.
+func (l DecimalBookLevel) Price() Decimal { return l[0] }
+
+// Amount returns the total amount at this level.
+func (l DecimalBookLevel) Amount() Decimal { return l[1] }
+
+// OutrightAmount returns the outright amount at this level.
+func (l DecimalBookLevel) OutrightAmount() Decimal { return l[2] }
+
+// Decimal converts the level to exact decimals.
+func (l BookLevel) Decimal() DecimalBookLevel {
+	return DecimalBookLevel{DecimalFromFloat(l[0]), DecimalFromFloat(l[1]), DecimalFromFloat(l[2])}
+}
+
+// DecimalBook is a [Book] with exact prices and amounts.
+type DecimalBook struct {
+	Bids []DecimalBookLevel `json:"bids"`
+	Asks []DecimalBookLevel `json:"asks"`
+	Last *Decimal           `json:"last,omitempty"`
+	Time Timestamp          `json:"time"`
+}
+
+// Decimal converts the book to exact decimals.
+func (b Book) Decimal() DecimalBook {
+	return DecimalBook{
+		Bids: decimalLevels(b.Bids),
+		Asks: decimalLevels(b.Asks),
+		Last: decimalPtr(b.Last),
+		Time: b.Time,
+	}
+}
+
+// DecimalBookUpdate is a [BookUpdate] with exact prices and amounts.
+type DecimalBookUpdate struct {
+	Bids   []DecimalBookLevel `json:"bids"`
+	Asks   []DecimalBookLevel `json:"asks"`
+	Last   *Decimal           `json:"last,omitempty"`
+	Time   Timestamp          `json:"time"`
+	Trades []DecimalBookTrade `json:"trades,omitempty"`
+}
+
+// Decimal converts the update to exact decimals.
+func (u BookUpdate) Decimal() DecimalBookUpdate {
+	out := DecimalBookUpdate{
+		Bids: decimalLevels(u.Bids),
+		Asks: decimalLevels(u.Asks),
+		Last: decimalPtr(u.Last),
+		Time: u.Time,
+	}
+	for _, t := range u.Trades {
+		out.Trades = append(out.Trades, t.Decimal())
+	}
+	return out
+}
+
+// DecimalBookTrade is a [BookTrade] with exact price and amount.
+type DecimalBookTrade struct {
+	Direction enums.Direction `json:"d"`
+	Price     Decimal         `json:"p"`
+	Amount    Decimal         `json:"a"`
+	Time      Timestamp       `json:"t"`
+}
+
+// Decimal converts the trade tick to exact decimals.
+func (t BookTrade) Decimal() DecimalBookTrade {
+	return DecimalBookTrade{
+		Direction: t.Direction,
+		Price:     DecimalFromFloat(t.Price),
+		Amount:    DecimalFromFloat(t.Amount),
+		Time:      t.Time,
+	}
+}
+
+// DecimalTrade is a [Trade] with exact prices, amounts and fees.
+type DecimalTrade struct {
+	TradeType            enums.TradeType  `json:"trade_type"`
+	TradeID              string           `json:"trade_id"`
+	OrderID              string           `json:"order_id"`
+	InstrumentName       string           `json:"instrument_name"`
+	Direction            enums.Direction  `json:"direction"`
+	Price                Decimal          `json:"price"`
+	Amount               Decimal          `json:"amount"`
+	Label                string           `json:"label,omitempty"`
+	Time                 Timestamp        `json:"time"`
+	PositionAfter        Decimal          `json:"position_after"`
+	SessionRealisedAfter Decimal          `json:"session_realised_after,omitzero"`
+	PositionPnl          *Decimal         `json:"position_pnl,omitempty"`
+	PerpetualFundingPnl  *Decimal         `json:"perpetual_funding_pnl,omitempty"`
+	Fee                  Decimal          `json:"fee"`
+	Index                *Decimal         `json:"index,omitempty"`
+	FeeRate              Decimal          `json:"fee_rate"`
+	FeeBasis             Decimal          `json:"fee_basis"`
+	FundingMark          *Decimal         `json:"funding_mark,omitempty"`
+	LiquidationFee       *Decimal         `json:"liquidation_fee,omitempty"`
+	ClientOrderID        *uint64          `json:"client_order_id,omitempty"`
+	MakerTaker           enums.MakerTaker `json:"maker_taker,omitempty"`
+	BotID                string           `json:"bot_id,omitempty"`
+	LegIndex             int              `json:"leg_index"`
+}
+
+// Decimal converts the trade to exact decimals.
+func (t Trade) Decimal() DecimalTrade {
+	return DecimalTrade{
+		TradeType:            t.TradeType,
+		TradeID:              t.TradeID,
+		OrderID:              t.OrderID,
+		InstrumentName:       t.InstrumentName,
+		Direction:            t.Direction,
+		Price:                DecimalFromFloat(t.Price),
+		Amount:               DecimalFromFloat(t.Amount),
+		Label:                t.Label,
+		Time:                 t.Time,
+		PositionAfter:        DecimalFromFloat(t.PositionAfter),
+		SessionRealisedAfter: DecimalFromFloat(t.SessionRealisedAfter),
+		PositionPnl:          decimalPtr(t.PositionPnl),
+		PerpetualFundingPnl:  decimalPtr(t.PerpetualFundingPnl),
+		Fee:                  DecimalFromFloat(t.Fee),
+		Index:                decimalPtr(t.Index),
+		FeeRate:              DecimalFromFloat(t.FeeRate),
+		FeeBasis:             DecimalFromFloat(t.FeeBasis),
+		FundingMark:          decimalPtr(t.FundingMark),
+		LiquidationFee:       decimalPtr(t.LiquidationFee),
+		ClientOrderID:        t.ClientOrderID,
+		MakerTaker:           t.MakerTaker,
+		BotID:                t.BotID,
+		LegIndex:             t.LegIndex,
+	}
+}
+
+// DecimalLeg is a [Leg] with exact quantities.
+type DecimalLeg struct {
+	InstrumentName  string  `json:"instrument_name"`
+	Quantity        Decimal `json:"quantity"`
+	FilledAmount    Decimal `json:"filled_amount"`
+	RemainingAmount Decimal `json:"remaining_amount,omitzero"`
+}
+
+// Decimal converts the leg to exact decimals.
+func (l Leg) Decimal() DecimalLeg {
+	return DecimalLeg{
+		InstrumentName:  l.InstrumentName,
+		Quantity:        DecimalFromFloat(l.Quantity),
+		FilledAmount:    DecimalFromFloat(l.FilledAmount),
+		RemainingAmount: DecimalFromFloat(l.RemainingAmount),
+	}
+}
+
+// DecimalOrderFill is an [OrderFill] with exact price and amount.
+type DecimalOrderFill struct {
+	TradeID    string           `json:"trade_id"`
+	Price      Decimal          `json:"price"`
+	Amount     Decimal          `json:"amount"`
+	Time       Timestamp        `json:"time,omitempty"`
+	MakerTaker enums.MakerTaker `json:"maker_taker"`
+	LegIndex   int              `json:"leg_index"`
+}
+
+// Decimal converts the fill to exact decimals.
+func (f OrderFill) Decimal() DecimalOrderFill {
+	return DecimalOrderFill{
+		TradeID:    f.TradeID,
+		Price:      DecimalFromFloat(f.Price),
+		Amount:     DecimalFromFloat(f.Amount),
+		Time:       f.Time,
+		MakerTaker: f.MakerTaker,
+		LegIndex:   f.LegIndex,
+	}
+}
+
+// DecimalOrderStatus is an [OrderStatus] with exact prices and amounts.
+type DecimalOrderStatus struct {
+	OrderID            string                 `json:"order_id"`
+	OrderType          enums.OrderType        `json:"order_type"`
+	TimeInForce        enums.TimeInForce      `json:"time_in_force"`
+	InstrumentName     string                 `json:"instrument_name,omitempty"`
+	Legs               []DecimalLeg           `json:"legs,omitempty"`
+	Direction          enums.Direction        `json:"direction"`
+	Price              *Decimal               `json:"price,omitempty"`
+	Amount             Decimal                `json:"amount"`
+	FilledAmount       Decimal                `json:"filled_amount"`
+	RemainingAmount    Decimal                `json:"remaining_amount"`
+	Label              string                 `json:"label,omitempty"`
+	ClientOrderID      *uint64                `json:"client_order_id,omitempty"`
+	Status             enums.OrderStatusValue `json:"status"`
+	Fills              []DecimalOrderFill     `json:"fills"`
+	ChangeReason       enums.ChangeReason     `json:"change_reason"`
+	DeleteReason       enums.DeleteReason     `json:"delete_reason,omitempty"`
+	InsertReason       enums.InsertReason     `json:"insert_reason"`
+	ConditionalOrderID string                 `json:"conditional_order_id,omitempty"`
+	BotID              string                 `json:"bot_id,omitempty"`
+	CreateTime         Timestamp              `json:"create_time"`
+	CloseTime          *Timestamp             `json:"close_time,omitempty"`
+	ReduceOnly         bool                   `json:"reduce_only,omitempty"`
+	Persistent         bool                   `json:"persistent"`
+}
+
+// IsFullyFilled reports whether the filled amount equals the order amount.
+func (o DecimalOrderStatus) IsFullyFilled() bool {
+	return o.FilledAmount.Equal(o.Amount)
+}
+
+// Decimal converts the order status to exact decimals.
+func (o OrderStatus) Decimal() DecimalOrderStatus {
+	out := DecimalOrderStatus{
+		OrderID:            o.OrderID,
+		OrderType:          o.OrderType,
+		TimeInForce:        o.TimeInForce,
+		InstrumentName:     o.InstrumentName,
+		Direction:          o.Direction,
+		Price:              decimalPtr(o.Price),
+		Amount:             DecimalFromFloat(o.Amount),
+		FilledAmount:       DecimalFromFloat(o.FilledAmount),
+		RemainingAmount:    DecimalFromFloat(o.RemainingAmount),
+		Label:              o.Label,
+		ClientOrderID:      o.ClientOrderID,
+		Status:             o.Status,
+		ChangeReason:       o.ChangeReason,
+		DeleteReason:       o.DeleteReason,
+		InsertReason:       o.InsertReason,
+		ConditionalOrderID: o.ConditionalOrderID,
+		BotID:              o.BotID,
+		CreateTime:         o.CreateTime,
+		CloseTime:          o.CloseTime,
+		ReduceOnly:         o.ReduceOnly,
+		Persistent:         o.Persistent,
+	}
+	for _, l := range o.Legs {
+		out.Legs = append(out.Legs, l.Decimal())
+	}
+	for _, f := range o.Fills {
+		out.Fills = append(out.Fills, f.Decimal())
+	}
+	return out
+}
+
+// WithDecimalPrice sets the limit price from an exact decimal. Prices with
+// up to 15 significant digits are sent exactly.
+func (p *InsertOrderParams) WithDecimalPrice(v Decimal) *InsertOrderParams {
+	return p.WithPrice(v.Float64())
+}
+
+// WithDecimalAmount sets the order amount from an exact decimal.
+func (p *InsertOrderParams) WithDecimalAmount(v Decimal) *InsertOrderParams {
+	p.Amount = v.Float64()
+	return p
+}
+
+func decimalLevels(levels []BookLevel) []DecimalBookLevel {
+	if levels == nil {
+		return nil
+	}
+	out := make([]DecimalBookLevel, len(levels))
+	for i, l := range levels {
+		out[i] = l.Decimal()
+	}
+	return out
+}
+
+func decimalPtr(f *float64) *Decimal {
+	if f == nil {
+		return nil
+	}
+	d := DecimalFromFloat(*f)
+	return &d
+}
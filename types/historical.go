@@ -1,14 +1,20 @@
 package types
 
-import "github.com/amiwrpremium/go-thalex/enums"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/amiwrpremium/go-thalex/enums"
+)
 
 // OHLC represents a standard Open-High-Low-Close data point.
 type OHLC struct {
-	Time  float64 `json:"time"`
-	Open  float64 `json:"open"`
-	High  float64 `json:"high"`
-	Low   float64 `json:"low"`
-	Close float64 `json:"close"`
+	Time  Timestamp `json:"time"`
+	Open  float64   `json:"open"`
+	High  float64   `json:"high"`
+	Low   float64   `json:"low"`
+	Close float64   `json:"close"`
 }
 
 // TopOfBook represents best bid/ask at a point in time.
@@ -45,113 +51,177 @@ type OptionMarkData struct {
 	TopOfBook *TopOfBook `json:"top_of_book,omitempty"`
 }
 
+// ErrMalformedRow is wrapped by every [RowError].
+var ErrMalformedRow = errors.New("malformed historical data row")
+
+// errTopOfBook marks a RowError for a row that was kept without its
+// malformed top of book.
+var errTopOfBook = errors.New("top of book")
+
+// RowError reports a historical data row that could not be decoded, or
+// whose optional top of book could not.
+type RowError struct {
+	// Field is the JSON field holding the rows, "mark" or "index".
+	Field string
+	// Row is the index of the row within Field.
+	Row int
+	// Err describes the problem and wraps ErrMalformedRow.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *RowError) Error() string {
+	return fmt.Sprintf("types: %s[%d]: %v", e.Field, e.Row, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *RowError) Unwrap() error { return e.Err }
+
 // MarkPriceHistoricalResult contains mark price historical data.
-// The raw Mark field contains arrays whose format depends on InstrumentType.
-// Use the typed accessor methods to parse the data.
+//
+// When decoded from JSON, the rows are parsed into the typed field matching
+// InstrumentType: Perpetual, Option, or Future for futures and
+// combinations. A malformed row does not fail the decoding: it is left
+// out, or kept with a nil TopOfBook if only its top of book is malformed,
+// and reported by RowErrors. Mark keeps the raw rows.
 type MarkPriceHistoricalResult struct {
 	InstrumentType enums.InstrumentType `json:"instrument_type"`
 	Mark           [][]any              `json:"mark"`
 	NoData         bool                 `json:"no_data,omitempty"`
+
+	Perpetual []PerpetualMarkData `json:"-"`
+	Future    []FutureMarkData    `json:"-"`
+	Option    []OptionMarkData    `json:"-"`
+
+	rowErrs []error
+}
+
+// UnmarshalJSON decodes the result and parses the rows by InstrumentType.
+func (r *MarkPriceHistoricalResult) UnmarshalJSON(data []byte) error {
+	type plain MarkPriceHistoricalResult
+	var p plain
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	*r = MarkPriceHistoricalResult(p)
+	switch r.InstrumentType {
+	case enums.InstrumentTypePerpetual:
+		r.Perpetual, r.rowErrs = parseRows(r.Mark, "mark", parsePerpetualRow)
+	case enums.InstrumentTypeOption:
+		r.Option, r.rowErrs = parseRows(r.Mark, "mark", parseOptionRow)
+	case enums.InstrumentTypeFuture, enums.InstrumentTypeCombination:
+		r.Future, r.rowErrs = parseRows(r.Mark, "mark", parseFutureRow)
+	}
+	return nil
 }
 
-// PerpetualData parses the Mark field as perpetual data points.
+// RowErrors returns a [RowError] for every malformed row found when the
+// result was decoded from JSON, or nil.
+func (r *MarkPriceHistoricalResult) RowErrors() []error { return r.rowErrs }
+
+// PerpetualData returns the rows as perpetual data points. Rows that do
+// not match the perpetual format are skipped; a malformed top of book is
+// left nil.
 func (r *MarkPriceHistoricalResult) PerpetualData() []PerpetualMarkData {
-	out := make([]PerpetualMarkData, 0, len(r.Mark))
-	for _, row := range r.Mark {
-		if len(row) < 6 {
-			continue
-		}
-		d := PerpetualMarkData{
-			OHLC: OHLC{
-				Time:  toFloat(row[0]),
-				Open:  toFloat(row[1]),
-				High:  toFloat(row[2]),
-				Low:   toFloat(row[3]),
-				Close: toFloat(row[4]),
-			},
-			FundingPayment: toFloat(row[5]),
-		}
-		if len(row) >= 7 {
-			d.TopOfBook = parseTopOfBook(row[6])
-		}
-		out = append(out, d)
+	if r.Perpetual != nil {
+		return r.Perpetual
 	}
+	out, _ := parseRows(r.Mark, "mark", parsePerpetualRow)
 	return out
 }
 
-// FutureData parses the Mark field as future/combination data points.
+// FutureData returns the rows as future/combination data points. Rows that
+// do not match the future format are skipped; a malformed top of book is
+// left nil.
 func (r *MarkPriceHistoricalResult) FutureData() []FutureMarkData {
-	out := make([]FutureMarkData, 0, len(r.Mark))
-	for _, row := range r.Mark {
-		if len(row) < 5 {
-			continue
-		}
-		d := FutureMarkData{
-			OHLC: OHLC{
-				Time:  toFloat(row[0]),
-				Open:  toFloat(row[1]),
-				High:  toFloat(row[2]),
-				Low:   toFloat(row[3]),
-				Close: toFloat(row[4]),
-			},
-		}
-		if len(row) >= 6 {
-			d.TopOfBook = parseTopOfBook(row[5])
-		}
-		out = append(out, d)
+	if r.Future != nil {
+		return r.Future
 	}
+	out, _ := parseRows(r.Mark, "mark", parseFutureRow)
 	return out
 }
 
-// OptionData parses the Mark field as option data points.
+// OptionData returns the rows as option data points. Rows that do not
+// match the option format are skipped; a malformed top of book is left
+// nil.
 func (r *MarkPriceHistoricalResult) OptionData() []OptionMarkData {
-	out := make([]OptionMarkData, 0, len(r.Mark))
-	for _, row := range r.Mark {
-		if len(row) < 9 {
-			continue
+	if r.Option != nil {
+		return r.Option
+	}
+	out, _ := parseRows(r.Mark, "mark", parseOptionRow)
+	return out
+}
+
+// MarkCandle is one mark price data point of any instrument type. The
+// common OHLC and top-of-book fields are always set; exactly one of
+// Perpetual, Future and Option holds the type-specific data, as given by
+// Type.
+type MarkCandle struct {
+	OHLC
+	TopOfBook *TopOfBook
+	Type      enums.InstrumentType
+	Perpetual *PerpetualMarkData
+	Future    *FutureMarkData
+	Option    *OptionMarkData
+}
+
+// Candles returns the rows for whatever InstrumentType the result has.
+// It returns nil if InstrumentType is not recognized.
+func (r *MarkPriceHistoricalResult) Candles() []MarkCandle {
+	var out []MarkCandle
+	switch r.InstrumentType {
+	case enums.InstrumentTypePerpetual:
+		for _, d := range r.PerpetualData() {
+			out = append(out, MarkCandle{OHLC: d.OHLC, TopOfBook: d.TopOfBook, Type: r.InstrumentType, Perpetual: &d})
 		}
-		d := OptionMarkData{
-			OHLC: OHLC{
-				Time:  toFloat(row[0]),
-				Open:  toFloat(row[1]),
-				High:  toFloat(row[2]),
-				Low:   toFloat(row[3]),
-				Close: toFloat(row[4]),
-			},
-			IVOpen:  toFloat(row[5]),
-			IVHigh:  toFloat(row[6]),
-			IVLow:   toFloat(row[7]),
-			IVClose: toFloat(row[8]),
+	case enums.InstrumentTypeOption:
+		for _, d := range r.OptionData() {
+			out = append(out, MarkCandle{OHLC: d.OHLC, TopOfBook: d.TopOfBook, Type: r.InstrumentType, Option: &d})
 		}
-		if len(row) >= 10 {
-			d.TopOfBook = parseTopOfBook(row[9])
+	case enums.InstrumentTypeFuture, enums.InstrumentTypeCombination:
+		for _, d := range r.FutureData() {
+			out = append(out, MarkCandle{OHLC: d.OHLC, TopOfBook: d.TopOfBook, Type: r.InstrumentType, Future: &d})
 		}
-		out = append(out, d)
 	}
 	return out
 }
 
 // IndexPriceHistoricalResult contains index price historical data.
+//
+// When decoded from JSON, the rows are parsed into Rows. A malformed row
+// does not fail the decoding: it is left out and reported by RowErrors.
+// Index keeps the raw rows.
 type IndexPriceHistoricalResult struct {
 	Index  [][]any `json:"index"`
 	NoData bool    `json:"no_data,omitempty"`
+
+	Rows []OHLC `json:"-"`
+
+	rowErrs []error
+}
+
+// UnmarshalJSON decodes the result and parses the rows.
+func (r *IndexPriceHistoricalResult) UnmarshalJSON(data []byte) error {
+	type plain IndexPriceHistoricalResult
+	var p plain
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	*r = IndexPriceHistoricalResult(p)
+	r.Rows, r.rowErrs = parseRows(r.Index, "index", parseOHLCRow)
+	return nil
 }
 
-// Data parses the Index field as OHLC data points.
+// RowErrors returns a [RowError] for every malformed row found when the
+// result was decoded from JSON, or nil.
+func (r *IndexPriceHistoricalResult) RowErrors() []error { return r.rowErrs }
+
+// Data returns the rows as OHLC data points. Malformed rows are skipped.
 func (r *IndexPriceHistoricalResult) Data() []OHLC {
-	out := make([]OHLC, 0, len(r.Index))
-	for _, row := range r.Index {
-		if len(row) < 5 {
-			continue
-		}
-		out = append(out, OHLC{
-			Time:  toFloat(row[0]),
-			Open:  toFloat(row[1]),
-			High:  toFloat(row[2]),
-			Low:   toFloat(row[3]),
-			Close: toFloat(row[4]),
-		})
+	if r.Rows != nil {
+		return r.Rows
 	}
+	out, _ := parseRows(r.Index, "index", parseOHLCRow)
 	return out
 }
 
@@ -164,40 +234,133 @@ type HistoricalDataParams struct {
 	Resolution     enums.Resolution `json:"resolution"`
 }
 
-func toFloat(v any) float64 {
+// parseRows parses every row with parse, collecting a RowError for each
+// malformed row. A row whose only problem is its top of book is kept.
+func parseRows[T any](rows [][]any, field string, parse func([]any) (T, error)) ([]T, []error) {
+	out := make([]T, 0, len(rows))
+	var errs []error
+	for i, row := range rows {
+		v, err := parse(row)
+		if err != nil {
+			errs = append(errs, &RowError{Field: field, Row: i, Err: err})
+			if !errors.Is(err, errTopOfBook) {
+				continue
+			}
+		}
+		out = append(out, v)
+	}
+	return out, errs
+}
+
+// cells checks that row has n required cells and at most one optional
+// trailing top-of-book cell, and returns the numeric values of the
+// required ones. A malformed top of book is returned as an error wrapping
+// errTopOfBook, alongside the values.
+func cells(row []any, n int) ([]float64, *TopOfBook, error) {
+	if len(row) < n || len(row) > n+1 {
+		return nil, nil, fmt.Errorf("%w: %d cells, want %d or %d", ErrMalformedRow, len(row), n, n+1)
+	}
+	out := make([]float64, n)
+	for i := range n {
+		f, ok := toFloat(row[i])
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: cell %d is %T, want a number", ErrMalformedRow, i, row[i])
+		}
+		out[i] = f
+	}
+	if len(row) == n {
+		return out, nil, nil
+	}
+	tob, err := parseTopOfBook(row[n])
+	if err != nil {
+		return out, nil, fmt.Errorf("%w: cell %d: %w: %v", ErrMalformedRow, n, errTopOfBook, err)
+	}
+	return out, tob, nil
+}
+
+func ohlcFrom(v []float64) OHLC {
+	return OHLC{Time: Timestamp(v[0]), Open: v[1], High: v[2], Low: v[3], Close: v[4]}
+}
+
+// parseOHLCRow parses [time, open, high, low, close].
+func parseOHLCRow(row []any) (OHLC, error) {
+	if len(row) != 5 {
+		return OHLC{}, fmt.Errorf("%w: %d cells, want 5", ErrMalformedRow, len(row))
+	}
+	v, _, err := cells(row, 5)
+	if err != nil {
+		return OHLC{}, err
+	}
+	return ohlcFrom(v), nil
+}
+
+// parsePerpetualRow parses [time, open, high, low, close, funding_payment, tob?].
+func parsePerpetualRow(row []any) (PerpetualMarkData, error) {
+	v, tob, err := cells(row, 6)
+	if v == nil {
+		return PerpetualMarkData{}, err
+	}
+	return PerpetualMarkData{OHLC: ohlcFrom(v), FundingPayment: v[5], TopOfBook: tob}, err
+}
+
+// parseFutureRow parses [time, open, high, low, close, tob?].
+func parseFutureRow(row []any) (FutureMarkData, error) {
+	v, tob, err := cells(row, 5)
+	if v == nil {
+		return FutureMarkData{}, err
+	}
+	return FutureMarkData{OHLC: ohlcFrom(v), TopOfBook: tob}, err
+}
+
+// parseOptionRow parses [time, open, high, low, close, iv_open, iv_high,
+// iv_low, iv_close, tob?].
+func parseOptionRow(row []any) (OptionMarkData, error) {
+	v, tob, err := cells(row, 9)
+	if v == nil {
+		return OptionMarkData{}, err
+	}
+	return OptionMarkData{
+		OHLC:   ohlcFrom(v),
+		IVOpen: v[5], IVHigh: v[6], IVLow: v[7], IVClose: v[8],
+		TopOfBook: tob,
+	}, err
+}
+
+func toFloat(v any) (float64, bool) {
 	switch n := v.(type) {
 	case float64:
-		return n
+		return n, true
 	case int:
-		return float64(n)
+		return float64(n), true
 	case int64:
-		return float64(n)
-	default:
-		return 0
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
 	}
+	return 0, false
 }
 
-func parseTopOfBook(v any) *TopOfBook {
-	arr, ok := v.([]any)
-	if !ok || len(arr) < 4 {
-		return nil
-	}
-	tob := &TopOfBook{}
-	if arr[0] != nil {
-		f := toFloat(arr[0])
-		tob.BidPrice = &f
-	}
-	if arr[1] != nil {
-		f := toFloat(arr[1])
-		tob.BidSize = &f
+// parseTopOfBook parses [bid_price, bid_size, ask_price, ask_size], where
+// each value may be null. A null cell yields a nil TopOfBook.
+func parseTopOfBook(v any) (*TopOfBook, error) {
+	if v == nil {
+		return nil, nil
 	}
-	if arr[2] != nil {
-		f := toFloat(arr[2])
-		tob.AskPrice = &f
+	arr, ok := v.([]any)
+	if !ok || len(arr) != 4 {
+		return nil, fmt.Errorf("top of book is %T, want an array of 4", v)
 	}
-	if arr[3] != nil {
-		f := toFloat(arr[3])
-		tob.AskSize = &f
+	var vals [4]*float64
+	for i, c := range arr {
+		if c == nil {
+			continue
+		}
+		f, ok := toFloat(c)
+		if !ok {
+			return nil, fmt.Errorf("top of book value %d is %T, want a number or null", i, c)
+		}
+		vals[i] = &f
 	}
-	return tob
+	return &TopOfBook{BidPrice: vals[0], BidSize: vals[1], AskPrice: vals[2], AskSize: vals[3]}, nil
 }
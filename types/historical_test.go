@@ -2,6 +2,7 @@ package types_test
 
 import (
 	"encoding/json"
+	"errors"
 	"testing"
 
 	"github.com/amiwrpremium/go-thalex/enums"
@@ -211,18 +212,19 @@ func TestMarkPriceHistoricalResult_ToFloatEdgeCases(t *testing.T) {
 		}
 	})
 
-	t.Run("unsupported_type_returns_zero", func(t *testing.T) {
+	t.Run("non_numeric_row_skipped", func(t *testing.T) {
 		r := &types.MarkPriceHistoricalResult{
 			Mark: [][]any{
 				{"not_a_number", "a", "b", "c", "d", "e"},
+				{1.0, 2.0, 3.0, 4.0, 5.0, 6.0},
 			},
 		}
 		data := r.PerpetualData()
 		if len(data) != 1 {
-			t.Fatalf("expected 1 data point, got %d", len(data))
+			t.Fatalf("expected 1 data point (non-numeric row skipped), got %d", len(data))
 		}
-		if data[0].Time != 0 {
-			t.Errorf("Time = %v, want 0 for unsupported type", data[0].Time)
+		if data[0].Time != 1 {
+			t.Errorf("Time = %v, want 1", data[0].Time)
 		}
 	})
 }
@@ -512,3 +514,127 @@ func TestOHLC_JSONRoundTrip(t *testing.T) {
 		t.Errorf("High = %v, want 50100.0", got.High)
 	}
 }
+
+// ---------- Typed decoding ----------
+
+func TestMarkPriceHistoricalResult_UnmarshalJSON(t *testing.T) {
+	t.Run("perpetual", func(t *testing.T) {
+		var r types.MarkPriceHistoricalResult
+		err := json.Unmarshal([]byte(`{"instrument_type":"perpetual","mark":[
+			[1700000000,50000,50100,49900,50050,0.001],
+			[1700003600,50050,50200,49950,50100,0.002,[49990,5,null,null]]]}`), &r)
+		if err != nil {
+			t.Fatalf("Unmarshal error: %v", err)
+		}
+		if len(r.Perpetual) != 2 || r.Future != nil || r.Option != nil {
+			t.Fatalf("Perpetual = %d rows, Future = %v, Option = %v", len(r.Perpetual), r.Future, r.Option)
+		}
+		if r.Perpetual[1].FundingPayment != 0.002 || r.Perpetual[1].TopOfBook.AskPrice != nil {
+			t.Errorf("row 1 = %+v", r.Perpetual[1])
+		}
+		if len(r.Mark) != 2 {
+			t.Errorf("raw rows = %d, want 2", len(r.Mark))
+		}
+	})
+
+	t.Run("combination decodes as future", func(t *testing.T) {
+		var r types.MarkPriceHistoricalResult
+		err := json.Unmarshal([]byte(`{"instrument_type":"combination","mark":[[1,2,3,1,2]]}`), &r)
+		if err != nil || len(r.Future) != 1 || r.Future[0].Close != 2 {
+			t.Errorf("Future = %+v, err = %v", r.Future, err)
+		}
+	})
+
+	t.Run("malformed rows are reported", func(t *testing.T) {
+		var r types.MarkPriceHistoricalResult
+		err := json.Unmarshal([]byte(`{"instrument_type":"option","mark":[
+			[1,0.05,0.06,0.04,0.055,0.5,0.6,0.4,0.55],
+			[2,0.05,0.06,0.04,null,0.5,0.6,0.4,0.55],
+			[3,0.05,0.06,0.04,0.055],
+			[4,0.05,0.06,0.04,0.055,0.5,0.6,0.4,0.55,"tob"]]}`), &r)
+		if err != nil {
+			t.Fatalf("Unmarshal error: %v", err)
+		}
+		errs := r.RowErrors()
+		if len(errs) != 3 {
+			t.Fatalf("RowErrors = %v, want 3", errs)
+		}
+		for i, err := range errs {
+			var rowErr *types.RowError
+			if !errors.As(err, &rowErr) || !errors.Is(err, types.ErrMalformedRow) || rowErr.Field != "mark" || rowErr.Row != i+1 {
+				t.Errorf("RowErrors[%d] = %v", i, err)
+			}
+		}
+		// The last row is kept without its malformed top of book.
+		if len(r.Option) != 2 || r.Option[0].Time != 1 || r.Option[1].Time != 4 || r.Option[1].TopOfBook != nil {
+			t.Errorf("Option = %+v, want rows 1 and 4", r.Option)
+		}
+	})
+}
+
+func TestMarkPriceHistoricalResult_Candles(t *testing.T) {
+	tests := []struct {
+		json string
+		typ  enums.InstrumentType
+	}{
+		{`{"instrument_type":"perpetual","mark":[[1,2,3,1,2.5,0.1]]}`, enums.InstrumentTypePerpetual},
+		{`{"instrument_type":"future","mark":[[1,2,3,1,2.5,[1,1,3,1]]]}`, enums.InstrumentTypeFuture},
+		{`{"instrument_type":"option","mark":[[1,2,3,1,2.5,0.5,0.6,0.4,0.55]]}`, enums.InstrumentTypeOption},
+	}
+	for _, tt := range tests {
+		t.Run(tt.typ.String(), func(t *testing.T) {
+			var r types.MarkPriceHistoricalResult
+			if err := json.Unmarshal([]byte(tt.json), &r); err != nil {
+				t.Fatalf("Unmarshal error: %v", err)
+			}
+			c := r.Candles()
+			if len(c) != 1 || c[0].Type != tt.typ || c[0].Close != 2.5 {
+				t.Fatalf("Candles = %+v", c)
+			}
+			set := 0
+			for _, p := range []bool{c[0].Perpetual != nil, c[0].Future != nil, c[0].Option != nil} {
+				if p {
+					set++
+				}
+			}
+			if set != 1 {
+				t.Errorf("%d variants set, want 1", set)
+			}
+			switch tt.typ {
+			case enums.InstrumentTypePerpetual:
+				if c[0].Perpetual == nil || c[0].Perpetual.FundingPayment != 0.1 {
+					t.Errorf("Perpetual = %+v", c[0].Perpetual)
+				}
+			case enums.InstrumentTypeFuture:
+				if c[0].TopOfBook == nil || *c[0].TopOfBook.AskPrice != 3 {
+					t.Errorf("TopOfBook = %+v", c[0].TopOfBook)
+				}
+			case enums.InstrumentTypeOption:
+				if c[0].Option == nil || c[0].Option.IVClose != 0.55 {
+					t.Errorf("Option = %+v", c[0].Option)
+				}
+			}
+		})
+	}
+
+	if c := (&types.MarkPriceHistoricalResult{}).Candles(); c != nil {
+		t.Errorf("Candles without InstrumentType = %+v, want nil", c)
+	}
+}
+
+func TestIndexPriceHistoricalResult_UnmarshalJSON(t *testing.T) {
+	var r types.IndexPriceHistoricalResult
+	if err := json.Unmarshal([]byte(`{"index":[[1,2,3,1,2],[2,"x",3,1,2],[3,2,3,1,2]]}`), &r); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	var rowErr *types.RowError
+	if errs := r.RowErrors(); len(errs) != 1 || !errors.As(errs[0], &rowErr) || rowErr.Field != "index" || rowErr.Row != 1 {
+		t.Fatalf("RowErrors = %v, want a RowError for index[1]", errs)
+	}
+	if len(r.Rows) != 2 || r.Rows[1].Time != 3 {
+		t.Errorf("Rows = %+v", r.Rows)
+	}
+	if len(r.Data()) != 2 {
+		t.Errorf("Data() = %+v", r.Data())
+	}
+}
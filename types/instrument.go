@@ -17,7 +17,7 @@ type Instrument struct {
 	StrikePrice          *float64             `json:"strike_price,omitempty"`
 	BaseCurrency         string               `json:"base_currency,omitempty"`
 	Legs                 []Leg                `json:"legs,omitempty"`
-	CreateTime           float64              `json:"create_time,omitempty"`
+	CreateTime           Timestamp            `json:"create_time,omitempty"`
 	SettlementPrice      *float64             `json:"settlement_price,omitempty"`
 	SettlementIndexPrice *float64             `json:"settlement_index_price,omitempty"`
 }
@@ -36,29 +36,29 @@ func (i *Instrument) IsCombination() bool { return i.Type == enums.InstrumentTyp
 
 // Ticker represents a full ticker for an instrument.
 type Ticker struct {
-	BestBidPrice          *float64 `json:"best_bid_price"`
-	BestBidAmount         *float64 `json:"best_bid_amount"`
-	BestAskPrice          *float64 `json:"best_ask_price"`
-	BestAskAmount         *float64 `json:"best_ask_amount"`
-	LastPrice             *float64 `json:"last_price"`
-	MarkPrice             float64  `json:"mark_price"`
-	MarkTimestamp         float64  `json:"mark_timestamp"`
-	IV                    *float64 `json:"iv,omitempty"`
-	Delta                 *float64 `json:"delta,omitempty"`
-	Index                 *float64 `json:"index,omitempty"`
-	Forward               *float64 `json:"forward,omitempty"`
-	Volume24h             *float64 `json:"volume_24h,omitempty"`
-	Value24h              *float64 `json:"value_24h,omitempty"`
-	LowPrice24h           *float64 `json:"low_price_24h,omitempty"`
-	HighPrice24h          *float64 `json:"high_price_24h,omitempty"`
-	Change24h             *float64 `json:"change_24h,omitempty"`
-	CollarLow             *float64 `json:"collar_low,omitempty"`
-	CollarHigh            *float64 `json:"collar_high,omitempty"`
-	OpenInterest          *float64 `json:"open_interest,omitempty"`
-	FundingRate           *float64 `json:"funding_rate,omitempty"`
-	FundingMark           *float64 `json:"funding_mark,omitempty"`
-	RealisedFunding24h    *float64 `json:"realised_funding_24h,omitempty"`
-	AverageFundingRate24h *float64 `json:"average_funding_rate_24h,omitempty"`
+	BestBidPrice          *float64  `json:"best_bid_price"`
+	BestBidAmount         *float64  `json:"best_bid_amount"`
+	BestAskPrice          *float64  `json:"best_ask_price"`
+	BestAskAmount         *float64  `json:"best_ask_amount"`
+	LastPrice             *float64  `json:"last_price"`
+	MarkPrice             float64   `json:"mark_price"`
+	MarkTimestamp         Timestamp `json:"mark_timestamp"`
+	IV                    *float64  `json:"iv,omitempty"`
+	Delta                 *float64  `json:"delta,omitempty"`
+	Index                 *float64  `json:"index,omitempty"`
+	Forward               *float64  `json:"forward,omitempty"`
+	Volume24h             *float64  `json:"volume_24h,omitempty"`
+	Value24h              *float64  `json:"value_24h,omitempty"`
+	LowPrice24h           *float64  `json:"low_price_24h,omitempty"`
+	HighPrice24h          *float64  `json:"high_price_24h,omitempty"`
+	Change24h             *float64  `json:"change_24h,omitempty"`
+	CollarLow             *float64  `json:"collar_low,omitempty"`
+	CollarHigh            *float64  `json:"collar_high,omitempty"`
+	OpenInterest          *float64  `json:"open_interest,omitempty"`
+	FundingRate           *float64  `json:"funding_rate,omitempty"`
+	FundingMark           *float64  `json:"funding_mark,omitempty"`
+	RealisedFunding24h    *float64  `json:"realised_funding_24h,omitempty"`
+	AverageFundingRate24h *float64  `json:"average_funding_rate_24h,omitempty"`
 }
 
 // Spread returns the bid-ask spread, or nil if either side is empty.
@@ -81,13 +81,13 @@ func (t *Ticker) MidPrice() *float64 {
 
 // IndexPrice represents index price information.
 type IndexPrice struct {
-	IndexName               string   `json:"index_name"`
-	Price                   float64  `json:"price"`
-	Timestamp               float64  `json:"timestamp"`
-	ExpirationPrintAverage  *float64 `json:"expiration_print_average,omitempty"`
-	ExpirationProgress      *float64 `json:"expiration_progress,omitempty"`
-	ExpectedExpirationPrice *float64 `json:"expected_expiration_price,omitempty"`
-	PreviousSettlementPrice *float64 `json:"previous_settlement_price,omitempty"`
+	IndexName               string    `json:"index_name"`
+	Price                   float64   `json:"price"`
+	Timestamp               Timestamp `json:"timestamp"`
+	ExpirationPrintAverage  *float64  `json:"expiration_print_average,omitempty"`
+	ExpirationProgress      *float64  `json:"expiration_progress,omitempty"`
+	ExpectedExpirationPrice *float64  `json:"expected_expiration_price,omitempty"`
+	PreviousSettlementPrice *float64  `json:"previous_settlement_price,omitempty"`
 }
 
 // BookLevel represents a single price level [price, amount, outright_amount].
@@ -107,5 +107,5 @@ type Book struct {
 	Bids []BookLevel `json:"bids"`
 	Asks []BookLevel `json:"asks"`
 	Last *float64    `json:"last,omitempty"`
-	Time float64     `json:"time"`
+	Time Timestamp   `json:"time"`
 }
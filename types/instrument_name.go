@@ -0,0 +1,223 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amiwrpremium/go-thalex/enums"
+)
+
+// ErrInvalidInstrumentName is wrapped by every error returned from
+// [ParseInstrumentName] and [InstrumentName.Validate].
+var ErrInvalidInstrumentName = errors.New("invalid instrument name")
+
+// instrumentExpiryLayout is the expiry date format used in instrument
+// names, e.g. 27DEC24. Days are not zero-padded.
+const instrumentExpiryLayout = "2Jan06"
+
+// InstrumentName is the structured form of an outright instrument name:
+//
+//	BTC-PERPETUAL          perpetual
+//	BTC-27DEC24            future
+//	BTC-27DEC24-50000-C    option
+//
+// Combinations have no name grammar of their own and are not supported.
+type InstrumentName struct {
+	// Underlying is the base currency as it appears in the name, e.g. BTC.
+	Underlying string
+	// Kind is perpetual, future or option.
+	Kind enums.InstrumentType
+	// Expiry is the expiry date at midnight UTC. Zero for perpetuals.
+	Expiry time.Time
+	// Strike is the option strike price. Zero unless Kind is option.
+	Strike float64
+	// OptionType is call or put. Empty unless Kind is option.
+	OptionType enums.OptionType
+}
+
+// NewPerpetualName returns the name of the perpetual on underlying.
+func NewPerpetualName(underlying string) InstrumentName {
+	return InstrumentName{Underlying: underlying, Kind: enums.InstrumentTypePerpetual}
+}
+
+// NewFutureName returns the name of the future on underlying expiring on
+// the date of expiry.
+func NewFutureName(underlying string, expiry time.Time) InstrumentName {
+	return InstrumentName{Underlying: underlying, Kind: enums.InstrumentTypeFuture, Expiry: expiryDate(expiry)}
+}
+
+// NewOptionName returns the name of the option on underlying with the given
+// expiry date, strike and option type.
+func NewOptionName(underlying string, expiry time.Time, strike float64, optionType enums.OptionType) InstrumentName {
+	return InstrumentName{
+		Underlying: underlying,
+		Kind:       enums.InstrumentTypeOption,
+		Expiry:     expiryDate(expiry),
+		Strike:     strike,
+		OptionType: optionType,
+	}
+}
+
+// ParseInstrumentName parses a perpetual, future or option name. Only the
+// canonical spelling is accepted, so for any name s that parses,
+// ParseInstrumentName(s).String() == s.
+func ParseInstrumentName(s string) (InstrumentName, error) {
+	parts := strings.Split(s, "-")
+	var n InstrumentName
+	switch {
+	case len(parts) == 2 && parts[1] == "PERPETUAL":
+		n = NewPerpetualName(parts[0])
+	case len(parts) == 2:
+		expiry, err := parseExpiry(parts[1])
+		if err != nil {
+			return InstrumentName{}, invalidName(s, err.Error())
+		}
+		n = NewFutureName(parts[0], expiry)
+	case len(parts) == 4:
+		expiry, err := parseExpiry(parts[1])
+		if err != nil {
+			return InstrumentName{}, invalidName(s, err.Error())
+		}
+		strike, err := strconv.ParseFloat(parts[2], 64)
+		if err != nil {
+			return InstrumentName{}, invalidName(s, "bad strike "+strconv.Quote(parts[2]))
+		}
+		var optionType enums.OptionType
+		switch parts[3] {
+		case "C":
+			optionType = enums.OptionTypeCall
+		case "P":
+			optionType = enums.OptionTypePut
+		default:
+			return InstrumentName{}, invalidName(s, "bad option type "+strconv.Quote(parts[3]))
+		}
+		n = NewOptionName(parts[0], expiry, strike, optionType)
+	default:
+		return InstrumentName{}, invalidName(s, "unrecognised format")
+	}
+	if err := n.Validate(); err != nil {
+		return InstrumentName{}, err
+	}
+	if n.String() != s {
+		return InstrumentName{}, invalidName(s, "not canonical, expected "+strconv.Quote(n.String()))
+	}
+	return n, nil
+}
+
+// MustParseInstrumentName is like [ParseInstrumentName] but panics on error.
+func MustParseInstrumentName(s string) InstrumentName {
+	n, err := ParseInstrumentName(s)
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
+// String returns the canonical instrument name. It does not validate n;
+// call Validate first when the parts come from user input.
+func (n InstrumentName) String() string {
+	switch n.Kind {
+	case enums.InstrumentTypePerpetual:
+		return n.Underlying + "-PERPETUAL"
+	case enums.InstrumentTypeFuture:
+		return n.Underlying + "-" + formatExpiry(n.Expiry)
+	case enums.InstrumentTypeOption:
+		suffix := "C"
+		if n.OptionType == enums.OptionTypePut {
+			suffix = "P"
+		}
+		return n.Underlying + "-" + formatExpiry(n.Expiry) + "-" + strconv.FormatFloat(n.Strike, 'f', -1, 64) + "-" + suffix
+	}
+	return n.Underlying
+}
+
+// Validate checks that the parts describe a valid outright instrument.
+func (n InstrumentName) Validate() error {
+	if n.Underlying == "" || strings.IndexFunc(n.Underlying, func(r rune) bool {
+		return (r < 'A' || r > 'Z') && (r < '0' || r > '9')
+	}) >= 0 {
+		return invalidName(n.String(), "underlying must be upper-case letters and digits")
+	}
+	if !n.Kind.IsValid() || n.Kind == enums.InstrumentTypeCombination {
+		return invalidName(n.String(), "unsupported kind "+strconv.Quote(string(n.Kind)))
+	}
+	if n.Kind == enums.InstrumentTypePerpetual {
+		if !n.Expiry.IsZero() || n.Strike != 0 || n.OptionType != "" {
+			return invalidName(n.String(), "perpetual cannot have expiry, strike or option type")
+		}
+		return nil
+	}
+	if n.Expiry.IsZero() {
+		return invalidName(n.String(), "missing expiry")
+	}
+	if n.Kind == enums.InstrumentTypeFuture {
+		if n.Strike != 0 || n.OptionType != "" {
+			return invalidName(n.String(), "future cannot have strike or option type")
+		}
+		return nil
+	}
+	if n.Strike <= 0 {
+		return invalidName(n.String(), "strike must be positive")
+	}
+	if !n.OptionType.IsValid() {
+		return invalidName(n.String(), "invalid option type "+strconv.Quote(string(n.OptionType)))
+	}
+	return nil
+}
+
+// IsPerpetual returns true if the name is a perpetual.
+func (n InstrumentName) IsPerpetual() bool { return n.Kind == enums.InstrumentTypePerpetual }
+
+// IsFuture returns true if the name is a future.
+func (n InstrumentName) IsFuture() bool { return n.Kind == enums.InstrumentTypeFuture }
+
+// IsOption returns true if the name is an option.
+func (n InstrumentName) IsOption() bool { return n.Kind == enums.InstrumentTypeOption }
+
+// ParsedName parses the instrument's name and checks that it agrees with
+// the instrument's type, option type, strike and expiry date.
+func (i *Instrument) ParsedName() (InstrumentName, error) {
+	n, err := ParseInstrumentName(i.InstrumentName)
+	if err != nil {
+		return InstrumentName{}, err
+	}
+	switch {
+	case n.Kind != i.Type:
+		return InstrumentName{}, invalidName(i.InstrumentName, "name is a "+string(n.Kind)+", instrument is a "+string(i.Type))
+	case n.OptionType != i.OptionType:
+		return InstrumentName{}, invalidName(i.InstrumentName, "option type does not match "+strconv.Quote(string(i.OptionType)))
+	case i.StrikePrice != nil && n.Strike != *i.StrikePrice:
+		return InstrumentName{}, invalidName(i.InstrumentName, "strike does not match "+strconv.FormatFloat(*i.StrikePrice, 'f', -1, 64))
+	case i.ExpiryDate != "" && n.Expiry.Format(time.DateOnly) != i.ExpiryDate:
+		return InstrumentName{}, invalidName(i.InstrumentName, "expiry does not match "+i.ExpiryDate)
+	}
+	return n, nil
+}
+
+func parseExpiry(s string) (time.Time, error) {
+	t, err := time.Parse(instrumentExpiryLayout, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("bad expiry %q", s)
+	}
+	return t, nil
+}
+
+func formatExpiry(t time.Time) string {
+	return strings.ToUpper(t.Format(instrumentExpiryLayout))
+}
+
+// expiryDate truncates t to its calendar date in UTC.
+func expiryDate(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func invalidName(name, reason string) error {
+	return fmt.Errorf("types: %w %q: %s", ErrInvalidInstrumentName, name, reason)
+}
@@ -0,0 +1,103 @@
+package types_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/amiwrpremium/go-thalex/enums"
+	"github.com/amiwrpremium/go-thalex/types"
+)
+
+func TestParseInstrumentName(t *testing.T) {
+	mar28 := time.Date(2025, 3, 28, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in   string
+		want types.InstrumentName
+	}{
+		{"BTC-PERPETUAL", types.NewPerpetualName("BTC")},
+		{"BTC-28MAR25", types.NewFutureName("BTC", mar28)},
+		{"BTC-28MAR25-100000-C", types.NewOptionName("BTC", mar28, 100000, enums.OptionTypeCall)},
+		{"ETH-3JAN25-3250.5-P", types.NewOptionName("ETH", time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC), 3250.5, enums.OptionTypePut)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := types.ParseInstrumentName(tt.in)
+			if err != nil {
+				t.Fatalf("ParseInstrumentName error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+			if got.String() != tt.in {
+				t.Errorf("String() = %q, want %q", got.String(), tt.in)
+			}
+		})
+	}
+}
+
+func TestParseInstrumentName_Invalid(t *testing.T) {
+	for _, in := range []string{
+		"", "BTC", "btc-PERPETUAL", "-PERPETUAL", "BTC-28MAR", "BTC-32MAR25", "BTC-03JAN25",
+		"BTC-28mar25", "BTC-28MAR25-100000", "BTC-28MAR25-100000-X", "BTC-28MAR25-abc-C",
+		"BTC-28MAR25-0-C", "BTC-28MAR25-100000.0-C", "BTC-28MAR25-100000-C-X",
+	} {
+		if _, err := types.ParseInstrumentName(in); !errors.Is(err, types.ErrInvalidInstrumentName) {
+			t.Errorf("ParseInstrumentName(%q) error = %v, want ErrInvalidInstrumentName", in, err)
+		}
+	}
+}
+
+func TestInstrumentName_Validate(t *testing.T) {
+	exp := time.Date(2025, 3, 28, 15, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		n    types.InstrumentName
+	}{
+		{"Combination", types.InstrumentName{Underlying: "BTC", Kind: enums.InstrumentTypeCombination}},
+		{"UnknownKind", types.InstrumentName{Underlying: "BTC", Kind: "swap"}},
+		{"PerpetualWithExpiry", types.InstrumentName{Underlying: "BTC", Kind: enums.InstrumentTypePerpetual, Expiry: exp}},
+		{"FutureWithoutExpiry", types.NewFutureName("BTC", time.Time{})},
+		{"FutureWithStrike", types.InstrumentName{Underlying: "BTC", Kind: enums.InstrumentTypeFuture, Expiry: exp, Strike: 1}},
+		{"OptionBadType", types.NewOptionName("BTC", exp, 100000, "straddle")},
+		{"OptionNegativeStrike", types.NewOptionName("BTC", exp, -1, enums.OptionTypePut)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.n.Validate(); !errors.Is(err, types.ErrInvalidInstrumentName) {
+				t.Errorf("Validate() = %v, want ErrInvalidInstrumentName", err)
+			}
+		})
+	}
+	if got := types.NewFutureName("BTC", exp).String(); got != "BTC-28MAR25" {
+		t.Errorf("NewFutureName should drop the time of day, got %q", got)
+	}
+}
+
+func TestInstrument_ParsedName(t *testing.T) {
+	strike := 100000.0
+	inst := types.Instrument{
+		InstrumentName: "BTC-28MAR25-100000-C",
+		Type:           enums.InstrumentTypeOption,
+		OptionType:     enums.OptionTypeCall,
+		ExpiryDate:     "2025-03-28",
+		StrikePrice:    &strike,
+	}
+	n, err := inst.ParsedName()
+	if err != nil {
+		t.Fatalf("ParsedName error: %v", err)
+	}
+	if !n.IsOption() || n.Strike != strike {
+		t.Errorf("unexpected name: %+v", n)
+	}
+
+	inst.ExpiryDate = "2025-03-27"
+	if _, err := inst.ParsedName(); !errors.Is(err, types.ErrInvalidInstrumentName) {
+		t.Errorf("mismatched expiry should fail, got %v", err)
+	}
+	inst.ExpiryDate = "2025-03-28"
+	inst.Type = enums.InstrumentTypeFuture
+	if _, err := inst.ParsedName(); err == nil {
+		t.Error("mismatched type should fail")
+	}
+}
@@ -5,7 +5,7 @@ import "github.com/amiwrpremium/go-thalex/enums"
 // Notification represents a user notification.
 type Notification struct {
 	ID            string            `json:"id"`
-	Time          float64           `json:"time"`
+	Time          Timestamp         `json:"time"`
 	Category      string            `json:"category"`
 	Title         string            `json:"title"`
 	Message       string            `json:"message"`
@@ -23,8 +23,8 @@ type OrderStatus struct {
 	InsertReason       enums.InsertReason     `json:"insert_reason"`
 	ConditionalOrderID string                 `json:"conditional_order_id,omitempty"`
 	BotID              string                 `json:"bot_id,omitempty"`
-	CreateTime         float64                `json:"create_time"`
-	CloseTime          *float64               `json:"close_time,omitempty"`
+	CreateTime         Timestamp              `json:"create_time"`
+	CloseTime          *Timestamp             `json:"close_time,omitempty"`
 	ReduceOnly         bool                   `json:"reduce_only,omitempty"`
 	Persistent         bool                   `json:"persistent"`
 }
@@ -34,7 +34,7 @@ type OrderFill struct {
 	TradeID    string           `json:"trade_id"`
 	Price      float64          `json:"price"`
 	Amount     float64          `json:"amount"`
-	Time       float64          `json:"time,omitempty"`
+	Time       Timestamp        `json:"time,omitempty"`
 	MakerTaker enums.MakerTaker `json:"maker_taker"`
 	LegIndex   int              `json:"leg_index"`
 }
@@ -57,8 +57,8 @@ type OrderHistory struct {
 	InsertReason       enums.InsertReason     `json:"insert_reason"`
 	ConditionalOrderID string                 `json:"conditional_order_id,omitempty"`
 	BotID              string                 `json:"bot_id,omitempty"`
-	CreateTime         float64                `json:"create_time"`
-	CloseTime          float64                `json:"close_time"`
+	CreateTime         Timestamp              `json:"create_time"`
+	CloseTime          Timestamp              `json:"close_time"`
 	ReduceOnly         bool                   `json:"reduce_only,omitempty"`
 }
 
@@ -163,6 +163,11 @@ type AmendOrderParams struct {
 	Price         float64      `json:"price"`
 	Amount        float64      `json:"amount"`
 	Collar        enums.Collar `json:"collar,omitempty"`
+
+	// InstrumentName and Direction are not sent. When set, they let a
+	// pre-trade Validator check and round the new price and amount.
+	InstrumentName string          `json:"-"`
+	Direction      enums.Direction `json:"-"`
 }
 
 // NewAmendByOrderID creates amend parameters using a system order ID.
@@ -178,6 +183,13 @@ func NewAmendByClientOrderID(clientOrderID uint64, price, amount float64) *Amend
 // WithCollar sets the collar handling mode for the amend.
 func (p *AmendOrderParams) WithCollar(v enums.Collar) *AmendOrderParams { p.Collar = v; return p }
 
+// WithInstrument records the order's instrument and side for pre-trade
+// validation. Neither is sent to the exchange.
+func (p *AmendOrderParams) WithInstrument(instrumentName string, direction enums.Direction) *AmendOrderParams {
+	p.InstrumentName, p.Direction = instrumentName, direction
+	return p
+}
+
 // CancelOrderParams identifies an order to cancel.
 type CancelOrderParams struct {
 	OrderID       string  `json:"order_id,omitempty"`
@@ -385,7 +385,7 @@ func TestCancelByClientOrderID(t *testing.T) {
 func TestOrderStatus_JSONRoundTrip(t *testing.T) {
 	price := 50000.0
 	clientOID := uint64(42)
-	closeTime := 1700001000.0
+	closeTime := types.Timestamp(1700001000.0)
 
 	os := types.OrderStatus{
 		OrderID:         "order-123",
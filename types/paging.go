@@ -0,0 +1,67 @@
+package types
+
+import (
+	"errors"
+	"time"
+)
+
+// DefaultPageSize is the page size of the history iterators, such as
+// TradeHistoryAll, when neither the params' Limit nor WithPageSize is set.
+const DefaultPageSize = 100
+
+// ErrNoPagingStart is returned by history iterators paging by time window
+// when the params have no From.
+var ErrNoPagingStart = errors.New("types: time window paging needs a from time")
+
+// ErrPagingStalled is returned by history iterators when a page holds only
+// items from the previous page, as happens when an endpoint ignores the
+// offset.
+var ErrPagingStalled = errors.New("types: page has no new items")
+
+// PageOption configures a history iterator.
+type PageOption func(*PageSettings)
+
+// PageSettings is the configuration a history iterator runs with.
+type PageSettings struct {
+	// PageSize is the number of items requested per page.
+	PageSize int
+	// MaxItems stops the iteration after this many items; 0 means no cap.
+	MaxItems int
+	// Window, if set, splits [From, To] into windows of this length that
+	// are paged through one after another, instead of paging the whole
+	// range by offset.
+	Window time.Duration
+}
+
+// WithPageSize sets the number of items requested per page. It takes
+// precedence over the params' Limit.
+func WithPageSize(n int) PageOption {
+	return func(s *PageSettings) { s.PageSize = n }
+}
+
+// WithMaxItems stops the iteration after n items.
+func WithMaxItems(n int) PageOption {
+	return func(s *PageSettings) { s.MaxItems = n }
+}
+
+// WithTimeWindow pages by time windows of length d. Offsets then only run
+// within a window, so items arriving at the live end of the range do not
+// shift the pages of older windows. It needs the params' From; a missing
+// To is fixed to the time the iteration starts.
+func WithTimeWindow(d time.Duration) PageOption {
+	return func(s *PageSettings) { s.Window = d }
+}
+
+// NewPageSettings applies opts over the defaults. limit is the params'
+// Limit, used as the page size unless WithPageSize is given.
+func NewPageSettings(limit *int, opts ...PageOption) PageSettings {
+	s := PageSettings{PageSize: DefaultPageSize}
+	if limit != nil && *limit > 0 {
+		s.PageSize = *limit
+	}
+	for _, opt := range opts {
+		opt(&s)
+	}
+	s.PageSize = max(s.PageSize, 1)
+	return s
+}
@@ -0,0 +1,25 @@
+package types_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/amiwrpremium/go-thalex/types"
+)
+
+func TestNewPageSettings(t *testing.T) {
+	if s := types.NewPageSettings(nil); s.PageSize != types.DefaultPageSize || s.MaxItems != 0 || s.Window != 0 {
+		t.Errorf("defaults = %+v", s)
+	}
+	limit := 50
+	if s := types.NewPageSettings(&limit); s.PageSize != 50 {
+		t.Errorf("PageSize = %d, want the params' Limit", s.PageSize)
+	}
+	s := types.NewPageSettings(&limit, types.WithPageSize(10), types.WithMaxItems(25), types.WithTimeWindow(time.Hour))
+	if s.PageSize != 10 || s.MaxItems != 25 || s.Window != time.Hour {
+		t.Errorf("settings = %+v", s)
+	}
+	if s := types.NewPageSettings(nil, types.WithPageSize(0)); s.PageSize != 1 {
+		t.Errorf("PageSize = %d, want at least 1", s.PageSize)
+	}
+}
@@ -20,7 +20,7 @@ type Rfq struct {
 	RfqID          string                `json:"rfq_id"`
 	Legs           []RfqLeg              `json:"legs"`
 	Amount         float64               `json:"amount"`
-	CreateTime     float64               `json:"create_time"`
+	CreateTime     Timestamp             `json:"create_time"`
 	ValidUntil     *float64              `json:"valid_until,omitempty"`
 	Label          string                `json:"label,omitempty"`
 	InsertReason   enums.RfqInsertReason `json:"insert_reason,omitempty"`
@@ -30,7 +30,7 @@ type Rfq struct {
 	QuotedAsk      *RfqQuotedSide        `json:"quoted_ask,omitempty"`
 	TradePrice     *float64              `json:"trade_price,omitempty"`
 	TradeAmount    *float64              `json:"trade_amount,omitempty"`
-	CloseTime      *float64              `json:"close_time,omitempty"`
+	CloseTime      *Timestamp            `json:"close_time,omitempty"`
 	Event          enums.RfqEvent        `json:"event,omitempty"`
 }
 
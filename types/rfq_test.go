@@ -15,7 +15,7 @@ func TestRfq_JSONRoundTrip(t *testing.T) {
 	volTickSize := 0.01
 	tradePrice := 50000.0
 	tradeAmount := 1.0
-	closeTime := 1700002000.0
+	closeTime := types.Timestamp(1700002000.0)
 
 	rfq := types.Rfq{
 		RfqID: "rfq-123",
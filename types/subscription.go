@@ -11,7 +11,7 @@ type BookUpdate struct {
 	Bids   []BookLevel `json:"bids"`
 	Asks   []BookLevel `json:"asks"`
 	Last   *float64    `json:"last,omitempty"`
-	Time   float64     `json:"time"`
+	Time   Timestamp   `json:"time"`
 	Trades []BookTrade `json:"trades,omitempty"`
 }
 
@@ -20,7 +20,7 @@ type BookTrade struct {
 	Direction enums.Direction `json:"d"`
 	Price     float64         `json:"p"`
 	Amount    float64         `json:"a"`
-	Time      float64         `json:"t"`
+	Time      Timestamp       `json:"t"`
 }
 
 // LightweightTicker represents a condensed ticker from an lwt subscription.
@@ -41,15 +41,15 @@ type RecentTrade struct {
 	Direction      enums.Direction `json:"direction"`
 	Price          float64         `json:"price"`
 	Amount         float64         `json:"amount"`
-	Time           float64         `json:"time"`
+	Time           Timestamp       `json:"time"`
 	TradeType      enums.TradeType `json:"trade_type"`
 	Index          *float64        `json:"index,omitempty"`
 }
 
 // UnderlyingStatistics represents statistics for a single underlying.
 type UnderlyingStatistics struct {
-	Underlying string  `json:"underlying"`
-	Time       float64 `json:"time"`
+	Underlying string    `json:"underlying"`
+	Time       Timestamp `json:"time"`
 	OpenInterest
 }
 
@@ -80,10 +80,10 @@ type OpenInterest struct {
 
 // BasePrice represents a forward price for a specific expiration.
 type BasePrice struct {
-	Underlying string  `json:"underlying"`
-	Expiration string  `json:"expiration"`
-	Price      float64 `json:"price"`
-	Time       float64 `json:"time"`
+	Underlying string    `json:"underlying"`
+	Expiration string    `json:"expiration"`
+	Price      float64   `json:"price"`
+	Time       Timestamp `json:"time"`
 }
 
 // InstrumentChange represents an instrument activation or deactivation event.
@@ -103,7 +103,7 @@ type IndexComponent struct {
 type IndexComponents struct {
 	IndexName  string           `json:"index_name"`
 	Price      float64          `json:"price"`
-	Time       float64          `json:"time"`
+	Time       Timestamp        `json:"time"`
 	Components []IndexComponent `json:"components"`
 }
 
@@ -111,7 +111,7 @@ type IndexComponents struct {
 type MMProtectionUpdate struct {
 	Product enums.Product            `json:"product"`
 	Reason  enums.MMProtectionReason `json:"reason"`
-	Time    float64                  `json:"time"`
+	Time    Timestamp                `json:"time"`
 }
 
 // SubscriptionNotification wraps a notification from a subscription channel.
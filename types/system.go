@@ -12,7 +12,7 @@ type SystemInfo struct {
 // Banner represents a system banner/announcement.
 type Banner struct {
 	ID       *int           `json:"id,omitempty"`
-	Time     float64        `json:"time"`
+	Time     Timestamp      `json:"time"`
 	Severity enums.Severity `json:"severity"`
 	Title    string         `json:"title,omitempty"`
 	Message  string         `json:"message"`
@@ -0,0 +1,91 @@
+package types
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// Timestamp is a Thalex timestamp: seconds since the Unix epoch with a
+// fractional part. It has float64 underneath, so existing arithmetic and
+// untyped constants keep working, and it encodes to JSON with microsecond
+// precision, the resolution Thalex uses.
+type Timestamp float64
+
+// NewTimestamp converts t to a Timestamp, truncated to the microsecond.
+func NewTimestamp(t time.Time) Timestamp {
+	return Timestamp(float64(t.Unix()) + float64(t.Nanosecond()/1000)/1e6)
+}
+
+// TimestampNow returns the current time as a Timestamp.
+func TimestampNow() Timestamp {
+	return NewTimestamp(time.Now())
+}
+
+// TimestampIn returns the time d from now as a Timestamp, e.g. the end time
+// of a bot that should run for d.
+func TimestampIn(d time.Duration) Timestamp {
+	return NewTimestamp(time.Now().Add(d))
+}
+
+// Time returns the timestamp as a time.Time in UTC, rounded to the
+// microsecond. The zero Timestamp maps to the Unix epoch.
+func (t Timestamp) Time() time.Time {
+	sec, frac := math.Modf(float64(t))
+	micros := math.Round(frac * 1e6)
+	return time.Unix(int64(sec), int64(micros)*1000).UTC()
+}
+
+// Seconds returns the timestamp as float64 seconds since the epoch.
+func (t Timestamp) Seconds() float64 { return float64(t) }
+
+// IsZero reports whether the timestamp is unset.
+func (t Timestamp) IsZero() bool { return t == 0 }
+
+// Add returns t + d.
+func (t Timestamp) Add(d time.Duration) Timestamp {
+	return t + Timestamp(d.Seconds())
+}
+
+// Sub returns the duration t - u, rounded to the microsecond.
+func (t Timestamp) Sub(u Timestamp) time.Duration {
+	return time.Duration(math.Round(float64(t-u)*1e6)) * time.Microsecond
+}
+
+// Before reports whether t is before u.
+func (t Timestamp) Before(u Timestamp) bool { return t < u }
+
+// After reports whether t is after u.
+func (t Timestamp) After(u Timestamp) bool { return t > u }
+
+// String formats the timestamp as RFC 3339 with microseconds.
+func (t Timestamp) String() string {
+	return t.Time().Format("2006-01-02T15:04:05.000000Z07:00")
+}
+
+// MarshalJSON encodes the timestamp as epoch seconds with at most six
+// fractional digits.
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	return strconv.AppendFloat(nil, math.Round(float64(t)*1e6)/1e6, 'f', -1, 64), nil
+}
+
+// UnmarshalJSON decodes epoch seconds. Null leaves t unchanged.
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return fmt.Errorf("types: invalid timestamp %s: %w", s, err)
+	}
+	*t = Timestamp(f)
+	return nil
+}
+
+// TimestampPtr returns a pointer to t, for optional params fields.
+func TimestampPtr(t time.Time) *Timestamp {
+	ts := NewTimestamp(t)
+	return &ts
+}
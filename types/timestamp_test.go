@@ -0,0 +1,94 @@
+package types_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/amiwrpremium/go-thalex/types"
+)
+
+func TestTimestamp_TimeRoundTrip(t *testing.T) {
+	tm := time.Date(2024, 3, 1, 12, 30, 45, 123456789, time.UTC)
+	ts := types.NewTimestamp(tm)
+	want := tm.Truncate(time.Microsecond)
+	if got := ts.Time(); !got.Equal(want) {
+		t.Errorf("Time() = %v, want %v", got, want)
+	}
+	if got := ts.String(); got != "2024-03-01T12:30:45.123456Z" {
+		t.Errorf("String() = %q", got)
+	}
+	if types.Timestamp(0).Time().Unix() != 0 || !types.Timestamp(0).IsZero() {
+		t.Error("zero timestamp should map to the epoch")
+	}
+}
+
+func TestTimestamp_Arithmetic(t *testing.T) {
+	a := types.Timestamp(1700000000.25)
+	b := a.Add(1500 * time.Millisecond)
+	if b != 1700000001.75 {
+		t.Errorf("Add = %v", b.Seconds())
+	}
+	if d := b.Sub(a); d != 1500*time.Millisecond {
+		t.Errorf("Sub = %v", d)
+	}
+	if !a.Before(b) || !b.After(a) {
+		t.Error("unexpected ordering")
+	}
+}
+
+func TestTimestamp_JSON(t *testing.T) {
+	var v struct {
+		Time  types.Timestamp  `json:"time"`
+		Close *types.Timestamp `json:"close_time"`
+	}
+	if err := json.Unmarshal([]byte(`{"time":1700000000.123456,"close_time":null}`), &v); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	if v.Time.Time().Nanosecond() != 123456000 || v.Close != nil {
+		t.Errorf("decoded %+v", v)
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	if string(out) != `{"time":1700000000.123456,"close_time":null}` {
+		t.Errorf("Marshal = %s", out)
+	}
+	if err := json.Unmarshal([]byte(`{"time":"soon"}`), &v); err == nil {
+		t.Error("expected error for non-numeric timestamp")
+	}
+}
+
+func TestBotParams_EndTimeBuilders(t *testing.T) {
+	end := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	p := types.NewGridBotParamsUntil("BTC-PERPETUAL", []float64{94000, 96000}, 0.01, end)
+	if !types.Timestamp(p.EndTime).Time().Equal(end) {
+		t.Errorf("EndTime = %v, want %v", p.EndTime, end)
+	}
+	p.WithEndTimeIn(time.Hour)
+	if d := time.Until(types.Timestamp(p.EndTime).Time()); d < 59*time.Minute || d > time.Hour {
+		t.Errorf("EndTime is %v from now, want ~1h", d)
+	}
+}
+
+func TestTradeHistoryParams_WithTimeRange(t *testing.T) {
+	from := time.Unix(1700000000, 0)
+	to := from.Add(time.Hour)
+	p := (&types.TradeHistoryParams{}).WithTimeRange(from, to)
+	if p.From == nil || p.To == nil || *p.To-*p.From != 3600 {
+		t.Fatalf("unexpected range: %v - %v", p.From, p.To)
+	}
+	p.WithLookback(24 * time.Hour)
+	if d := types.Timestamp(*p.To).Sub(types.Timestamp(*p.From)); d != 24*time.Hour {
+		t.Errorf("lookback range = %v", d)
+	}
+}
+
+func TestHistoryParams_FromToTime(t *testing.T) {
+	from := time.Unix(1700000000, 250000000)
+	p := (&types.OrderHistoryParams{}).WithFromTime(from).WithToTime(from.Add(time.Minute))
+	if p.From == nil || *p.From != 1700000000.25 || p.To == nil || *p.To != 1700000060.25 {
+		t.Errorf("range = %v - %v", p.From, p.To)
+	}
+}
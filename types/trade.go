@@ -1,6 +1,10 @@
 package types
 
-import "github.com/amiwrpremium/go-thalex/enums"
+import (
+	"time"
+
+	"github.com/amiwrpremium/go-thalex/enums"
+)
 
 // Trade represents a single trade.
 type Trade struct {
@@ -12,7 +16,7 @@ type Trade struct {
 	Price                float64          `json:"price"`
 	Amount               float64          `json:"amount"`
 	Label                string           `json:"label,omitempty"`
-	Time                 float64          `json:"time"`
+	Time                 Timestamp        `json:"time"`
 	PositionAfter        float64          `json:"position_after"`
 	SessionRealisedAfter float64          `json:"session_realised_after,omitempty"`
 	PositionPnl          *float64         `json:"position_pnl,omitempty"`
@@ -31,23 +35,23 @@ type Trade struct {
 
 // DailyMark represents a daily mark settlement entry.
 type DailyMark struct {
-	Time                float64  `json:"time"`
-	InstrumentName      string   `json:"instrument_name"`
-	Position            float64  `json:"position"`
-	MarkPrice           float64  `json:"mark_price"`
-	RealizedPositionPnl float64  `json:"realized_position_pnl"`
-	RealizedFundingPnl  *float64 `json:"realized_funding_pnl,omitempty"`
+	Time                Timestamp `json:"time"`
+	InstrumentName      string    `json:"instrument_name"`
+	Position            float64   `json:"position"`
+	MarkPrice           float64   `json:"mark_price"`
+	RealizedPositionPnl float64   `json:"realized_position_pnl"`
+	RealizedFundingPnl  *float64  `json:"realized_funding_pnl,omitempty"`
 }
 
 // Transaction represents a transaction in the account history.
 type Transaction struct {
-	TransactionID  string  `json:"transaction_id"`
-	Time           float64 `json:"time"`
-	Type           string  `json:"type"`
-	InstrumentName string  `json:"instrument_name,omitempty"`
-	Amount         float64 `json:"amount"`
-	Currency       string  `json:"currency"`
-	TradeID        string  `json:"trade_id,omitempty"`
+	TransactionID  string    `json:"transaction_id"`
+	Time           Timestamp `json:"time"`
+	Type           string    `json:"type"`
+	InstrumentName string    `json:"instrument_name,omitempty"`
+	Amount         float64   `json:"amount"`
+	Currency       string    `json:"currency"`
+	TradeID        string    `json:"trade_id,omitempty"`
 }
 
 // TradeHistoryParams configures a trade history query.
@@ -87,3 +91,106 @@ type TransactionHistoryParams struct {
 	Limit  *int       `json:"limit,omitempty"`
 	Sort   enums.Sort `json:"sort,omitempty"`
 }
+
+// WithFromTime limits the query to times at or after t.
+func (p *TradeHistoryParams) WithFromTime(t time.Time) *TradeHistoryParams {
+	p.From = secondsPtr(t)
+	return p
+}
+
+// WithToTime limits the query to times at or before t.
+func (p *TradeHistoryParams) WithToTime(t time.Time) *TradeHistoryParams {
+	p.To = secondsPtr(t)
+	return p
+}
+
+// WithTimeRange limits the query to [from, to].
+func (p *TradeHistoryParams) WithTimeRange(from, to time.Time) *TradeHistoryParams {
+	p.From, p.To = secondsPtr(from), secondsPtr(to)
+	return p
+}
+
+// WithLookback limits the query to the last d.
+func (p *TradeHistoryParams) WithLookback(d time.Duration) *TradeHistoryParams {
+	now := time.Now()
+	return p.WithTimeRange(now.Add(-d), now)
+}
+
+// WithFromTime limits the query to times at or after t.
+func (p *OrderHistoryParams) WithFromTime(t time.Time) *OrderHistoryParams {
+	p.From = secondsPtr(t)
+	return p
+}
+
+// WithToTime limits the query to times at or before t.
+func (p *OrderHistoryParams) WithToTime(t time.Time) *OrderHistoryParams {
+	p.To = secondsPtr(t)
+	return p
+}
+
+// WithTimeRange limits the query to [from, to].
+func (p *OrderHistoryParams) WithTimeRange(from, to time.Time) *OrderHistoryParams {
+	p.From, p.To = secondsPtr(from), secondsPtr(to)
+	return p
+}
+
+// WithLookback limits the query to the last d.
+func (p *OrderHistoryParams) WithLookback(d time.Duration) *OrderHistoryParams {
+	now := time.Now()
+	return p.WithTimeRange(now.Add(-d), now)
+}
+
+// WithFromTime limits the query to times at or after t.
+func (p *DailyMarkHistoryParams) WithFromTime(t time.Time) *DailyMarkHistoryParams {
+	p.From = secondsPtr(t)
+	return p
+}
+
+// WithToTime limits the query to times at or before t.
+func (p *DailyMarkHistoryParams) WithToTime(t time.Time) *DailyMarkHistoryParams {
+	p.To = secondsPtr(t)
+	return p
+}
+
+// WithTimeRange limits the query to [from, to].
+func (p *DailyMarkHistoryParams) WithTimeRange(from, to time.Time) *DailyMarkHistoryParams {
+	p.From, p.To = secondsPtr(from), secondsPtr(to)
+	return p
+}
+
+// WithLookback limits the query to the last d.
+func (p *DailyMarkHistoryParams) WithLookback(d time.Duration) *DailyMarkHistoryParams {
+	now := time.Now()
+	return p.WithTimeRange(now.Add(-d), now)
+}
+
+// WithFromTime limits the query to times at or after t.
+func (p *TransactionHistoryParams) WithFromTime(t time.Time) *TransactionHistoryParams {
+	p.From = secondsPtr(t)
+	return p
+}
+
+// WithToTime limits the query to times at or before t.
+func (p *TransactionHistoryParams) WithToTime(t time.Time) *TransactionHistoryParams {
+	p.To = secondsPtr(t)
+	return p
+}
+
+// WithTimeRange limits the query to [from, to].
+func (p *TransactionHistoryParams) WithTimeRange(from, to time.Time) *TransactionHistoryParams {
+	p.From, p.To = secondsPtr(from), secondsPtr(to)
+	return p
+}
+
+// WithLookback limits the query to the last d.
+func (p *TransactionHistoryParams) WithLookback(d time.Duration) *TransactionHistoryParams {
+	now := time.Now()
+	return p.WithTimeRange(now.Add(-d), now)
+}
+
+// secondsPtr returns t as epoch seconds with microsecond precision, for
+// the optional From and To fields.
+func secondsPtr(t time.Time) *float64 {
+	secs := NewTimestamp(t).Seconds()
+	return &secs
+}
@@ -0,0 +1,623 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/amiwrpremium/go-thalex/enums"
+)
+
+// Sentinel errors wrapped by [FieldError]. Match them with errors.Is.
+var (
+	ErrUnknownInstrument = errors.New("unknown instrument")
+	ErrOffTick           = errors.New("not a multiple of tick size")
+	ErrOffVolumeTick     = errors.New("not a multiple of volume tick size")
+	ErrBelowMinAmount    = errors.New("below minimum order amount")
+	ErrInvalidParam      = errors.New("invalid parameter")
+	ErrLegMismatch       = errors.New("inconsistent combination legs")
+)
+
+// FieldError describes a request parameter rejected by a [Validator].
+type FieldError struct {
+	// Field is the JSON path of the parameter, e.g. "price" or
+	// "legs[1].quantity".
+	Field string
+	// Err describes the problem and wraps one of the sentinel errors above.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("types: %s: %v", e.Field, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// InstrumentLookup returns the instrument with the given name. The Get
+// method of an instruments.Registry has this signature.
+type InstrumentLookup func(name string) (Instrument, bool)
+
+// TickRounding selects how a [Validator] treats values that are not on
+// the instrument's tick or volume tick.
+type TickRounding int
+
+const (
+	// TickRoundOff rejects off-tick values with a FieldError.
+	TickRoundOff TickRounding = iota
+	// TickRoundFloor rounds down to the tick.
+	TickRoundFloor
+	// TickRoundCeil rounds up to the tick.
+	TickRoundCeil
+	// TickRoundNearest rounds to the nearest tick, ties away from zero.
+	TickRoundNearest
+	// TickRoundPassive rounds prices away from the market: bids down and
+	// asks up. Amounts are rounded toward zero. Prices whose side is not
+	// known, such as bot grid levels, are rejected as with TickRoundOff.
+	TickRoundPassive
+)
+
+// String returns the name of the rounding policy.
+func (r TickRounding) String() string {
+	switch r {
+	case TickRoundOff:
+		return "off"
+	case TickRoundFloor:
+		return "floor"
+	case TickRoundCeil:
+		return "ceil"
+	case TickRoundNearest:
+		return "nearest"
+	case TickRoundPassive:
+		return "passive"
+	default:
+		return fmt.Sprintf("TickRounding(%d)", int(r))
+	}
+}
+
+// mode resolves the policy to a Decimal rounding mode for a value on the
+// given side. ok is false when the value must not be rounded.
+func (r TickRounding) mode(side enums.Direction) (RoundingMode, bool) {
+	switch r {
+	case TickRoundFloor:
+		return RoundFloor, true
+	case TickRoundCeil:
+		return RoundCeil, true
+	case TickRoundNearest:
+		return RoundNearest, true
+	case TickRoundPassive:
+		switch side {
+		case enums.DirectionBuy:
+			return RoundFloor, true
+		case enums.DirectionSell:
+			return RoundCeil, true
+		}
+	}
+	return 0, false
+}
+
+// amountMode resolves the policy to a Decimal rounding mode for amounts
+// and positions, where passive means toward zero.
+func (r TickRounding) amountMode() (RoundingMode, bool) {
+	if r == TickRoundPassive {
+		return RoundTowardZero, true
+	}
+	return r.mode("")
+}
+
+// Validator checks order, quote and bot parameters against instrument
+// metadata before they are sent, so tick, lot and minimum size problems
+// are caught client-side with the offending field named. With rounding
+// enabled it snaps prices and amounts to the tick in place instead of
+// rejecting them.
+//
+// Install it on a client with config.WithPreTradeCheck(v.Validate), or
+// call the Validate methods directly.
+type Validator struct {
+	lookup         InstrumentLookup
+	priceRounding  TickRounding
+	amountRounding TickRounding
+	now            func() time.Time
+}
+
+// NewValidator creates a validator that resolves instruments with lookup.
+// Rounding is off, so off-tick values are rejected.
+func NewValidator(lookup InstrumentLookup) *Validator {
+	return &Validator{lookup: lookup, now: time.Now}
+}
+
+// WithPriceRounding sets how off-tick prices are handled.
+func (v *Validator) WithPriceRounding(r TickRounding) *Validator {
+	v.priceRounding = r
+	return v
+}
+
+// WithAmountRounding sets how amounts off the volume tick are handled.
+// Amounts that round below the minimum order amount are still rejected.
+func (v *Validator) WithAmountRounding(r TickRounding) *Validator {
+	v.amountRounding = r
+	return v
+}
+
+// Validate checks any supported params value: *InsertOrderParams,
+// *AmendOrderParams, *MassQuoteParams, *CreateConditionalOrderParams or
+// any bot params type. Other values are accepted unchanged.
+func (v *Validator) Validate(params any) error {
+	switch p := params.(type) {
+	case *InsertOrderParams:
+		return v.ValidateInsert(p)
+	case *AmendOrderParams:
+		return v.ValidateAmend(p)
+	case *MassQuoteParams:
+		return v.ValidateMassQuote(p)
+	case *CreateConditionalOrderParams:
+		return v.ValidateConditional(p)
+	case *SGSLBotParams:
+		return v.ValidateSGSLBot(p)
+	case *OCQBotParams:
+		return v.ValidateOCQBot(p)
+	case *LevelsBotParams:
+		return v.ValidateLevelsBot(p)
+	case *GridBotParams:
+		return v.ValidateGridBot(p)
+	case *DHedgeBotParams:
+		return v.ValidateDHedgeBot(p)
+	case *DFollowBotParams:
+		return v.ValidateDFollowBot(p)
+	}
+	return nil
+}
+
+// ValidateInsert checks an order insert. For combination orders it also
+// checks that the legs are distinct, on one underlying and sized on each
+// leg's volume tick.
+func (v *Validator) ValidateInsert(p *InsertOrderParams) error {
+	c := v.checker()
+	if !p.Direction.IsValid() {
+		c.invalid("direction", "must be buy or sell")
+	}
+	if p.OrderType != "" && !p.OrderType.IsValid() {
+		c.invalid("order_type", fmt.Sprintf("unknown order type %q", p.OrderType))
+	}
+	if len(p.Legs) > 0 {
+		if p.InstrumentName != "" {
+			c.invalid("instrument_name", "cannot be combined with legs")
+		}
+		c.legs(p.Legs)
+		c.size("amount", &p.Amount, Instrument{}, sizeOrder)
+		if p.Price != nil {
+			c.finite("price", *p.Price)
+		}
+		return c.err()
+	}
+	inst, ok := c.instrument("instrument_name", p.InstrumentName)
+	if !ok {
+		return c.err()
+	}
+	c.size("amount", &p.Amount, inst, sizeOrder)
+	if p.Price != nil {
+		c.price("price", p.Price, inst, p.Direction)
+	}
+	return c.err()
+}
+
+// ValidateAmend checks an amend. Amend requests do not name the
+// instrument, so tick and lot checks only run when the params carry it,
+// see [AmendOrderParams.WithInstrument].
+func (v *Validator) ValidateAmend(p *AmendOrderParams) error {
+	c := v.checker()
+	if p.OrderID == "" && p.ClientOrderID == nil {
+		c.invalid("order_id", "order_id or client_order_id is required")
+	}
+	if p.InstrumentName == "" {
+		c.finite("price", p.Price)
+		c.size("amount", &p.Amount, Instrument{}, sizeOrder)
+		return c.err()
+	}
+	inst, ok := c.instrument("instrument_name", p.InstrumentName)
+	if !ok {
+		return c.err()
+	}
+	c.price("price", &p.Price, inst, p.Direction)
+	c.size("amount", &p.Amount, inst, sizeOrder)
+	return c.err()
+}
+
+// ValidateMassQuote checks every quote level. Zero amounts are allowed,
+// since they pull a level. Each instrument may appear once, and its best
+// bid must be below its best ask.
+func (v *Validator) ValidateMassQuote(p *MassQuoteParams) error {
+	c := v.checker()
+	seen := make(map[string]bool, len(p.Quotes))
+	for i := range p.Quotes {
+		q := &p.Quotes[i]
+		prefix := fmt.Sprintf("quotes[%d]", i)
+		if seen[q.I] {
+			c.invalid(prefix+".i", fmt.Sprintf("duplicate instrument %q", q.I))
+		}
+		seen[q.I] = true
+		inst, ok := c.instrument(prefix+".i", q.I)
+		if !ok {
+			continue
+		}
+		bestBid, hasBid := c.quoteSide(prefix+".b", &q.B, inst, enums.DirectionBuy)
+		bestAsk, hasAsk := c.quoteSide(prefix+".a", &q.A, inst, enums.DirectionSell)
+		if hasBid && hasAsk && bestBid >= bestAsk {
+			c.invalid(prefix, fmt.Sprintf("bid %v crosses ask %v", bestBid, bestAsk))
+		}
+	}
+	return c.err()
+}
+
+// ValidateConditional checks a stop, stop limit, bracket or trailing stop
+// order.
+func (v *Validator) ValidateConditional(p *CreateConditionalOrderParams) error {
+	c := v.checker()
+	if !p.Direction.IsValid() {
+		c.invalid("direction", "must be buy or sell")
+	}
+	if p.Target != "" && !p.Target.IsValid() {
+		c.invalid("target", fmt.Sprintf("unknown target %q", p.Target))
+	}
+	if p.TrailingStopCallbackRate != nil && !(*p.TrailingStopCallbackRate > 0) {
+		c.invalid("trailing_stop_callback_rate", "must be positive")
+	}
+	inst, ok := c.instrument("instrument_name", p.InstrumentName)
+	if !ok {
+		return c.err()
+	}
+	c.size("amount", &p.Amount, inst, sizeOrder)
+	c.price("stop_price", &p.StopPrice, inst, "")
+	if p.LimitPrice != nil {
+		c.price("limit_price", p.LimitPrice, inst, p.Direction)
+	}
+	if p.BracketPrice != nil {
+		c.price("bracket_price", p.BracketPrice, inst, "")
+	}
+	return c.err()
+}
+
+// ValidateSGSLBot checks SGSL bot parameters.
+func (v *Validator) ValidateSGSLBot(p *SGSLBotParams) error {
+	c := v.checker()
+	c.endTime("end_time", p.EndTime)
+	c.nonNegative("max_slippage", p.MaxSlippage)
+	inst, ok := c.instrument("instrument_name", p.InstrumentName)
+	if !ok {
+		return c.err()
+	}
+	c.price("entry_price", &p.EntryPrice, inst, "")
+	c.price("exit_price", &p.ExitPrice, inst, "")
+	c.size("target_position", &p.TargetPosition, inst, sizePosition)
+	c.size("exit_position", &p.ExitPosition, inst, sizePosition)
+	return c.err()
+}
+
+// ValidateOCQBot checks OCQ bot parameters.
+func (v *Validator) ValidateOCQBot(p *OCQBotParams) error {
+	c := v.checker()
+	c.endTime("end_time", p.EndTime)
+	if p.MinPosition > p.MaxPosition {
+		c.invalid("min_position", fmt.Sprintf("%v is above max_position %v", p.MinPosition, p.MaxPosition))
+	}
+	inst, ok := c.instrument("instrument_name", p.InstrumentName)
+	if !ok {
+		return c.err()
+	}
+	c.size("quote_size", &p.QuoteSize, inst, sizeOrder)
+	c.size("min_position", &p.MinPosition, inst, sizePosition)
+	c.size("max_position", &p.MaxPosition, inst, sizePosition)
+	if p.TargetPosition != nil {
+		c.size("target_position", p.TargetPosition, inst, sizePosition)
+	}
+	return c.err()
+}
+
+// ValidateLevelsBot checks Levels bot parameters.
+func (v *Validator) ValidateLevelsBot(p *LevelsBotParams) error {
+	c := v.checker()
+	c.endTime("end_time", p.EndTime)
+	c.nonNegative("max_slippage", p.MaxSlippage)
+	inst, ok := c.instrument("instrument_name", p.InstrumentName)
+	if !ok {
+		return c.err()
+	}
+	for i := range p.Bids {
+		c.price(fmt.Sprintf("bids[%d]", i), &p.Bids[i], inst, enums.DirectionBuy)
+	}
+	for i := range p.Asks {
+		c.price(fmt.Sprintf("asks[%d]", i), &p.Asks[i], inst, enums.DirectionSell)
+	}
+	c.size("step_size", &p.StepSize, inst, sizeOrder)
+	c.botCommon(inst, p.BasePosition, p.UpsideExitPrice, p.DownsideExitPrice)
+	return c.err()
+}
+
+// ValidateGridBot checks Grid bot parameters. Grid levels must be strictly
+// ascending.
+func (v *Validator) ValidateGridBot(p *GridBotParams) error {
+	c := v.checker()
+	c.endTime("end_time", p.EndTime)
+	c.nonNegative("max_slippage", p.MaxSlippage)
+	inst, ok := c.instrument("instrument_name", p.InstrumentName)
+	if !ok {
+		return c.err()
+	}
+	for i := range p.Grid {
+		field := fmt.Sprintf("grid[%d]", i)
+		c.price(field, &p.Grid[i], inst, "")
+		if i > 0 && p.Grid[i] <= p.Grid[i-1] {
+			c.invalid(field, "grid levels must be strictly ascending")
+		}
+	}
+	c.size("step_size", &p.StepSize, inst, sizeOrder)
+	c.botCommon(inst, p.BasePosition, p.UpsideExitPrice, p.DownsideExitPrice)
+	return c.err()
+}
+
+// ValidateDHedgeBot checks DHedge bot parameters.
+func (v *Validator) ValidateDHedgeBot(p *DHedgeBotParams) error {
+	c := v.checker()
+	c.instrument("instrument_name", p.InstrumentName)
+	if !(p.Period > 0) {
+		c.invalid("period", "must be positive")
+	}
+	c.nonNegative("threshold", p.Threshold)
+	c.nonNegative("tolerance", p.Tolerance)
+	c.nonNegative("max_slippage", p.MaxSlippage)
+	if p.EndTime != nil {
+		c.endTime("end_time", p.EndTime.Seconds())
+	}
+	return c.err()
+}
+
+// ValidateDFollowBot checks DFollow bot parameters.
+func (v *Validator) ValidateDFollowBot(p *DFollowBotParams) error {
+	c := v.checker()
+	c.endTime("end_time", p.EndTime)
+	if !(p.Period > 0) {
+		c.invalid("period", "must be positive")
+	}
+	c.nonNegative("threshold", p.Threshold)
+	c.nonNegative("tolerance", p.Tolerance)
+	c.nonNegative("max_slippage", p.MaxSlippage)
+	c.instrument("target_instrument", p.TargetInstrument)
+	if inst, ok := c.instrument("instrument_name", p.InstrumentName); ok {
+		c.size("target_amount", &p.TargetAmount, inst, sizePosition)
+	}
+	return c.err()
+}
+
+// --- checker ---
+
+// checker accumulates FieldErrors for one params value.
+type checker struct {
+	v    *Validator
+	errs []error
+}
+
+func (v *Validator) checker() *checker { return &checker{v: v} }
+
+func (c *checker) err() error { return errors.Join(c.errs...) }
+
+func (c *checker) fail(field string, err error) {
+	c.errs = append(c.errs, &FieldError{Field: field, Err: err})
+}
+
+func (c *checker) invalid(field, reason string) {
+	c.fail(field, fmt.Errorf("%w: %s", ErrInvalidParam, reason))
+}
+
+func (c *checker) instrument(field, name string) (Instrument, bool) {
+	if name == "" {
+		c.invalid(field, "required")
+		return Instrument{}, false
+	}
+	inst, ok := c.v.lookup(name)
+	if !ok {
+		c.fail(field, fmt.Errorf("%w %q", ErrUnknownInstrument, name))
+	}
+	return inst, ok
+}
+
+func (c *checker) finite(field string, x float64) bool {
+	if math.IsNaN(x) || math.IsInf(x, 0) {
+		c.invalid(field, "must be a finite number")
+		return false
+	}
+	return true
+}
+
+// decimal converts x, which must be finite, to a Decimal. It fails for a
+// value too large for a Decimal instead of letting DecimalFromFloat panic.
+func (c *checker) decimal(field string, x float64) (Decimal, bool) {
+	if math.Abs(x) >= 1<<63 {
+		c.invalid(field, fmt.Sprintf("%v is out of range", x))
+		return Decimal{}, false
+	}
+	return DecimalFromFloat(x), true
+}
+
+func (c *checker) nonNegative(field string, x *float64) {
+	if x != nil && !(*x >= 0) {
+		c.invalid(field, "must not be negative")
+	}
+}
+
+func (c *checker) endTime(field string, secs float64) {
+	if t := Timestamp(secs); !t.After(NewTimestamp(c.v.now())) {
+		c.invalid(field, fmt.Sprintf("%s is not in the future", t))
+	}
+}
+
+// price checks that *p is on the instrument's tick, rounding it in place
+// when the validator is configured to.
+func (c *checker) price(field string, p *float64, inst Instrument, side enums.Direction) {
+	if !c.finite(field, *p) || inst.TickSize <= 0 {
+		return
+	}
+	d, ok := c.decimal(field, *p)
+	if !ok {
+		return
+	}
+	tick := DecimalFromFloat(inst.TickSize)
+	if d.IsMultipleOf(tick) {
+		return
+	}
+	if mode, ok := c.v.priceRounding.mode(side); ok {
+		c.round(field, p, d, tick, mode)
+		return
+	}
+	c.fail(field, fmt.Errorf("%v is %w %v", *p, ErrOffTick, inst.TickSize))
+}
+
+// round rounds d to a multiple of tick and stores it in *x. It fails if
+// the rounded value does not fit in a Decimal.
+func (c *checker) round(field string, x *float64, d, tick Decimal, mode RoundingMode) bool {
+	r, err := d.RoundToTickErr(tick, mode)
+	if err != nil {
+		c.invalid(field, fmt.Sprintf("%v is out of range", *x))
+		return false
+	}
+	*x = r.Float64()
+	return true
+}
+
+// sizeKind selects the rules applied by checker.size.
+type sizeKind int
+
+const (
+	// sizeOrder is an order amount: positive and at least the minimum.
+	sizeOrder sizeKind = iota
+	// sizeQuote is a quote amount: zero or at least the minimum.
+	sizeQuote
+	// sizePosition is a signed position target: only the lot is checked.
+	sizePosition
+)
+
+// size checks that *a is on the instrument's volume tick and meets the
+// minimum, rounding it in place when the validator is configured to. A
+// zero Instrument skips the lot and minimum checks.
+func (c *checker) size(field string, a *float64, inst Instrument, kind sizeKind) {
+	if !c.finite(field, *a) {
+		return
+	}
+	switch {
+	case kind == sizeOrder && !(*a > 0):
+		c.invalid(field, "must be positive")
+		return
+	case kind == sizeQuote && *a < 0:
+		c.invalid(field, "must not be negative")
+		return
+	}
+	d, ok := c.decimal(field, *a)
+	if !ok {
+		return
+	}
+	if tick := DecimalFromFloat(inst.VolumeTickSize); !d.IsMultipleOf(tick) {
+		mode, ok := c.v.amountRounding.amountMode()
+		if !ok {
+			c.fail(field, fmt.Errorf("%v is %w %v", *a, ErrOffVolumeTick, inst.VolumeTickSize))
+			return
+		}
+		if !c.round(field, a, d, tick, mode) {
+			return
+		}
+	}
+	if kind != sizePosition && *a != 0 && *a < inst.MinOrderAmount {
+		c.fail(field, fmt.Errorf("%v is %w %v", *a, ErrBelowMinAmount, inst.MinOrderAmount))
+	}
+}
+
+// legs checks combination legs for consistency.
+func (c *checker) legs(legs []InsertLeg) {
+	if len(legs) < 2 {
+		c.fail("legs", fmt.Errorf("%w: a combination needs at least two legs", ErrLegMismatch))
+	}
+	seen := make(map[string]bool, len(legs))
+	underlying := ""
+	for i, leg := range legs {
+		prefix := fmt.Sprintf("legs[%d]", i)
+		if seen[leg.InstrumentName] {
+			c.fail(prefix+".instrument_name", fmt.Errorf("%w: duplicate leg %q", ErrLegMismatch, leg.InstrumentName))
+			continue
+		}
+		seen[leg.InstrumentName] = true
+		if !c.finite(prefix+".quantity", leg.Quantity) {
+			continue
+		}
+		if leg.Quantity == 0 {
+			c.invalid(prefix+".quantity", "must not be zero")
+		}
+		inst, ok := c.instrument(prefix+".instrument_name", leg.InstrumentName)
+		if !ok {
+			continue
+		}
+		if inst.IsCombination() {
+			c.fail(prefix+".instrument_name", fmt.Errorf("%w: leg %q is itself a combination", ErrLegMismatch, leg.InstrumentName))
+		}
+		switch {
+		case underlying == "":
+			underlying = inst.Underlying
+		case inst.Underlying != underlying:
+			c.fail(prefix+".instrument_name", fmt.Errorf("%w: underlying %s differs from %s", ErrLegMismatch, inst.Underlying, underlying))
+		}
+		if d, ok := c.decimal(prefix+".quantity", leg.Quantity); ok && !d.IsMultipleOf(DecimalFromFloat(inst.VolumeTickSize)) {
+			c.fail(prefix+".quantity", fmt.Errorf("%v is %w %v", leg.Quantity, ErrOffVolumeTick, inst.VolumeTickSize))
+		}
+	}
+}
+
+// quoteSide checks one side of a mass quote, which is a SingleLevelQuote
+// or a list of [price, amount] pairs, and returns its best price among
+// levels with a non-zero amount.
+func (c *checker) quoteSide(field string, side *any, inst Instrument, dir enums.Direction) (best float64, ok bool) {
+	consider := func(price, amount float64) {
+		if amount == 0 {
+			return
+		}
+		if !ok || (dir == enums.DirectionBuy && price > best) || (dir == enums.DirectionSell && price < best) {
+			best, ok = price, true
+		}
+	}
+	switch s := (*side).(type) {
+	case nil:
+	case SingleLevelQuote:
+		c.price(field+".p", &s.P, inst, dir)
+		c.size(field+".a", &s.A, inst, sizeQuote)
+		*side = s
+		consider(s.P, s.A)
+	case *SingleLevelQuote:
+		c.price(field+".p", &s.P, inst, dir)
+		c.size(field+".a", &s.A, inst, sizeQuote)
+		consider(s.P, s.A)
+	case [][2]float64:
+		for i := range s {
+			level := fmt.Sprintf("%s[%d]", field, i)
+			c.price(level+".price", &s[i][0], inst, dir)
+			c.size(level+".amount", &s[i][1], inst, sizeQuote)
+			consider(s[i][0], s[i][1])
+		}
+	default:
+		c.invalid(field, fmt.Sprintf("unsupported quote type %T", s))
+	}
+	return best, ok
+}
+
+// botCommon checks the optional position and exit prices shared by the
+// Levels and Grid bots.
+func (c *checker) botCommon(inst Instrument, basePosition, upsideExit, downsideExit *float64) {
+	if basePosition != nil {
+		c.size("base_position", basePosition, inst, sizePosition)
+	}
+	if upsideExit != nil {
+		c.price("upside_exit_price", upsideExit, inst, "")
+	}
+	if downsideExit != nil {
+		c.price("downside_exit_price", downsideExit, inst, "")
+	}
+}
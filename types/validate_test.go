@@ -0,0 +1,279 @@
+package types_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/amiwrpremium/go-thalex/enums"
+	"github.com/amiwrpremium/go-thalex/types"
+)
+
+func testLookup(name string) (types.Instrument, bool) {
+	insts := map[string]types.Instrument{
+		"BTC-PERPETUAL":        {InstrumentName: "BTC-PERPETUAL", Underlying: "BTCUSD", Type: enums.InstrumentTypePerpetual, TickSize: 0.5, VolumeTickSize: 0.001, MinOrderAmount: 0.001},
+		"BTC-28MAR25":          {InstrumentName: "BTC-28MAR25", Underlying: "BTCUSD", Type: enums.InstrumentTypeFuture, TickSize: 0.5, VolumeTickSize: 0.001, MinOrderAmount: 0.001},
+		"BTC-28MAR25-100000-C": {InstrumentName: "BTC-28MAR25-100000-C", Underlying: "BTCUSD", Type: enums.InstrumentTypeOption, TickSize: 5, VolumeTickSize: 0.01, MinOrderAmount: 0.1},
+		"ETH-PERPETUAL":        {InstrumentName: "ETH-PERPETUAL", Underlying: "ETHUSD", Type: enums.InstrumentTypePerpetual, TickSize: 0.05, VolumeTickSize: 0.01, MinOrderAmount: 0.01},
+	}
+	inst, ok := insts[name]
+	return inst, ok
+}
+
+// fieldErrors returns the FieldErrors joined in err, keyed by field.
+func fieldErrors(t *testing.T, err error) map[string]*types.FieldError {
+	t.Helper()
+	out := make(map[string]*types.FieldError)
+	if err == nil {
+		return out
+	}
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		t.Fatalf("expected joined errors, got %T: %v", err, err)
+	}
+	for _, e := range joined.Unwrap() {
+		var fe *types.FieldError
+		if !errors.As(e, &fe) {
+			t.Fatalf("expected *FieldError, got %T: %v", e, e)
+		}
+		out[fe.Field] = fe
+	}
+	return out
+}
+
+func TestValidateInsert(t *testing.T) {
+	v := types.NewValidator(testLookup)
+	if err := v.ValidateInsert(types.NewBuyOrderParams("BTC-PERPETUAL", 0.01).WithPrice(95000.5)); err != nil {
+		t.Fatalf("valid order rejected: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		params *types.InsertOrderParams
+		field  string
+		want   error
+	}{
+		{"OffTick", types.NewBuyOrderParams("BTC-PERPETUAL", 0.01).WithPrice(95000.3), "price", types.ErrOffTick},
+		{"OffLot", types.NewBuyOrderParams("BTC-PERPETUAL", 0.0105), "amount", types.ErrOffVolumeTick},
+		{"BelowMin", types.NewSellOrderParams("BTC-28MAR25-100000-C", 0.05), "amount", types.ErrBelowMinAmount},
+		{"ZeroAmount", types.NewSellOrderParams("BTC-PERPETUAL", 0), "amount", types.ErrInvalidParam},
+		{"HugePrice", types.NewBuyOrderParams("BTC-PERPETUAL", 0.01).WithPrice(1e19), "price", types.ErrInvalidParam},
+		{"HugeAmount", types.NewBuyOrderParams("BTC-PERPETUAL", 1e19), "amount", types.ErrInvalidParam},
+		{"UnknownInstrument", types.NewBuyOrderParams("DOGE-PERPETUAL", 1), "instrument_name", types.ErrUnknownInstrument},
+		{"MissingInstrument", types.NewBuyOrderParams("", 1), "instrument_name", types.ErrInvalidParam},
+		{"BadDirection", types.NewInsertOrderParams("hold", "BTC-PERPETUAL", 1), "direction", types.ErrInvalidParam},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := fieldErrors(t, v.ValidateInsert(tt.params))
+			fe, ok := errs[tt.field]
+			if !ok {
+				t.Fatalf("no error for %s, got %v", tt.field, errs)
+			}
+			if !errors.Is(fe, tt.want) {
+				t.Errorf("%s error = %v, want %v", tt.field, fe, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateInsert_ReportsAllFields(t *testing.T) {
+	v := types.NewValidator(testLookup)
+	errs := fieldErrors(t, v.ValidateInsert(types.NewBuyOrderParams("BTC-PERPETUAL", 0.0105).WithPrice(95000.3)))
+	if len(errs) != 2 || errs["price"] == nil || errs["amount"] == nil {
+		t.Errorf("expected price and amount errors, got %v", errs)
+	}
+	if got := errs["price"].Error(); got != "types: price: 95000.3 is not a multiple of tick size 0.5" {
+		t.Errorf("Error() = %q", got)
+	}
+}
+
+func TestValidateInsert_Rounding(t *testing.T) {
+	tests := []struct {
+		name      string
+		rounding  types.TickRounding
+		direction enums.Direction
+		wantPrice float64
+	}{
+		{"Floor", types.TickRoundFloor, enums.DirectionSell, 95000.0},
+		{"Ceil", types.TickRoundCeil, enums.DirectionBuy, 95000.5},
+		{"Nearest", types.TickRoundNearest, enums.DirectionBuy, 95000.5},
+		{"PassiveBuy", types.TickRoundPassive, enums.DirectionBuy, 95000.0},
+		{"PassiveSell", types.TickRoundPassive, enums.DirectionSell, 95000.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := types.NewValidator(testLookup).WithPriceRounding(tt.rounding).WithAmountRounding(tt.rounding)
+			p := types.NewInsertOrderParams(tt.direction, "BTC-PERPETUAL", 0.0105).WithPrice(95000.3)
+			if err := v.ValidateInsert(p); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if *p.Price != tt.wantPrice {
+				t.Errorf("price = %v, want %v", *p.Price, tt.wantPrice)
+			}
+			if p.Amount != 0.01 && p.Amount != 0.011 {
+				t.Errorf("amount = %v, want a multiple of 0.001", p.Amount)
+			}
+		})
+	}
+
+	v := types.NewValidator(testLookup).WithPriceRounding(types.TickRoundNearest)
+	errs := fieldErrors(t, v.ValidateInsert(types.NewBuyOrderParams("BTC-PERPETUAL", 0.01).WithPrice(-1e19)))
+	if got := errs["price"]; got == nil || got.Error() != "types: price: invalid parameter: -1e+19 is out of range" {
+		t.Errorf("price error = %v", got)
+	}
+
+	v = types.NewValidator(testLookup).WithAmountRounding(types.TickRoundFloor)
+	p := types.NewSellOrderParams("BTC-28MAR25-100000-C", 0.105)
+	if err := v.ValidateInsert(p); err != nil || p.Amount != 0.1 {
+		t.Errorf("0.105 floored to the 0.1 minimum should pass; err=%v amount=%v", err, p.Amount)
+	}
+	p = types.NewSellOrderParams("BTC-28MAR25-100000-C", 0.095)
+	if err := v.ValidateInsert(p); !errors.Is(err, types.ErrBelowMinAmount) {
+		t.Errorf("amount rounded below the minimum should fail, got %v", err)
+	}
+}
+
+func TestValidateInsert_Combo(t *testing.T) {
+	v := types.NewValidator(testLookup)
+	ok := types.NewComboInsertOrderParams(enums.DirectionBuy, []types.InsertLeg{
+		{InstrumentName: "BTC-28MAR25", Quantity: 1},
+		{InstrumentName: "BTC-PERPETUAL", Quantity: -1},
+	}, 0.1)
+	if err := v.ValidateInsert(ok); err != nil {
+		t.Fatalf("valid combo rejected: %v", err)
+	}
+
+	bad := types.NewComboInsertOrderParams(enums.DirectionBuy, []types.InsertLeg{
+		{InstrumentName: "BTC-28MAR25", Quantity: 1},
+		{InstrumentName: "BTC-28MAR25", Quantity: 1},
+		{InstrumentName: "ETH-PERPETUAL", Quantity: 0.005},
+		{InstrumentName: "BTC-PERPETUAL", Quantity: 0},
+	}, 0.1)
+	errs := fieldErrors(t, v.ValidateInsert(bad))
+	for field, want := range map[string]error{
+		"legs[1].instrument_name": types.ErrLegMismatch,
+		"legs[2].instrument_name": types.ErrLegMismatch,
+		"legs[2].quantity":        types.ErrOffVolumeTick,
+		"legs[3].quantity":        types.ErrInvalidParam,
+	} {
+		if fe := errs[field]; fe == nil || !errors.Is(fe, want) {
+			t.Errorf("%s: got %v, want %v", field, fe, want)
+		}
+	}
+
+	single := types.NewComboInsertOrderParams(enums.DirectionBuy, []types.InsertLeg{{InstrumentName: "BTC-28MAR25", Quantity: 1}}, 0.1)
+	if err := v.ValidateInsert(single); !errors.Is(err, types.ErrLegMismatch) {
+		t.Errorf("single-leg combo error = %v", err)
+	}
+}
+
+func TestValidateAmend(t *testing.T) {
+	v := types.NewValidator(testLookup).WithPriceRounding(types.TickRoundPassive)
+
+	// Without the instrument only basic checks run.
+	p := types.NewAmendByOrderID("o1", 95000.3, 0.01)
+	if err := v.ValidateAmend(p); err != nil || p.Price != 95000.3 {
+		t.Errorf("amend without instrument: err=%v price=%v", err, p.Price)
+	}
+
+	p.WithInstrument("BTC-PERPETUAL", enums.DirectionSell)
+	if err := v.ValidateAmend(p); err != nil || p.Price != 95000.5 {
+		t.Errorf("amend with instrument: err=%v price=%v", err, p.Price)
+	}
+
+	// Passive rounding without a side cannot pick a direction.
+	p = types.NewAmendByOrderID("o1", 95000.3, 0.01)
+	p.InstrumentName = "BTC-PERPETUAL"
+	if err := v.ValidateAmend(p); !errors.Is(err, types.ErrOffTick) {
+		t.Errorf("sideless passive amend error = %v", err)
+	}
+
+	if err := v.ValidateAmend(&types.AmendOrderParams{Price: 1, Amount: 1}); !errors.Is(err, types.ErrInvalidParam) {
+		t.Errorf("amend without order id error = %v", err)
+	}
+}
+
+func TestValidateMassQuote(t *testing.T) {
+	v := types.NewValidator(testLookup).WithPriceRounding(types.TickRoundPassive)
+	p := types.NewMassQuoteParams([]types.DoubleSidedQuote{
+		types.NewSingleLevelQuote("BTC-PERPETUAL", 95000.3, 0.01, 95001.2, 0.01),
+		types.NewDoubleSidedQuote("ETH-PERPETUAL",
+			[]types.QuoteLevel{{Price: 3000.02, Amount: 0.1}, {Price: 2999.5, Amount: 0}},
+			[]types.QuoteLevel{{Price: 3000.07, Amount: 0.1}}),
+	})
+	if err := v.ValidateMassQuote(p); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b := p.Quotes[0].B.(types.SingleLevelQuote); b.P != 95000.0 {
+		t.Errorf("bid = %v, want 95000", b.P)
+	}
+	if a := p.Quotes[0].A.(types.SingleLevelQuote); a.P != 95001.5 {
+		t.Errorf("ask = %v, want 95001.5", a.P)
+	}
+	if b := p.Quotes[1].B.([][2]float64); b[0][0] != 3000.0 {
+		t.Errorf("eth bid = %v, want 3000", b[0][0])
+	}
+	if a := p.Quotes[1].A.([][2]float64); a[0][0] != 3000.1 {
+		t.Errorf("eth ask = %v, want 3000.1", a[0][0])
+	}
+
+	crossed := types.NewMassQuoteParams([]types.DoubleSidedQuote{
+		types.NewSingleLevelQuote("BTC-PERPETUAL", 95001, 0.01, 95000, 0.01),
+		types.NewSingleLevelQuote("BTC-PERPETUAL", 94000, 0.01, 96000, 0.0001),
+	})
+	errs := fieldErrors(t, types.NewValidator(testLookup).ValidateMassQuote(crossed))
+	if errs["quotes[0]"] == nil || errs["quotes[1].i"] == nil || errs["quotes[1].a.a"] == nil {
+		t.Errorf("expected crossed, duplicate and lot errors, got %v", errs)
+	}
+}
+
+func TestValidateConditional(t *testing.T) {
+	v := types.NewValidator(testLookup)
+	if err := v.ValidateConditional(types.NewStopLimitOrder(enums.DirectionSell, "BTC-PERPETUAL", 0.01, 90000, 89999.5)); err != nil {
+		t.Fatalf("valid stop limit rejected: %v", err)
+	}
+	p := types.NewStopLimitOrder(enums.DirectionSell, "BTC-PERPETUAL", 0.01, 90000.2, 89999.7).WithTarget("nowhere")
+	errs := fieldErrors(t, v.ValidateConditional(p))
+	if errs["stop_price"] == nil || errs["limit_price"] == nil || errs["target"] == nil {
+		t.Errorf("expected stop_price, limit_price and target errors, got %v", errs)
+	}
+}
+
+func TestValidateBots(t *testing.T) {
+	v := types.NewValidator(testLookup)
+	end := time.Now().Add(time.Hour)
+
+	grid := types.NewGridBotParamsUntil("BTC-PERPETUAL", []float64{94000, 95000.5, 95000}, 0.01, end)
+	errs := fieldErrors(t, v.ValidateGridBot(grid))
+	if fe := errs["grid[2]"]; fe == nil || !errors.Is(fe, types.ErrInvalidParam) {
+		t.Errorf("unsorted grid: got %v", errs)
+	}
+
+	levels := types.NewLevelsBotParamsUntil("BTC-PERPETUAL", []float64{94000.2}, []float64{96000.2}, 0.01, end)
+	rounded := types.NewValidator(testLookup).WithPriceRounding(types.TickRoundPassive)
+	if err := rounded.ValidateLevelsBot(levels); err != nil || levels.Bids[0] != 94000 || levels.Asks[0] != 96000.5 {
+		t.Errorf("levels rounding: err=%v bids=%v asks=%v", err, levels.Bids, levels.Asks)
+	}
+
+	expired := types.NewSGSLBotParamsUntil("BTC-PERPETUAL", enums.TargetMark, 95000, 0.1, 90000, 0, time.Now().Add(-time.Minute))
+	if fe := fieldErrors(t, v.ValidateSGSLBot(expired))["end_time"]; fe == nil {
+		t.Error("expired SGSL bot should fail on end_time")
+	}
+
+	ocq := types.NewOCQBotParamsUntil("BTC-28MAR25-100000-C", enums.TargetMark, 5, 5, 0.1, 1, -1, end)
+	if fe := fieldErrors(t, v.ValidateOCQBot(ocq))["min_position"]; fe == nil {
+		t.Error("min_position above max_position should fail")
+	}
+
+	if err := v.Validate(types.NewDHedgeBotParams("BTC-PERPETUAL", 0)); !errors.Is(err, types.ErrInvalidParam) {
+		t.Errorf("DHedge with zero period error = %v", err)
+	}
+	follow := types.NewDFollowBotParamsUntil("BTC-PERPETUAL", "DOGE-PERPETUAL", 0.01, 60, end)
+	if fe := fieldErrors(t, v.Validate(follow))["target_instrument"]; fe == nil || !errors.Is(fe, types.ErrUnknownInstrument) {
+		t.Errorf("unknown target instrument: got %v", fe)
+	}
+	if err := v.Validate(types.CancelByOrderID("o1")); err != nil {
+		t.Errorf("unsupported params should pass, got %v", err)
+	}
+}
@@ -11,7 +11,7 @@ type Deposit struct {
 	Amount               float64             `json:"amount"`
 	Blockchain           string              `json:"blockchain"`
 	TransactionHash      string              `json:"transaction_hash"`
-	TransactionTimestamp float64             `json:"transaction_timestamp"`
+	TransactionTimestamp Timestamp           `json:"transaction_timestamp"`
 	Status               enums.DepositStatus `json:"status"`
 	Confirmations        *int                `json:"confirmations,omitempty"`
 }
@@ -29,7 +29,7 @@ type Withdrawal struct {
 	TargetAddress   string                 `json:"target_address"`
 	Blockchain      string                 `json:"blockchain,omitempty"`
 	TransactionHash string                 `json:"transaction_hash,omitempty"`
-	CreateTime      float64                `json:"create_time"`
+	CreateTime      Timestamp              `json:"create_time"`
 	Label           string                 `json:"label,omitempty"`
 	State           enums.WithdrawalStatus `json:"state"`
 	Remark          string                 `json:"remark,omitempty"`
@@ -18,6 +18,9 @@ func (ws *Client) Bots(ctx context.Context, includeInactive bool) ([]types.Bot,
 // CreateSGSLBot creates a new SGSL bot via WebSocket.
 func (ws *Client) CreateSGSLBot(ctx context.Context, params *types.SGSLBotParams) (types.Bot, error) {
 	var result types.Bot
+	if err := ws.preTradeCheck(ctx, params); err != nil {
+		return result, err
+	}
 	err := ws.call(ctx, "private/create_bot", params, &result)
 	return result, err
 }
@@ -25,6 +28,9 @@ func (ws *Client) CreateSGSLBot(ctx context.Context, params *types.SGSLBotParams
 // CreateOCQBot creates a new OCQ bot via WebSocket.
 func (ws *Client) CreateOCQBot(ctx context.Context, params *types.OCQBotParams) (types.Bot, error) {
 	var result types.Bot
+	if err := ws.preTradeCheck(ctx, params); err != nil {
+		return result, err
+	}
 	err := ws.call(ctx, "private/create_bot", params, &result)
 	return result, err
 }
@@ -32,6 +38,9 @@ func (ws *Client) CreateOCQBot(ctx context.Context, params *types.OCQBotParams)
 // CreateLevelsBot creates a new Levels bot via WebSocket.
 func (ws *Client) CreateLevelsBot(ctx context.Context, params *types.LevelsBotParams) (types.Bot, error) {
 	var result types.Bot
+	if err := ws.preTradeCheck(ctx, params); err != nil {
+		return result, err
+	}
 	err := ws.call(ctx, "private/create_bot", params, &result)
 	return result, err
 }
@@ -39,6 +48,9 @@ func (ws *Client) CreateLevelsBot(ctx context.Context, params *types.LevelsBotPa
 // CreateGridBot creates a new Grid bot via WebSocket.
 func (ws *Client) CreateGridBot(ctx context.Context, params *types.GridBotParams) (types.Bot, error) {
 	var result types.Bot
+	if err := ws.preTradeCheck(ctx, params); err != nil {
+		return result, err
+	}
 	err := ws.call(ctx, "private/create_bot", params, &result)
 	return result, err
 }
@@ -46,6 +58,9 @@ func (ws *Client) CreateGridBot(ctx context.Context, params *types.GridBotParams
 // CreateDHedgeBot creates a new Delta Hedger bot via WebSocket.
 func (ws *Client) CreateDHedgeBot(ctx context.Context, params *types.DHedgeBotParams) (types.Bot, error) {
 	var result types.Bot
+	if err := ws.preTradeCheck(ctx, params); err != nil {
+		return result, err
+	}
 	err := ws.call(ctx, "private/create_bot", params, &result)
 	return result, err
 }
@@ -53,6 +68,9 @@ func (ws *Client) CreateDHedgeBot(ctx context.Context, params *types.DHedgeBotPa
 // CreateDFollowBot creates a new Delta Follower bot via WebSocket.
 func (ws *Client) CreateDFollowBot(ctx context.Context, params *types.DFollowBotParams) (types.Bot, error) {
 	var result types.Bot
+	if err := ws.preTradeCheck(ctx, params); err != nil {
+		return result, err
+	}
 	err := ws.call(ctx, "private/create_bot", params, &result)
 	return result, err
 }
@@ -10,6 +10,7 @@ import (
 	"github.com/amiwrpremium/go-thalex/apierr"
 	"github.com/amiwrpremium/go-thalex/config"
 	"github.com/amiwrpremium/go-thalex/internal/jsonrpc"
+	"github.com/amiwrpremium/go-thalex/internal/pretrade"
 	"github.com/amiwrpremium/go-thalex/internal/transport"
 	"github.com/amiwrpremium/go-thalex/types"
 )
@@ -30,7 +31,11 @@ type Client struct {
 	subMu    sync.RWMutex
 	handlers map[string]any
 
-	onError func(error)
+	onError        func(error)
+	onDecodeError  func(*DecodeError)
+	onReconnected  []func()
+	hooks          []Hook
+	decodeCounters decodeCounters
 }
 
 // NewClient creates a new WebSocket API client.
@@ -44,11 +49,25 @@ func NewClient(opts ...config.ClientOption) *Client {
 		pending:  make(map[uint64]*pendingCall),
 		handlers: make(map[string]any),
 	}
+	endpoints := cfg.WebSocketEndpoints()
+	urls := make([]string, len(endpoints))
+	names := make(map[string]string, len(endpoints))
+	for i, e := range endpoints {
+		urls[i] = e.WebSocketURL
+		if _, ok := names[e.WebSocketURL]; !ok {
+			names[e.WebSocketURL] = e.Name
+		}
+	}
 	ws.transport = transport.NewWSTransport(transport.WSTransportConfig{
-		URL:          cfg.Network.WebSocketURL(),
+		URL:          urls[0],
+		FailoverURLs: urls[1:],
+		Names:        names,
 		DialTimeout:  cfg.WSDialTimeout,
 		PingInterval: cfg.WSPingInterval,
 		Handler:      ws,
+		TLSConfig:    cfg.WSTLSConfig,
+		Proxy:        cfg.WSProxy,
+		Header:       cfg.WSHeaders,
 	})
 	if cfg.WSReconnect {
 		ws.reconnector = transport.NewReconnector(ws.transport, transport.ReconnectConfig{
@@ -61,9 +80,10 @@ func NewClient(opts ...config.ClientOption) *Client {
 	return ws
 }
 
-// Connect establishes the WebSocket connection.
+// Connect establishes the WebSocket connection, trying the failover
+// endpoints in order if the primary cannot be reached.
 func (ws *Client) Connect(ctx context.Context) error {
-	if err := ws.transport.Connect(ctx); err != nil {
+	if err := ws.transport.ConnectAny(ctx); err != nil {
 		return err
 	}
 	if ws.reconnector != nil {
@@ -91,13 +111,49 @@ func (ws *Client) OnErrorHandler(fn func(error)) {
 	ws.onError = fn
 }
 
+// OnReconnectHandler registers a callback that runs after an automatic
+// reconnect has logged in again and resubscribed every channel. Unlike the
+// other handlers, callbacks accumulate, so independent components can each
+// resync their state.
+func (ws *Client) OnReconnectHandler(fn func()) {
+	ws.subMu.Lock()
+	ws.onReconnected = append(ws.onReconnected, fn)
+	ws.subMu.Unlock()
+}
+
 // IsConnected returns true if the WebSocket is currently connected.
 func (ws *Client) IsConnected() bool {
 	return ws.transport.IsConnected()
 }
 
-// call sends a JSON-RPC request and waits for the response.
+// preTradeCheck runs the configured pre-trade check, if any, on params.
+func (ws *Client) preTradeCheck(ctx context.Context, params any) error {
+	return pretrade.Check(ctx, ws.cfg.PreTradeCheck, params)
+}
+
+// call sends a JSON-RPC request and waits for the response, reporting both
+// to the hooks.
 func (ws *Client) call(ctx context.Context, method string, params any, result any) error {
+	hooks := ws.currentHooks()
+	if len(hooks) == 0 {
+		return ws.roundTrip(ctx, method, params, result)
+	}
+	for _, h := range hooks {
+		h.OnRequest(method, params)
+	}
+	err := ws.roundTrip(ctx, method, params, result)
+	res := result
+	if err != nil {
+		res = nil
+	}
+	for _, h := range hooks {
+		h.OnResult(method, params, res, err)
+	}
+	return err
+}
+
+// roundTrip sends a JSON-RPC request and waits for the response.
+func (ws *Client) roundTrip(ctx context.Context, method string, params any, result any) error {
 	pc := &pendingCall{result: make(chan *jsonrpc.Response, 1)}
 
 	id, err := ws.transport.Send(ctx, method, params)
@@ -117,10 +173,10 @@ func (ws *Client) call(ctx context.Context, method string, params any, result an
 
 	select {
 	case <-ctx.Done():
-		return ctx.Err()
+		return transport.ContextError(ctx.Err(), "waiting for response to "+method)
 	case resp, ok := <-pc.result:
 		if !ok {
-			return &apierr.ConnectionError{Message: "connection closed while waiting for response"}
+			return &apierr.ConnectionError{Message: "connection closed while waiting for response", Sent: true}
 		}
 		if resp.Error != nil {
 			return &apierr.APIError{Code: resp.Error.Code, Message: resp.Error.Message}
@@ -158,7 +214,7 @@ func (ws *Client) OnNotification(notif *jsonrpc.Notification) {
 	handler, ok := ws.handlers[notif.Method]
 	ws.subMu.RUnlock()
 	if ok {
-		ws.dispatchNotification(handler, notif.Params)
+		ws.dispatchNotification(notif.Method, handler, notif.Params)
 	}
 }
 
@@ -189,6 +245,7 @@ func (ws *Client) onReconnect() error {
 	}
 
 	ws.subMu.RLock()
+	callbacks := ws.onReconnected
 	var pub, priv []string
 	for ch := range ws.handlers {
 		if isPrivateChannel(ch) {
@@ -205,6 +262,9 @@ func (ws *Client) onReconnect() error {
 	if len(priv) > 0 {
 		_ = ws.callNoResult(ctx, "private/subscribe", map[string]any{"channels": priv})
 	}
+	for _, fn := range callbacks {
+		fn()
+	}
 	return nil
 }
 
@@ -213,105 +273,49 @@ func isPrivateChannel(ch string) bool {
 		strings.HasPrefix(ch, "user.") || strings.HasPrefix(ch, "mm.")
 }
 
-func (ws *Client) dispatchNotification(handler any, data json.RawMessage) {
+func (ws *Client) dispatchNotification(channel string, handler any, data json.RawMessage) {
 	go func() {
 		switch fn := handler.(type) {
 		case func(types.BookUpdate):
-			var v types.BookUpdate
-			if json.Unmarshal(data, &v) == nil {
-				fn(v)
-			}
+			deliver(ws, channel, data, fn)
 		case func(types.Ticker):
-			var v types.Ticker
-			if json.Unmarshal(data, &v) == nil {
-				fn(v)
-			}
+			deliver(ws, channel, data, fn)
 		case func(types.LightweightTicker):
-			var v types.LightweightTicker
-			if json.Unmarshal(data, &v) == nil {
-				fn(v)
-			}
+			deliver(ws, channel, data, fn)
 		case func([]types.RecentTrade):
-			var v []types.RecentTrade
-			if json.Unmarshal(data, &v) == nil {
-				fn(v)
-			}
+			deliver(ws, channel, data, fn)
 		case func(types.IndexPrice):
-			var v types.IndexPrice
-			if json.Unmarshal(data, &v) == nil {
-				fn(v)
-			}
+			deliver(ws, channel, data, fn)
 		case func([]types.Instrument):
-			var v []types.Instrument
-			if json.Unmarshal(data, &v) == nil {
-				fn(v)
-			}
+			deliver(ws, channel, data, fn)
 		case func([]types.OrderStatus):
-			var v []types.OrderStatus
-			if json.Unmarshal(data, &v) == nil {
-				fn(v)
-			}
+			deliver(ws, channel, data, fn)
 		case func([]types.PortfolioEntry):
-			var v []types.PortfolioEntry
-			if json.Unmarshal(data, &v) == nil {
-				fn(v)
-			}
+			deliver(ws, channel, data, fn)
 		case func(types.AccountSummary):
-			var v types.AccountSummary
-			if json.Unmarshal(data, &v) == nil {
-				fn(v)
-			}
+			deliver(ws, channel, data, fn)
 		case func([]types.Trade):
-			var v []types.Trade
-			if json.Unmarshal(data, &v) == nil {
-				fn(v)
-			}
+			deliver(ws, channel, data, fn)
 		case func([]types.OrderHistory):
-			var v []types.OrderHistory
-			if json.Unmarshal(data, &v) == nil {
-				fn(v)
-			}
+			deliver(ws, channel, data, fn)
 		case func([]types.ConditionalOrder):
-			var v []types.ConditionalOrder
-			if json.Unmarshal(data, &v) == nil {
-				fn(v)
-			}
+			deliver(ws, channel, data, fn)
 		case func([]types.Bot):
-			var v []types.Bot
-			if json.Unmarshal(data, &v) == nil {
-				fn(v)
-			}
+			deliver(ws, channel, data, fn)
 		case func([]types.Rfq):
-			var v []types.Rfq
-			if json.Unmarshal(data, &v) == nil {
-				fn(v)
-			}
+			deliver(ws, channel, data, fn)
 		case func([]types.RfqOrder):
-			var v []types.RfqOrder
-			if json.Unmarshal(data, &v) == nil {
-				fn(v)
-			}
+			deliver(ws, channel, data, fn)
 		case func(types.MMProtectionUpdate):
-			var v types.MMProtectionUpdate
-			if json.Unmarshal(data, &v) == nil {
-				fn(v)
-			}
+			deliver(ws, channel, data, fn)
 		case func(types.Notification):
-			var v types.Notification
-			if json.Unmarshal(data, &v) == nil {
-				fn(v)
-			}
+			deliver(ws, channel, data, fn)
 		case func(types.SystemEvent):
-			var v types.SystemEvent
-			if json.Unmarshal(data, &v) == nil {
-				fn(v)
-			}
+			deliver(ws, channel, data, fn)
 		case func([]types.Banner):
-			var v []types.Banner
-			if json.Unmarshal(data, &v) == nil {
-				fn(v)
-			}
+			deliver(ws, channel, data, fn)
 		case func(json.RawMessage):
+			ws.reportDecode(channel, nil)
 			fn(data)
 		}
 	}()
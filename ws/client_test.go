@@ -6,6 +6,7 @@ import (
 	"crypto/rsa"
 	"encoding/json"
 	"errors"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -64,6 +65,60 @@ func TestNewClient_WithReconnect(t *testing.T) {
 	}
 }
 
+func TestNewClient_WithEndpoint(t *testing.T) {
+	srv := newMockWSServer(t, echoNull)
+	c := NewClient(
+		config.WithEndpoint(config.Endpoint{Name: "local", WebSocketURL: wsURLFromHTTP(srv.URL)}),
+		config.WithWSFailoverEndpoints(config.Endpoint{WebSocketURL: "ws://backup.invalid"}),
+	)
+	if got := c.transport.URL(); got != wsURLFromHTTP(srv.URL) {
+		t.Errorf("transport URL = %q, want mock server URL", got)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := c.Connect(ctx); err != nil {
+		t.Fatalf("Connect error: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	if err := c.callNoResult(ctx, "public/ping", nil); err != nil {
+		t.Fatalf("call error: %v", err)
+	}
+	if got := c.transport.NextURL(); got != "ws://backup.invalid" {
+		t.Errorf("NextURL() = %q, want failover URL", got)
+	}
+}
+
+func TestClient_ConnectFailsOver(t *testing.T) {
+	srv := newMockWSServer(t, echoNull)
+	c := NewClient(
+		config.WithEndpoint(config.Endpoint{Name: "down", WebSocketURL: "ws://127.0.0.1:1"}),
+		config.WithWSFailoverEndpoints(config.Endpoint{Name: "local", WebSocketURL: wsURLFromHTTP(srv.URL)}),
+		config.WithWSDialTimeout(time.Second),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := c.Connect(ctx); err != nil {
+		t.Fatalf("Connect error: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	if err := c.callNoResult(ctx, "public/ping", nil); err != nil {
+		t.Fatalf("call error: %v", err)
+	}
+}
+
+func TestClient_ConnectErrorNamesEndpoint(t *testing.T) {
+	c := NewClient(
+		config.WithEndpoint(config.Endpoint{Name: "colo", WebSocketURL: "ws://127.0.0.1:1"}),
+		config.WithWSDialTimeout(time.Second),
+	)
+	err := c.Connect(context.Background())
+	if err == nil || !strings.Contains(err.Error(), `"colo"`) {
+		t.Errorf("Connect error = %v, want the endpoint name", err)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // isPrivateChannel
 // ---------------------------------------------------------------------------
@@ -329,7 +384,7 @@ func TestDispatchNotification_BookUpdate(t *testing.T) {
 		mu.Unlock()
 	}
 	data := json.RawMessage(`{"bids":[],"asks":[],"time":1.0}`)
-	c.dispatchNotification(handler, data)
+	c.dispatchNotification("test", handler, data)
 	if !waitForBool(&mu, &called, 2*time.Second) {
 		t.Fatal("BookUpdate handler not called")
 	}
@@ -345,7 +400,7 @@ func TestDispatchNotification_Ticker(t *testing.T) {
 		mu.Unlock()
 	}
 	data := json.RawMessage(`{"mark_price":50000.0}`)
-	c.dispatchNotification(handler, data)
+	c.dispatchNotification("test", handler, data)
 	if !waitForBool(&mu, &called, 2*time.Second) {
 		t.Fatal("Ticker handler not called")
 	}
@@ -361,7 +416,7 @@ func TestDispatchNotification_LightweightTicker(t *testing.T) {
 		mu.Unlock()
 	}
 	data := json.RawMessage(`{"mark_price":50000.0}`)
-	c.dispatchNotification(handler, data)
+	c.dispatchNotification("test", handler, data)
 	if !waitForBool(&mu, &called, 2*time.Second) {
 		t.Fatal("LightweightTicker handler not called")
 	}
@@ -377,7 +432,7 @@ func TestDispatchNotification_RecentTrades(t *testing.T) {
 		mu.Unlock()
 	}
 	data := json.RawMessage(`[]`)
-	c.dispatchNotification(handler, data)
+	c.dispatchNotification("test", handler, data)
 	if !waitForBool(&mu, &called, 2*time.Second) {
 		t.Fatal("RecentTrades handler not called")
 	}
@@ -393,7 +448,7 @@ func TestDispatchNotification_IndexPrice(t *testing.T) {
 		mu.Unlock()
 	}
 	data := json.RawMessage(`{"index_name":"BTCUSD","price":50000.0,"timestamp":1.0}`)
-	c.dispatchNotification(handler, data)
+	c.dispatchNotification("test", handler, data)
 	if !waitForBool(&mu, &called, 2*time.Second) {
 		t.Fatal("IndexPrice handler not called")
 	}
@@ -409,7 +464,7 @@ func TestDispatchNotification_Instruments(t *testing.T) {
 		mu.Unlock()
 	}
 	data := json.RawMessage(`[]`)
-	c.dispatchNotification(handler, data)
+	c.dispatchNotification("test", handler, data)
 	if !waitForBool(&mu, &called, 2*time.Second) {
 		t.Fatal("Instruments handler not called")
 	}
@@ -425,7 +480,7 @@ func TestDispatchNotification_OrderStatuses(t *testing.T) {
 		mu.Unlock()
 	}
 	data := json.RawMessage(`[]`)
-	c.dispatchNotification(handler, data)
+	c.dispatchNotification("test", handler, data)
 	if !waitForBool(&mu, &called, 2*time.Second) {
 		t.Fatal("OrderStatuses handler not called")
 	}
@@ -441,7 +496,7 @@ func TestDispatchNotification_PortfolioEntries(t *testing.T) {
 		mu.Unlock()
 	}
 	data := json.RawMessage(`[]`)
-	c.dispatchNotification(handler, data)
+	c.dispatchNotification("test", handler, data)
 	if !waitForBool(&mu, &called, 2*time.Second) {
 		t.Fatal("PortfolioEntries handler not called")
 	}
@@ -457,7 +512,7 @@ func TestDispatchNotification_AccountSummary(t *testing.T) {
 		mu.Unlock()
 	}
 	data := json.RawMessage(`{"cash":[],"margin":1.0}`)
-	c.dispatchNotification(handler, data)
+	c.dispatchNotification("test", handler, data)
 	if !waitForBool(&mu, &called, 2*time.Second) {
 		t.Fatal("AccountSummary handler not called")
 	}
@@ -473,7 +528,7 @@ func TestDispatchNotification_Trades(t *testing.T) {
 		mu.Unlock()
 	}
 	data := json.RawMessage(`[]`)
-	c.dispatchNotification(handler, data)
+	c.dispatchNotification("test", handler, data)
 	if !waitForBool(&mu, &called, 2*time.Second) {
 		t.Fatal("Trades handler not called")
 	}
@@ -489,7 +544,7 @@ func TestDispatchNotification_OrderHistory(t *testing.T) {
 		mu.Unlock()
 	}
 	data := json.RawMessage(`[]`)
-	c.dispatchNotification(handler, data)
+	c.dispatchNotification("test", handler, data)
 	if !waitForBool(&mu, &called, 2*time.Second) {
 		t.Fatal("OrderHistory handler not called")
 	}
@@ -505,7 +560,7 @@ func TestDispatchNotification_ConditionalOrders(t *testing.T) {
 		mu.Unlock()
 	}
 	data := json.RawMessage(`[]`)
-	c.dispatchNotification(handler, data)
+	c.dispatchNotification("test", handler, data)
 	if !waitForBool(&mu, &called, 2*time.Second) {
 		t.Fatal("ConditionalOrders handler not called")
 	}
@@ -521,7 +576,7 @@ func TestDispatchNotification_Bots(t *testing.T) {
 		mu.Unlock()
 	}
 	data := json.RawMessage(`[]`)
-	c.dispatchNotification(handler, data)
+	c.dispatchNotification("test", handler, data)
 	if !waitForBool(&mu, &called, 2*time.Second) {
 		t.Fatal("Bots handler not called")
 	}
@@ -537,7 +592,7 @@ func TestDispatchNotification_Rfqs(t *testing.T) {
 		mu.Unlock()
 	}
 	data := json.RawMessage(`[]`)
-	c.dispatchNotification(handler, data)
+	c.dispatchNotification("test", handler, data)
 	if !waitForBool(&mu, &called, 2*time.Second) {
 		t.Fatal("Rfqs handler not called")
 	}
@@ -553,7 +608,7 @@ func TestDispatchNotification_RfqOrders(t *testing.T) {
 		mu.Unlock()
 	}
 	data := json.RawMessage(`[]`)
-	c.dispatchNotification(handler, data)
+	c.dispatchNotification("test", handler, data)
 	if !waitForBool(&mu, &called, 2*time.Second) {
 		t.Fatal("RfqOrders handler not called")
 	}
@@ -569,7 +624,7 @@ func TestDispatchNotification_MMProtectionUpdate(t *testing.T) {
 		mu.Unlock()
 	}
 	data := json.RawMessage(`{"product":"options","reason":"delta","time":1.0}`)
-	c.dispatchNotification(handler, data)
+	c.dispatchNotification("test", handler, data)
 	if !waitForBool(&mu, &called, 2*time.Second) {
 		t.Fatal("MMProtectionUpdate handler not called")
 	}
@@ -585,7 +640,7 @@ func TestDispatchNotification_Notification(t *testing.T) {
 		mu.Unlock()
 	}
 	data := json.RawMessage(`{"id":"n1","time":1.0,"category":"trade","title":"t","message":"m","display_type":"popup","read":false,"popup":false}`)
-	c.dispatchNotification(handler, data)
+	c.dispatchNotification("test", handler, data)
 	if !waitForBool(&mu, &called, 2*time.Second) {
 		t.Fatal("Notification handler not called")
 	}
@@ -601,7 +656,7 @@ func TestDispatchNotification_SystemEvent(t *testing.T) {
 		mu.Unlock()
 	}
 	data := json.RawMessage(`{"event":"maintenance"}`)
-	c.dispatchNotification(handler, data)
+	c.dispatchNotification("test", handler, data)
 	if !waitForBool(&mu, &called, 2*time.Second) {
 		t.Fatal("SystemEvent handler not called")
 	}
@@ -617,7 +672,7 @@ func TestDispatchNotification_Banners(t *testing.T) {
 		mu.Unlock()
 	}
 	data := json.RawMessage(`[]`)
-	c.dispatchNotification(handler, data)
+	c.dispatchNotification("test", handler, data)
 	if !waitForBool(&mu, &called, 2*time.Second) {
 		t.Fatal("Banners handler not called")
 	}
@@ -633,7 +688,7 @@ func TestDispatchNotification_RawJSON(t *testing.T) {
 		mu.Unlock()
 	}
 	data := json.RawMessage(`{"arbitrary":"data"}`)
-	c.dispatchNotification(handler, data)
+	c.dispatchNotification("test", handler, data)
 	if !waitForBool(&mu, &called, 2*time.Second) {
 		t.Fatal("RawJSON handler not called")
 	}
@@ -645,7 +700,7 @@ func TestDispatchNotification_UnknownType_Ignored(t *testing.T) {
 	handler := func(v string) {}
 	data := json.RawMessage(`"hello"`)
 	// Should not panic.
-	c.dispatchNotification(handler, data)
+	c.dispatchNotification("test", handler, data)
 	// Give the goroutine a moment.
 	time.Sleep(50 * time.Millisecond)
 }
@@ -660,7 +715,7 @@ func TestDispatchNotification_InvalidJSON_HandlerNotCalled(t *testing.T) {
 		mu.Unlock()
 	}
 	data := json.RawMessage(`{invalid json}`)
-	c.dispatchNotification(handler, data)
+	c.dispatchNotification("test", handler, data)
 	// Wait briefly; handler should NOT be called because unmarshal fails.
 	time.Sleep(100 * time.Millisecond)
 	mu.Lock()
@@ -792,6 +847,10 @@ func TestCall_ContextCancelled(t *testing.T) {
 	if !errors.Is(err, context.DeadlineExceeded) {
 		t.Errorf("expected DeadlineExceeded, got %v", err)
 	}
+	var timeoutErr *apierr.TimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Errorf("expected *apierr.TimeoutError, got %T", err)
+	}
 }
 
 // ---------------------------------------------------------------------------
@@ -1085,6 +1144,20 @@ func TestOnReconnect_NoHandlers(t *testing.T) {
 	}
 }
 
+func TestOnReconnect_CallsReconnectHandlers(t *testing.T) {
+	c := newConnectedClient(t, echoNull)
+	var calls []int
+	c.OnReconnectHandler(func() { calls = append(calls, 1) })
+	c.OnReconnectHandler(func() { calls = append(calls, 2) })
+
+	if err := c.onReconnect(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(calls) != 2 || calls[0] != 1 || calls[1] != 2 {
+		t.Errorf("reconnect handlers called %v, want [1 2]", calls)
+	}
+}
+
 func TestOnReconnect_PublicChannelsOnly(t *testing.T) {
 	var mu sync.Mutex
 	var subscribedPublic bool
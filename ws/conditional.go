@@ -16,6 +16,9 @@ func (ws *Client) ConditionalOrders(ctx context.Context) ([]types.ConditionalOrd
 // CreateConditionalOrder creates a new conditional order via WebSocket.
 func (ws *Client) CreateConditionalOrder(ctx context.Context, params *types.CreateConditionalOrderParams) (types.ConditionalOrder, error) {
 	var result types.ConditionalOrder
+	if err := ws.preTradeCheck(ctx, params); err != nil {
+		return result, err
+	}
 	err := ws.call(ctx, "private/create_conditional_order", params, &result)
 	return result, err
 }
@@ -0,0 +1,198 @@
+package ws
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// DecodeError describes a subscription notification that could not be
+// decoded into the handler's type, or that failed strict checks.
+type DecodeError struct {
+	// Channel is the subscription channel the notification arrived on.
+	Channel string
+	// Payload is the raw notification payload.
+	Payload json.RawMessage
+	// Err describes the failure.
+	Err error
+	// Strict is true when the payload decoded but failed strict checks.
+	// Such notifications are still delivered to the handler.
+	Strict bool
+}
+
+// Error implements the error interface.
+func (e *DecodeError) Error() string {
+	if e.Strict {
+		return fmt.Sprintf("thalex: strict decode of %s notification: %v", e.Channel, e.Err)
+	}
+	return fmt.Sprintf("thalex: decoding %s notification: %v", e.Channel, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeStats counts the outcome of notification decoding.
+type DecodeStats struct {
+	// Decoded is the number of notifications delivered to a handler.
+	Decoded uint64
+	// Failed is the number of notifications dropped because they could not
+	// be decoded.
+	Failed uint64
+	// StrictViolations is the number of delivered notifications that failed
+	// strict checks. It stays zero unless strict decoding is enabled.
+	StrictViolations uint64
+	// FailedByChannel breaks Failed and StrictViolations down by channel.
+	FailedByChannel map[string]uint64
+}
+
+// decodeCounters is the mutable state behind DecodeStats.
+type decodeCounters struct {
+	mu    sync.Mutex
+	stats DecodeStats
+}
+
+func (c *decodeCounters) record(channel string, err *DecodeError) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch {
+	case err == nil:
+		c.stats.Decoded++
+		return
+	case err.Strict:
+		c.stats.Decoded++
+		c.stats.StrictViolations++
+	default:
+		c.stats.Failed++
+	}
+	if c.stats.FailedByChannel == nil {
+		c.stats.FailedByChannel = make(map[string]uint64)
+	}
+	c.stats.FailedByChannel[channel]++
+}
+
+func (c *decodeCounters) snapshot() DecodeStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := c.stats
+	out.FailedByChannel = make(map[string]uint64, len(c.stats.FailedByChannel))
+	for ch, n := range c.stats.FailedByChannel {
+		out.FailedByChannel[ch] = n
+	}
+	return out
+}
+
+// OnDecodeErrorHandler registers a callback for notifications that fail to
+// decode or, with strict decoding enabled, fail strict checks. Without a
+// decode error handler these errors go to the OnErrorHandler callback.
+func (ws *Client) OnDecodeErrorHandler(fn func(*DecodeError)) {
+	ws.onDecodeError = fn
+}
+
+// DecodeStats returns a snapshot of the notification decode counters.
+func (ws *Client) DecodeStats() DecodeStats {
+	return ws.decodeCounters.snapshot()
+}
+
+func (ws *Client) reportDecode(channel string, err *DecodeError) {
+	ws.decodeCounters.record(channel, err)
+	if err == nil {
+		return
+	}
+	if ws.onDecodeError != nil {
+		ws.onDecodeError(err)
+	} else if ws.onError != nil {
+		ws.onError(err)
+	}
+}
+
+// deliver decodes a notification payload into T and passes it to fn,
+// reporting decode failures and, in strict mode, API drift.
+func deliver[T any](ws *Client, channel string, data json.RawMessage, fn func(T)) {
+	var v T
+	if err := decodeNotification(data, &v); err != nil {
+		ws.reportDecode(channel, &DecodeError{Channel: channel, Payload: data, Err: err})
+		return
+	}
+	var strictErr *DecodeError
+	if ws.cfg.WSStrictDecoding {
+		if err := checkStrict[T](data, v); err != nil {
+			strictErr = &DecodeError{Channel: channel, Payload: data, Err: err, Strict: true}
+		}
+	}
+	ws.reportDecode(channel, strictErr)
+	for _, h := range ws.currentHooks() {
+		h.OnNotification(channel, v)
+	}
+	fn(v)
+}
+
+// checkStrict rejects payloads with fields unknown to T and enum values
+// whose IsValid method reports false.
+func checkStrict[T any](data json.RawMessage, v T) error {
+	var probe T
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&probe); err != nil {
+		return err
+	}
+	return checkEnums(reflect.ValueOf(v), "")
+}
+
+type validator interface {
+	IsValid() bool
+}
+
+var validatorType = reflect.TypeOf((*validator)(nil)).Elem()
+
+// checkEnums walks v and reports the first non-empty value implementing
+// IsValid that is not valid. Zero values are treated as absent.
+func checkEnums(v reflect.Value, path string) error {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return checkEnums(v.Elem(), path)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := checkEnums(v.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if v.Type().Implements(validatorType) && !v.IsZero() {
+		if !v.Interface().(validator).IsValid() {
+			return fmt.Errorf("%s: invalid %s value %q", strings.TrimPrefix(path, "."), v.Type().Name(), fmt.Sprint(v.Interface()))
+		}
+		return nil
+	}
+
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if err := checkEnums(v.Field(i), path+"."+name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package ws
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amiwrpremium/go-thalex/config"
+	"github.com/amiwrpremium/go-thalex/types"
+)
+
+// waitDecodeError waits for a decode error on ch or fails the test.
+func waitDecodeError(t *testing.T, ch <-chan *DecodeError) *DecodeError {
+	t.Helper()
+	select {
+	case err := <-ch:
+		return err
+	case <-time.After(2 * time.Second):
+		t.Fatal("decode error handler not called")
+		return nil
+	}
+}
+
+func TestDispatchNotification_DecodeFailure(t *testing.T) {
+	c := NewClient()
+	errs := make(chan *DecodeError, 1)
+	c.OnDecodeErrorHandler(func(err *DecodeError) { errs <- err })
+
+	called := make(chan struct{}, 1)
+	data := json.RawMessage(`{"mark_price":"not a number"}`)
+	c.dispatchNotification("ticker.BTC-PERPETUAL.raw", func(types.Ticker) { called <- struct{}{} }, data)
+
+	err := waitDecodeError(t, errs)
+	if err.Channel != "ticker.BTC-PERPETUAL.raw" || err.Strict {
+		t.Errorf("unexpected decode error: %+v", err)
+	}
+	if string(err.Payload) != string(data) {
+		t.Errorf("Payload = %s, want %s", err.Payload, data)
+	}
+	select {
+	case <-called:
+		t.Error("handler should not be called for an undecodable payload")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	stats := c.DecodeStats()
+	if stats.Failed != 1 || stats.Decoded != 0 || stats.FailedByChannel["ticker.BTC-PERPETUAL.raw"] != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestDispatchNotification_DecodeFailureFallsBackToOnError(t *testing.T) {
+	c := NewClient()
+	errs := make(chan error, 1)
+	c.OnErrorHandler(func(err error) { errs <- err })
+
+	c.dispatchNotification("test", func(types.Ticker) {}, json.RawMessage(`[]`))
+
+	select {
+	case err := <-errs:
+		if !strings.Contains(err.Error(), "decoding test notification") {
+			t.Errorf("unexpected error: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("OnErrorHandler not called")
+	}
+}
+
+func TestDispatchNotification_Strict(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		wantErr string
+	}{
+		{"UnknownField", `[{"order_id":"o1","status":"open","brand_new_field":1}]`, "brand_new_field"},
+		{"InvalidEnum", `[{"order_id":"o1","status":"half_filled"}]`, "[0].status"},
+		{"InvalidDeleteReason", `[{"order_id":"o1","status":"cancelled","delete_reason":"gremlins"}]`, "delete_reason"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewClient(config.WithWSStrictDecoding(true))
+			errs := make(chan *DecodeError, 1)
+			c.OnDecodeErrorHandler(func(err *DecodeError) { errs <- err })
+			delivered := make(chan []types.OrderStatus, 1)
+
+			c.dispatchNotification("session.orders", func(v []types.OrderStatus) { delivered <- v }, json.RawMessage(tt.payload))
+
+			err := waitDecodeError(t, errs)
+			if !err.Strict || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v (strict=%v), want strict error mentioning %q", err, err.Strict, tt.wantErr)
+			}
+			select {
+			case v := <-delivered:
+				if len(v) != 1 || v[0].OrderID != "o1" {
+					t.Errorf("delivered %+v", v)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("strict violations should still be delivered")
+			}
+			if stats := c.DecodeStats(); stats.StrictViolations != 1 || stats.Decoded != 1 {
+				t.Errorf("unexpected stats: %+v", stats)
+			}
+		})
+	}
+}
+
+func TestDispatchNotification_StrictAcceptsValidPayload(t *testing.T) {
+	c := NewClient(config.WithWSStrictDecoding(true))
+	errs := make(chan *DecodeError, 1)
+	c.OnDecodeErrorHandler(func(err *DecodeError) { errs <- err })
+	delivered := make(chan struct{}, 1)
+
+	c.dispatchNotification("session.orders", func([]types.OrderStatus) { delivered <- struct{}{} },
+		json.RawMessage(`[{"order_id":"o1","status":"filled","delete_reason":"filled"}]`))
+
+	select {
+	case <-delivered:
+	case err := <-errs:
+		t.Fatalf("unexpected decode error: %v", err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("handler not called")
+	}
+	select {
+	case err := <-errs:
+		t.Fatalf("unexpected decode error: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
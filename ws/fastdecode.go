@@ -0,0 +1,384 @@
+package ws
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/amiwrpremium/go-thalex/enums"
+	"github.com/amiwrpremium/go-thalex/internal/fastjson"
+	"github.com/amiwrpremium/go-thalex/types"
+)
+
+// decodeNotification decodes a notification payload into v. The high-rate
+// market data types go through hand-written decoders first; whatever they
+// decline is decoded by encoding/json, so the result is always the same.
+func decodeNotification[T any](data []byte, v *T) error {
+	if decodeFast(data, v) {
+		return nil
+	}
+	var zero T
+	*v = zero
+	return json.Unmarshal(data, v)
+}
+
+// decodeFast reports whether v was decoded by a fast path decoder.
+func decodeFast(data []byte, v any) bool {
+	var err error
+	switch v := v.(type) {
+	case *types.BookUpdate:
+		err = decodeBookUpdate(data, v)
+	case *types.LightweightTicker:
+		err = decodeLightweightTicker(data, v)
+	case *types.Ticker:
+		err = decodeTicker(data, v)
+	case *[]types.RecentTrade:
+		err = decodeRecentTrades(data, v)
+	default:
+		return false
+	}
+	return err == nil
+}
+
+// fieldSet records the fields read from an object. Repeated keys fall back
+// to encoding/json, which merges them in ways not worth copying.
+type fieldSet uint32
+
+func (s *fieldSet) mark(r *fastjson.Reader, field uint) {
+	if *s&(1<<field) != 0 {
+		r.Fail()
+	}
+	*s |= 1 << field
+}
+
+// Scratch slices for decoding arrays of unknown length. Results are copied
+// into exactly sized slices, so a decode allocates once per array.
+var (
+	levelPool = sync.Pool{New: func() any { return new([]types.BookLevel) }}
+	tradePool = sync.Pool{New: func() any { return new([]types.RecentTrade) }}
+)
+
+// Enum values decoded without allocating.
+var (
+	directions = []string{string(enums.DirectionBuy), string(enums.DirectionSell)}
+	tradeTypes = []string{string(enums.TradeTypeNormal), string(enums.TradeTypeBlock), string(enums.TradeTypeCombo)}
+)
+
+// maxPooledItems is the largest scratch slice returned to a pool.
+const maxPooledItems = 4096
+
+var bookUpdateKeys = []string{"bids", "asks", "last", "time", "trades"}
+
+func decodeBookUpdate(data []byte, u *types.BookUpdate) error {
+	var r fastjson.Reader
+	r.Reset(data)
+	if r.Null() {
+		return r.End()
+	}
+	if !r.BeginObject() {
+		return r.Err()
+	}
+	var seen fieldSet
+	for i := 0; r.NextField(i); i++ {
+		switch key := r.Key(); string(key) {
+		case "bids":
+			seen.mark(&r, 0)
+			readLevels(&r, &u.Bids)
+		case "asks":
+			seen.mark(&r, 1)
+			readLevels(&r, &u.Asks)
+		case "last":
+			seen.mark(&r, 2)
+			r.FloatPtr(&u.Last)
+		case "time":
+			seen.mark(&r, 3)
+			r.Float((*float64)(&u.Time))
+		case "trades":
+			seen.mark(&r, 4)
+			readBookTrades(&r, &u.Trades)
+		default:
+			r.UnknownKey(key, bookUpdateKeys)
+		}
+	}
+	return r.End()
+}
+
+// readLevels reads an array of [price, amount, outright amount] levels.
+func readLevels(r *fastjson.Reader, dst *[]types.BookLevel) {
+	if r.Null() {
+		*dst = nil
+		return
+	}
+	if !r.BeginArray() {
+		return
+	}
+	scratch := levelPool.Get().(*[]types.BookLevel)
+	levels := (*scratch)[:0]
+	for i := 0; r.NextElement(i); i++ {
+		var l types.BookLevel
+		if !r.Null() && r.BeginArray() {
+			for j := 0; r.NextElement(j); j++ {
+				if j < len(l) {
+					r.Float(&l[j])
+				} else {
+					r.Skip()
+				}
+			}
+		}
+		levels = append(levels, l)
+	}
+	*dst = append(make([]types.BookLevel, 0, len(levels)), levels...)
+	if cap(levels) <= maxPooledItems {
+		*scratch = levels[:0]
+		levelPool.Put(scratch)
+	}
+}
+
+var bookTradeKeys = []string{"d", "p", "a", "t"}
+
+func readBookTrades(r *fastjson.Reader, dst *[]types.BookTrade) {
+	if r.Null() {
+		*dst = nil
+		return
+	}
+	if !r.BeginArray() {
+		return
+	}
+	// Book updates carry few trades; append directly.
+	trades := make([]types.BookTrade, 0, 4)
+	for i := 0; r.NextElement(i); i++ {
+		var t types.BookTrade
+		if !r.Null() && r.BeginObject() {
+			var seen fieldSet
+			for j := 0; r.NextField(j); j++ {
+				switch key := r.Key(); string(key) {
+				case "d":
+					seen.mark(r, 0)
+					r.Enum((*string)(&t.Direction), directions)
+				case "p":
+					seen.mark(r, 1)
+					r.Float(&t.Price)
+				case "a":
+					seen.mark(r, 2)
+					r.Float(&t.Amount)
+				case "t":
+					seen.mark(r, 3)
+					r.Float((*float64)(&t.Time))
+				default:
+					r.UnknownKey(key, bookTradeKeys)
+				}
+			}
+		}
+		trades = append(trades, t)
+	}
+	*dst = trades
+}
+
+var lightweightTickerKeys = []string{
+	"best_bid_price", "best_ask_price", "mark_price", "iv", "last_price",
+	"best_bid_amount", "best_ask_amount",
+}
+
+func decodeLightweightTicker(data []byte, t *types.LightweightTicker) error {
+	// The optional prices share one allocation.
+	vals := new([6]float64)
+	var r fastjson.Reader
+	r.Reset(data)
+	if r.Null() {
+		return r.End()
+	}
+	if !r.BeginObject() {
+		return r.Err()
+	}
+	var seen fieldSet
+	for i := 0; r.NextField(i); i++ {
+		switch key := r.Key(); string(key) {
+		case "best_bid_price":
+			seen.mark(&r, 0)
+			r.FloatPtrIn(&t.BestBidPrice, &vals[0])
+		case "best_ask_price":
+			seen.mark(&r, 1)
+			r.FloatPtrIn(&t.BestAskPrice, &vals[1])
+		case "mark_price":
+			seen.mark(&r, 2)
+			r.Float(&t.MarkPrice)
+		case "iv":
+			seen.mark(&r, 3)
+			r.FloatPtrIn(&t.IV, &vals[2])
+		case "last_price":
+			seen.mark(&r, 4)
+			r.FloatPtrIn(&t.LastPrice, &vals[3])
+		case "best_bid_amount":
+			seen.mark(&r, 5)
+			r.FloatPtrIn(&t.BestBidAmount, &vals[4])
+		case "best_ask_amount":
+			seen.mark(&r, 6)
+			r.FloatPtrIn(&t.BestAskAmount, &vals[5])
+		default:
+			r.UnknownKey(key, lightweightTickerKeys)
+		}
+	}
+	return r.End()
+}
+
+// tickerOptionalFields is the number of *float64 fields in types.Ticker.
+const tickerOptionalFields = 21
+
+var tickerKeys = []string{
+	"best_bid_price", "best_bid_amount", "best_ask_price", "best_ask_amount",
+	"last_price", "mark_price", "mark_timestamp", "iv", "delta", "index",
+	"forward", "volume_24h", "value_24h", "low_price_24h", "high_price_24h",
+	"change_24h", "collar_low", "collar_high", "open_interest", "funding_rate",
+	"funding_mark", "realised_funding_24h", "average_funding_rate_24h",
+}
+
+func decodeTicker(data []byte, t *types.Ticker) error {
+	// The optional fields share one allocation; field numbers 2 and up
+	// index it.
+	vals := new([tickerOptionalFields]float64)
+	var r fastjson.Reader
+	r.Reset(data)
+	if r.Null() {
+		return r.End()
+	}
+	if !r.BeginObject() {
+		return r.Err()
+	}
+	var seen fieldSet
+	for i := 0; r.NextField(i); i++ {
+		key := r.Key()
+		if string(key) == "mark_price" {
+			seen.mark(&r, 0)
+			r.Float(&t.MarkPrice)
+			continue
+		}
+		if string(key) == "mark_timestamp" {
+			seen.mark(&r, 1)
+			r.Float((*float64)(&t.MarkTimestamp))
+			continue
+		}
+		var dst **float64
+		var field uint
+		switch string(key) {
+		case "best_bid_price":
+			dst, field = &t.BestBidPrice, 2
+		case "best_bid_amount":
+			dst, field = &t.BestBidAmount, 3
+		case "best_ask_price":
+			dst, field = &t.BestAskPrice, 4
+		case "best_ask_amount":
+			dst, field = &t.BestAskAmount, 5
+		case "last_price":
+			dst, field = &t.LastPrice, 6
+		case "iv":
+			dst, field = &t.IV, 7
+		case "delta":
+			dst, field = &t.Delta, 8
+		case "index":
+			dst, field = &t.Index, 9
+		case "forward":
+			dst, field = &t.Forward, 10
+		case "volume_24h":
+			dst, field = &t.Volume24h, 11
+		case "value_24h":
+			dst, field = &t.Value24h, 12
+		case "low_price_24h":
+			dst, field = &t.LowPrice24h, 13
+		case "high_price_24h":
+			dst, field = &t.HighPrice24h, 14
+		case "change_24h":
+			dst, field = &t.Change24h, 15
+		case "collar_low":
+			dst, field = &t.CollarLow, 16
+		case "collar_high":
+			dst, field = &t.CollarHigh, 17
+		case "open_interest":
+			dst, field = &t.OpenInterest, 18
+		case "funding_rate":
+			dst, field = &t.FundingRate, 19
+		case "funding_mark":
+			dst, field = &t.FundingMark, 20
+		case "realised_funding_24h":
+			dst, field = &t.RealisedFunding24h, 21
+		case "average_funding_rate_24h":
+			dst, field = &t.AverageFundingRate24h, 22
+		default:
+			r.UnknownKey(key, tickerKeys)
+			continue
+		}
+		seen.mark(&r, field)
+		r.FloatPtrIn(dst, &vals[field-2])
+	}
+	return r.End()
+}
+
+var recentTradeKeys = []string{
+	"trade_id", "instrument_name", "direction", "price", "amount", "time",
+	"trade_type", "index",
+}
+
+func decodeRecentTrades(data []byte, dst *[]types.RecentTrade) error {
+	var r fastjson.Reader
+	r.Reset(data)
+	if r.Null() {
+		*dst = nil
+		return r.End()
+	}
+	if !r.BeginArray() {
+		return r.Err()
+	}
+	scratch := tradePool.Get().(*[]types.RecentTrade)
+	trades := (*scratch)[:0]
+	for i := 0; r.NextElement(i); i++ {
+		var t types.RecentTrade
+		if !r.Null() {
+			readRecentTrade(&r, &t)
+		}
+		trades = append(trades, t)
+	}
+	if r.End() == nil {
+		*dst = append(make([]types.RecentTrade, 0, len(trades)), trades...)
+	}
+	if cap(trades) <= maxPooledItems {
+		clear(trades)
+		*scratch = trades[:0]
+		tradePool.Put(scratch)
+	}
+	return r.Err()
+}
+
+func readRecentTrade(r *fastjson.Reader, t *types.RecentTrade) {
+	if !r.BeginObject() {
+		return
+	}
+	var seen fieldSet
+	for i := 0; r.NextField(i); i++ {
+		switch key := r.Key(); string(key) {
+		case "trade_id":
+			seen.mark(r, 0)
+			r.String(&t.TradeID)
+		case "instrument_name":
+			seen.mark(r, 1)
+			r.String(&t.InstrumentName)
+		case "direction":
+			seen.mark(r, 2)
+			r.Enum((*string)(&t.Direction), directions)
+		case "price":
+			seen.mark(r, 3)
+			r.Float(&t.Price)
+		case "amount":
+			seen.mark(r, 4)
+			r.Float(&t.Amount)
+		case "time":
+			seen.mark(r, 5)
+			r.Float((*float64)(&t.Time))
+		case "trade_type":
+			seen.mark(r, 6)
+			r.Enum((*string)(&t.TradeType), tradeTypes)
+		case "index":
+			seen.mark(r, 7)
+			r.FloatPtr(&t.Index)
+		default:
+			r.UnknownKey(key, recentTradeKeys)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package ws
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/amiwrpremium/go-thalex/types"
+)
+
+const (
+	bookPayload  = `{"bids":[[64000.5,1.2,0],[64000,3.5,1],[63999,0.4,0]],"asks":[[64001,2.1,0],[64002.5,1,0]],"last":64000.5,"time":1718000000.123,"trades":[{"d":"buy","p":64001,"a":0.1,"t":1718000000.1}]}`
+	lwtPayload   = `{"best_bid_price":64000.5,"best_ask_price":64001,"mark_price":64000.7,"iv":null,"last_price":64000.5,"best_bid_amount":1.2,"best_ask_amount":2.1}`
+	tickPayload  = `{"best_bid_price":64000.5,"best_bid_amount":1.2,"best_ask_price":64001,"best_ask_amount":2.1,"last_price":64000.5,"mark_price":64000.7,"mark_timestamp":1718000000.05,"index":63990.1,"volume_24h":1520.5,"value_24h":97000000,"low_price_24h":63000,"high_price_24h":65000,"change_24h":0.012,"collar_low":62000,"collar_high":66000,"open_interest":8000,"funding_rate":0.0001,"funding_mark":0.00009,"realised_funding_24h":0.0002,"average_funding_rate_24h":0.0001}`
+	tradePayload = `[{"trade_id":"T1","instrument_name":"BTC-PERPETUAL","direction":"buy","price":64001,"amount":0.1,"time":1718000000.1,"trade_type":"normal"},{"trade_id":"T2","instrument_name":"BTC-27JUN25","direction":"sell","price":65000,"amount":0.2,"time":1718000000.2,"trade_type":"block","index":63990}]`
+)
+
+// decodeCase decodes a payload with the fast path and with encoding/json.
+type decodeCase struct {
+	name string
+	fast func([]byte) (any, error)
+	std  func([]byte) (any, error)
+}
+
+func caseFor[T any](name string, fast func([]byte, *T) error) decodeCase {
+	return decodeCase{
+		name: name,
+		fast: func(data []byte) (any, error) {
+			var v T
+			err := fast(data, &v)
+			return v, err
+		},
+		std: func(data []byte) (any, error) {
+			var v T
+			err := json.Unmarshal(data, &v)
+			return v, err
+		},
+	}
+}
+
+var decodeCases = []decodeCase{
+	caseFor("BookUpdate", decodeBookUpdate),
+	caseFor("LightweightTicker", decodeLightweightTicker),
+	caseFor("Ticker", decodeTicker),
+	caseFor("RecentTrades", decodeRecentTrades),
+}
+
+var decodeSeeds = []string{
+	bookPayload, lwtPayload, tickPayload, tradePayload,
+	`{"bids":[],"asks":null,"last":null,"time":null,"trades":[null,{"d":"sell","x":[1]}]}`,
+	`{"bids":[[1],[1,2,3,4],null],"extra":{"nested":["é",true]}}`,
+	`[null,{"trade_id":"a"},{}]`,
+	`[]`,
+	`null`,
+	`{"Bids":[]}`,
+	`{"bids":[],"bids":[[1,2,3]]}`,
+	`{"mark_price":"1"}`,
+	`{"trade_id":"caf\u00e9"}`,
+	`{"time":1e400}`,
+	`{"mark_price":1,}`,
+}
+
+// TestFastDecode_Taken checks that typical payloads use the fast path.
+func TestFastDecode_Taken(t *testing.T) {
+	for i, tc := range decodeCases {
+		if _, err := tc.fast([]byte(decodeSeeds[i])); err != nil {
+			t.Errorf("%s: fast path declined: %v", tc.name, err)
+		}
+	}
+}
+
+// TestFastDecode_MatchesStd checks decodeNotification, fast path and
+// fallback together, against encoding/json.
+func TestFastDecode_MatchesStd(t *testing.T) {
+	check := func(t *testing.T, data []byte, got any, gotErr error, tc decodeCase) {
+		t.Helper()
+		want, wantErr := tc.std(data)
+		if (gotErr != nil) != (wantErr != nil) {
+			t.Errorf("%s %s: err = %v, encoding/json: %v", tc.name, data, gotErr, wantErr)
+		}
+		if gotErr == nil && !reflect.DeepEqual(got, want) {
+			t.Errorf("%s %s:\n got  %+v\n want %+v", tc.name, data, got, want)
+		}
+	}
+	for _, s := range decodeSeeds {
+		data := []byte(s)
+		var book types.BookUpdate
+		check(t, data, book, decodeNotification(data, &book), decodeCases[0])
+		var lwt types.LightweightTicker
+		check(t, data, lwt, decodeNotification(data, &lwt), decodeCases[1])
+		var tick types.Ticker
+		check(t, data, tick, decodeNotification(data, &tick), decodeCases[2])
+		var trades []types.RecentTrade
+		check(t, data, trades, decodeNotification(data, &trades), decodeCases[3])
+	}
+}
+
+// FuzzFastDecode checks that whatever the fast path accepts decodes exactly
+// as with encoding/json.
+func FuzzFastDecode(f *testing.F) {
+	for _, s := range decodeSeeds {
+		f.Add([]byte(s))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, tc := range decodeCases {
+			got, err := tc.fast(data)
+			if err != nil {
+				continue
+			}
+			want, err := tc.std(data)
+			if err != nil {
+				t.Fatalf("%s: fast path accepted %q, encoding/json: %v", tc.name, data, err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("%s %q:\n fast %+v\n std  %+v", tc.name, data, got, want)
+			}
+		}
+	})
+}
+
+// deepBook is a 50-level book snapshot.
+func deepBook() string {
+	var b strings.Builder
+	b.WriteString(`{"bids":[`)
+	for i := range 50 {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, "[%g,%g,0]", 64000-0.5*float64(i), 0.1*float64(i+1))
+	}
+	b.WriteString(`],"asks":[`)
+	for i := range 50 {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, "[%g,%g,0]", 64000.5+0.5*float64(i), 0.1*float64(i+1))
+	}
+	b.WriteString(`],"time":1718000000.123}`)
+	return b.String()
+}
+
+func BenchmarkDecode(b *testing.B) {
+	payloads := []struct {
+		name string
+		data string
+		tc   decodeCase
+	}{
+		{"BookUpdate", bookPayload, decodeCases[0]},
+		{"BookSnapshot50", deepBook(), decodeCases[0]},
+		{"LightweightTicker", lwtPayload, decodeCases[1]},
+		{"Ticker", tickPayload, decodeCases[2]},
+		{"RecentTrades", tradePayload, decodeCases[3]},
+	}
+	for _, p := range payloads {
+		data := []byte(p.data)
+		for _, impl := range []struct {
+			name   string
+			decode func([]byte) (any, error)
+		}{
+			{"std", p.tc.std},
+			{"fast", p.tc.fast},
+		} {
+			b.Run(p.name+"/"+impl.name, func(b *testing.B) {
+				b.ReportAllocs()
+				b.SetBytes(int64(len(data)))
+				for b.Loop() {
+					if _, err := impl.decode(data); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
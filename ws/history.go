@@ -2,7 +2,9 @@ package ws
 
 import (
 	"context"
+	"iter"
 
+	"github.com/amiwrpremium/go-thalex/internal/paging"
 	"github.com/amiwrpremium/go-thalex/types"
 )
 
@@ -33,3 +35,63 @@ func (ws *Client) TransactionHistory(ctx context.Context, params *types.Transact
 	err := ws.call(ctx, "private/transaction_history", params, &result)
 	return result, err
 }
+
+// TradeHistoryAll iterates over the trade history matching params, fetching
+// pages as needed. See [types.PageOption] for the paging options.
+func (ws *Client) TradeHistoryAll(ctx context.Context, params *types.TradeHistoryParams, opts ...types.PageOption) iter.Seq2[types.Trade, error] {
+	var p types.TradeHistoryParams
+	if params != nil {
+		p = *params
+	}
+	q := paging.Query{From: paging.Timestamp(p.From), To: paging.Timestamp(p.To), Offset: p.Offset, Limit: p.Limit, Sort: p.Sort}
+	return paging.All(ctx, q, opts, func(ctx context.Context, from, to *types.Timestamp, offset, limit int) ([]types.Trade, error) {
+		p := p
+		p.From, p.To, p.Offset, p.Limit = paging.Seconds(from), paging.Seconds(to), &offset, &limit
+		return ws.TradeHistory(ctx, &p)
+	}, paging.TradeKey)
+}
+
+// OrderHistoryAll iterates over the order history matching params, fetching
+// pages as needed.
+func (ws *Client) OrderHistoryAll(ctx context.Context, params *types.OrderHistoryParams, opts ...types.PageOption) iter.Seq2[types.OrderHistory, error] {
+	var p types.OrderHistoryParams
+	if params != nil {
+		p = *params
+	}
+	q := paging.Query{From: paging.Timestamp(p.From), To: paging.Timestamp(p.To), Offset: p.Offset, Limit: p.Limit, Sort: p.Sort}
+	return paging.All(ctx, q, opts, func(ctx context.Context, from, to *types.Timestamp, offset, limit int) ([]types.OrderHistory, error) {
+		p := p
+		p.From, p.To, p.Offset, p.Limit = paging.Seconds(from), paging.Seconds(to), &offset, &limit
+		return ws.OrderHistory(ctx, &p)
+	}, paging.OrderKey)
+}
+
+// DailyMarkHistoryAll iterates over the daily mark history matching params,
+// fetching pages as needed.
+func (ws *Client) DailyMarkHistoryAll(ctx context.Context, params *types.DailyMarkHistoryParams, opts ...types.PageOption) iter.Seq2[types.DailyMark, error] {
+	var p types.DailyMarkHistoryParams
+	if params != nil {
+		p = *params
+	}
+	q := paging.Query{From: paging.Timestamp(p.From), To: paging.Timestamp(p.To), Offset: p.Offset, Limit: p.Limit}
+	return paging.All(ctx, q, opts, func(ctx context.Context, from, to *types.Timestamp, offset, limit int) ([]types.DailyMark, error) {
+		p := p
+		p.From, p.To, p.Offset, p.Limit = paging.Seconds(from), paging.Seconds(to), &offset, &limit
+		return ws.DailyMarkHistory(ctx, &p)
+	}, paging.DailyMarkKey)
+}
+
+// TransactionHistoryAll iterates over the transaction history matching
+// params, fetching pages as needed.
+func (ws *Client) TransactionHistoryAll(ctx context.Context, params *types.TransactionHistoryParams, opts ...types.PageOption) iter.Seq2[types.Transaction, error] {
+	var p types.TransactionHistoryParams
+	if params != nil {
+		p = *params
+	}
+	q := paging.Query{From: paging.Timestamp(p.From), To: paging.Timestamp(p.To), Offset: p.Offset, Limit: p.Limit, Sort: p.Sort}
+	return paging.All(ctx, q, opts, func(ctx context.Context, from, to *types.Timestamp, offset, limit int) ([]types.Transaction, error) {
+		p := p
+		p.From, p.To, p.Offset, p.Limit = paging.Seconds(from), paging.Seconds(to), &offset, &limit
+		return ws.TransactionHistory(ctx, &p)
+	}, paging.TransactionKey)
+}
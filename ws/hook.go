@@ -0,0 +1,36 @@
+package ws
+
+// Hook observes the traffic of a Client, for example to journal orders and
+// trades. Hooks run synchronously, so they should be quick. Notifications
+// are delivered concurrently, so a Hook must be safe for concurrent use.
+type Hook interface {
+	// OnRequest is called with the method and params of a request just
+	// before it is sent. Requests refused by the pre-trade check are never
+	// sent and not seen.
+	OnRequest(method string, params any)
+	// OnResult is called when a request completes. On success, result is
+	// the pointer the response was decoded into, or nil for methods
+	// without a result. On failure, err is the error returned to the
+	// caller.
+	OnResult(method string, params any, result any, err error)
+	// OnNotification is called with a decoded notification of a channel
+	// with a registered handler, before the handler. v has the type of
+	// the handler's argument, such as []types.OrderStatus.
+	OnNotification(channel string, v any)
+}
+
+// AddHook registers a hook. Like OnReconnectHandler, and unlike the other
+// handlers, hooks accumulate; they run in the order they were added.
+func (ws *Client) AddHook(h Hook) {
+	ws.subMu.Lock()
+	ws.hooks = append(ws.hooks, h)
+	ws.subMu.Unlock()
+}
+
+// currentHooks returns the registered hooks. The slice is never modified in
+// place, so callers may use it without holding the lock.
+func (ws *Client) currentHooks() []Hook {
+	ws.subMu.RLock()
+	defer ws.subMu.RUnlock()
+	return ws.hooks
+}
@@ -0,0 +1,128 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/amiwrpremium/go-thalex/apierr"
+	"github.com/amiwrpremium/go-thalex/internal/jsonrpc"
+	"github.com/amiwrpremium/go-thalex/types"
+)
+
+// recordingHook records every hook call as a line of text.
+type recordingHook struct {
+	name  string
+	mu    sync.Mutex
+	calls []string
+}
+
+func (h *recordingHook) OnRequest(method string, params any) {
+	h.add(fmt.Sprintf("%s request %s %T", h.name, method, params))
+}
+
+func (h *recordingHook) OnResult(method string, params any, result any, err error) {
+	h.add(fmt.Sprintf("%s result %s %T %v", h.name, method, result, err != nil))
+}
+
+func (h *recordingHook) OnNotification(channel string, v any) {
+	h.add(fmt.Sprintf("%s notification %s %T", h.name, channel, v))
+}
+
+func (h *recordingHook) add(s string) {
+	h.mu.Lock()
+	h.calls = append(h.calls, s)
+	h.mu.Unlock()
+}
+
+func (h *recordingHook) lines() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string(nil), h.calls...)
+}
+
+func TestHook_Requests(t *testing.T) {
+	handler := methodRouter(map[string]rpcHandler{
+		"private/insert": func(*jsonrpc.Request) (json.RawMessage, *jsonrpc.Error) {
+			return json.RawMessage(`{"order_id":"ord-1","status":"open"}`), nil
+		},
+		"private/cancel": func(*jsonrpc.Request) (json.RawMessage, *jsonrpc.Error) {
+			return nil, &jsonrpc.Error{Code: 1, Message: "order not found"}
+		},
+	})
+	c := newConnectedClient(t, handler)
+	a, b := &recordingHook{name: "a"}, &recordingHook{name: "b"}
+	c.AddHook(a)
+	c.AddHook(b)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := c.Insert(ctx, types.NewBuyOrderParams("BTC-PERPETUAL", 1)); err != nil {
+		t.Fatal(err)
+	}
+	_, err := c.Cancel(ctx, types.CancelByOrderID("ord-1"))
+	var apiErr *apierr.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Cancel error = %v", err)
+	}
+
+	want := []string{
+		"a request private/insert *types.InsertOrderParams",
+		"a result private/insert *types.OrderStatus false",
+		"a request private/cancel *types.CancelOrderParams",
+		"a result private/cancel <nil> true",
+	}
+	got := a.lines()
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("hook a saw\n%q\nwant\n%q", got, want)
+	}
+	if len(b.lines()) != len(want) {
+		t.Errorf("hook b saw %q", b.lines())
+	}
+}
+
+func TestHook_PreTradeCheckNotSeen(t *testing.T) {
+	c := NewClient()
+	c.cfg.PreTradeCheck = func(any) error { return errors.New("refused") }
+	h := &recordingHook{name: "h"}
+	c.AddHook(h)
+	if _, err := c.Insert(context.Background(), types.NewBuyOrderParams("BTC-PERPETUAL", 1)); err == nil {
+		t.Fatal("expected the pre-trade check to refuse")
+	}
+	if got := h.lines(); len(got) != 0 {
+		t.Errorf("hook saw %q", got)
+	}
+}
+
+func TestHook_Notifications(t *testing.T) {
+	c := NewClient()
+	h := &recordingHook{name: "h"}
+	c.AddHook(h)
+
+	var mu sync.Mutex
+	called := false
+	c.dispatchNotification("account.orders", func([]types.OrderStatus) {
+		mu.Lock()
+		called = true
+		mu.Unlock()
+	}, json.RawMessage(`[{"order_id":"ord-1"}]`))
+	if !waitForBool(&mu, &called, 2*time.Second) {
+		t.Fatal("handler not called")
+	}
+	if got := h.lines(); len(got) != 1 || got[0] != "h notification account.orders []types.OrderStatus" {
+		t.Errorf("hook saw %q", got)
+	}
+
+	// Undecodable notifications reach neither the hook nor the handler.
+	c.dispatchNotification("account.orders", func([]types.OrderStatus) {
+		t.Error("handler called for a bad payload")
+	}, json.RawMessage(`{`))
+	time.Sleep(50 * time.Millisecond)
+	if got := h.lines(); len(got) != 1 {
+		t.Errorf("hook saw %q after a bad payload", got)
+	}
+}
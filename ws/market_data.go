@@ -2,6 +2,7 @@ package ws
 
 import (
 	"context"
+	"time"
 
 	"github.com/amiwrpremium/go-thalex/enums"
 	"github.com/amiwrpremium/go-thalex/types"
@@ -87,3 +88,15 @@ func (ws *Client) IndexPriceHistoricalData(ctx context.Context, indexName string
 	}, &result)
 	return result, err
 }
+
+// MarkPriceHistoricalDataBetween is [Client.MarkPriceHistoricalData] with a
+// time range instead of epoch seconds.
+func (ws *Client) MarkPriceHistoricalDataBetween(ctx context.Context, instrumentName string, from, to time.Time, resolution enums.Resolution) (types.MarkPriceHistoricalResult, error) {
+	return ws.MarkPriceHistoricalData(ctx, instrumentName, types.NewTimestamp(from).Seconds(), types.NewTimestamp(to).Seconds(), resolution)
+}
+
+// IndexPriceHistoricalDataBetween is [Client.IndexPriceHistoricalData] with a
+// time range instead of epoch seconds.
+func (ws *Client) IndexPriceHistoricalDataBetween(ctx context.Context, indexName string, from, to time.Time, resolution enums.Resolution) (types.IndexPriceHistoricalResult, error) {
+	return ws.IndexPriceHistoricalData(ctx, indexName, types.NewTimestamp(from).Seconds(), types.NewTimestamp(to).Seconds(), resolution)
+}
@@ -9,6 +9,9 @@ import (
 // MassQuote sends a mass quote (WebSocket-only).
 func (ws *Client) MassQuote(ctx context.Context, params *types.MassQuoteParams) (types.DoubleSidedQuoteResult, error) {
 	var result types.DoubleSidedQuoteResult
+	if err := ws.preTradeCheck(ctx, params); err != nil {
+		return result, err
+	}
 	err := ws.call(ctx, "private/mass_quote", params, &result)
 	return result, err
 }
@@ -2,7 +2,9 @@ package ws
 
 import (
 	"context"
+	"iter"
 
+	"github.com/amiwrpremium/go-thalex/internal/paging"
 	"github.com/amiwrpremium/go-thalex/types"
 )
 
@@ -54,6 +56,15 @@ func (ws *Client) RfqHistory(ctx context.Context, from, to *float64, offset, lim
 	return result, err
 }
 
+// RfqHistoryAll iterates over historical RFQs in [from, to], fetching
+// pages as needed.
+func (ws *Client) RfqHistoryAll(ctx context.Context, from, to *float64, opts ...types.PageOption) iter.Seq2[types.Rfq, error] {
+	q := paging.Query{From: paging.Timestamp(from), To: paging.Timestamp(to)}
+	return paging.All(ctx, q, opts, func(ctx context.Context, from, to *types.Timestamp, offset, limit int) ([]types.Rfq, error) {
+		return ws.RfqHistory(ctx, paging.Seconds(from), paging.Seconds(to), &offset, &limit)
+	}, paging.RfqKey)
+}
+
 // MMRfqs retrieves market maker RFQ opportunities via WebSocket.
 func (ws *Client) MMRfqs(ctx context.Context) ([]types.Rfq, error) {
 	var result []types.Rfq
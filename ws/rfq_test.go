@@ -3,6 +3,7 @@ package ws
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"testing"
 	"time"
 
@@ -278,3 +279,38 @@ func TestMMRfqQuotes_Success(t *testing.T) {
 		t.Fatalf("expected 1 quote, got %d", len(quotes))
 	}
 }
+
+func TestRfqHistoryAll_Pages(t *testing.T) {
+	var offsets []float64
+	handler := func(req *jsonrpc.Request) (json.RawMessage, *jsonrpc.Error) {
+		p, _ := req.Params.(map[string]any)
+		if p["from"] != 1.0 || p["limit"] != 1.0 {
+			return nil, &jsonrpc.Error{Code: -32602, Message: fmt.Sprintf("unexpected params: %v", p)}
+		}
+		offset, _ := p["offset"].(float64)
+		offsets = append(offsets, offset)
+		if offset < 2 {
+			return json.RawMessage(fmt.Sprintf(`[{"rfq_id":"rfq-%d"}]`, int(offset))), nil
+		}
+		return json.RawMessage(`[]`), nil
+	}
+	c := newConnectedClient(t, handler)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	from := 1.0
+	var ids []string
+	for rfq, err := range c.RfqHistoryAll(ctx, &from, nil, types.WithPageSize(1)) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		ids = append(ids, rfq.RfqID)
+	}
+	if len(ids) != 2 || ids[0] != "rfq-0" || ids[1] != "rfq-1" {
+		t.Errorf("rfqs = %v", ids)
+	}
+	if len(offsets) != 3 {
+		t.Errorf("made %d calls, want 3", len(offsets))
+	}
+}
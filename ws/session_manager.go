@@ -0,0 +1,120 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/amiwrpremium/go-thalex/config"
+	"github.com/amiwrpremium/go-thalex/internal/account"
+	"github.com/amiwrpremium/go-thalex/types"
+)
+
+// SessionManager keeps one WebSocket connection per account behind a single API.
+//
+// A Thalex WebSocket session is bound to one account at login, so per-call
+// account overrides are not possible on a single [Client]. SessionManager
+// instead owns a Client for each account and selects one by account number,
+// either explicitly via [SessionManager.Client] or from a context built with
+// thalex.WithAccount via [SessionManager.ForContext].
+type SessionManager struct {
+	accounts []string
+	clients  map[string]*Client
+}
+
+// NewSessionManager creates a session manager with one client per account.
+// The options are applied to every client, followed by the account number.
+func NewSessionManager(accounts []string, opts ...config.ClientOption) *SessionManager {
+	m := &SessionManager{
+		accounts: make([]string, 0, len(accounts)),
+		clients:  make(map[string]*Client, len(accounts)),
+	}
+	for _, acct := range accounts {
+		if _, dup := m.clients[acct]; dup {
+			continue
+		}
+		clientOpts := make([]config.ClientOption, 0, len(opts)+1)
+		clientOpts = append(clientOpts, opts...)
+		clientOpts = append(clientOpts, config.WithAccountNumber(acct))
+		m.accounts = append(m.accounts, acct)
+		m.clients[acct] = NewClient(clientOpts...)
+	}
+	return m
+}
+
+// Connect connects every session concurrently and, when credentials are
+// configured, logs each one in to its account. Sessions that connected
+// successfully stay open even if others fail.
+func (m *SessionManager) Connect(ctx context.Context) error {
+	_, err := account.FanOut(ctx, m.accounts, func(ctx context.Context, acct string) (struct{}, error) {
+		c := m.clients[acct]
+		if err := c.Connect(ctx); err != nil {
+			return struct{}{}, err
+		}
+		if c.cfg.Credentials != nil {
+			if err := c.Login(ctx); err != nil {
+				return struct{}{}, err
+			}
+		}
+		return struct{}{}, nil
+	})
+	return err
+}
+
+// Close closes every session.
+func (m *SessionManager) Close() error {
+	var errs []error
+	for _, acct := range m.accounts {
+		if err := m.clients[acct].Close(); err != nil {
+			errs = append(errs, fmt.Errorf("account %s: %w", acct, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Accounts returns the managed account numbers in the order they were given.
+func (m *SessionManager) Accounts() []string {
+	out := make([]string, len(m.accounts))
+	copy(out, m.accounts)
+	return out
+}
+
+// Client returns the session for the given account.
+func (m *SessionManager) Client(accountNumber string) (*Client, error) {
+	c, ok := m.clients[accountNumber]
+	if !ok {
+		return nil, fmt.Errorf("no session for account %q", accountNumber)
+	}
+	return c, nil
+}
+
+// ForContext returns the session for the account carried by ctx. If ctx
+// carries no account, the first managed account is used.
+func (m *SessionManager) ForContext(ctx context.Context) (*Client, error) {
+	if acct, ok := account.FromContext(ctx); ok {
+		return m.Client(acct)
+	}
+	if len(m.accounts) == 0 {
+		return nil, fmt.Errorf("no sessions configured")
+	}
+	return m.clients[m.accounts[0]], nil
+}
+
+// AccountSummaries retrieves the account summary of every session
+// concurrently. Results are keyed by account number; accounts whose request
+// failed are omitted and their errors joined into the returned error.
+// Use [types.MergeAccountSummaries] to aggregate the results.
+func (m *SessionManager) AccountSummaries(ctx context.Context) (map[string]types.AccountSummary, error) {
+	return account.FanOut(ctx, m.accounts, func(ctx context.Context, acct string) (types.AccountSummary, error) {
+		return m.clients[acct].AccountSummary(ctx)
+	})
+}
+
+// Portfolios retrieves the portfolio of every session concurrently. Results
+// are keyed by account number; accounts whose request failed are omitted and
+// their errors joined into the returned error.
+func (m *SessionManager) Portfolios(ctx context.Context) (map[string][]types.PortfolioEntry, error) {
+	return account.FanOut(ctx, m.accounts, func(ctx context.Context, acct string) ([]types.PortfolioEntry, error) {
+		return m.clients[acct].Portfolio(ctx)
+	})
+}
@@ -0,0 +1,141 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/amiwrpremium/go-thalex/config"
+	"github.com/amiwrpremium/go-thalex/internal/account"
+	"github.com/amiwrpremium/go-thalex/internal/jsonrpc"
+	"github.com/amiwrpremium/go-thalex/internal/transport"
+	"github.com/amiwrpremium/go-thalex/types"
+)
+
+// newTestSessionManager builds a SessionManager whose clients each point at
+// their own mock server, returning the given account summary margin.
+func newTestSessionManager(t *testing.T, margins map[string]float64, accounts ...string) *SessionManager {
+	t.Helper()
+	m := &SessionManager{clients: make(map[string]*Client)}
+	for _, acct := range accounts {
+		margin := margins[acct]
+		srv := newMockWSServer(t, methodRouter(map[string]rpcHandler{
+			"private/account_summary": func(_ *jsonrpc.Request) (json.RawMessage, *jsonrpc.Error) {
+				data, _ := json.Marshal(types.AccountSummary{Margin: margin})
+				return data, nil
+			},
+		}))
+		cfg := config.DefaultClientConfig()
+		cfg.AccountNumber = acct
+		c := &Client{
+			cfg:      cfg,
+			pending:  make(map[uint64]*pendingCall),
+			handlers: make(map[string]any),
+		}
+		c.transport = transport.NewWSTransport(transport.WSTransportConfig{
+			URL:          wsURLFromHTTP(srv.URL),
+			PingInterval: 60 * time.Second,
+			Handler:      c,
+		})
+		m.accounts = append(m.accounts, acct)
+		m.clients[acct] = c
+	}
+	t.Cleanup(func() { _ = m.Close() })
+	return m
+}
+
+func TestNewSessionManager(t *testing.T) {
+	m := NewSessionManager([]string{"A1", "A2", "A1"}, config.WithNetwork(config.Testnet))
+
+	accts := m.Accounts()
+	if len(accts) != 2 || accts[0] != "A1" || accts[1] != "A2" {
+		t.Fatalf("Accounts() = %v, want [A1 A2]", accts)
+	}
+	for _, acct := range accts {
+		c, err := m.Client(acct)
+		if err != nil {
+			t.Fatalf("Client(%q) error: %v", acct, err)
+		}
+		if c.cfg.AccountNumber != acct {
+			t.Errorf("client AccountNumber = %q, want %q", c.cfg.AccountNumber, acct)
+		}
+		if c.cfg.Network != config.Testnet {
+			t.Errorf("client Network = %v, want Testnet", c.cfg.Network)
+		}
+	}
+}
+
+func TestSessionManager_Client_Unknown(t *testing.T) {
+	m := NewSessionManager([]string{"A1"})
+	if _, err := m.Client("nope"); err == nil {
+		t.Fatal("expected error for unknown account")
+	}
+}
+
+func TestSessionManager_ForContext(t *testing.T) {
+	m := NewSessionManager([]string{"A1", "A2"})
+
+	t.Run("uses context account", func(t *testing.T) {
+		c, err := m.ForContext(account.WithAccount(context.Background(), "A2"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if c.cfg.AccountNumber != "A2" {
+			t.Errorf("AccountNumber = %q, want A2", c.cfg.AccountNumber)
+		}
+	})
+
+	t.Run("defaults to first account", func(t *testing.T) {
+		c, err := m.ForContext(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if c.cfg.AccountNumber != "A1" {
+			t.Errorf("AccountNumber = %q, want A1", c.cfg.AccountNumber)
+		}
+	})
+
+	t.Run("no sessions", func(t *testing.T) {
+		if _, err := NewSessionManager(nil).ForContext(context.Background()); err == nil {
+			t.Fatal("expected error with no sessions")
+		}
+	})
+}
+
+func TestSessionManager_ConnectAndAccountSummaries(t *testing.T) {
+	m := newTestSessionManager(t, map[string]float64{"A1": 100, "A2": 250}, "A1", "A2")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := m.Connect(ctx); err != nil {
+		t.Fatalf("Connect error: %v", err)
+	}
+	for _, acct := range m.Accounts() {
+		c, _ := m.Client(acct)
+		if !c.IsConnected() {
+			t.Errorf("session %s not connected", acct)
+		}
+	}
+
+	summaries, err := m.AccountSummaries(ctx)
+	if err != nil {
+		t.Fatalf("AccountSummaries error: %v", err)
+	}
+	if summaries["A1"].Margin != 100 || summaries["A2"].Margin != 250 {
+		t.Errorf("unexpected summaries: %+v", summaries)
+	}
+	if merged := types.MergeAccountSummaries(summaries["A1"], summaries["A2"]); merged.Margin != 350 {
+		t.Errorf("merged margin = %v, want 350", merged.Margin)
+	}
+
+	// Portfolio is not routed by the mock, so every account reports an error.
+	if _, err := m.Portfolios(ctx); err == nil {
+		t.Error("expected Portfolios error from mock servers")
+	}
+
+	if err := m.Close(); err != nil {
+		t.Fatalf("Close error: %v", err)
+	}
+}
@@ -3,12 +3,16 @@ package ws
 import (
 	"context"
 
+	"github.com/amiwrpremium/go-thalex/enums"
 	"github.com/amiwrpremium/go-thalex/types"
 )
 
 // Insert places a new order via WebSocket.
 func (ws *Client) Insert(ctx context.Context, params *types.InsertOrderParams) (types.OrderStatus, error) {
 	var result types.OrderStatus
+	if err := ws.preTradeCheck(ctx, params); err != nil {
+		return result, err
+	}
 	err := ws.call(ctx, "private/insert", params, &result)
 	return result, err
 }
@@ -16,9 +20,13 @@ func (ws *Client) Insert(ctx context.Context, params *types.InsertOrderParams) (
 // Buy places a market buy order via WebSocket.
 func (ws *Client) Buy(ctx context.Context, instrumentName string, amount float64) (types.OrderStatus, error) {
 	var result types.OrderStatus
+	params := types.NewBuyOrderParams(instrumentName, amount).WithOrderType(enums.OrderTypeMarket)
+	if err := ws.preTradeCheck(ctx, params); err != nil {
+		return result, err
+	}
 	err := ws.call(ctx, "private/buy", map[string]any{
-		"instrument_name": instrumentName,
-		"amount":          amount,
+		"instrument_name": params.InstrumentName,
+		"amount":          params.Amount,
 	}, &result)
 	return result, err
 }
@@ -26,9 +34,13 @@ func (ws *Client) Buy(ctx context.Context, instrumentName string, amount float64
 // Sell places a market sell order via WebSocket.
 func (ws *Client) Sell(ctx context.Context, instrumentName string, amount float64) (types.OrderStatus, error) {
 	var result types.OrderStatus
+	params := types.NewSellOrderParams(instrumentName, amount).WithOrderType(enums.OrderTypeMarket)
+	if err := ws.preTradeCheck(ctx, params); err != nil {
+		return result, err
+	}
 	err := ws.call(ctx, "private/sell", map[string]any{
-		"instrument_name": instrumentName,
-		"amount":          amount,
+		"instrument_name": params.InstrumentName,
+		"amount":          params.Amount,
 	}, &result)
 	return result, err
 }
@@ -36,6 +48,9 @@ func (ws *Client) Sell(ctx context.Context, instrumentName string, amount float6
 // Amend modifies an existing order via WebSocket.
 func (ws *Client) Amend(ctx context.Context, params *types.AmendOrderParams) (types.OrderStatus, error) {
 	var result types.OrderStatus
+	if err := ws.preTradeCheck(ctx, params); err != nil {
+		return result, err
+	}
 	err := ws.call(ctx, "private/amend", params, &result)
 	return result, err
 }
@@ -252,3 +252,34 @@ func TestOpenOrders_EmptyInstrument(t *testing.T) {
 		t.Errorf("expected 0 orders, got %d", len(orders))
 	}
 }
+
+func TestInsert_PreTradeCheck(t *testing.T) {
+	sent := make(chan string, 4)
+	handler := func(req *jsonrpc.Request) (json.RawMessage, *jsonrpc.Error) {
+		sent <- req.Method
+		return json.RawMessage(`{"order_id":"ord-1"}`), nil
+	}
+	c := newConnectedClient(t, handler)
+	lookup := func(name string) (types.Instrument, bool) {
+		return types.Instrument{InstrumentName: name, TickSize: 0.5, VolumeTickSize: 0.001}, name == "BTC-PERPETUAL"
+	}
+	c.cfg.PreTradeCheck = types.NewValidator(lookup).Validate
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := c.Insert(ctx, types.NewSellOrderParams("ETH-PERPETUAL", 1)); !errors.Is(err, types.ErrUnknownInstrument) {
+		t.Errorf("Insert error = %v, want ErrUnknownInstrument", err)
+	}
+	if _, err := c.MassQuote(ctx, types.NewMassQuoteParams([]types.DoubleSidedQuote{
+		types.NewSingleLevelQuote("BTC-PERPETUAL", 95000.3, 0.01, 95001, 0.01),
+	})); !errors.Is(err, types.ErrOffTick) {
+		t.Errorf("MassQuote error = %v, want ErrOffTick", err)
+	}
+	if _, err := c.Insert(ctx, types.NewSellOrderParams("BTC-PERPETUAL", 0.01).WithPrice(95000.5)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m := <-sent; m != "private/insert" {
+		t.Errorf("first request sent = %s, want only the valid insert", m)
+	}
+}